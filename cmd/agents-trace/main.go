@@ -0,0 +1,57 @@
+// Command agents-trace renders or replays run traces written by
+// tracing.FileTracer.
+//
+// Usage:
+//
+//	agents-trace [-replay] [-trace id] trace.ndjson
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/tracing"
+)
+
+func main() {
+	replay := flag.Bool("replay", false, "replay the conversation instead of rendering the span tree")
+	traceID := flag.String("trace", "", "only show the trace with this ID")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [-replay] [-trace id] trace.ndjson\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	spans, err := tracing.LoadTrace(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for _, trace := range tracing.Traces(spans) {
+		if *traceID != "" && trace[0].TraceID != *traceID {
+			continue
+		}
+
+		if *replay {
+			tracing.Replay(os.Stdout, trace)
+		} else {
+			fmt.Printf("trace %s\n", trace[0].TraceID)
+			tracing.Render(os.Stdout, trace)
+		}
+		fmt.Println()
+	}
+}
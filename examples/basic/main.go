@@ -39,87 +39,45 @@ func main() {
 		log.Fatal("Failed to create greet tool:", err)
 	}
 
-	// Create agents for different providers
-	openaiAgent := agents.NewAgent("OpenAI Assistant",
-		agents.WithInstructions(`System: Role and Objective:
-- Serve as a helpful assistant powered by OpenAI with mathematical and greeting capabilities.
-
-Instructions:
-- Use the available tools to perform calculations and generate greetings as requested.
-- For mathematical operations, always use the 'add' tool for accurate results.
-- For greeting requests, use the 'greet' tool to generate personalized messages.
-- Provide clear, concise responses that directly address the user's request.
-
-Available Tools:
-- 'add': Performs addition of two integer numbers. Call this when the user asks for addition or sum calculations.
-- 'greet': Generates a personalized greeting. Call this when the user wants to greet someone or requests a welcome message.
-
-Process:
-1. Analyze the user's request to determine which tool(s) to use.
-2. Execute the appropriate tool with the correct parameters.
-3. Present the result in a clear, friendly manner.`),
-		agents.WithModel(openai.ChatModelChatgpt4oLatest),
-		agents.WithTools(addTool, greetTool),
-		agents.WithTemperature(0.7),
-	)
-
-	anthropicAgent := agents.NewAgent("Anthropic Assistant",
-		agents.WithInstructions(`System: Role and Objective:
-- Serve as a helpful assistant powered by Anthropic Claude with mathematical and greeting capabilities.
-
-Instructions:
-- Use the available tools to perform calculations and generate greetings as requested.
-- For mathematical operations, always use the 'add' tool for accurate results.
-- For greeting requests, use the 'greet' tool to generate personalized messages.
-- Provide clear, concise responses that directly address the user's request.
-
-Available Tools:
-- 'add': Performs addition of two integer numbers. Call this when the user asks for addition or sum calculations.
-- 'greet': Generates a personalized greeting. Call this when the user wants to greet someone or requests a welcome message.
+	// Build one agent from instruction sections and derive a variant per
+	// provider; only the model and the role section differ
+	role := "Serve as a helpful assistant powered by %s with mathematical and greeting capabilities."
+
+	openaiAgent, err := agents.Builder().
+		Name("OpenAI Assistant").
+		Section(agents.SectionRole, fmt.Sprintf(role, "OpenAI")).
+		Section("instructions",
+			"- Use the available tools to perform calculations and generate greetings as requested.",
+			"- For mathematical operations, always use the 'add' tool for accurate results.",
+			"- For greeting requests, use the 'greet' tool to generate personalized messages.",
+			"- Provide clear, concise responses that directly address the user's request.").
+		Section(agents.SectionToolProtocol,
+			"- 'add': Performs addition of two integer numbers. Call this when the user asks for addition or sum calculations.",
+			"- 'greet': Generates a personalized greeting. Call this when the user wants to greet someone or requests a welcome message.").
+		Section(agents.SectionProcess,
+			"1. Analyze the user's request to determine which tool(s) to use.",
+			"2. Execute the appropriate tool with the correct parameters.",
+			"3. Present the result in a clear, friendly manner.").
+		Model(openai.ChatModelChatgpt4oLatest).
+		Tools(addTool, greetTool).
+		Temperature(0.7).
+		Build()
+	if err != nil {
+		log.Fatal("OpenAI agent validation failed:", err)
+	}
 
-Process:
-1. Analyze the user's request to determine which tool(s) to use.
-2. Execute the appropriate tool with the correct parameters.
-3. Present the result in a clear, friendly manner.`),
+	anthropicAgent := openaiAgent.With(
+		agents.WithName("Anthropic Assistant"),
+		agents.WithInstructionSections(agents.Section(agents.SectionRole, fmt.Sprintf(role, "Anthropic Claude"))),
 		agents.WithModel(string(anthropic.ModelClaude4Sonnet20250514)),
-		agents.WithTools(addTool, greetTool),
-		agents.WithTemperature(0.7),
 	)
 
-	geminiAgent := agents.NewAgent("Gemini Assistant",
-		agents.WithInstructions(`System: Role and Objective:
-- Serve as a helpful assistant powered by Google Gemini with mathematical and greeting capabilities.
-
-Instructions:
-- Use the available tools to perform calculations and generate greetings as requested.
-- For mathematical operations, always use the 'add' tool for accurate results.
-- For greeting requests, use the 'greet' tool to generate personalized messages.
-- Provide clear, concise responses that directly address the user's request.
-
-Available Tools:
-- 'add': Performs addition of two integer numbers. Call this when the user asks for addition or sum calculations.
-- 'greet': Generates a personalized greeting. Call this when the user wants to greet someone or requests a welcome message.
-
-Process:
-1. Analyze the user's request to determine which tool(s) to use.
-2. Execute the appropriate tool with the correct parameters.
-3. Present the result in a clear, friendly manner.`),
+	geminiAgent := openaiAgent.With(
+		agents.WithName("Gemini Assistant"),
+		agents.WithInstructionSections(agents.Section(agents.SectionRole, fmt.Sprintf(role, "Google Gemini"))),
 		agents.WithModel("gemini-2.0-flash"),
-		agents.WithTools(addTool, greetTool),
-		agents.WithTemperature(0.7),
 	)
 
-	// Validate agents
-	if err := openaiAgent.Validate(); err != nil {
-		log.Fatal("OpenAI agent validation failed:", err)
-	}
-	if err := anthropicAgent.Validate(); err != nil {
-		log.Fatal("Anthropic agent validation failed:", err)
-	}
-	if err := geminiAgent.Validate(); err != nil {
-		log.Fatal("Gemini agent validation failed:", err)
-	}
-
 	// Test input
 	input := "Hello! Can you add 5 and 3 for me?"
 	ctx := context.Background()
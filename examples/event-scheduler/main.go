@@ -1,18 +1,17 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"log"
-	"os"
-	"strings"
 
 	localagents "event-scheduler/agents"
 	"event-scheduler/db"
 
 	"github.com/ryanhill4L/agents-sdk/pkg/agents"
+	"github.com/ryanhill4L/agents-sdk/pkg/memory"
 	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+	"github.com/ryanhill4L/agents-sdk/pkg/repl"
 	"github.com/ryanhill4L/agents-sdk/pkg/tracing"
 )
 
@@ -43,63 +42,38 @@ func main() {
 		log.Fatal("Please set the ANTHROPIC_API_KEY environment variable")
 	}
 
+	// Store conversations next to the events so follow-up questions have context
+	sessions, err := memory.NewSQLiteStore("./sessions.db")
+	if err != nil {
+		log.Fatal("Failed to open session store:", err)
+	}
+	defer sessions.Close()
+
 	// Create the runner
 	runner := agents.NewRunner(
 		agents.WithProvider(provider),
 		agents.WithTracer(tracing.NewConsoleTracer()),
 		agents.WithMaxTurns(10),
+		agents.WithSessionStore(sessions),
 	)
 
 	// Interactive loop
-	fmt.Println("🗓️  Event Scheduling Assistant Ready!")
-	fmt.Println("=====================================")
-	fmt.Println("Try asking:")
-	fmt.Println("  - 'Show me all scheduled events'")
-	fmt.Println("  - 'Find users with scheduling conflicts'")
-	fmt.Println("  - 'Check for venue booking conflicts'")
-	fmt.Println("  - 'What events is Alice attending?'")
-	fmt.Println("  - 'List all events at the Conference Room'")
-	fmt.Println("  - 'Show me events happening tomorrow'")
-	fmt.Println("\nType 'exit' to quit")
-
-	scanner := bufio.NewScanner(os.Stdin)
-
-	for {
-		fmt.Print("> ")
-		if !scanner.Scan() {
-			break
-		}
-
-		input := strings.TrimSpace(scanner.Text())
-
-		if strings.ToLower(input) == "exit" || strings.ToLower(input) == "quit" {
-			break
-		}
-
-		if input == "" {
-			continue
-		}
-
-		// Run the agent
-		ctx := context.Background()
-		result, err := runner.Run(ctx, schedulerAgent, input)
-		if err != nil {
-			log.Printf("❌ Error: %v\n", err)
-			continue
-		}
-
-		fmt.Printf("\n🤖 %s\n\n", result.FinalOutput)
-
-		// Show some metrics
-		fmt.Printf("📊 Metrics: %d turns, %d tool calls, %d handoffs, %d tokens, %v duration\n",
-			result.Metrics.TotalTurns,
-			result.Metrics.ToolCalls,
-			result.Metrics.Handoffs,
-			result.Metrics.TotalTokens,
-			result.Metrics.Duration)
-	}
+	session := repl.New(runner, schedulerAgent,
+		repl.WithBanner(`🗓️  Event Scheduling Assistant Ready!
+=====================================
+Try asking:
+  - 'Show me all scheduled events'
+  - 'Find users with scheduling conflicts'
+  - 'Check for venue booking conflicts'
+  - 'What events is Alice attending?'
+  - 'List all events at the Conference Room'
+  - 'Show me events happening tomorrow'
+
+Type /help for commands or 'exit' to quit`),
+		repl.WithTrace(true),
+	)
 
-	if err := scanner.Err(); err != nil {
+	if err := session.Run(context.Background()); err != nil {
 		log.Printf("Error reading input: %v", err)
 	}
 
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -75,7 +74,7 @@ func main() {
 		fmt.Println("Using mock responses for demonstration...")
 
 		// Use a mock provider that returns structured JSON
-		provider = &MockProvider{}
+		provider = newMockProvider()
 		providerName = "Mock"
 	}
 
@@ -215,7 +214,7 @@ Process Checklist:
 		} else {
 			fmt.Printf("✅ Parsed Product Review:\n")
 			fmt.Printf("   Product: %s\n", review.ProductName)
-			fmt.Printf("   Rating: %d/5\n", review.Rating)
+			fmt.Printf("   Rating: %.1f/5\n", review.Rating)
 			fmt.Printf("   Pros: %v\n", review.Pros)
 			fmt.Printf("   Cons: %v\n", review.Cons)
 			fmt.Printf("   Summary: %s\n", review.Summary)
@@ -242,54 +241,20 @@ func runJSONAgent(ctx context.Context, provider providers.Provider, agent *agent
 	return runner.Run(ctx, agent, input)
 }
 
-// parseJSONResponse attempts to parse the JSON response into the target struct
+// parseJSONResponse parses the JSON response into the target struct,
+// repairing code fences, trailing commas and mistyped values such as a
+// rating written as a string
 func parseJSONResponse(response string, target interface{}) error {
-	// Clean the response - remove any markdown formatting
-	cleaned := cleanJSONResponse(response)
-
-	return json.Unmarshal([]byte(cleaned), target)
-}
-
-// cleanJSONResponse removes common formatting issues from LLM JSON responses
-func cleanJSONResponse(response string) string {
-	// Remove markdown code blocks if present
-	if len(response) > 6 && response[:3] == "```" {
-		// Find the first newline after ```
-		start := 3
-		for i := 3; i < len(response); i++ {
-			if response[i] == '\n' {
-				start = i + 1
-				break
-			}
-		}
-
-		// Find the ending ```
-		end := len(response)
-		for i := len(response) - 3; i >= 0; i-- {
-			if i+2 < len(response) && response[i:i+3] == "```" {
-				end = i
-				break
-			}
-		}
-
-		if start < end {
-			response = response[start:end]
-		}
-	}
-
-	return response
+	return agents.DecodeJSON(response, target)
 }
 
-// MockProvider provides sample JSON responses for demonstration
-type MockProvider struct{}
-
-func (m *MockProvider) Complete(ctx context.Context, agent providers.Agent, messages []providers.Message, tools []providers.ToolDefinition) (*providers.Completion, error) {
-	// Return different responses based on agent instructions
-	instructions := agent.GetInstructions()
-
-	var response string
-	if contains(instructions, "person") || contains(instructions, "Person") {
-		response = `{
+// newMockProvider provides sample JSON responses for demonstration
+func newMockProvider() providers.Provider {
+	return providers.NewScriptedProvider().WithResponder(func(req providers.ScriptedRequest) (*providers.Completion, error) {
+		// Return different responses based on agent instructions
+		var response string
+		if contains(req.Instructions, "person") || contains(req.Instructions, "Person") {
+			response = `{
   "name": "Albert Einstein", 
   "age": 76,
   "occupation": "Theoretical Physicist",
@@ -298,8 +263,8 @@ func (m *MockProvider) Complete(ctx context.Context, agent providers.Agent, mess
   "biography": "German-born theoretical physicist who developed the theory of relativity",
   "achievement": "Nobel Prize in Physics (1921) for photoelectric effect"
 }`
-	} else {
-		response = `{
+		} else {
+			response = `{
   "product_name": "iPhone 15 Pro",
   "rating": 4,
   "pros": ["Excellent camera quality", "Fast performance", "Premium build quality", "Good battery life"],
@@ -307,19 +272,20 @@ func (m *MockProvider) Complete(ctx context.Context, agent providers.Agent, mess
   "summary": "A high-quality flagship smartphone with excellent features but at a premium price point",
   "recommended": true
 }`
-	}
+		}
 
-	return &providers.Completion{
-		Message: providers.Message{
-			Role:    "assistant",
-			Content: response,
-		},
-		Usage: providers.Usage{
-			PromptTokens:     50,
-			CompletionTokens: 100,
-			TotalTokens:      150,
-		},
-	}, nil
+		return &providers.Completion{
+			Message: providers.Message{
+				Role:    "assistant",
+				Content: response,
+			},
+			Usage: providers.Usage{
+				PromptTokens:     50,
+				CompletionTokens: 100,
+				TotalTokens:      150,
+			},
+		}, nil
+	})
 }
 
 // Helper function to check if string contains substring (case insensitive)
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/ryanhill4L/agents-sdk/pkg/guardrails"
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
 	"github.com/ryanhill4L/agents-sdk/pkg/tools"
 )
 
@@ -17,17 +18,42 @@ type Agent struct {
 	Instructions string
 	Model        string
 
+	// Sections are named parts of the instructions, assembled after
+	// Instructions in a provider-specific layout
+	Sections []InstructionSection
+
 	// Capabilities
 	Tools      []tools.Tool
 	Handoffs   []*Agent
 	Guardrails []guardrails.Guardrail
 
+	// HandoffDescription tells agents that can hand off to this one what it
+	// handles; it describes the transfer tool they're given
+	HandoffDescription string
+
+	// HandoffInputFilters shape the history this agent receives when another
+	// agent hands off to it, applied in order
+	HandoffInputFilters []HandoffInputFilter
+
 	// Configuration
 	OutputType  OutputSchema
 	Temperature float32
 	MaxTokens   int
 	TopP        float32
 
+	// ParallelToolCalls controls whether the provider may return several tool
+	// calls per turn (nil keeps the provider default). This is independent of
+	// the runner's local parallel execution of returned calls.
+	ParallelToolCalls *bool
+
+	// ToolProtocol selects native function calling or a text protocol that
+	// emulates it for models without tool support
+	ToolProtocol ToolProtocol
+
+	// Scopes are the permissions granted to the agent; tools declaring
+	// required scopes can only be called when all of them are granted
+	Scopes []string
+
 	// Runtime
 	handoffMap map[string]*Agent
 }
@@ -48,13 +74,22 @@ func NewAgent(name string, opts ...AgentOption) *Agent {
 	}
 
 	// Build handoff map for quick lookup
-	for _, handoff := range agent.Handoffs {
-		agent.handoffMap[handoff.Name] = handoff
-	}
+	agent.indexHandoffs()
 
 	return agent
 }
 
+// indexHandoffs rebuilds the handoff lookup map; nil targets are left for
+// Validate to report
+func (a *Agent) indexHandoffs() {
+	a.handoffMap = make(map[string]*Agent, len(a.Handoffs))
+	for _, handoff := range a.Handoffs {
+		if handoff != nil {
+			a.handoffMap[handoff.Name] = handoff
+		}
+	}
+}
+
 // Validate checks if the agent configuration is valid
 func (a *Agent) Validate() error {
 	if a.Name == "" {
@@ -65,10 +100,15 @@ func (a *Agent) Validate() error {
 		return ErrInvalidModel
 	}
 
-	// Validate tools
+	if err := validateParameters(a.Temperature, a.TopP, a.MaxTokens); err != nil {
+		return err
+	}
+
+	// Validate tools; calls are routed by name, so names must be unique
+	names := make(map[string]bool, len(a.Tools))
 	for _, tool := range a.Tools {
-		if err := tool.Validate(); err != nil {
-			return fmt.Errorf("invalid tool %s: %w", tool.Name(), err)
+		if err := validateTool(tool, names); err != nil {
+			return err
 		}
 	}
 
@@ -80,6 +120,37 @@ func (a *Agent) Validate() error {
 	return nil
 }
 
+// validateParameters checks the sampling parameters against the range every
+// provider accepts
+func validateParameters(temperature, topP float32, maxTokens int) error {
+	if temperature < 0 || temperature > 2 {
+		return fmt.Errorf("%w: temperature %g outside 0-2", ErrInvalidParameter, temperature)
+	}
+	if topP < 0 || topP > 1 {
+		return fmt.Errorf("%w: top_p %g outside 0-1", ErrInvalidParameter, topP)
+	}
+	if maxTokens < 0 {
+		return fmt.Errorf("%w: max tokens %d is negative", ErrInvalidParameter, maxTokens)
+	}
+	return nil
+}
+
+// validateTool checks a tool and that its name isn't already in names,
+// recording it
+func validateTool(tool tools.Tool, names map[string]bool) error {
+	if tool == nil {
+		return fmt.Errorf("invalid tool: nil")
+	}
+	if err := tool.Validate(); err != nil {
+		return fmt.Errorf("invalid tool %s: %w", tool.Name(), err)
+	}
+	if names[tool.Name()] {
+		return fmt.Errorf("%w: %s", tools.ErrToolConflict, tool.Name())
+	}
+	names[tool.Name()] = true
+	return nil
+}
+
 // validateHandoffs checks for circular dependencies
 func (a *Agent) validateHandoffs(visited map[string]bool) error {
 	if visited[a.Name] {
@@ -90,6 +161,9 @@ func (a *Agent) validateHandoffs(visited map[string]bool) error {
 	defer delete(visited, a.Name)
 
 	for _, handoff := range a.Handoffs {
+		if handoff == nil {
+			return ErrMissingHandoff
+		}
 		if err := handoff.validateHandoffs(visited); err != nil {
 			return err
 		}
@@ -112,7 +186,12 @@ func (a *Agent) GetName() string {
 	return a.Name
 }
 
+// GetInstructions returns the instructions, with any sections assembled as
+// Markdown
 func (a *Agent) GetInstructions() string {
+	if sections := a.GetInstructionSections(); len(sections) > 0 {
+		return providers.FormatInstructions(sections, providers.InstructionsMarkdown)
+	}
 	return a.Instructions
 }
 
@@ -132,6 +211,23 @@ func (a *Agent) GetTopP() float32 {
 	return a.TopP
 }
 
+func (a *Agent) GetParallelToolCalls() *bool {
+	return a.ParallelToolCalls
+}
+
+// With returns a copy of the agent with opts applied, leaving the agent
+// itself unchanged. Use it to derive variants such as the same agent on a
+// different model.
+func (a *Agent) With(opts ...AgentOption) *Agent {
+	clone := a.Clone()
+	for _, opt := range opts {
+		opt(clone)
+	}
+
+	clone.indexHandoffs()
+	return clone
+}
+
 // Clone creates a deep copy of the agent
 func (a *Agent) Clone() *Agent {
 	a.mu.RLock()
@@ -145,9 +241,20 @@ func (a *Agent) Clone() *Agent {
 		Temperature:  a.Temperature,
 		MaxTokens:    a.MaxTokens,
 		TopP:         a.TopP,
+		ToolProtocol: a.ToolProtocol,
 		handoffMap:   make(map[string]*Agent),
+
+		HandoffDescription: a.HandoffDescription,
+	}
+
+	if a.ParallelToolCalls != nil {
+		parallel := *a.ParallelToolCalls
+		clone.ParallelToolCalls = &parallel
 	}
 
+	clone.Scopes = append([]string(nil), a.Scopes...)
+	clone.Sections = append([]InstructionSection(nil), a.Sections...)
+
 	// Deep copy tools
 	clone.Tools = make([]tools.Tool, len(a.Tools))
 	copy(clone.Tools, a.Tools)
@@ -156,6 +263,8 @@ func (a *Agent) Clone() *Agent {
 	clone.Guardrails = make([]guardrails.Guardrail, len(a.Guardrails))
 	copy(clone.Guardrails, a.Guardrails)
 
+	clone.HandoffInputFilters = append([]HandoffInputFilter(nil), a.HandoffInputFilters...)
+
 	// Note: Handoffs are shared references (agents are immutable once created)
 	clone.Handoffs = make([]*Agent, len(a.Handoffs))
 	copy(clone.Handoffs, a.Handoffs)
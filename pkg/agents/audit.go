@@ -0,0 +1,108 @@
+package agents
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ryanhill4L/agents-sdk/pkg/memory"
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// approvalsKey is the context key for the approvals of the running tool call
+type approvalsKey struct{}
+
+// toolApprovals collects RecordApproval calls made by a tool
+type toolApprovals struct {
+	mu sync.Mutex
+	by []string
+}
+
+func (a *toolApprovals) list() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]string(nil), a.by...)
+}
+
+// RecordApproval notes in the audit log that approver approved the tool call
+// running in ctx. Tools that obtain a human or policy approval before acting,
+// e.g. for refunds, call it with the context they were given. It does
+// nothing on a runner without an audit log.
+func RecordApproval(ctx context.Context, approver string) {
+	if approvals, ok := ctx.Value(approvalsKey{}).(*toolApprovals); ok {
+		approvals.mu.Lock()
+		defer approvals.mu.Unlock()
+
+		approvals.by = append(approvals.by, approver)
+	}
+}
+
+// audit appends a record for the run to the audit log, if configured. The
+// run's cancellation doesn't stop the write.
+func (r *Runner) audit(ctx *RunContext, record memory.AuditRecord) error {
+	if r.auditLog == nil {
+		return nil
+	}
+
+	record.ID = uuid.New().String()
+	record.Time = time.Now()
+	record.SessionID = ctx.SessionID
+	record.TenantID = ctx.TenantID
+	record.TraceID = ctx.TraceID
+	record.Actor = providers.RequestOptionsFromContext(ctx).User
+
+	if err := r.auditLog.Append(context.WithoutCancel(ctx), record); err != nil {
+		return fmt.Errorf("%w: %v", ErrAuditFailed, err)
+	}
+	return nil
+}
+
+// auditToolCall records how a tool call ended
+func (r *Runner) auditToolCall(ctx *RunContext, agent *Agent, call ToolCall, resp ToolResponse, approvals *toolApprovals) error {
+	record := memory.AuditRecord{
+		Kind:          memory.AuditToolCall,
+		Agent:         agent.Name,
+		Tool:          call.Name,
+		ToolCallID:    call.ID,
+		ArgumentsHash: argumentsHash(call.Arguments),
+		Status:        memory.AuditOK,
+		Duration:      resp.Duration,
+	}
+	if approvals != nil {
+		record.ApprovedBy = approvals.list()
+	}
+
+	var unauthorized *UnauthorizedError
+	switch {
+	case errors.As(resp.Error, &unauthorized), errors.Is(resp.Error, ErrNotApproved):
+		record.Status = memory.AuditDenied
+		record.Error = resp.Error.Error()
+	case resp.Error != nil:
+		record.Status = memory.AuditError
+		record.Error = resp.Error.Error()
+	case resp.Cached:
+		record.Status = memory.AuditCached
+	case resp.DryRun:
+		record.Status = memory.AuditDryRun
+	}
+
+	return r.audit(ctx, record)
+}
+
+// argumentsHash is the SHA-256 of the arguments' JSON encoding, whose keys
+// encoding/json sorts, so equal arguments hash equally
+func argumentsHash(args map[string]interface{}) string {
+	data, err := json.Marshal(args)
+	if err != nil {
+		data = []byte(fmt.Sprint(args))
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,158 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+	"golang.org/x/sync/errgroup"
+)
+
+// BatchOptions configures Runner.RunBatch
+type BatchOptions struct {
+	// Concurrency bounds the number of runs executing at once (default 4)
+	Concurrency int
+
+	// UseProviderBatch submits single-turn runs through the provider's batch
+	// API when available. Agents with tools or handoffs always run locally.
+	UseProviderBatch bool
+
+	// RunOptions are applied to every run in the batch
+	RunOptions []RunOption
+}
+
+// BatchResult contains the outcome of every run in a batch, indexed like the
+// inputs. A failed run has a nil result and a non-nil error at its index.
+type BatchResult struct {
+	Results []*RunResult `json:"results"`
+	Errors  []error      `json:"-"`
+	Metrics BatchMetrics `json:"metrics"`
+}
+
+// BatchMetrics aggregates metrics across all runs in a batch
+type BatchMetrics struct {
+	Total         int           `json:"total"`
+	Succeeded     int           `json:"succeeded"`
+	Failed        int           `json:"failed"`
+	TotalTokens   int           `json:"total_tokens"`
+	TotalTurns    int           `json:"total_turns"`
+	ToolCalls     int           `json:"tool_calls"`
+	Duration      time.Duration `json:"duration"`
+	ProviderBatch bool          `json:"provider_batch"`
+}
+
+// FailedIndexes returns the input positions whose runs failed
+func (b *BatchResult) FailedIndexes() []int {
+	var failed []int
+	for i, err := range b.Errors {
+		if err != nil {
+			failed = append(failed, i)
+		}
+	}
+	return failed
+}
+
+// RunBatch executes the agent once per input with bounded concurrency.
+// Individual failures are reported in BatchResult.Errors rather than
+// aborting the batch. Batch runs do not read or write the runner's session.
+func (r *Runner) RunBatch(ctx context.Context, agent *Agent, inputs []string, opts BatchOptions) (*BatchResult, error) {
+	startTime := time.Now()
+
+	result := &BatchResult{
+		Results: make([]*RunResult, len(inputs)),
+		Errors:  make([]error, len(inputs)),
+	}
+
+	batchProvider, ok := r.provider.(providers.BatchProvider)
+	if opts.UseProviderBatch && ok && len(agent.Tools) == 0 && len(agent.Handoffs) == 0 {
+		if err := r.runProviderBatch(ctx, batchProvider, agent, inputs, result); err != nil {
+			return nil, err
+		}
+		result.Metrics.ProviderBatch = true
+	} else {
+		concurrency := opts.Concurrency
+		if concurrency <= 0 {
+			concurrency = 4
+		}
+
+		runOpts := append([]RunOption{withoutSession()}, opts.RunOptions...)
+
+		var g errgroup.Group
+		g.SetLimit(concurrency)
+
+		for i, input := range inputs {
+			i, input := i, input // capture loop variables
+
+			g.Go(func() error {
+				result.Results[i], result.Errors[i] = r.Run(ctx, agent, input, runOpts...)
+				return nil
+			})
+		}
+		g.Wait()
+	}
+
+	result.Metrics.Total = len(inputs)
+	for i, run := range result.Results {
+		if result.Errors[i] != nil || run == nil {
+			result.Metrics.Failed++
+			continue
+		}
+		result.Metrics.Succeeded++
+		result.Metrics.TotalTokens += run.Metrics.TotalTokens
+		result.Metrics.TotalTurns += run.Metrics.TotalTurns
+		result.Metrics.ToolCalls += run.Metrics.ToolCalls
+	}
+	result.Metrics.Duration = time.Since(startTime)
+
+	return result, nil
+}
+
+// runProviderBatch submits every input as a single-turn request to the
+// provider's batch API
+func (r *Runner) runProviderBatch(ctx context.Context, provider providers.BatchProvider, agent *Agent, inputs []string, result *BatchResult) error {
+	requests := make([]providers.BatchRequest, len(inputs))
+	conversations := make([][]Message, len(inputs))
+	for i, input := range inputs {
+		conversations[i] = []Message{{
+			Role:      "user",
+			Content:   input,
+			Timestamp: time.Now(),
+		}}
+		requests[i] = providers.BatchRequest{Messages: messagesToProviders(conversations[i])}
+	}
+
+	responses, err := provider.CompleteBatch(ctx, agent, requests, nil)
+	if err != nil {
+		return fmt.Errorf("provider batch failed: %w", err)
+	}
+
+	for i, resp := range responses {
+		if i >= len(inputs) {
+			break
+		}
+		if resp.Err != nil {
+			result.Errors[i] = fmt.Errorf("completion failed: %w", resp.Err)
+			continue
+		}
+
+		messages := append(conversations[i], messageFromProviders(resp.Completion.Message))
+
+		var finalOutput interface{} = resp.Completion.Message.Content
+		if agent.OutputType != nil && resp.Completion.StructuredOutput != nil {
+			finalOutput = resp.Completion.StructuredOutput
+		}
+
+		result.Results[i] = &RunResult{
+			FinalOutput: finalOutput,
+			Messages:    messages,
+			Agent:       agent,
+			Metrics: RunMetrics{
+				TotalTurns:  1,
+				TotalTokens: resp.Completion.Usage.TotalTokens,
+			},
+		}
+	}
+
+	return nil
+}
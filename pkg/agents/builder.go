@@ -0,0 +1,165 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/guardrails"
+	"github.com/ryanhill4L/agents-sdk/pkg/tools"
+)
+
+// AgentBuilder assembles an Agent step by step, checking each step as it is
+// made. Errors are collected and reported together by Build, so a chain of
+// calls needs a single error check.
+type AgentBuilder struct {
+	agent     *Agent
+	toolNames map[string]bool
+	errs      []error
+}
+
+// Builder starts building an agent with the same defaults as NewAgent
+func Builder() *AgentBuilder {
+	return &AgentBuilder{
+		agent:     NewAgent(""),
+		toolNames: make(map[string]bool),
+	}
+}
+
+// Name sets the agent's name
+func (b *AgentBuilder) Name(name string) *AgentBuilder {
+	if name == "" {
+		b.errs = append(b.errs, ErrInvalidAgentName)
+	}
+	b.agent.Name = name
+	return b
+}
+
+// Instructions sets the agent's instructions
+func (b *AgentBuilder) Instructions(instructions string) *AgentBuilder {
+	b.agent.Instructions = instructions
+	return b
+}
+
+// Section adds or replaces a named instruction section
+func (b *AgentBuilder) Section(name string, lines ...string) *AgentBuilder {
+	b.agent.setSection(Section(name, lines...))
+	return b
+}
+
+// Model sets the LLM model
+func (b *AgentBuilder) Model(model string) *AgentBuilder {
+	if model == "" {
+		b.errs = append(b.errs, ErrInvalidModel)
+	}
+	b.agent.Model = model
+	return b
+}
+
+// Tools adds tools, rejecting invalid tools and names already taken
+func (b *AgentBuilder) Tools(tools ...tools.Tool) *AgentBuilder {
+	for _, tool := range tools {
+		if err := validateTool(tool, b.toolNames); err != nil {
+			b.errs = append(b.errs, err)
+			continue
+		}
+		b.agent.Tools = append(b.agent.Tools, tool)
+	}
+	return b
+}
+
+// Handoffs adds handoff targets, rejecting nil targets and duplicate names
+func (b *AgentBuilder) Handoffs(agents ...*Agent) *AgentBuilder {
+	for _, handoff := range agents {
+		if handoff == nil {
+			b.errs = append(b.errs, ErrMissingHandoff)
+			continue
+		}
+		if _, ok := b.agent.handoffMap[handoff.Name]; ok {
+			b.errs = append(b.errs, fmt.Errorf("duplicate handoff target: %s", handoff.Name))
+			continue
+		}
+		b.agent.Handoffs = append(b.agent.Handoffs, handoff)
+		b.agent.handoffMap[handoff.Name] = handoff
+	}
+	return b
+}
+
+// Guardrails adds guardrails
+func (b *AgentBuilder) Guardrails(guardrails ...guardrails.Guardrail) *AgentBuilder {
+	b.agent.Guardrails = append(b.agent.Guardrails, guardrails...)
+	return b
+}
+
+// Scopes grants permission scopes
+func (b *AgentBuilder) Scopes(scopes ...string) *AgentBuilder {
+	b.agent.Scopes = append(b.agent.Scopes, scopes...)
+	return b
+}
+
+// OutputType sets the structured output schema
+func (b *AgentBuilder) OutputType(schema OutputSchema) *AgentBuilder {
+	b.agent.OutputType = schema
+	return b
+}
+
+// Temperature sets the temperature, which must be between 0 and 2
+func (b *AgentBuilder) Temperature(temp float32) *AgentBuilder {
+	return b.parameters(temp, b.agent.TopP, b.agent.MaxTokens)
+}
+
+// TopP sets nucleus sampling, which must be between 0 and 1
+func (b *AgentBuilder) TopP(topP float32) *AgentBuilder {
+	return b.parameters(b.agent.Temperature, topP, b.agent.MaxTokens)
+}
+
+// MaxTokens sets the max tokens, which can't be negative
+func (b *AgentBuilder) MaxTokens(tokens int) *AgentBuilder {
+	return b.parameters(b.agent.Temperature, b.agent.TopP, tokens)
+}
+
+// ParallelToolCalls allows or forbids several tool calls per response
+func (b *AgentBuilder) ParallelToolCalls(enabled bool) *AgentBuilder {
+	b.agent.ParallelToolCalls = &enabled
+	return b
+}
+
+// ToolProtocol sets how tools are offered to the model
+func (b *AgentBuilder) ToolProtocol(protocol ToolProtocol) *AgentBuilder {
+	b.agent.ToolProtocol = protocol
+	return b
+}
+
+// parameters applies sampling parameters if they are in range
+func (b *AgentBuilder) parameters(temperature, topP float32, maxTokens int) *AgentBuilder {
+	if err := validateParameters(temperature, topP, maxTokens); err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.agent.Temperature, b.agent.TopP, b.agent.MaxTokens = temperature, topP, maxTokens
+	return b
+}
+
+// Build returns the agent, or every error recorded while building it along
+// with those found by Validate, such as circular handoffs. The agent is a
+// copy, so later calls on the builder don't change it.
+func (b *AgentBuilder) Build() (*Agent, error) {
+	if len(b.errs) > 0 {
+		return nil, errors.Join(b.errs...)
+	}
+
+	agent := b.agent.Clone()
+	if err := agent.Validate(); err != nil {
+		return nil, err
+	}
+	return agent, nil
+}
+
+// MustBuild is like Build but panics on error, for agents defined at
+// package level
+func (b *AgentBuilder) MustBuild() *Agent {
+	agent, err := b.Build()
+	if err != nil {
+		panic(fmt.Sprintf("agents: invalid agent: %v", err))
+	}
+	return agent
+}
@@ -0,0 +1,21 @@
+package agents
+
+import (
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// Citations returns the citations recorded on the message by providers that
+// cite sources
+func (m Message) Citations() []providers.Citation {
+	return providers.CitationsOf(m.Metadata)
+}
+
+// Citations returns the citations of the run's final response
+func (r *RunResult) Citations() []providers.Citation {
+	for i := len(r.Messages) - 1; i >= 0; i-- {
+		if r.Messages[i].Role == "assistant" {
+			return r.Messages[i].Citations()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// DebugStep is one recorded model call of a run: the request the runner sent
+// and the completion it got back
+type DebugStep struct {
+	Index int
+
+	// Request is the request as recorded: agent, model, instructions,
+	// messages and tool names
+	Request providers.CassetteRequest
+
+	// Completion is the provider's response
+	Completion *providers.Completion
+
+	// Messages are the request messages, including any edits made with
+	// EditMessage
+	Messages []Message
+
+	// Edited is set once a message of the step has been changed
+	Edited bool
+}
+
+// Debugger steps through a run recorded with providers.RecordingProvider,
+// one model call at a time. Messages can be edited, and the run resumed from
+// any step against a live provider to see what changes.
+type Debugger struct {
+	steps   []DebugStep
+	current int
+}
+
+// NewDebugger loads the run recorded in the cassette at cassettePath
+func NewDebugger(cassettePath string) (*Debugger, error) {
+	cassette, err := providers.LoadCassette(cassettePath)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Debugger{current: -1}
+	for i, interaction := range cassette.Interactions {
+		var completion providers.Completion
+		if err := json.Unmarshal(interaction.Completion, &completion); err != nil {
+			return nil, fmt.Errorf("invalid recorded completion for step %d: %w", i, err)
+		}
+		d.steps = append(d.steps, DebugStep{
+			Index:      i,
+			Request:    interaction.Request,
+			Completion: &completion,
+			Messages:   messagesFromCassette(interaction.Request.Messages),
+		})
+	}
+	return d, nil
+}
+
+// Len returns the number of recorded steps
+func (d *Debugger) Len() int {
+	return len(d.steps)
+}
+
+// Next advances to the next step, reporting false after the last one
+func (d *Debugger) Next() (DebugStep, bool) {
+	if d.current+1 >= len(d.steps) {
+		return DebugStep{}, false
+	}
+	d.current++
+	return d.steps[d.current], true
+}
+
+// Prev goes back to the previous step, reporting false before the first one
+func (d *Debugger) Prev() (DebugStep, bool) {
+	if d.current <= 0 {
+		return DebugStep{}, false
+	}
+	d.current--
+	return d.steps[d.current], true
+}
+
+// Seek moves to step i
+func (d *Debugger) Seek(i int) (DebugStep, error) {
+	step, err := d.Step(i)
+	if err != nil {
+		return DebugStep{}, err
+	}
+	d.current = i
+	return step, nil
+}
+
+// Step returns step i without moving to it
+func (d *Debugger) Step(i int) (DebugStep, error) {
+	if i < 0 || i >= len(d.steps) {
+		return DebugStep{}, fmt.Errorf("%w: step %d of %d", ErrNoSuchStep, i, len(d.steps))
+	}
+	return d.steps[i], nil
+}
+
+// Current returns the step the debugger is at, reporting false before the
+// first call to Next or Seek
+func (d *Debugger) Current() (DebugStep, bool) {
+	if d.current < 0 {
+		return DebugStep{}, false
+	}
+	return d.steps[d.current], true
+}
+
+// EditMessage replaces the content of message index of step i, for the
+// next Rerun from that step
+func (d *Debugger) EditMessage(i, index int, content string) error {
+	if _, err := d.Step(i); err != nil {
+		return err
+	}
+	step := &d.steps[i]
+	if index < 0 || index >= len(step.Messages) {
+		return fmt.Errorf("%w: message %d of %d in step %d", ErrNoSuchStep, index, len(step.Messages), i)
+	}
+
+	// Copy so the recorded messages of other steps stay as they were
+	step.Messages = append([]Message(nil), step.Messages...)
+	step.Messages[index].Content = content
+	step.Edited = true
+	return nil
+}
+
+// Rerun resumes the run at step i with runner, calling its provider for the
+// completion of that step and everything after. The step's agent is looked
+// up by name among root and the agents it hands off to, so changes to their
+// definitions apply too. The rerun never reads or writes a session.
+func (d *Debugger) Rerun(ctx context.Context, runner *Runner, root *Agent, i int, opts ...RunOption) (*RunResult, error) {
+	step, err := d.Step(i)
+	if err != nil {
+		return nil, err
+	}
+
+	agent := findAgent(root, step.Request.Agent)
+	if agent == nil {
+		return nil, fmt.Errorf("%w: agent %s of step %d", ErrMissingHandoff, step.Request.Agent, i)
+	}
+
+	history := append([]Message(nil), step.Messages...)
+	opts = append(opts, withHistory(history), withoutSession())
+	return runner.Run(ctx, agent, "", opts...)
+}
+
+// findAgent returns the agent named name among root and the agents it can
+// reach by handoffs
+func findAgent(root *Agent, name string) *Agent {
+	visited := make(map[*Agent]bool)
+	queue := []*Agent{root}
+	for len(queue) > 0 {
+		agent := queue[0]
+		queue = queue[1:]
+		if agent == nil || visited[agent] {
+			continue
+		}
+		visited[agent] = true
+		if agent.Name == name {
+			return agent
+		}
+		queue = append(queue, agent.Handoffs...)
+	}
+	return nil
+}
+
+// messagesFromCassette converts recorded request messages
+func messagesFromCassette(recorded []providers.CassetteMessage) []Message {
+	messages := make([]Message, len(recorded))
+	for i, msg := range recorded {
+		messages[i] = Message{
+			Role:      msg.Role,
+			Content:   msg.Content,
+			Parts:     partsFromProviders(msg.Parts),
+			ToolCalls: toolCallsFromProviders(msg.ToolCalls),
+			Metadata:  msg.Metadata,
+		}
+	}
+	return messages
+}
@@ -0,0 +1,228 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/memory"
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// DefaultEntityTypes are the kinds of entity ModelEntityExtractor looks for
+// when given none
+var DefaultEntityTypes = []string{"person", "organization", "account", "venue"}
+
+// DefaultEntityCards is how many entity cards a prompt gets by default
+const DefaultEntityCards = 5
+
+// EntityExtractor finds the entities a conversation tells something about,
+// with the facts it states about them. known holds the stored entities
+// relevant to the conversation, so an extractor can refer to them by the
+// same names.
+type EntityExtractor func(ctx context.Context, messages []Message, known []memory.Entity) ([]memory.Entity, error)
+
+// EntityMemory configures WithEntityMemory
+type EntityMemory struct {
+	Store     memory.EntityStore
+	Extractor EntityExtractor
+
+	// MaxCards caps the entity cards added to a run's prompts,
+	// DefaultEntityCards when zero
+	MaxCards int
+
+	// OnError receives failures to retrieve or extract entities, which
+	// never fail a run; they are dropped when nil
+	OnError func(error)
+}
+
+type entityCardsKey struct{}
+
+// entityOwner returns whose entities a run uses: its end user, or its
+// session when there is none, scoped to its tenant. Runs with neither have
+// no entity memory.
+func entityOwner(cfg *runConfig) string {
+	owner := cfg.request.User
+	if owner == "" {
+		owner = cfg.sessionID
+	}
+	if owner == "" {
+		return ""
+	}
+	return memory.TenantKey(cfg.tenantID, owner)
+}
+
+// retrieveEntities returns the owner's entities most relevant to input:
+// those it names first, then the most recently seen
+func (r *Runner) retrieveEntities(ctx context.Context, owner, input string) []memory.Entity {
+	limit := r.entityMemory.MaxCards
+	if limit <= 0 {
+		limit = DefaultEntityCards
+	}
+
+	// Recent entities are the candidates; older ones come back once
+	// they're mentioned again
+	candidates, err := r.entityMemory.Store.Entities(ctx, memory.EntityQuery{Owner: owner, Limit: 20 * limit})
+	if err != nil {
+		r.entityError(fmt.Errorf("failed to retrieve entities: %w", err))
+		return nil
+	}
+
+	text := strings.ToLower(input)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return strings.Contains(text, strings.ToLower(candidates[i].Name)) &&
+			!strings.Contains(text, strings.ToLower(candidates[j].Name))
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+// extractEntities stores the entities of a finished run's messages in the
+// background, so extraction doesn't delay the result. Shutdown waits for it.
+func (r *Runner) extractEntities(ctx context.Context, owner string, messages []Message, known []memory.Entity) {
+	ctx, done, err := r.inflight.start(context.WithoutCancel(ctx))
+	if err != nil {
+		return
+	}
+
+	go func() {
+		defer done()
+
+		ctx, cancel := context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+
+		entities, err := r.entityMemory.Extractor(ctx, messages, known)
+		if err == nil && len(entities) > 0 {
+			err = r.entityMemory.Store.UpsertEntities(ctx, owner, entities, time.Now())
+		}
+		if err != nil {
+			r.entityError(fmt.Errorf("failed to extract entities: %w", err))
+		}
+	}()
+}
+
+func (r *Runner) entityError(err error) {
+	if r.entityMemory.OnError != nil {
+		r.entityMemory.OnError(err)
+	}
+}
+
+// entityCards renders entities for the prompt
+func entityCards(entities []memory.Entity) string {
+	if len(entities) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("What you know from earlier conversations with this user. It may be out of date; trust the conversation when they differ.\n")
+	for _, entity := range entities {
+		fmt.Fprintf(&b, "\n- %s (%s)", entity.Name, entity.Type)
+		keys := make([]string, 0, len(entity.Attributes))
+		for key := range entity.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			sep := ", "
+			if i == 0 {
+				sep = ": "
+			}
+			fmt.Fprintf(&b, "%s%s: %s", sep, key, entity.Attributes[key])
+		}
+	}
+	return b.String()
+}
+
+// withEntityCards adds the run's entity cards to the start of the prompt as
+// a system message. Like the scratchpad, they are only sent, never added to
+// the history.
+func withEntityCards(ctx context.Context, messages []Message) []Message {
+	cards, _ := ctx.Value(entityCardsKey{}).(string)
+	if cards == "" {
+		return messages
+	}
+
+	prompt := make([]Message, 0, len(messages)+1)
+	prompt = append(prompt, SystemMessage(cards))
+	return append(prompt, messages...)
+}
+
+// runMessages returns the messages a run added: those from its input on
+func runMessages(messages []Message, input string) []Message {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" && messages[i].Content == input {
+			return messages[i:]
+		}
+	}
+	return messages
+}
+
+// ModelEntityExtractor extracts entities of the given types, DefaultEntityTypes
+// if none, by asking model on provider. A cheap, fast model is usually enough.
+func ModelEntityExtractor(provider providers.Provider, model string, types ...string) EntityExtractor {
+	if len(types) == 0 {
+		types = DefaultEntityTypes
+	}
+	allowed := make(map[string]bool, len(types))
+	for _, t := range types {
+		allowed[strings.ToLower(t)] = true
+	}
+
+	extractor := NewAgent("entity-extractor",
+		WithModel(model),
+		WithTemperature(0),
+		WithInstructions(fmt.Sprintf(`You extract entities from conversations for a long-term memory.
+Find the entities of these types that the conversation states facts about: %s.
+Record each fact as a short attribute, such as "role": "CFO" or "preferred contact": "email".
+Skip entities only mentioned in passing, guesses and facts about the assistant itself.
+Refer to known entities by their known names.
+Reply with JSON only, in this form:
+{"entities": [{"type": "person", "name": "Dana Kim", "attributes": {"role": "CFO"}}]}
+Reply {"entities": []} when there are none.`, strings.Join(types, ", "))),
+	)
+
+	return func(ctx context.Context, messages []Message, known []memory.Entity) ([]memory.Entity, error) {
+		var transcript strings.Builder
+		if len(known) > 0 {
+			transcript.WriteString("Known entities:\n")
+			for _, entity := range known {
+				fmt.Fprintf(&transcript, "- %s (%s)\n", entity.Name, entity.Type)
+			}
+			transcript.WriteString("\n")
+		}
+		transcript.WriteString("Conversation:\n")
+		for _, msg := range messages {
+			if (msg.Role == "user" || msg.Role == "assistant") && msg.Content != "" {
+				fmt.Fprintf(&transcript, "\n%s: %s\n", msg.Role, msg.Content)
+			}
+		}
+
+		completion, err := provider.Complete(ctx, extractor, []providers.Message{{
+			Role:      "user",
+			Content:   transcript.String(),
+			Timestamp: time.Now(),
+		}}, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var extracted struct {
+			Entities []memory.Entity `json:"entities"`
+		}
+		if err := DecodeJSON(completion.Message.Content, &extracted); err != nil {
+			return nil, err
+		}
+
+		entities := extracted.Entities[:0]
+		for _, entity := range extracted.Entities {
+			if allowed[strings.ToLower(entity.Type)] && strings.TrimSpace(entity.Name) != "" {
+				entities = append(entities, entity)
+			}
+		}
+		return entities, nil
+	}
+}
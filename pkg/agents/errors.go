@@ -1,22 +1,147 @@
 package agents
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/memory"
+)
 
 var (
 	// Agent errors
 	ErrInvalidAgentName = errors.New("agent name cannot be empty")
 	ErrInvalidModel     = errors.New("model cannot be empty")
 	ErrCircularHandoff  = errors.New("circular handoff detected")
+	ErrInvalidParameter = errors.New("invalid model parameter")
+	ErrMissingHandoff   = errors.New("missing handoff target")
+	ErrDuplicateAgent   = errors.New("duplicate agent name")
+	ErrUnreachableAgent = errors.New("agent unreachable")
 
 	// Runner errors
 	ErrMaxTurnsExceeded = errors.New("maximum turns exceeded")
 	ErrTimeout          = errors.New("execution timeout")
 	ErrNoProvider       = errors.New("no LLM provider configured")
+	ErrUnsupported      = errors.New("provider does not support required capability")
+	ErrNoSessionStore   = errors.New("no session store configured")
+	ErrNoRunStore       = errors.New("no run store configured")
+	ErrRunInProgress    = memory.ErrRunInProgress
+	ErrRunFailed        = errors.New("run failed after calling mutating tools")
+	ErrShutdown         = errors.New("runner is shut down")
+	ErrInvalidOutput    = errors.New("invalid structured output")
+	ErrAuditFailed      = errors.New("audit log write failed")
+	ErrTenantSession    = errors.New("tenant runs cannot use the runner-wide session")
+	ErrTenantLimit      = errors.New("tenant limit exceeded")
+	ErrQuotaExceeded    = errors.New("usage quota exceeded")
+	ErrHandoffLimit     = errors.New("handoff limit exceeded")
+	ErrSpeechSynthesis  = errors.New("speech synthesis failed")
+
+	// Configuration errors
+	ErrInvalidConfig = errors.New("invalid agent configuration")
+
+	// Debugger errors
+	ErrNoSuchStep = errors.New("no such step in recording")
+
+	// Scheduler errors
+	ErrScheduleExists   = errors.New("schedule already exists")
+	ErrScheduleNotFound = memory.ErrScheduleNotFound
 
 	// Tool errors
 	ErrToolNotFound  = errors.New("tool not found")
 	ErrToolExecution = errors.New("tool execution failed")
+	ErrUnauthorized  = errors.New("tool call not authorized")
+	ErrNotApproved   = errors.New("tool call not approved")
 
 	// Guardrail errors
 	ErrGuardrailViolation = errors.New("guardrail violation")
 )
+
+// UnauthorizedError reports a tool call blocked because the agent or run
+// lacks some of the tool's required scopes
+type UnauthorizedError struct {
+	Agent   string   `json:"agent"`
+	Tool    string   `json:"tool"`
+	Missing []string `json:"missing"`
+}
+
+// Error implements the error interface
+func (e *UnauthorizedError) Error() string {
+	return fmt.Sprintf("%v: %s requires %s (agent %s)", ErrUnauthorized, e.Tool, strings.Join(e.Missing, ", "), e.Agent)
+}
+
+// Unwrap lets errors.Is match ErrUnauthorized
+func (e *UnauthorizedError) Unwrap() error {
+	return ErrUnauthorized
+}
+
+// TenantLimitError reports a run rejected by the runner's TenantLimiter
+type TenantLimitError struct {
+	Tenant string `json:"tenant"`
+
+	// Limit names the exceeded limit: "runs_per_minute", "tokens_per_minute"
+	// or "concurrent_runs"
+	Limit string `json:"limit"`
+
+	// RetryAfter is how long until the limit admits the run again; zero for
+	// concurrency limits
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
+}
+
+// Error implements the error interface
+func (e *TenantLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%v: %s for tenant %s, retry after %s", ErrTenantLimit, e.Limit, e.Tenant, e.RetryAfter)
+	}
+	return fmt.Sprintf("%v: %s for tenant %s", ErrTenantLimit, e.Limit, e.Tenant)
+}
+
+// Unwrap lets errors.Is match ErrTenantLimit
+func (e *TenantLimitError) Unwrap() error {
+	return ErrTenantLimit
+}
+
+// QuotaExceededError reports a run rejected by the runner's QuotaEnforcer
+type QuotaExceededError struct {
+	Tenant string `json:"tenant"`
+
+	// Key is set when the key's own quota is spent rather than the tenant's
+	Key string `json:"key,omitempty"`
+
+	// Limit names the spent budget: "daily_runs", "daily_tokens" or
+	// "daily_cost"
+	Limit    string    `json:"limit"`
+	ResetsAt time.Time `json:"resets_at"`
+}
+
+// Error implements the error interface
+func (e *QuotaExceededError) Error() string {
+	scope := "tenant " + e.Tenant
+	if e.Key != "" {
+		scope = "key " + e.Key + " of " + scope
+	}
+	return fmt.Sprintf("%v: %s for %s, resets at %s", ErrQuotaExceeded, e.Limit, scope, e.ResetsAt.Format(time.RFC3339))
+}
+
+// Unwrap lets errors.Is match ErrQuotaExceeded
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// TimeoutError is returned when a run hits its deadline or is interrupted by
+// Runner.Shutdown. Partial holds the messages and metrics recorded up to
+// that point.
+type TimeoutError struct {
+	Partial *RunResult
+	Err     error
+}
+
+// Error implements the error interface
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%v after %d turns: %v", ErrTimeout, e.Partial.Metrics.TotalTurns, e.Err)
+}
+
+// Unwrap lets errors.Is match ErrTimeout and the underlying context error
+func (e *TimeoutError) Unwrap() []error {
+	return []error{ErrTimeout, e.Err}
+}
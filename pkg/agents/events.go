@@ -0,0 +1,168 @@
+package agents
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// EventType identifies a point in a run's lifecycle
+type EventType string
+
+const (
+	EventRunStart   EventType = "run_start"
+	EventRunEnd     EventType = "run_end"
+	EventTurnStart  EventType = "turn_start"
+	EventCompletion EventType = "completion"
+	EventToolStart  EventType = "tool_start"
+	EventToolEnd    EventType = "tool_end"
+	EventHandoff    EventType = "handoff"
+)
+
+// Event describes something that happened during a run. Fields that don't
+// apply to the event's type are left empty.
+type Event struct {
+	Type      EventType `json:"type"`
+	Time      time.Time `json:"time"`
+	TraceID   string    `json:"trace_id"`
+	SessionID string    `json:"session_id"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	Agent     string    `json:"agent,omitempty"`
+	Turn      int       `json:"turn"`
+
+	// Input is the user input, set on EventRunStart
+	Input string `json:"input,omitempty"`
+
+	// Usage is set on EventCompletion
+	Usage *TurnMetrics `json:"usage,omitempty"`
+
+	// ToolCall is set on EventToolStart and EventToolEnd, ToolResponse on
+	// EventToolEnd
+	ToolCall     *ToolCall     `json:"tool_call,omitempty"`
+	ToolResponse *ToolResponse `json:"tool_response,omitempty"`
+
+	// Target is the agent handed off to, set on EventHandoff
+	Target string `json:"target,omitempty"`
+
+	// Result and Err are set on EventRunEnd
+	Result *RunResult `json:"-"`
+	Err    error      `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes Err as its message, since error values have no JSON
+// form of their own
+func (e Event) MarshalJSON() ([]byte, error) {
+	type event Event
+	return json.Marshal(struct {
+		event
+		Err string `json:"error,omitempty"`
+	}{event: event(e), Err: errorMessage(e.Err)})
+}
+
+// errorMessage returns err's message, or "" for a nil error
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// EventHandler receives run events. Handlers are called synchronously on
+// the run's goroutine, or a tool's goroutine for parallel tool calls, so
+// they must be safe for concurrent use and shouldn't block.
+type EventHandler func(Event)
+
+// EventBus fans run events out to any number of subscribers
+type EventBus struct {
+	mu     sync.RWMutex
+	subs   []subscription
+	nextID int
+}
+
+type subscription struct {
+	id      int
+	handler EventHandler
+	types   map[EventType]bool
+}
+
+// NewEventBus creates an empty event bus
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+var defaultEvents = NewEventBus()
+
+// Events returns the process-wide event bus that runners publish to unless
+// configured WithEventBus
+func Events() *EventBus {
+	return defaultEvents
+}
+
+// Subscribe registers handler for the given event types, or for every event
+// when none are given. The returned function removes the subscription.
+func (b *EventBus) Subscribe(handler EventHandler, types ...EventType) (unsubscribe func()) {
+	sub := subscription{handler: handler}
+	if len(types) > 0 {
+		sub.types = make(map[EventType]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	sub.id = id
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			for i, s := range b.subs {
+				if s.id == id {
+					b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+					return
+				}
+			}
+		})
+	}
+}
+
+// Publish delivers event to every matching subscriber in subscription order
+func (b *EventBus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := make([]EventHandler, 0, len(b.subs))
+	for _, sub := range b.subs {
+		if sub.types == nil || sub.types[event.Type] {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// emit publishes an event for the run to the runner's bus
+func (r *Runner) emit(ctx *RunContext, agent *Agent, event Event) {
+	if r.events == nil {
+		return
+	}
+
+	event.TraceID = ctx.TraceID
+	event.SessionID = ctx.SessionID
+	event.TenantID = ctx.TenantID
+	event.Turn = ctx.CurrentTurn
+	if agent != nil {
+		event.Agent = agent.Name
+	}
+	r.events.Publish(event)
+}
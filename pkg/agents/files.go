@@ -0,0 +1,50 @@
+package agents
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// File is a document attached to a run, such as a PDF or text file
+type File struct {
+	Name     string
+	MIMEType string
+	Data     []byte
+
+	// cited marks files attached WithCitedFiles
+	cited bool
+}
+
+// LoadFile reads a file from disk, detecting its media type from the
+// extension or, failing that, from its contents
+func LoadFile(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+
+	return File{
+		Name:     filepath.Base(path),
+		MIMEType: mimeType,
+		Data:     data,
+	}, nil
+}
+
+// contentPart converts the file into a message content part
+func (f File) contentPart() ContentPart {
+	return ContentPart{
+		Type:      "file",
+		Name:      f.Name,
+		MIMEType:  f.MIMEType,
+		Data:      f.Data,
+		Citations: f.cited,
+	}
+}
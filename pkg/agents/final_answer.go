@@ -0,0 +1,197 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// FinalAnswerTool names the synthetic tool that WithFinalAnswerTool offers
+// agents with an output schema
+const FinalAnswerTool = "final_answer"
+
+// Arguments of the final_answer tool besides the answer's own
+const (
+	// finalAnswerWrapper holds the answer when it isn't passed as the
+	// arguments themselves, since tool arguments are always an object
+	finalAnswerWrapper = "answer"
+
+	finalAnswerConfidence = "confidence"
+	finalAnswerUnresolved = "unresolved_questions"
+)
+
+// SelfEvaluation is the model's own assessment of its final answer,
+// requested with WithSelfEvaluation
+type SelfEvaluation struct {
+	// Confidence that the answer is correct and complete, from 0 to 1
+	Confidence float64 `json:"confidence"`
+
+	// UnresolvedQuestions lists what the model couldn't settle, for a
+	// person to follow up on
+	UnresolvedQuestions []string `json:"unresolved_questions,omitempty"`
+}
+
+// finalAnswer describes the final_answer tool offered to an agent
+type finalAnswer struct {
+	// output is the agent's output schema; without one the answer is text
+	output OutputSchema
+
+	// wrapped answers are passed in the "answer" argument
+	wrapped bool
+
+	// evaluate asks for a SelfEvaluation alongside the answer
+	evaluate bool
+}
+
+// finalAnswerFor returns the final_answer tool an agent is offered, if any.
+// Agents with an output schema get one with WithFinalAnswerTool; every
+// agent gets one with WithSelfEvaluation.
+func (r *Runner) finalAnswerFor(agent *Agent) (*finalAnswer, bool) {
+	if !r.selfEvaluation && (!r.finalAnswerTool || agent.OutputType == nil) {
+		return nil, false
+	}
+	return &finalAnswer{output: agent.OutputType, evaluate: r.selfEvaluation}, true
+}
+
+// definition builds the tool, wrapping the answer when the output schema
+// isn't an object or shares the arguments with the self-evaluation
+func (f *finalAnswer) definition() providers.ToolDefinition {
+	def := providers.ToolDefinition{
+		Name:        FinalAnswerTool,
+		Description: "Give your final answer. Call this once, when you're done; its arguments are the answer and end the run.",
+	}
+
+	answer := providers.PropertySchema{Type: "string", Description: "Your final answer"}
+	if f.output != nil {
+		var schema providers.ParameterSchema
+		if err := json.Unmarshal(f.output.Schema(), &schema); err == nil && schema.Type == "object" && !f.evaluate {
+			def.Schema = schema
+			return def
+		}
+		answer = providers.PropertySchema{}
+		_ = json.Unmarshal(f.output.Schema(), &answer)
+	}
+
+	f.wrapped = true
+	def.Schema = providers.ParameterSchema{
+		Type:       "object",
+		Properties: map[string]providers.PropertySchema{finalAnswerWrapper: answer},
+		Required:   []string{finalAnswerWrapper},
+	}
+	if f.evaluate {
+		minimum, maximum := 0.0, 1.0
+		def.Schema.Properties[finalAnswerConfidence] = providers.PropertySchema{
+			Type:        "number",
+			Description: "How confident you are that the answer is correct and complete, from 0 to 1",
+			Minimum:     &minimum,
+			Maximum:     &maximum,
+		}
+		def.Schema.Properties[finalAnswerUnresolved] = providers.PropertySchema{
+			Type:        "array",
+			Description: "Questions you couldn't resolve and a person should follow up on",
+			Items:       &providers.PropertySchema{Type: "string"},
+		}
+		def.Schema.Required = append(def.Schema.Required, finalAnswerConfidence)
+	}
+	return def
+}
+
+// parse decodes the arguments of a final_answer call into the final output
+// and, when requested, the self-evaluation
+func (f *finalAnswer) parse(call providers.ToolCall) (interface{}, *SelfEvaluation, error) {
+	var answer interface{} = call.Arguments
+	if f.wrapped {
+		value, ok := call.Arguments[finalAnswerWrapper]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: missing %q argument", ErrInvalidOutput, finalAnswerWrapper)
+		}
+		answer = value
+	}
+
+	var evaluation *SelfEvaluation
+	if f.evaluate {
+		var err error
+		if evaluation, err = parseSelfEvaluation(call.Arguments); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if f.output == nil {
+		text, ok := answer.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %q must be a string", ErrInvalidOutput, finalAnswerWrapper)
+		}
+		return text, evaluation, nil
+	}
+
+	data, err := json.Marshal(answer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+	}
+	output, err := parseOutput(f.output, string(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	return output, evaluation, nil
+}
+
+// parseSelfEvaluation reads the self-evaluation arguments of a final_answer
+// call
+func parseSelfEvaluation(arguments map[string]interface{}) (*SelfEvaluation, error) {
+	var evaluation SelfEvaluation
+	data, err := json.Marshal(map[string]interface{}{
+		finalAnswerConfidence: arguments[finalAnswerConfidence],
+		finalAnswerUnresolved: arguments[finalAnswerUnresolved],
+	})
+	if err == nil {
+		err = DecodeJSON(string(data), &evaluation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid self-evaluation: %v", ErrInvalidOutput, err)
+	}
+	if _, ok := arguments[finalAnswerConfidence]; !ok {
+		return nil, fmt.Errorf("%w: missing %q argument", ErrInvalidOutput, finalAnswerConfidence)
+	}
+	if evaluation.Confidence < 0 || evaluation.Confidence > 1 {
+		return nil, fmt.Errorf("%w: confidence %g outside 0-1", ErrInvalidOutput, evaluation.Confidence)
+	}
+	return &evaluation, nil
+}
+
+// finalAnswerCall returns the model's call to the final_answer tool, if any
+func finalAnswerCall(calls []providers.ToolCall) (providers.ToolCall, bool) {
+	for _, call := range calls {
+		if call.Name == FinalAnswerTool {
+			return call, true
+		}
+	}
+	return providers.ToolCall{}, false
+}
+
+// finalAnswerResults answers the calls of the turn that gave the final
+// answer, so the conversation can be replayed: the answer is reported as
+// accepted or rejected with err, and other calls as not run
+func finalAnswerResults(calls []providers.ToolCall, err error) []Message {
+	results := make([]Message, 0, len(calls))
+	for _, call := range calls {
+		metadata := map[string]interface{}{"tool_call_id": call.ID}
+		content := "Not run: the final answer was given in the same turn."
+		switch {
+		case call.Name != FinalAnswerTool:
+		case err != nil:
+			content = fmt.Sprintf("Final answer rejected: %v. Call %s again with a corrected answer.", err, FinalAnswerTool)
+			metadata["is_error"] = true
+		default:
+			content = "Final answer accepted."
+		}
+		results = append(results, Message{
+			Role:      "tool",
+			Content:   content,
+			Timestamp: time.Now(),
+			Metadata:  metadata,
+		})
+	}
+	return results
+}
@@ -0,0 +1,122 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+	"github.com/ryanhill4L/agents-sdk/pkg/tools"
+)
+
+// ValidateGraph checks root and every agent it can hand off to, directly or
+// through other agents, against the runner's provider. It reports every
+// configuration error it finds, so they surface before the first API call:
+// missing names and models, different agents sharing a name, handoffs the
+// model can never take, colliding tool names, circular handoffs,
+// parameters the model doesn't allow and tools the provider doesn't
+// support.
+func (r *Runner) ValidateGraph(root *Agent) error {
+	if root == nil {
+		return ErrMissingHandoff
+	}
+
+	var errs []error
+	if r.provider == nil {
+		errs = append(errs, ErrNoProvider)
+	}
+	if err := root.validateHandoffs(make(map[string]bool)); err != nil {
+		errs = append(errs, fmt.Errorf("%w: %v", ErrCircularHandoff, err))
+	}
+
+	named := make(map[string]*Agent)
+	visited := make(map[*Agent]bool)
+	queue := []*Agent{root}
+	for len(queue) > 0 {
+		agent := queue[0]
+		queue = queue[1:]
+		if visited[agent] {
+			continue
+		}
+		visited[agent] = true
+
+		if other, ok := named[agent.Name]; ok && !sameAgent(agent, other) {
+			errs = append(errs, fmt.Errorf("%w: %q names two different agents", ErrDuplicateAgent, agent.Name))
+		} else if !ok {
+			named[agent.Name] = agent
+		}
+
+		errs = append(errs, r.validateGraphAgent(agent)...)
+		for _, handoff := range agent.Handoffs {
+			if handoff != nil {
+				queue = append(queue, handoff)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateGraphAgent checks one agent of the graph and its handoffs
+func (r *Runner) validateGraphAgent(agent *Agent) []error {
+	var errs []error
+	fail := func(err error) {
+		errs = append(errs, fmt.Errorf("agent %s: %w", agent.Name, err))
+	}
+
+	if agent.Name == "" {
+		errs = append(errs, ErrInvalidAgentName)
+	}
+	if agent.Model == "" {
+		fail(ErrInvalidModel)
+	}
+	if err := validateParameters(agent.Temperature, agent.TopP, agent.MaxTokens); err != nil {
+		fail(err)
+	}
+	if limit := providers.MaxOutputTokens(agent.Model); limit > 0 && agent.MaxTokens > limit {
+		fail(fmt.Errorf("%w: max tokens %d above the %d %s allows", ErrInvalidParameter, agent.MaxTokens, limit, agent.Model))
+	}
+
+	names := make(map[string]bool, len(agent.Tools))
+	for _, tool := range agent.Tools {
+		if err := validateTool(tool, names); err != nil {
+			fail(err)
+		}
+	}
+
+	transfers := make(map[string]string)
+	for _, handoff := range agent.Handoffs {
+		if handoff == nil {
+			fail(ErrMissingHandoff)
+			continue
+		}
+		name := handoffToolName(handoff.Name)
+		if names[name] {
+			fail(fmt.Errorf("%w: tool %s collides with the transfer tool for %s", tools.ErrToolConflict, name, handoff.Name))
+		}
+		if earlier, ok := transfers[name]; ok {
+			fail(fmt.Errorf("%w: handoff to %s is shadowed by %s", ErrUnreachableAgent, handoff.Name, earlier))
+			continue
+		}
+		transfers[name] = handoff.Name
+	}
+
+	if r.provider == nil {
+		return errs
+	}
+	if len(agent.Handoffs) > 0 && !providers.CapabilitiesOf(r.provider).Tools && r.toolProtocol(agent) == ToolProtocolNative {
+		fail(fmt.Errorf("%w: handoffs need function tools, which the provider lacks", ErrUnreachableAgent))
+	}
+	if err := r.checkCapabilities(agent, nil); err != nil {
+		fail(err)
+	}
+	return errs
+}
+
+// sameAgent reports whether two agents sharing a name are variants of one
+// agent, such as those HandoffTo derives, rather than different agents
+func sameAgent(a, b *Agent) bool {
+	return a.Model == b.Model &&
+		a.Instructions == b.Instructions &&
+		len(a.Tools) == len(b.Tools) &&
+		len(a.Handoffs) == len(b.Handoffs)
+}
@@ -0,0 +1,306 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// Metadata of the message that briefs an agent on a handoff
+const (
+	// MetadataHandoff marks the briefing; its value is the name of the
+	// agent that handed off
+	MetadataHandoff = "handoff"
+
+	// MetadataHandoffTarget names the agent taking over
+	MetadataHandoffTarget = "handoff_target"
+)
+
+// handoffSummaryLimit caps the derived conversation summary, in runes
+const handoffSummaryLimit = 500
+
+// HandoffToParent is the handoff target with which an agent hands control
+// back to the agent that delegated to it. The delegator's name works too.
+const HandoffToParent = "parent"
+
+// resolveHandoff finds the agent a handoff targets: one of current's
+// handoffs or, failing that, the delegator it returns to. handoffs counts the
+// run's handoffs including this one.
+func (r *Runner) resolveHandoff(current *Agent, target string, delegators []*Agent, handoffs int) (*Agent, bool, error) {
+	if r.maxHandoffs > 0 && handoffs > r.maxHandoffs {
+		return nil, false, fmt.Errorf("%w: more than %d handoffs", ErrHandoffLimit, r.maxHandoffs)
+	}
+
+	if agent, ok := current.GetHandoff(target); ok {
+		if r.maxHandoffDepth > 0 && len(delegators) >= r.maxHandoffDepth {
+			return nil, false, fmt.Errorf("%w: handoff to %s exceeds depth %d", ErrHandoffLimit, target, r.maxHandoffDepth)
+		}
+		return agent, false, nil
+	}
+
+	if n := len(delegators); n > 0 && (target == HandoffToParent || target == delegators[n-1].Name) {
+		return delegators[n-1], true, nil
+	}
+	return nil, false, fmt.Errorf("handoff agent not found: %s", target)
+}
+
+// handoffToolPrefix starts the name of every generated transfer tool
+const handoffToolPrefix = "transfer_to_"
+
+// handoffTools generates a transfer tool for each agent current can hand off
+// to, and one for returning to its delegator, with the agent each one
+// targets. Providers without function tools get none unless the agent
+// emulates them.
+func (r *Runner) handoffTools(current *Agent, delegators []*Agent) ([]providers.ToolDefinition, map[string]string) {
+	if !providers.CapabilitiesOf(r.provider).Tools && r.toolProtocol(current) == ToolProtocolNative {
+		return nil, nil
+	}
+
+	var defs []providers.ToolDefinition
+	targets := make(map[string]string)
+	add := func(name, target, description, summary string) {
+		if _, ok := targets[name]; ok {
+			return
+		}
+		targets[name] = target
+		defs = append(defs, providers.ToolDefinition{
+			Name:        name,
+			Description: description,
+			Schema: providers.ParameterSchema{
+				Type: "object",
+				Properties: map[string]providers.PropertySchema{
+					"reason":  {Type: "string", Description: "Why you're handing off"},
+					"summary": {Type: "string", Description: summary},
+				},
+			},
+		})
+	}
+
+	for _, agent := range current.Handoffs {
+		if agent == nil {
+			continue
+		}
+		description := fmt.Sprintf("Hand the conversation off to the %s agent.", agent.Name)
+		if agent.HandoffDescription != "" {
+			description += " " + agent.HandoffDescription
+		}
+		add(handoffToolName(agent.Name), agent.Name, description, "What the next agent needs to know about the conversation")
+	}
+	if n := len(delegators); n > 0 {
+		parent := delegators[n-1].Name
+		add(handoffToolPrefix+HandoffToParent, parent,
+			fmt.Sprintf("Hand control back to %s, the agent that handed off to you, once your part is done.", parent),
+			"Your results for the agent you're returning to")
+	}
+	return defs, targets
+}
+
+// handoffToolName derives a transfer tool name from an agent name, keeping
+// the characters every provider accepts
+func handoffToolName(agent string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '_'
+	}, agent)
+	return handoffToolPrefix + name
+}
+
+// transferHandoff turns the first transfer tool call into a handoff request
+func transferHandoff(calls []providers.ToolCall, targets map[string]string) *providers.HandoffRequest {
+	for _, call := range calls {
+		target, ok := targets[call.Name]
+		if !ok {
+			continue
+		}
+		handoff := &providers.HandoffRequest{TargetAgent: target}
+		handoff.Reason, _ = call.Arguments["reason"].(string)
+		handoff.Summary, _ = call.Arguments["summary"].(string)
+		return handoff
+	}
+	return nil
+}
+
+// transferResults answers every tool call of a turn that handed off, since
+// providers expect each call to have a result: the transfer succeeded, and
+// the other calls weren't run
+func transferResults(calls []providers.ToolCall, targets map[string]string, target string) []Message {
+	results := make([]Message, 0, len(calls))
+	for _, call := range calls {
+		content := fmt.Sprintf("Not run: the conversation was handed off to %s.", target)
+		if targets[call.Name] == target {
+			content = fmt.Sprintf("Transferred to %s.", target)
+		}
+		results = append(results, Message{
+			Role:      "tool",
+			Content:   content,
+			Timestamp: time.Now(),
+			Metadata:  map[string]interface{}{"tool_call_id": call.ID},
+		})
+	}
+	return results
+}
+
+// HandoffInputFilter rewrites the conversation history passed to an agent
+// taking over. KeepLastMessages returns one.
+type HandoffInputFilter func(ctx context.Context, messages []Message) ([]Message, error)
+
+// DropToolMessages returns a filter removing tool results and the tool calls
+// that requested them, along with assistant messages left empty, so the
+// next agent sees only the conversation
+func DropToolMessages() HandoffInputFilter {
+	return func(ctx context.Context, messages []Message) ([]Message, error) {
+		filtered := make([]Message, 0, len(messages))
+		for _, msg := range messages {
+			if msg.Role == "tool" {
+				continue
+			}
+			if msg.Role == "assistant" {
+				msg.ToolCalls = nil
+				if strings.TrimSpace(msg.Content) == "" && len(msg.Parts) == 0 {
+					continue
+				}
+			}
+			filtered = append(filtered, msg)
+		}
+		return filtered, nil
+	}
+}
+
+// SummarizeHistory returns a filter replacing the history with summarize's
+// account of it, followed by the latest user message
+func SummarizeHistory(summarize func(ctx context.Context, messages []Message) (string, error)) HandoffInputFilter {
+	return func(ctx context.Context, messages []Message) ([]Message, error) {
+		summary, err := summarize(ctx, messages)
+		if err != nil {
+			return nil, err
+		}
+
+		filtered := []Message{SystemMessage("Summary of the conversation so far:\n" + summary)}
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role == "user" {
+				filtered = append(filtered, messages[i])
+				break
+			}
+		}
+		return filtered, nil
+	}
+}
+
+// filterHandoffInput applies the input filters of the agent taking over
+func filterHandoffInput(ctx context.Context, agent *Agent, messages []Message) ([]Message, error) {
+	for _, filter := range agent.HandoffInputFilters {
+		var err error
+		if messages, err = filter(ctx, messages); err != nil {
+			return nil, fmt.Errorf("handoff input filter failed: %w", err)
+		}
+	}
+	return messages, nil
+}
+
+// handoffFromProviders converts a provider handoff request, recording the
+// agent it came from
+func handoffFromProviders(req *providers.HandoffRequest, from string) *HandoffRequest {
+	return &HandoffRequest{
+		TargetAgent: req.TargetAgent,
+		Context:     req.Context,
+		Reason:      req.Reason,
+		Summary:     req.Summary,
+		From:        from,
+	}
+}
+
+// handoffMessage briefs the agent taking over with the handoff's reason,
+// context and a summary of the conversation. Without a summary from the
+// model, the latest user message stands in for one, or for a return the
+// returning agent's latest response stands in for its results.
+func handoffMessage(handoff *HandoffRequest, messages []Message) Message {
+	var b strings.Builder
+	if handoff.Returned {
+		fmt.Fprintf(&b, "%s handed control back to you.", handoff.From)
+	} else {
+		fmt.Fprintf(&b, "Handoff from %s: you are now handling this conversation.", handoff.From)
+	}
+	if handoff.Reason != "" {
+		fmt.Fprintf(&b, "\nReason: %s", handoff.Reason)
+	}
+
+	if len(handoff.Context) > 0 {
+		keys := make([]string, 0, len(handoff.Context))
+		for key := range handoff.Context {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		b.WriteString("\nContext:")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "\n- %s: %s", key, handoffValue(handoff.Context[key]))
+		}
+	}
+
+	switch {
+	case handoff.Returned && handoff.Summary != "":
+		fmt.Fprintf(&b, "\nResults: %s", handoff.Summary)
+	case handoff.Returned:
+		if latest := latestMessage(messages, "assistant"); latest != "" {
+			fmt.Fprintf(&b, "\nResults: %s", truncateRunes(latest, handoffSummaryLimit))
+		}
+	case handoff.Summary != "":
+		fmt.Fprintf(&b, "\nConversation summary: %s", handoff.Summary)
+	default:
+		if latest := latestMessage(messages, "user"); latest != "" {
+			fmt.Fprintf(&b, "\nConversation summary: The user's latest message was: %s", truncateRunes(latest, handoffSummaryLimit))
+		}
+	}
+
+	return Message{
+		Role:      "system",
+		Content:   b.String(),
+		Timestamp: time.Now(),
+		Metadata: map[string]interface{}{
+			MetadataHandoff:       handoff.From,
+			MetadataHandoffTarget: handoff.TargetAgent,
+		},
+	}
+}
+
+// handoffValue renders a context value: strings as they are, anything else
+// as JSON
+func handoffValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// latestMessage returns the content of the last non-empty message with role
+func latestMessage(messages []Message, role string) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == role && strings.TrimSpace(messages[i].Content) != "" {
+			return strings.TrimSpace(messages[i].Content)
+		}
+	}
+	return ""
+}
+
+// truncateRunes shortens s to at most limit runes, marking the cut
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "…"
+}
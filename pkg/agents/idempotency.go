@@ -0,0 +1,135 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/memory"
+	"github.com/ryanhill4L/agents-sdk/pkg/speech"
+)
+
+// storedRun is the persisted form of a RunResult. Structured output is
+// restored as decoded JSON rather than the agent's output type.
+type storedRun struct {
+	FinalOutput json.RawMessage `json:"final_output"`
+	Messages    []Message       `json:"messages"`
+	Agent       string          `json:"agent"`
+	Metrics     RunMetrics      `json:"metrics"`
+	Audio       *speech.Audio   `json:"audio,omitempty"`
+	Stopped     bool            `json:"stopped,omitempty"`
+
+	// Error is the failure of a run that called mutating tools before it
+	// failed, which is stored so retries don't call them again
+	Error string `json:"error,omitempty"`
+}
+
+// runLeaseGrace is how long a reservation outlives the run timeout, for the
+// work around the run such as storing its result
+const runLeaseGrace = time.Minute
+
+// runIdempotent runs at most once per idempotency key, returning the stored
+// result for keys that already completed. Failed runs release the key so
+// the caller can retry, unless they had called a tool that may mutate: one
+// declared mutating, or one that declares no capabilities at all. Retrying
+// could repeat its side effects, so their failure is stored instead.
+func (r *Runner) runIdempotent(ctx context.Context, agent *Agent, input string, cfg *runConfig) (*RunResult, error) {
+	if r.runStore == nil {
+		return nil, ErrNoRunStore
+	}
+	key := memory.TenantKey(cfg.tenantID, cfg.idempotencyKey)
+
+	// The run can't hold its reservation past its timeout
+	stored, err := r.runStore.Reserve(ctx, key, r.timeout+runLeaseGrace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve run %s: %w", cfg.idempotencyKey, err)
+	}
+	if stored != nil {
+		return decodeRun(stored, agent)
+	}
+
+	// A run that completed is stored even if it returns an error, such as
+	// failed speech synthesis
+	result, err := r.run(ctx, agent, input, cfg)
+	if result == nil {
+		if cfg.mutated.Load() {
+			// Retrying could repeat the side effects. A failure that can't be
+			// stored keeps the reservation until its lease expires.
+			if data, encodeErr := json.Marshal(storedRun{Error: err.Error()}); encodeErr == nil {
+				_ = r.runStore.Complete(context.WithoutCancel(ctx), key, data)
+			}
+			return nil, err
+		}
+
+		// Keep the run's error; a failed release only delays retries until
+		// the reservation's lease expires
+		_ = r.runStore.Release(context.WithoutCancel(ctx), key)
+		return nil, err
+	}
+
+	data, encodeErr := encodeRun(result)
+	if encodeErr != nil {
+		return nil, encodeErr
+	}
+	if storeErr := r.runStore.Complete(context.WithoutCancel(ctx), key, data); storeErr != nil {
+		return nil, fmt.Errorf("failed to store run %s: %w", cfg.idempotencyKey, storeErr)
+	}
+
+	return result, err
+}
+
+// encodeRun serializes a result for the run store
+func encodeRun(result *RunResult) ([]byte, error) {
+	output, err := json.Marshal(result.FinalOutput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode final output: %w", err)
+	}
+
+	run := storedRun{
+		FinalOutput: output,
+		Messages:    result.Messages,
+		Metrics:     result.Metrics,
+		Audio:       result.Audio,
+		Stopped:     result.Stopped,
+	}
+	if result.Agent != nil {
+		run.Agent = result.Agent.Name
+	}
+
+	return json.Marshal(run)
+}
+
+// decodeRun restores a stored result, resolving the final agent among agent
+// and its handoffs
+func decodeRun(data []byte, agent *Agent) (*RunResult, error) {
+	var run storedRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to decode stored run: %w", err)
+	}
+	if run.Error != "" {
+		return nil, fmt.Errorf("%w: %s", ErrRunFailed, run.Error)
+	}
+
+	var output interface{}
+	if len(run.FinalOutput) > 0 {
+		if err := json.Unmarshal(run.FinalOutput, &output); err != nil {
+			return nil, fmt.Errorf("failed to decode stored output: %w", err)
+		}
+	}
+
+	finalAgent := agent
+	if handoff, ok := agent.GetHandoff(run.Agent); ok {
+		finalAgent = handoff
+	}
+
+	return &RunResult{
+		FinalOutput: output,
+		Messages:    run.Messages,
+		Agent:       finalAgent,
+		Metrics:     run.Metrics,
+		Audio:       run.Audio,
+		Stopped:     run.Stopped,
+		Replayed:    true,
+	}, nil
+}
@@ -0,0 +1,293 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Variables set by the built-in input processors
+const (
+	// VarOriginalInput holds the input as received, before any processor
+	// changed it
+	VarOriginalInput = "original_input"
+
+	// VarInputLanguage holds the ISO 639-1 code detected by DetectLanguage
+	VarInputLanguage = "input_language"
+
+	// VarProfanityMasked holds the number of words MaskProfanity replaced
+	VarProfanityMasked = "profanity_masked"
+)
+
+// InputProcessor transforms a run's input before the first turn. Processors
+// run in order, each receiving the previous one's output, and may annotate
+// ctx.Variables for turn context functions and tools further down the run.
+type InputProcessor interface {
+	// Name identifies the processor in errors and traces
+	Name() string
+
+	// Process returns the transformed input
+	Process(ctx *RunContext, input string) (string, error)
+}
+
+// InputProcessorFunc adapts a function to InputProcessor
+type InputProcessorFunc struct {
+	ProcessorName string
+	Fn            func(ctx *RunContext, input string) (string, error)
+}
+
+// Name implements InputProcessor
+func (f InputProcessorFunc) Name() string { return f.ProcessorName }
+
+// Process implements InputProcessor
+func (f InputProcessorFunc) Process(ctx *RunContext, input string) (string, error) {
+	return f.Fn(ctx, input)
+}
+
+// processInput runs the processors over input, recording the original in
+// the run's variables when it changes
+func (r *Runner) processInput(ctx *RunContext, processors []InputProcessor, input string) (string, error) {
+	if len(processors) == 0 {
+		return input, nil
+	}
+
+	_, span := r.startSpan(ctx, ctx.Context, "input.process")
+	defer r.tracer.EndSpan(span)
+	span.SetAttribute("processors", len(processors))
+
+	original := input
+	for _, processor := range processors {
+		processed, err := processor.Process(ctx, input)
+		if err != nil {
+			err = fmt.Errorf("input processor %s failed: %w", processor.Name(), err)
+			span.SetError(err)
+			return "", err
+		}
+		input = processed
+	}
+
+	if input != original {
+		ctx.Variables[VarOriginalInput] = original
+	}
+	return input, nil
+}
+
+// NormalizeInput returns a processor that trims the input, normalizes line
+// endings, drops control characters and collapses runs of spaces and blank
+// lines
+func NormalizeInput() InputProcessor {
+	return InputProcessorFunc{ProcessorName: "normalize", Fn: func(_ *RunContext, input string) (string, error) {
+		input = strings.ReplaceAll(input, "\r\n", "\n")
+
+		var b strings.Builder
+		b.Grow(len(input))
+		newlines, space := 0, false
+		for _, c := range input {
+			switch {
+			case c == '\n':
+				newlines++
+				space = false
+				continue
+			case c == '\t' || unicode.IsSpace(c):
+				space = true
+				continue
+			case unicode.IsControl(c) || c == '\u200b' || c == '\ufeff':
+				continue
+			}
+
+			if b.Len() > 0 {
+				switch {
+				case newlines > 1:
+					b.WriteString("\n\n")
+				case newlines == 1:
+					b.WriteByte('\n')
+				case space:
+					b.WriteByte(' ')
+				}
+			}
+			newlines, space = 0, false
+			b.WriteRune(c)
+		}
+		return b.String(), nil
+	}}
+}
+
+// scriptLanguages maps scripts used by a single common language to it
+var scriptLanguages = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Thai, "th"},
+}
+
+// stopwords are frequent function words of languages written in Latin
+// script, enough to tell them apart in a sentence or two
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "to", "of", "you", "what", "how", "can", "please", "with", "my", "for"},
+	"es": {"el", "la", "los", "las", "que", "es", "y", "de", "por", "para", "como", "puedes", "mi", "con"},
+	"fr": {"le", "la", "les", "est", "et", "de", "que", "pour", "vous", "je", "comment", "mon", "avec", "une"},
+	"de": {"der", "die", "das", "und", "ist", "ich", "nicht", "mit", "wie", "für", "sie", "mein", "ein", "zu"},
+	"it": {"il", "lo", "gli", "che", "è", "e", "di", "per", "come", "puoi", "mio", "con", "una", "sono"},
+	"pt": {"o", "os", "as", "que", "é", "e", "de", "para", "como", "você", "meu", "com", "uma", "não"},
+	"nl": {"de", "het", "een", "en", "is", "van", "ik", "niet", "met", "hoe", "voor", "mijn", "je", "dat"},
+}
+
+// DetectLanguage returns a processor that records the input's language in
+// ctx.Variables[VarInputLanguage] without changing it. Detection is a
+// lightweight heuristic on the script and common words; the code is "und"
+// when it can't tell.
+func DetectLanguage() InputProcessor {
+	return InputProcessorFunc{ProcessorName: "detect_language", Fn: func(ctx *RunContext, input string) (string, error) {
+		ctx.Variables[VarInputLanguage] = detectLanguage(input)
+		return input, nil
+	}}
+}
+
+func detectLanguage(input string) string {
+	// A script used by one language decides when it dominates the letters
+	counts := make(map[string]int)
+	letters := 0
+	for _, c := range input {
+		if !unicode.IsLetter(c) {
+			continue
+		}
+		letters++
+		for _, s := range scriptLanguages {
+			if unicode.Is(s.table, c) {
+				counts[s.language]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return "und"
+	}
+	// Japanese text mixes kana with kanji
+	if counts["ja"] > 0 {
+		counts["ja"] += counts["zh"]
+		delete(counts, "zh")
+	}
+	for language, n := range counts {
+		if n*2 > letters {
+			return language
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(input), func(c rune) bool {
+		return !unicode.IsLetter(c)
+	})
+	scores := make(map[string]int)
+	for _, word := range words {
+		for language, list := range stopwords {
+			for _, stopword := range list {
+				if word == stopword {
+					scores[language]++
+				}
+			}
+		}
+	}
+
+	best, bestScore := "und", 0
+	languages := make([]string, 0, len(scores))
+	for language := range scores {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	for _, language := range languages {
+		if scores[language] > bestScore {
+			best, bestScore = language, scores[language]
+		}
+	}
+	return best
+}
+
+// Translator translates text into the target language; from is the
+// detected source language, or "und" when unknown
+type Translator interface {
+	Translate(ctx context.Context, text, from, to string) (string, error)
+}
+
+// TranslatorFunc adapts a function to Translator
+type TranslatorFunc func(ctx context.Context, text, from, to string) (string, error)
+
+// Translate implements Translator
+func (f TranslatorFunc) Translate(ctx context.Context, text, from, to string) (string, error) {
+	return f(ctx, text, from, to)
+}
+
+// AgentTranslator translates with an agent on runner, for use with
+// TranslateInput. Use a runner without a translating input processor, or
+// every translation would itself be translated.
+func AgentTranslator(runner *Runner, agent *Agent) Translator {
+	return TranslatorFunc(func(ctx context.Context, text, from, to string) (string, error) {
+		prompt := fmt.Sprintf("Translate the following text to the language with ISO 639-1 code %q. Reply with the translation only.\n\n%s", to, text)
+		result, err := runner.Run(ctx, agent, prompt, withoutSession())
+		if err != nil {
+			return "", err
+		}
+		translated, ok := result.FinalOutput.(string)
+		if !ok {
+			return "", fmt.Errorf("translator returned %T, expected text", result.FinalOutput)
+		}
+		return strings.TrimSpace(translated), nil
+	})
+}
+
+// TranslateInput returns a processor that translates input into target
+// (an ISO 639-1 code) unless it is already in that language. Place it after
+// DetectLanguage to skip translating input that doesn't need it; the
+// detected language stays in the variables so replies can be translated
+// back.
+func TranslateInput(translator Translator, target string) InputProcessor {
+	return InputProcessorFunc{ProcessorName: "translate", Fn: func(ctx *RunContext, input string) (string, error) {
+		from, _ := ctx.Variables[VarInputLanguage].(string)
+		if from == "" {
+			from = detectLanguage(input)
+		}
+		if from == target {
+			return input, nil
+		}
+		return translator.Translate(ctx, input, from, target)
+	}}
+}
+
+// defaultProfanity is the word list MaskProfanity uses without arguments
+var defaultProfanity = []string{
+	"fuck", "fucking", "shit", "bitch", "bastard", "asshole", "dick", "cunt", "damn", "crap",
+}
+
+// MaskProfanity returns a processor that replaces whole-word,
+// case-insensitive matches of words (a small English list by default) with
+// asterisks, recording the count in ctx.Variables[VarProfanityMasked]
+func MaskProfanity(words ...string) InputProcessor {
+	if len(words) == 0 {
+		words = defaultProfanity
+	}
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	pattern := regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+
+	return InputProcessorFunc{ProcessorName: "mask_profanity", Fn: func(ctx *RunContext, input string) (string, error) {
+		count := 0
+		masked := pattern.ReplaceAllStringFunc(input, func(word string) string {
+			count++
+			return strings.Repeat("*", len([]rune(word)))
+		})
+		ctx.Variables[VarProfanityMasked] = count
+		return masked, nil
+	}}
+}
@@ -0,0 +1,95 @@
+package agents
+
+import (
+	"strings"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// Standard instruction section names. Any name can be used; these cover the
+// parts most agents need, in the order they are usually given.
+const (
+	SectionRole         = "role"
+	SectionContext      = "context"
+	SectionToolProtocol = "tool_protocol"
+	SectionProcess      = "process"
+	SectionOutputFormat = "output_format"
+	SectionSafety       = "safety"
+)
+
+// InstructionSection is a named part of an agent's instructions
+type InstructionSection struct {
+	Name    string
+	Content string
+}
+
+// Section creates an instruction section whose content is lines joined by
+// newlines
+func Section(name string, lines ...string) InstructionSection {
+	return InstructionSection{Name: name, Content: strings.Join(lines, "\n")}
+}
+
+// WithInstructionSections assembles the agent's instructions from named
+// sections. A section replaces an earlier one with the same name in place,
+// so a derived agent can override a single part with Agent.With. Each
+// provider lays the sections out in its preferred format: Markdown headings
+// for OpenAI and Gemini, XML tags for Anthropic. Plain Instructions, if
+// any, come first as a preamble.
+func WithInstructionSections(sections ...InstructionSection) AgentOption {
+	return func(a *Agent) {
+		for _, section := range sections {
+			a.setSection(section)
+		}
+	}
+}
+
+// WithoutInstructionSection removes the named section
+func WithoutInstructionSection(name string) AgentOption {
+	return func(a *Agent) {
+		for i, section := range a.Sections {
+			if section.Name == name {
+				a.Sections = append(a.Sections[:i:i], a.Sections[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (a *Agent) setSection(section InstructionSection) {
+	for i, existing := range a.Sections {
+		if existing.Name == section.Name {
+			// Copy before writing so clones sharing the array are unaffected
+			a.Sections = append([]InstructionSection(nil), a.Sections...)
+			a.Sections[i] = section
+			return
+		}
+	}
+	a.Sections = append(a.Sections, section)
+}
+
+// InstructionSection returns the content of the named section
+func (a *Agent) InstructionSection(name string) (string, bool) {
+	for _, section := range a.Sections {
+		if section.Name == name {
+			return section.Content, true
+		}
+	}
+	return "", false
+}
+
+// GetInstructionSections implements providers.SectionedAgent, returning the
+// preamble and sections in order
+func (a *Agent) GetInstructionSections() []providers.InstructionSection {
+	if len(a.Sections) == 0 {
+		return nil
+	}
+
+	sections := make([]providers.InstructionSection, 0, len(a.Sections)+1)
+	if a.Instructions != "" {
+		sections = append(sections, providers.InstructionSection{Content: a.Instructions})
+	}
+	for _, section := range a.Sections {
+		sections = append(sections, providers.InstructionSection{Name: section.Name, Content: section.Content})
+	}
+	return sections
+}
@@ -0,0 +1,222 @@
+package agents
+
+import (
+	"time"
+)
+
+// MetadataReasoning holds reasoning a provider returned with an assistant
+// message, such as a reasoning summary; it becomes a RunItemReasoning
+const MetadataReasoning = "reasoning"
+
+// RunItemType identifies what a RunItem records
+type RunItemType string
+
+const (
+	// RunItemMessage is text or media from the user, the model or the
+	// application
+	RunItemMessage RunItemType = "message"
+
+	// RunItemToolCall is a tool call the model made
+	RunItemToolCall RunItemType = "tool_call"
+
+	// RunItemToolOutput is the result of a tool call
+	RunItemToolOutput RunItemType = "tool_output"
+
+	// RunItemHandoff is control passing from one agent to another
+	RunItemHandoff RunItemType = "handoff"
+
+	// RunItemReasoning is reasoning the model showed before responding
+	RunItemReasoning RunItemType = "reasoning"
+)
+
+// RunItem is one step of a conversation. Messages flatten tool calls into
+// the assistant message that made them and handoffs into system messages;
+// items keep each step separate, for interfaces that render them
+// differently.
+type RunItem struct {
+	Type RunItemType `json:"type"`
+
+	// Role is the author of a message item
+	Role string `json:"role,omitempty"`
+
+	// Content is the text of messages, tool outputs, reasoning and handoff
+	// briefings
+	Content string        `json:"content,omitempty"`
+	Parts   []ContentPart `json:"parts,omitempty"`
+
+	// ToolCall is the call of a tool_call item
+	ToolCall *ToolCall `json:"tool_call,omitempty"`
+
+	// CallID links a tool_output item to its tool_call
+	CallID  string `json:"call_id,omitempty"`
+	IsError bool   `json:"is_error,omitempty"`
+
+	// From and To name the agents of a handoff item
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// Items returns the run's conversation as items
+func (r *RunResult) Items() []RunItem {
+	return RunItemsOf(r.Messages)
+}
+
+// RunItemsOf splits messages into items. An assistant message yields its
+// reasoning, its text and then each of its tool calls.
+func RunItemsOf(messages []Message) []RunItem {
+	items := make([]RunItem, 0, len(messages))
+	for _, msg := range messages {
+		if from, ok := msg.Metadata[MetadataHandoff].(string); ok {
+			to, _ := msg.Metadata[MetadataHandoffTarget].(string)
+			items = append(items, RunItem{
+				Type:      RunItemHandoff,
+				Content:   msg.Content,
+				From:      from,
+				To:        to,
+				Metadata:  msg.Metadata,
+				Timestamp: msg.Timestamp,
+			})
+			continue
+		}
+
+		if msg.Role == "tool" {
+			id, _ := msg.Metadata["tool_call_id"].(string)
+			isError, _ := msg.Metadata["is_error"].(bool)
+			items = append(items, RunItem{
+				Type:      RunItemToolOutput,
+				Content:   msg.Content,
+				Parts:     msg.Parts,
+				CallID:    id,
+				IsError:   isError,
+				Metadata:  msg.Metadata,
+				Timestamp: msg.Timestamp,
+			})
+			continue
+		}
+
+		if reasoning, ok := msg.Metadata[MetadataReasoning].(string); ok && reasoning != "" {
+			items = append(items, RunItem{Type: RunItemReasoning, Content: reasoning, Timestamp: msg.Timestamp})
+		}
+		if msg.Content != "" || len(msg.Parts) > 0 || len(msg.ToolCalls) == 0 {
+			items = append(items, RunItem{
+				Type:      RunItemMessage,
+				Role:      msg.Role,
+				Content:   msg.Content,
+				Parts:     msg.Parts,
+				Metadata:  msg.Metadata,
+				Timestamp: msg.Timestamp,
+			})
+		}
+		for i := range msg.ToolCalls {
+			call := msg.ToolCalls[i]
+			items = append(items, RunItem{Type: RunItemToolCall, ToolCall: &call, Timestamp: msg.Timestamp})
+		}
+	}
+	return items
+}
+
+// MessagesOf joins items back into messages, the inverse of RunItemsOf:
+// reasoning, text and tool calls in a row become one assistant message.
+// The result can continue a conversation with any provider.
+func MessagesOf(items []RunItem) []Message {
+	var messages []Message
+
+	// open is the index of the assistant message still taking items, or -1
+	open := -1
+	assistant := func(item RunItem) *Message {
+		if open < 0 {
+			messages = append(messages, Message{Role: "assistant", Timestamp: item.Timestamp})
+			open = len(messages) - 1
+		}
+		return &messages[open]
+	}
+
+	for _, item := range items {
+		switch item.Type {
+		case RunItemReasoning:
+			msg := assistant(item)
+			msg.Metadata = withMetadata(msg.Metadata, MetadataReasoning, item.Content)
+
+		case RunItemToolCall:
+			if item.ToolCall != nil {
+				msg := assistant(item)
+				msg.ToolCalls = append(msg.ToolCalls, *item.ToolCall)
+			}
+
+		case RunItemMessage:
+			if item.Role == "assistant" {
+				msg := assistant(item)
+				if msg.Content != "" || len(msg.Parts) > 0 || len(msg.ToolCalls) > 0 {
+					// Text after tool calls starts a new response
+					open = -1
+					msg = assistant(item)
+				}
+				msg.Content, msg.Parts = item.Content, item.Parts
+				msg.Metadata = mergeMetadata(msg.Metadata, item.Metadata)
+				continue
+			}
+			open = -1
+			messages = append(messages, Message{
+				Role:      item.Role,
+				Content:   item.Content,
+				Parts:     item.Parts,
+				Metadata:  item.Metadata,
+				Timestamp: item.Timestamp,
+			})
+
+		case RunItemToolOutput:
+			open = -1
+			metadata := withMetadata(item.Metadata, "tool_call_id", item.CallID)
+			if item.IsError {
+				metadata["is_error"] = true
+			}
+			messages = append(messages, Message{
+				Role:      "tool",
+				Content:   item.Content,
+				Parts:     item.Parts,
+				Metadata:  metadata,
+				Timestamp: item.Timestamp,
+			})
+
+		case RunItemHandoff:
+			open = -1
+			metadata := withMetadata(item.Metadata, MetadataHandoff, item.From)
+			metadata[MetadataHandoffTarget] = item.To
+			messages = append(messages, Message{
+				Role:      "system",
+				Content:   item.Content,
+				Metadata:  metadata,
+				Timestamp: item.Timestamp,
+			})
+		}
+	}
+	return messages
+}
+
+// withMetadata returns a copy of metadata with key set to value
+func withMetadata(metadata map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	copied[key] = value
+	return copied
+}
+
+// mergeMetadata returns the keys of both maps, those of b winning
+func mergeMetadata(a, b map[string]interface{}) map[string]interface{} {
+	if len(a) == 0 {
+		return b
+	}
+	merged := make(map[string]interface{}, len(a)+len(b))
+	for k, v := range a {
+		merged[k] = v
+	}
+	for k, v := range b {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,328 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PatchOperation is one operation of a JSON Patch (RFC 6902)
+type PatchOperation struct {
+	// Op is add, remove, replace, move, copy or test
+	Op string `json:"op"`
+
+	// Path is a JSON Pointer (RFC 6901) such as "/tasks/0/done"
+	Path string `json:"path"`
+
+	// From is the source pointer of move and copy
+	From string `json:"from,omitempty"`
+
+	// Value is the value to add, replace with or test against
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ApplyPatch applies a JSON Patch to a JSON document. The operations apply
+// all or not at all: if one fails, the error names it and the document is
+// left as it was.
+func ApplyPatch(doc json.RawMessage, ops []PatchOperation) (json.RawMessage, error) {
+	var root interface{}
+	if len(doc) > 0 {
+		if err := json.Unmarshal(doc, &root); err != nil {
+			return nil, fmt.Errorf("invalid document: %w", err)
+		}
+	}
+
+	for i, op := range ops {
+		var err error
+		if root, err = applyOperation(root, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+func applyOperation(root interface{}, op PatchOperation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		return patchAt(root, path, func(parent interface{}, key string) (interface{}, error) {
+			return addValue(parent, key, copyValue(op.Value))
+		})
+
+	case "remove":
+		if len(path) == 0 {
+			return nil, fmt.Errorf("can't remove the whole document")
+		}
+		return patchAt(root, path, removeValue)
+
+	case "replace":
+		if _, err := getValue(root, path); err != nil {
+			return nil, err
+		}
+		return patchAt(root, path, func(parent interface{}, key string) (interface{}, error) {
+			return replaceValue(parent, key, copyValue(op.Value))
+		})
+
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		value, err := getValue(root, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+
+		if op.Op == "copy" {
+			value = copyValue(value)
+		} else {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, fmt.Errorf("can't move a value into itself")
+			}
+			if root, err = patchAt(root, from, removeValue); err != nil {
+				return nil, err
+			}
+		}
+		return patchAt(root, path, func(parent interface{}, key string) (interface{}, error) {
+			return addValue(parent, key, value)
+		})
+
+	case "test":
+		value, err := getValue(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, copyValue(op.Value)) {
+			return nil, fmt.Errorf("test failed: value is %s", compactJSON(value))
+		}
+		return root, nil
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// parsePointer splits a JSON Pointer into its unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q: must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// patchAt replaces the value at path with the result of fn, which gets the
+// parent container and the last token, and returns the updated document.
+// Containers are updated on the way back up because appending to an array
+// may move it.
+func patchAt(root interface{}, path []string, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 0 {
+		// Whole-document operations act on a wrapper holding the root
+		wrapper, err := fn(map[string]interface{}{"": root}, "")
+		if err != nil {
+			return nil, err
+		}
+		return wrapper.(map[string]interface{})[""], nil
+	}
+	if len(path) == 1 {
+		return fn(root, path[0])
+	}
+
+	child, err := childValue(root, path[0])
+	if err != nil {
+		return nil, err
+	}
+	child, err = patchAt(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+	return replaceValue(root, path[0], child)
+}
+
+func getValue(root interface{}, path []string) (interface{}, error) {
+	value := root
+	for _, token := range path {
+		var err error
+		if value, err = childValue(value, token); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+func childValue(container interface{}, key string) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		value, ok := c[key]
+		if !ok {
+			return nil, fmt.Errorf("no member %q", key)
+		}
+		return value, nil
+	case []interface{}:
+		i, err := arrayIndex(c, key, false)
+		if err != nil {
+			return nil, err
+		}
+		return c[i], nil
+	default:
+		return nil, fmt.Errorf("can't look up %q in %s", key, kindOf(container))
+	}
+}
+
+func addValue(container interface{}, key string, value interface{}) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		c[key] = value
+		return c, nil
+	case []interface{}:
+		i, err := arrayIndex(c, key, true)
+		if err != nil {
+			return nil, err
+		}
+		c = append(c, nil)
+		copy(c[i+1:], c[i:])
+		c[i] = value
+		return c, nil
+	default:
+		return nil, fmt.Errorf("can't add %q to %s", key, kindOf(container))
+	}
+}
+
+func replaceValue(container interface{}, key string, value interface{}) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		c[key] = value
+		return c, nil
+	case []interface{}:
+		i, err := arrayIndex(c, key, false)
+		if err != nil {
+			return nil, err
+		}
+		c[i] = value
+		return c, nil
+	default:
+		return nil, fmt.Errorf("can't set %q in %s", key, kindOf(container))
+	}
+}
+
+func removeValue(container interface{}, key string) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		if _, ok := c[key]; !ok {
+			return nil, fmt.Errorf("no member %q", key)
+		}
+		delete(c, key)
+		return c, nil
+	case []interface{}:
+		i, err := arrayIndex(c, key, false)
+		if err != nil {
+			return nil, err
+		}
+		return append(c[:i], c[i+1:]...), nil
+	default:
+		return nil, fmt.Errorf("can't remove %q from %s", key, kindOf(container))
+	}
+}
+
+// arrayIndex parses an array index token; insertions may use "-" or the
+// array's length to append
+func arrayIndex(array []interface{}, key string, insert bool) (int, error) {
+	if insert && key == "-" {
+		return len(array), nil
+	}
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || (key != "0" && strings.HasPrefix(key, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", key)
+	}
+	if i > len(array) || (!insert && i == len(array)) {
+		return 0, fmt.Errorf("index %d out of range for array of length %d", i, len(array))
+	}
+	return i, nil
+}
+
+// isPrefix reports whether pointer prefix is path or one of its ancestors
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// copyValue deep-copies a value so the document never shares containers
+// with an operation or with another part of itself
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = copyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	default:
+		return normalizeValue(value)
+	}
+}
+
+// normalizeValue converts a value to the types encoding/json decodes into,
+// so values from Go callers compare equal to those in the document
+func normalizeValue(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, bool, float64, string, map[string]interface{}, []interface{}:
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+func kindOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	default:
+		return compactJSON(value)
+	}
+}
+
+func compactJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,372 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RepairJSON fixes the mistakes models commonly make when asked for JSON:
+// markdown code fences, prose around the value, comments, trailing commas,
+// single-quoted strings, unquoted keys, Python literals (True, False, None)
+// and output truncated before its closing brackets. It returns an error if
+// the result is still not valid JSON.
+func RepairJSON(raw string) (string, error) {
+	text := extractJSON(stripCodeFence(strings.TrimSpace(raw)))
+
+	var out strings.Builder
+	var closers []byte
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '"' || c == '\'':
+			i = copyString(&out, runes, i)
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			for i+1 < len(runes) && runes[i+1] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			for i += 2; i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/'); i++ {
+			}
+			i++
+		case c == '{' || c == '[':
+			out.WriteRune(c)
+			if c == '{' {
+				closers = append(closers, '}')
+			} else {
+				closers = append(closers, ']')
+			}
+		case c == '}' || c == ']':
+			trimTrailingComma(&out)
+			out.WriteRune(c)
+			if len(closers) > 0 {
+				closers = closers[:len(closers)-1]
+			}
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1]) || runes[i+1] == '_') {
+				i++
+			}
+			word := string(runes[start : i+1])
+
+			switch {
+			case nextNonSpace(runes, i+1) == ':':
+				// An unquoted object key
+				out.WriteString(strconv.Quote(word))
+			case word == "True":
+				out.WriteString("true")
+			case word == "False":
+				out.WriteString("false")
+			case word == "None":
+				out.WriteString("null")
+			default:
+				out.WriteString(word)
+			}
+		default:
+			out.WriteRune(c)
+		}
+	}
+
+	// Close whatever a truncated response left open
+	trimTrailingComma(&out)
+	for i := len(closers) - 1; i >= 0; i-- {
+		out.WriteByte(closers[i])
+	}
+
+	repaired := out.String()
+	if !json.Valid([]byte(repaired)) {
+		return "", fmt.Errorf("%w: could not repair JSON", ErrInvalidOutput)
+	}
+	return repaired, nil
+}
+
+// stripCodeFence returns the content of the first markdown code block, or
+// text unchanged when it has none
+func stripCodeFence(text string) string {
+	start := strings.Index(text, "```")
+	if start < 0 {
+		return text
+	}
+
+	body := text[start+3:]
+	// Skip the language tag on the opening fence
+	if newline := strings.IndexByte(body, '\n'); newline >= 0 {
+		body = body[newline+1:]
+	}
+	if end := strings.Index(body, "```"); end >= 0 {
+		body = body[:end]
+	}
+	return strings.TrimSpace(body)
+}
+
+// extractJSON drops prose before the first object or array and after the
+// bracket that closes it. Output truncated before that bracket is kept
+// whole, so RepairJSON can close what was left open.
+func extractJSON(text string) string {
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return text
+	}
+
+	depth := 0
+	var quote byte
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '/' && strings.HasPrefix(text[i:], "//"):
+			if end := strings.IndexByte(text[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(text)
+			}
+		case c == '/' && strings.HasPrefix(text[i:], "/*"):
+			if end := strings.Index(text[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(text)
+			}
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return text[start : i+1]
+			}
+		}
+	}
+	return text[start:]
+}
+
+// copyString writes the string literal starting at runes[i] as a JSON
+// string, converting single quotes and closing an unterminated string, and
+// returns the index of its closing quote
+func copyString(out *strings.Builder, runes []rune, i int) int {
+	quote := runes[i]
+	out.WriteByte('"')
+
+	for i++; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\\' && i+1 < len(runes):
+			i++
+			if quote == '\'' && runes[i] == '\'' {
+				out.WriteRune('\'')
+			} else {
+				out.WriteRune('\\')
+				out.WriteRune(runes[i])
+			}
+		case c == quote:
+			out.WriteByte('"')
+			return i
+		case c == '"':
+			out.WriteString(`\"`)
+		case c == '\n':
+			out.WriteString(`\n`)
+		case c == '\t':
+			out.WriteString(`\t`)
+		default:
+			out.WriteRune(c)
+		}
+	}
+
+	out.WriteByte('"')
+	return i
+}
+
+// trimTrailingComma removes a comma, and the whitespace after it, from the
+// end of out
+func trimTrailingComma(out *strings.Builder) {
+	s := out.String()
+	trimmed := strings.TrimRightFunc(s, unicode.IsSpace)
+	if strings.HasSuffix(trimmed, ",") {
+		out.Reset()
+		out.WriteString(trimmed[:len(trimmed)-1])
+	}
+}
+
+// nextNonSpace returns the first non-space rune at or after i, or 0
+func nextNonSpace(runes []rune, i int) rune {
+	for ; i < len(runes); i++ {
+		if !unicode.IsSpace(runes[i]) {
+			return runes[i]
+		}
+	}
+	return 0
+}
+
+// DecodeJSON repairs raw with RepairJSON and decodes it into target,
+// coercing values to the target's field types: numeric and boolean strings
+// become numbers and booleans, numbers become strings, floats are rounded
+// into integer fields and a single value fills a slice. This is how
+// structured output is decoded.
+func DecodeJSON(raw string, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("DecodeJSON requires a non-nil pointer, got %T", target)
+	}
+
+	repaired, err := RepairJSON(raw)
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(repaired), &value); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+	}
+
+	coerced, err := json.Marshal(coerceJSON(value, rv.Type().Elem()))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+	}
+	if err := json.Unmarshal(coerced, target); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+	}
+	return nil
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	rawJSONType   = reflect.TypeOf(json.RawMessage{})
+	unmarshalType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// coerceJSON converts a decoded JSON value to the shape t expects, leaving
+// values it can't convert for json.Unmarshal to report
+func coerceJSON(v interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if v == nil || t == timeType || t == rawJSONType || reflect.PointerTo(t).Implements(unmarshalType) {
+		return v
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		switch x := v.(type) {
+		case float64:
+			return strconv.FormatFloat(x, 'f', -1, 64)
+		case bool:
+			return strconv.FormatBool(x)
+		}
+	case reflect.Bool:
+		switch x := v.(type) {
+		case string:
+			switch strings.ToLower(strings.TrimSpace(x)) {
+			case "true", "yes", "y", "1":
+				return true
+			case "false", "no", "n", "0", "":
+				return false
+			}
+		case float64:
+			return x != 0
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if f, ok := jsonNumber(v); ok {
+			return math.Round(f)
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := jsonNumber(v); ok {
+			return f
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		items, ok := v.([]interface{})
+		if !ok {
+			items = []interface{}{v}
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			result[i] = coerceJSON(item, t.Elem())
+		}
+		return result
+	case reflect.Map:
+		if obj, ok := v.(map[string]interface{}); ok {
+			result := make(map[string]interface{}, len(obj))
+			for key, value := range obj {
+				result[key] = coerceJSON(value, t.Elem())
+			}
+			return result
+		}
+	case reflect.Struct:
+		if obj, ok := v.(map[string]interface{}); ok {
+			fields := jsonFields(t)
+			result := make(map[string]interface{}, len(obj))
+			for key, value := range obj {
+				if field, ok := fields[strings.ToLower(key)]; ok {
+					value = coerceJSON(value, field)
+				}
+				result[key] = value
+			}
+			return result
+		}
+	}
+	return v
+}
+
+// jsonNumber reads a number decoded from JSON or written as a string
+func jsonNumber(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// jsonFields maps the lowercased JSON names of a struct's fields, including
+// promoted fields of embedded structs, to their types
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, typ := range jsonFields(embedded) {
+					if _, ok := fields[key]; !ok {
+						fields[key] = typ
+					}
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+	return fields
+}
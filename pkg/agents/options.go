@@ -1,11 +1,14 @@
 package agents
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/ryanhill4L/agents-sdk/pkg/guardrails"
 	"github.com/ryanhill4L/agents-sdk/pkg/memory"
+	"github.com/ryanhill4L/agents-sdk/pkg/metrics"
 	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+	"github.com/ryanhill4L/agents-sdk/pkg/speech"
 	"github.com/ryanhill4L/agents-sdk/pkg/tools"
 	"github.com/ryanhill4L/agents-sdk/pkg/tracing"
 )
@@ -13,6 +16,13 @@ import (
 // AgentOption configures an Agent
 type AgentOption func(*Agent)
 
+// WithName sets the agent's name, mostly for deriving agents with With
+func WithName(name string) AgentOption {
+	return func(a *Agent) {
+		a.Name = name
+	}
+}
+
 // WithInstructions sets the agent's instructions
 func WithInstructions(instructions string) AgentOption {
 	return func(a *Agent) {
@@ -34,16 +44,43 @@ func WithTools(tools ...tools.Tool) AgentOption {
 	}
 }
 
+// WithScopes grants permission scopes to the agent
+func WithScopes(scopes ...string) AgentOption {
+	return func(a *Agent) {
+		a.Scopes = append(a.Scopes, scopes...)
+	}
+}
+
 // WithHandoffs adds handoff agents
 func WithHandoffs(agents ...*Agent) AgentOption {
 	return func(a *Agent) {
 		a.Handoffs = append(a.Handoffs, agents...)
+		a.indexHandoffs()
+	}
+}
 
-		// Rebuild handoff map
-		a.handoffMap = make(map[string]*Agent)
-		for _, handoff := range a.Handoffs {
-			a.handoffMap[handoff.Name] = handoff
-		}
+// WithHandoffDescription describes what the agent handles to the agents
+// that can hand off to it
+func WithHandoffDescription(description string) AgentOption {
+	return func(a *Agent) {
+		a.HandoffDescription = description
+	}
+}
+
+// HandoffTo returns a variant of agent described for handoffs, for use with
+// WithHandoffs:
+//
+//	agents.WithHandoffs(agents.HandoffTo(billing, "use for billing refunds and invoices"))
+func HandoffTo(agent *Agent, description string) *Agent {
+	return agent.With(WithHandoffDescription(description))
+}
+
+// WithHandoffInputFilters shapes the history the agent receives when it's
+// handed off to, e.g. with KeepLastMessages or DropToolMessages. Derive a
+// variant with Agent.With to filter differently per handoff.
+func WithHandoffInputFilters(filters ...HandoffInputFilter) AgentOption {
+	return func(a *Agent) {
+		a.HandoffInputFilters = append(a.HandoffInputFilters, filters...)
 	}
 }
 
@@ -54,7 +91,9 @@ func WithGuardrails(guardrails ...guardrails.Guardrail) AgentOption {
 	}
 }
 
-// WithOutputType sets structured output schema
+// WithOutputType sets structured output schema. Text responses are decoded
+// as JSON after repairing common mistakes such as code fences and trailing
+// commas; see RepairJSON and OutputOf.
 func WithOutputType(schema OutputSchema) AgentOption {
 	return func(a *Agent) {
 		a.OutputType = schema
@@ -75,6 +114,31 @@ func WithMaxTokens(tokens int) AgentOption {
 	}
 }
 
+// WithTopP sets the nucleus sampling parameter
+func WithTopP(topP float32) AgentOption {
+	return func(a *Agent) {
+		a.TopP = topP
+	}
+}
+
+// WithParallelToolCalls allows or forbids the model from requesting several
+// tool calls in a single response. Disable it for stateful tools that must
+// be used one at a time.
+func WithParallelToolCalls(enabled bool) AgentOption {
+	return func(a *Agent) {
+		a.ParallelToolCalls = &enabled
+	}
+}
+
+// WithToolProtocol sets how the agent's tools are offered to the model.
+// Use ToolProtocolReAct or ToolProtocolXML for models without native
+// function calling, or ToolProtocolAuto to decide by provider capabilities.
+func WithToolProtocol(protocol ToolProtocol) AgentOption {
+	return func(a *Agent) {
+		a.ToolProtocol = protocol
+	}
+}
+
 // RunnerOption configures a Runner
 type RunnerOption func(*Runner)
 
@@ -99,6 +163,101 @@ func WithSession(session memory.Session) RunnerOption {
 	}
 }
 
+// WithBatchPersistence saves a run's messages to its session only once the
+// run completes, instead of before each model call. A run that fails or
+// times out then leaves its session as it was, save for shutdown
+// checkpoints.
+func WithBatchPersistence(enabled bool) RunnerOption {
+	return func(r *Runner) {
+		r.batchPersistence = enabled
+	}
+}
+
+// WithSessionStore sets the store used to open sessions selected per call
+// with WithSessionID or Runner.Continue
+func WithSessionStore(store memory.SessionStore) RunnerOption {
+	return func(r *Runner) {
+		r.sessionStore = store
+	}
+}
+
+// WithInputProcessors preprocesses the input of every run, in order,
+// before the first turn and before input guardrails see it
+func WithInputProcessors(processors ...InputProcessor) RunnerOption {
+	return func(r *Runner) {
+		r.inputProcessors = append(r.inputProcessors, processors...)
+	}
+}
+
+// WithOutputRepair lets the model retry up to attempts times when its
+// response to an agent with an output schema is not valid JSON for the
+// schema, even after local repair. Each retry is a turn that sends the
+// model the error and the schema.
+func WithOutputRepair(attempts int) RunnerOption {
+	return func(r *Runner) {
+		r.outputRepairs = attempts
+	}
+}
+
+// WithSessionState gives each session a JSON state document in store and
+// offers every agent get_state and patch_state tools to read it and update
+// it with JSON Patch, so task state such as a plan or collected facts
+// survives beyond the chat history and its compaction. patch_state is a
+// mutating tool, so dry runs don't change the state. Runs with a tenant
+// store the document under memory.TenantKey(tenantID, sessionID).
+func WithSessionState(store memory.StateStore) RunnerOption {
+	return func(r *Runner) {
+		r.stateStore = store
+	}
+}
+
+// WithScratchpad gives each run a scratchpad and offers every agent an
+// update_scratchpad tool to write plans and intermediate notes to it. The
+// notes are shown to the model at the end of every prompt but aren't added
+// to the conversation or saved to the session, so working notes don't
+// clutter the history; the run's final notes are in RunResult.Scratchpad.
+func WithScratchpad(enabled bool) RunnerOption {
+	return func(r *Runner) {
+		r.scratchpad = enabled
+	}
+}
+
+// WithEntityMemory remembers the people, accounts, venues and other
+// entities a user's conversations mention. After each run the extractor
+// records the entities and facts from its messages in the store, in the
+// background, and each run starts by adding cards for the user's most
+// relevant entities to its prompts, without adding them to the history.
+// Entities belong to the run's end user (WithEndUser), or its session when
+// it has none, within its tenant; runs with neither don't use the memory.
+func WithEntityMemory(config EntityMemory) RunnerOption {
+	return func(r *Runner) {
+		r.entityMemory = &config
+	}
+}
+
+// WithFinalAnswerTool offers agents with an output schema a final_answer
+// tool taking the schema as its parameters. The run ends when the model
+// calls it with a valid answer, which is more reliable than parsing text
+// from models without native structured output. An invalid answer is
+// returned to the model as the tool's error, within the WithOutputRepair
+// budget. Text responses are still parsed as before.
+func WithFinalAnswerTool(enabled bool) RunnerOption {
+	return func(r *Runner) {
+		r.finalAnswerTool = enabled
+	}
+}
+
+// WithSelfEvaluation has the model rate its confidence and list unresolved
+// questions when it answers, surfaced as RunResult.Evaluation for routing
+// doubtful answers to people. Every agent is offered the final_answer tool
+// (see WithFinalAnswerTool), taking the answer, a confidence from 0 to 1 and
+// the questions; agents without an output schema answer in text.
+func WithSelfEvaluation(enabled bool) RunnerOption {
+	return func(r *Runner) {
+		r.selfEvaluation = enabled
+	}
+}
+
 // WithMaxTurns sets the maximum turns
 func WithMaxTurns(turns int) RunnerOption {
 	return func(r *Runner) {
@@ -106,16 +265,380 @@ func WithMaxTurns(turns int) RunnerOption {
 	}
 }
 
-// WithTimeout sets the execution timeout
+// WithMaxHandoffs fails a run with ErrHandoffLimit once its agents have
+// handed off more than n times, returns included, ending loops where agents
+// keep passing the conversation back and forth. Zero means no limit.
+func WithMaxHandoffs(n int) RunnerOption {
+	return func(r *Runner) {
+		r.maxHandoffs = n
+	}
+}
+
+// WithMaxHandoffDepth fails a run with ErrHandoffLimit when a handoff would
+// leave more than n delegating agents waiting to be handed back to. Zero
+// means no limit.
+func WithMaxHandoffDepth(n int) RunnerOption {
+	return func(r *Runner) {
+		r.maxHandoffDepth = n
+	}
+}
+
+// WithTimeout sets the execution timeout for a whole run. Provider
+// retries and per-request timeouts (ProviderConfig.Timeout, MaxRetries)
+// happen within it. A run that exceeds it fails with a *TimeoutError
+// carrying the partial result.
 func WithTimeout(timeout time.Duration) RunnerOption {
 	return func(r *Runner) {
 		r.timeout = timeout
 	}
 }
 
-// WithParallelTools enables/disables parallel tool execution
+// WithParallelTools enables/disables parallel tool execution. Calls to
+// mutating tools (see tools.Capabilities) run one at a time even when enabled.
 func WithParallelTools(parallel bool) RunnerOption {
 	return func(r *Runner) {
 		r.parallelTools = parallel
 	}
 }
+
+// WithToolErrorBehavior sets how tool execution errors are handled
+func WithToolErrorBehavior(behavior ToolErrorBehavior) RunnerOption {
+	return func(r *Runner) {
+		r.toolErrorBehavior = behavior
+	}
+}
+
+// WithToolErrorHandler installs a custom handler for tool execution errors
+func WithToolErrorHandler(handler ToolErrorHandler) RunnerOption {
+	return func(r *Runner) {
+		r.toolErrorBehavior = ToolErrorCustom
+		r.toolErrorHandler = handler
+	}
+}
+
+// WithToolArgumentValidation toggles checking tool call arguments against
+// the tool schema before execution (enabled by default)
+func WithToolArgumentValidation(enabled bool) RunnerOption {
+	return func(r *Runner) {
+		r.validateToolArgs = enabled
+	}
+}
+
+// WithDryRun toggles dry-run mode, in which calls to mutating tools (see
+// tools.MutatingTool) aren't executed. The model is told the call was
+// skipped, and the calls are listed in RunMetrics.DryRunCalls, so a new
+// agent can be tried on production prompts without side effects. Tools that
+// don't declare themselves mutating still run.
+func WithDryRun(enabled bool) RunnerOption {
+	return func(r *Runner) {
+		r.dryRun = enabled
+	}
+}
+
+// WithToolPrefetch toggles speculative tool execution. With a provider that
+// streams tool calls (see providers.ToolCallStreamer), calls to read-only
+// tools start as soon as the model has finished writing them, while the rest
+// of the completion streams in, and the result is used if the final call has
+// the same arguments. Mutating tools, and every tool when an audit log or
+// tool approval is configured, wait for the completion as usual. Calls the
+// final completion drops are cancelled, but a prefetched tool may already
+// have done its work, so only enable this for tools that are cheap to waste.
+func WithToolPrefetch(enabled bool) RunnerOption {
+	return func(r *Runner) {
+		r.prefetchTools = enabled
+	}
+}
+
+// WithToolApproval sets a hook that approves each tool call before it runs,
+// e.g. to require sign-off for mutating or costly tools:
+//
+//	agents.WithToolApproval(func(ctx context.Context, call agents.ToolCall, caps tools.Capabilities) error {
+//		if caps.Mutating || caps.Cost == tools.CostHigh {
+//			return askOperator(ctx, call)
+//		}
+//		return nil
+//	})
+//
+// Calls skipped by a dry run aren't sent for approval.
+func WithToolApproval(approver ToolApprover) RunnerOption {
+	return func(r *Runner) {
+		r.toolApprover = approver
+	}
+}
+
+// WithToolCallCache enables reuse of results for repeated identical calls to
+// idempotent tools within a run
+func WithToolCallCache(enabled bool) RunnerOption {
+	return func(r *Runner) {
+		r.cacheToolCalls = enabled
+	}
+}
+
+// WithSpeech synthesizes audio of the final output after each run. If
+// synthesis fails, Run returns the completed result without audio together
+// with an error wrapping ErrSpeechSynthesis.
+func WithSpeech(synthesizer speech.Synthesizer) RunnerOption {
+	return func(r *Runner) {
+		r.synthesizer = synthesizer
+	}
+}
+
+// WithContextOverflowPolicy retries context-too-long failures on a larger
+// model and/or a compacted history instead of failing the run
+func WithContextOverflowPolicy(policy ContextOverflowPolicy) RunnerOption {
+	return func(r *Runner) {
+		r.overflowPolicy = &policy
+	}
+}
+
+// WithStopCondition ends a run after any turn for which condition returns true.
+// The last assistant message becomes the final output.
+func WithStopCondition(condition StopCondition) RunnerOption {
+	return func(r *Runner) {
+		r.stopConditions = append(r.stopConditions, condition)
+	}
+}
+
+// WithMetrics reports tool execution measurements to recorder
+func WithMetrics(recorder metrics.Recorder) RunnerOption {
+	return func(r *Runner) {
+		r.metrics = recorder
+	}
+}
+
+// WithTraceContent attaches message content, tool arguments and tool results
+// to spans so traces can be replayed. Leave it off when conversations hold
+// data that must not reach the tracing backend.
+func WithTraceContent(enabled bool) RunnerOption {
+	return func(r *Runner) {
+		r.traceContent = enabled
+	}
+}
+
+// WithEventBus sets the bus run events are published to, replacing the
+// process-wide Events() bus; nil disables events
+func WithEventBus(bus *EventBus) RunnerOption {
+	return func(r *Runner) {
+		r.events = bus
+	}
+}
+
+// WithAuditLog records every tool call and handoff in log. Tool calls are
+// recorded before they run and again when they end; a tool whose start
+// can't be recorded isn't run, and a failed handoff record fails the run.
+func WithAuditLog(log memory.AuditLog) RunnerOption {
+	return func(r *Runner) {
+		r.auditLog = log
+	}
+}
+
+// WithTenantLimiter enforces per-tenant rate limits and concurrency on runs
+// made WithTenant. Runs over a limit fail with a *TenantLimitError.
+func WithTenantLimiter(limiter *TenantLimiter) RunnerOption {
+	return func(r *Runner) {
+		r.tenantLimiter = limiter
+	}
+}
+
+// WithQuotaEnforcer records the usage of every run and enforces the daily
+// budgets of their tenants and usage keys (see WithTenant and WithUsageKey)
+func WithQuotaEnforcer(enforcer *QuotaEnforcer) RunnerOption {
+	return func(r *Runner) {
+		r.quotaEnforcer = enforcer
+	}
+}
+
+// WithRunStore persists run results by idempotency key
+func WithRunStore(store memory.RunStore) RunnerOption {
+	return func(r *Runner) {
+		r.runStore = store
+	}
+}
+
+// RunOption configures a single call to Runner.Run
+type RunOption func(*runConfig)
+
+// runConfig holds per-call settings collected from RunOptions
+type runConfig struct {
+	files       []File
+	metadata    map[string]interface{}
+	skipSession bool
+	sessionID   string
+	tenantID    string
+	usageKey    string
+	scopes      []string
+	request     providers.RequestOptions
+	priority    providers.Priority
+
+	idempotencyKey string
+	turnContext    TurnContextFunc
+
+	samples        int
+	sampleSelector SampleSelector
+
+	// history, when set, is the whole conversation to run on in place of
+	// the input
+	history []Message
+
+	// mutated is set once the run calls a tool that may mutate
+	mutated atomic.Bool
+}
+
+// withoutSession keeps a run from loading or saving the runner's session
+func withoutSession() RunOption {
+	return func(c *runConfig) {
+		c.skipSession = true
+	}
+}
+
+// withHistory runs on messages instead of the input, as the debugger does to
+// resume a recorded run
+func withHistory(messages []Message) RunOption {
+	return func(c *runConfig) {
+		c.history = messages
+	}
+}
+
+// WithSessionID runs against the named session from the runner's session
+// store instead of the runner-wide session
+func WithSessionID(sessionID string) RunOption {
+	return func(c *runConfig) {
+		c.sessionID = sessionID
+	}
+}
+
+// WithPriority sets the priority at which the run waits for a RunnerPool
+// slot and for capacity on a providers.LimitedProvider, so interactive runs
+// go ahead of batch work sharing the same pool or provider
+func WithPriority(priority providers.Priority) RunOption {
+	return func(c *runConfig) {
+		c.priority = priority
+	}
+}
+
+// WithTenant runs on behalf of a tenant of a multi-tenant application. The
+// run's session and idempotency key are stored under the tenant, so tenants
+// using the same IDs never see each other's data, its usage is recorded per
+// tenant and the runner's TenantLimiter applies. Tenant runs must use
+// WithSessionID or no session at all.
+func WithTenant(tenantID string) RunOption {
+	return func(c *runConfig) {
+		c.tenantID = tenantID
+	}
+}
+
+// WithUsageKey attributes the run's usage to a key within its tenant, such
+// as the API key or end user it was made with, for the runner's
+// QuotaEnforcer
+func WithUsageKey(key string) RunOption {
+	return func(c *runConfig) {
+		c.usageKey = key
+	}
+}
+
+// WithGrantedScopes limits the run to the given permission scopes, such as
+// those of the end user. A scoped tool then needs its scopes granted to both
+// the agent and the run.
+func WithGrantedScopes(scopes ...string) RunOption {
+	return func(c *runConfig) {
+		if c.scopes == nil {
+			c.scopes = make([]string, 0, len(scopes))
+		}
+		c.scopes = append(c.scopes, scopes...)
+	}
+}
+
+// WithRequestHeaders adds HTTP headers, such as tracking IDs or gateway
+// routing hints, to every provider request of the run
+func WithRequestHeaders(headers map[string]string) RunOption {
+	return func(c *runConfig) {
+		c.request = c.request.Merge(providers.RequestOptions{Headers: headers})
+	}
+}
+
+// WithRequestMetadata attaches metadata to every provider request of the run
+func WithRequestMetadata(metadata map[string]string) RunOption {
+	return func(c *runConfig) {
+		c.request = c.request.Merge(providers.RequestOptions{Metadata: metadata})
+	}
+}
+
+// WithEndUser identifies the end user in every provider request of the run
+func WithEndUser(user string) RunOption {
+	return func(c *runConfig) {
+		c.request.User = user
+	}
+}
+
+// WithIdempotencyKey makes the run execute at most once per key: repeating
+// a completed run returns its stored result without calling the model or
+// tools again. A failed run may be retried under the same key unless it
+// called a tool that may have side effects: tools declared WithMutating(true)
+// and tools that declare neither WithMutating nor WithIdempotent. Declare
+// read-only tools WithMutating(false) so their runs stay retryable. Requires
+// a runner configured WithRunStore.
+func WithIdempotencyKey(key string) RunOption {
+	return func(c *runConfig) {
+		c.idempotencyKey = key
+	}
+}
+
+// WithTurnContext adds the messages fn returns to the conversation before
+// each turn, such as system messages carrying state that changed since the
+// previous turn. The messages are kept in the history and saved with it.
+func WithTurnContext(fn TurnContextFunc) RunOption {
+	return func(c *runConfig) {
+		c.turnContext = fn
+	}
+}
+
+// WithNSamples requests n alternative completions per turn, in one request
+// when the provider supports it and as parallel calls otherwise, and
+// continues with the one picked by the WithSampleSelector selector (the
+// first without one). Every candidate is kept in RunResult.Candidates and
+// the tokens of all of them are counted.
+func WithNSamples(n int) RunOption {
+	return func(c *runConfig) {
+		c.samples = n
+	}
+}
+
+// WithSampleSelector sets how WithNSamples picks the best candidate, such
+// as ScoreSelector or JudgeSelector
+func WithSampleSelector(selector SampleSelector) RunOption {
+	return func(c *runConfig) {
+		c.sampleSelector = selector
+	}
+}
+
+// WithFiles attaches documents to the run's input message
+func WithFiles(files ...File) RunOption {
+	return func(c *runConfig) {
+		c.files = append(c.files, files...)
+	}
+}
+
+// WithInputMetadata sets metadata on the run's input message, such as the
+// speaker's providers.MetadataName or a providers.MetadataCache breakpoint
+func WithInputMetadata(metadata map[string]interface{}) RunOption {
+	return func(c *runConfig) {
+		if c.metadata == nil {
+			c.metadata = make(map[string]interface{}, len(metadata))
+		}
+		for key, value := range metadata {
+			c.metadata[key] = value
+		}
+	}
+}
+
+// WithCitedFiles attaches source documents the model should cite. Providers
+// that support citations (Anthropic) return the cited passages, available
+// from RunResult.Citations; others receive the files like WithFiles.
+func WithCitedFiles(files ...File) RunOption {
+	return func(c *runConfig) {
+		for _, file := range files {
+			file.cited = true
+			c.files = append(c.files, file)
+		}
+	}
+}
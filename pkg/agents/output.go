@@ -0,0 +1,98 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/tools"
+)
+
+// outputDecoder is implemented by output schemas that decode text into a
+// typed value, such as those created by OutputOf
+type outputDecoder interface {
+	decode(raw string) (interface{}, error)
+}
+
+// typedOutput is the OutputSchema created by OutputOf
+type typedOutput[T any] struct {
+	schema json.RawMessage
+}
+
+// OutputOf returns an output schema for T: its JSON schema is derived from
+// the type, and a run's final output is decoded into a T with DecodeJSON,
+// so FinalOutput holds a T rather than text
+func OutputOf[T any]() OutputSchema {
+	var zero T
+	schema := json.RawMessage(`{"type":"object"}`)
+	if derived, err := tools.SchemaOf(zero); err == nil {
+		if data, err := json.Marshal(derived); err == nil {
+			schema = data
+		}
+	}
+	return &typedOutput[T]{schema: schema}
+}
+
+// Schema implements OutputSchema
+func (o *typedOutput[T]) Schema() json.RawMessage {
+	return o.schema
+}
+
+// Validate implements OutputSchema, accepting a T or any value that
+// converts to one
+func (o *typedOutput[T]) Validate(value interface{}) error {
+	if _, ok := value.(T); ok {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+	}
+	var target T
+	return DecodeJSON(string(data), &target)
+}
+
+func (o *typedOutput[T]) decode(raw string) (interface{}, error) {
+	var value T
+	if err := DecodeJSON(raw, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// parseOutput decodes a text response for an agent with an output schema,
+// repairing the JSON first
+func parseOutput(schema OutputSchema, content string) (interface{}, error) {
+	if decoder, ok := schema.(outputDecoder); ok {
+		return decoder.decode(content)
+	}
+
+	repaired, err := RepairJSON(content)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(repaired), &value); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+	}
+	if err := schema.Validate(value); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOutput, err)
+	}
+	return value, nil
+}
+
+// repairRequest asks the model to fix output that didn't match the schema
+func repairRequest(schema OutputSchema, err error) Message {
+	var b strings.Builder
+	b.WriteString("Your previous response could not be used: ")
+	b.WriteString(err.Error())
+	b.WriteString("\n\nFix it to match this JSON schema and respond with only the corrected JSON object:\n")
+	b.Write(schema.Schema())
+	return Message{
+		Role:      "user",
+		Content:   b.String(),
+		Metadata:  map[string]interface{}{"output_repair": true},
+		Timestamp: time.Now(),
+	}
+}
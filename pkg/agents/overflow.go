@@ -0,0 +1,85 @@
+package agents
+
+import (
+	"context"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// ContextOverflowPolicy controls recovery when a completion fails because the
+// conversation no longer fits the model's context window
+type ContextOverflowPolicy struct {
+	// FallbackModel is a larger-context model to retry with. The rest of the
+	// run continues on this model.
+	FallbackModel string
+
+	// Compact shrinks the conversation before retrying. It runs after the
+	// fallback model also overflows, or first when no fallback is set.
+	Compact func(ctx context.Context, messages []Message) ([]Message, error)
+}
+
+// KeepLastMessages returns a compaction function that keeps the most recent
+// n messages, never starting them with an orphaned tool result, after the
+// current run's input: the latest user message, since earlier ones belong to
+// the session's previous runs
+func KeepLastMessages(n int) func(ctx context.Context, messages []Message) ([]Message, error) {
+	return func(ctx context.Context, messages []Message) ([]Message, error) {
+		if n <= 0 || len(messages) <= n+1 {
+			return messages, nil
+		}
+
+		start := len(messages) - n
+		for start < len(messages) && messages[start].Role == "tool" {
+			start++
+		}
+
+		compacted := make([]Message, 0, len(messages)-start+1)
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role == "user" {
+				// An input already in the tail isn't kept twice
+				if i < start {
+					compacted = append(compacted, messages[i])
+				}
+				break
+			}
+		}
+		compacted = append(compacted, messages[start:]...)
+		return compacted, nil
+	}
+}
+
+// complete requests n alternative completions (usually one), applying the
+// context overflow policy when the provider rejects the prompt as too long.
+// It returns the agent and messages actually used so the run can continue
+// with them.
+func (r *Runner) complete(ctx context.Context, agent *Agent, messages []Message, toolDefs []providers.ToolDefinition, n int) ([]*providers.Completion, *Agent, []Message, error) {
+	completions, err := r.sample(ctx, agent, messages, toolDefs, n)
+	if err == nil || r.overflowPolicy == nil || !providers.IsContextTooLongError(err) {
+		return completions, agent, messages, err
+	}
+
+	policy := r.overflowPolicy
+
+	if policy.FallbackModel != "" && agent.Model != policy.FallbackModel {
+		fallback := agent.Clone()
+		fallback.Model = policy.FallbackModel
+
+		completions, err = r.sample(ctx, fallback, messages, toolDefs, n)
+		agent = fallback
+		if err == nil || !providers.IsContextTooLongError(err) {
+			return completions, agent, messages, err
+		}
+	}
+
+	if policy.Compact != nil {
+		compacted, cerr := policy.Compact(ctx, messages)
+		if cerr != nil {
+			return nil, agent, messages, cerr
+		}
+
+		completions, err = r.sample(ctx, agent, compacted, toolDefs, n)
+		return completions, agent, compacted, err
+	}
+
+	return nil, agent, messages, err
+}
@@ -0,0 +1,137 @@
+package agents
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// RunnerPool serves runs from many callers with one shared Runner, so the
+// provider and its HTTP connections are reused, and at most n runs execute
+// at once. Callers beyond the limit wait in line, highest WithPriority
+// first, so interactive runs aren't stuck behind queued batch jobs.
+type RunnerPool struct {
+	runner *Runner
+	slots  *providers.PriorityLimiter
+
+	mu    sync.Mutex
+	stats PoolStats
+}
+
+// PoolStats reports the load and latency of a RunnerPool
+type PoolStats struct {
+	// Size is the maximum number of concurrent runs
+	Size int `json:"size"`
+
+	// Running and Queued are the runs executing and waiting for a slot
+	Running int `json:"running"`
+	Queued  int `json:"queued"`
+
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+
+	// Rejected counts callers whose context ended while queued
+	Rejected int `json:"rejected"`
+
+	// TotalWait and TotalRun sum the time runs spent queued and executing
+	TotalWait time.Duration `json:"total_wait"`
+	TotalRun  time.Duration `json:"total_run"`
+	MaxWait   time.Duration `json:"max_wait"`
+}
+
+// MeanWait returns the average time runs waited for a slot
+func (s PoolStats) MeanWait() time.Duration {
+	if n := s.Completed + s.Failed; n > 0 {
+		return s.TotalWait / time.Duration(n)
+	}
+	return 0
+}
+
+// MeanRun returns the average run duration
+func (s PoolStats) MeanRun() time.Duration {
+	if n := s.Completed + s.Failed; n > 0 {
+		return s.TotalRun / time.Duration(n)
+	}
+	return 0
+}
+
+// NewRunnerPool creates a pool running at most n runs at once on a runner
+// built from opts
+func NewRunnerPool(n int, opts ...RunnerOption) *RunnerPool {
+	if n <= 0 {
+		n = 1
+	}
+
+	return &RunnerPool{
+		runner: NewRunner(opts...),
+		slots:  providers.NewPriorityLimiter(n),
+		stats:  PoolStats{Size: n},
+	}
+}
+
+// Runner returns the shared runner
+func (p *RunnerPool) Runner() *Runner {
+	return p.runner
+}
+
+// Run waits for a free slot and executes the agent. It returns the context's
+// error if ctx ends while waiting.
+func (p *RunnerPool) Run(ctx context.Context, agent *Agent, input string, opts ...RunOption) (*RunResult, error) {
+	cfg := &runConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	queuedAt := time.Now()
+	p.update(func(s *PoolStats) { s.Queued++ })
+
+	if err := p.slots.Acquire(ctx, cfg.priority); err != nil {
+		p.update(func(s *PoolStats) {
+			s.Queued--
+			s.Rejected++
+		})
+		return nil, err
+	}
+	defer p.slots.Release()
+
+	wait := time.Since(queuedAt)
+	p.update(func(s *PoolStats) {
+		s.Queued--
+		s.Running++
+		s.TotalWait += wait
+		if wait > s.MaxWait {
+			s.MaxWait = wait
+		}
+	})
+
+	start := time.Now()
+	result, err := p.runner.Run(ctx, agent, input, opts...)
+	elapsed := time.Since(start)
+
+	p.update(func(s *PoolStats) {
+		s.Running--
+		s.TotalRun += elapsed
+		if err != nil {
+			s.Failed++
+		} else {
+			s.Completed++
+		}
+	})
+
+	return result, err
+}
+
+// Stats returns a snapshot of the pool's load and latency
+func (p *RunnerPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+func (p *RunnerPool) update(fn func(*PoolStats)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fn(&p.stats)
+}
@@ -0,0 +1,161 @@
+package agents
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+	"github.com/ryanhill4L/agents-sdk/pkg/tools"
+)
+
+// toolPrefetch runs the tool calls a streaming completion reports before the
+// completion finishes. When the run gets to a call, it takes the prefetched
+// result if the final call has the same name and arguments; calls the final
+// completion doesn't make, or makes differently, are cancelled.
+type toolPrefetch struct {
+	r      *Runner
+	runCtx *RunContext
+	agent  *Agent
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	pending map[string]*prefetchedCall
+}
+
+// prefetchedCall is a tool call running ahead of its completion
+type prefetchedCall struct {
+	done     chan struct{}
+	result   interface{}
+	err      error
+	duration time.Duration
+}
+
+// newToolPrefetch prefetches calls to the agent's tools for one turn
+func (r *Runner) newToolPrefetch(ctx context.Context, runCtx *RunContext, agent *Agent) *toolPrefetch {
+	ctx, cancel := context.WithCancel(ctx)
+	return &toolPrefetch{
+		r:       r,
+		runCtx:  runCtx,
+		agent:   agent,
+		ctx:     ctx,
+		cancel:  cancel,
+		pending: make(map[string]*prefetchedCall),
+	}
+}
+
+// start begins a call if it is safe to run before the model commits to it:
+// the tool must not be mutating and the call must pass the checks
+// executeTool would make. Calls that need approval or an audit record
+// always wait for the completion.
+func (p *toolPrefetch) start(call providers.ToolCall) {
+	r := p.r
+	if r.auditLog != nil || r.toolApprover != nil {
+		return
+	}
+
+	tool := r.findTool(p.agent, call.Name)
+	if tool == nil || tools.IsMutating(tool) {
+		return
+	}
+	// A scratchpad write can't be taken back if the final call differs
+	if _, ok := tool.(scratchpadTool); ok {
+		return
+	}
+	if err := authorizeTool(p.runCtx, p.agent, tool); err != nil {
+		return
+	}
+	if r.validateToolArgs {
+		if err := tools.ValidateArguments(tool, call.Arguments); err != nil {
+			return
+		}
+	}
+
+	toolCall := ToolCall(call)
+	if p.runCtx.toolCache != nil && tools.IsIdempotent(tool) {
+		if _, ok := p.runCtx.toolCache.get(toolCall); ok {
+			return
+		}
+	}
+
+	key, ok := callKey(toolCall)
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.pending[key]; ok {
+		return
+	}
+	pc := &prefetchedCall{done: make(chan struct{})}
+	p.pending[key] = pc
+
+	go func() {
+		defer close(pc.done)
+
+		start := time.Now()
+		pc.result, pc.err = executeWithContext(p.ctx, tool, call.Arguments)
+		if pc.err == nil {
+			if text, ok := tools.FormatResult(tool, pc.result); ok {
+				pc.result = text
+			}
+		}
+		pc.duration = time.Since(start)
+	}()
+}
+
+// take claims the prefetched run of call, if there is one
+func (p *toolPrefetch) take(call ToolCall) (*prefetchedCall, bool) {
+	if p == nil {
+		return nil, false
+	}
+	key, ok := callKey(call)
+	if !ok {
+		return nil, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pc, ok := p.pending[key]
+	delete(p.pending, key)
+	return pc, ok
+}
+
+// stop cancels the calls that weren't taken. Taken calls have finished by
+// the time the turn's tools have run, so it is called once they have.
+func (p *toolPrefetch) stop() {
+	if p == nil {
+		return
+	}
+	p.cancel()
+}
+
+// wait returns the result of a prefetched call, or ctx's error if it ends
+// first
+func (pc *prefetchedCall) wait(ctx context.Context) (interface{}, error) {
+	select {
+	case <-pc.done:
+		return pc.result, pc.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type prefetchKey struct{}
+
+// contextWithPrefetch returns a context carrying the turn's prefetch to the
+// provider call
+func contextWithPrefetch(ctx context.Context, p *toolPrefetch) context.Context {
+	return context.WithValue(ctx, prefetchKey{}, p)
+}
+
+// prefetchFromContext returns the turn's prefetch, nil if tools aren't
+// prefetched
+func prefetchFromContext(ctx context.Context) *toolPrefetch {
+	p, _ := ctx.Value(prefetchKey{}).(*toolPrefetch)
+	return p
+}
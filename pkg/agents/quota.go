@@ -0,0 +1,251 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/memory"
+)
+
+// QuotaAction selects what happens to a run whose budget is exhausted
+type QuotaAction int
+
+const (
+	// QuotaReject fails the run with a *QuotaExceededError
+	QuotaReject QuotaAction = iota
+
+	// QuotaQueue holds the run until the budget is raised, the day rolls
+	// over or the run's context ends
+	QuotaQueue
+)
+
+// Quota is a daily budget. Zero fields are unlimited.
+type Quota struct {
+	DailyRuns   int
+	DailyTokens int
+	DailyCost   float64
+
+	// Exhausted selects what happens to runs once a budget is spent
+	Exhausted QuotaAction
+}
+
+// QuotaStatus reports a budget's use on the current day
+type QuotaStatus struct {
+	Quota Quota              `json:"quota"`
+	Used  memory.UsageTotals `json:"used"`
+
+	// Remaining fields of unlimited budgets are -1
+	Remaining memory.UsageTotals `json:"remaining"`
+
+	// ResetsAt is the start of the next UTC day
+	ResetsAt time.Time `json:"resets_at"`
+}
+
+// exceeded names the first budget used up, or "" when none is
+func (s QuotaStatus) exceeded() string {
+	switch {
+	case s.Quota.DailyRuns > 0 && s.Used.Runs >= s.Quota.DailyRuns:
+		return "daily_runs"
+	case s.Quota.DailyTokens > 0 && s.Used.Tokens >= s.Quota.DailyTokens:
+		return "daily_tokens"
+	case s.Quota.DailyCost > 0 && s.Used.Cost >= s.Quota.DailyCost:
+		return "daily_cost"
+	}
+	return ""
+}
+
+// QuotaEnforcer accounts each run's tokens and cost in a UsageStore per
+// tenant, usage key and day, and admits runs only while their budgets last.
+// A tenant's quota covers all its keys; a key can have its own quota on top.
+// Budgets are checked when a run starts, so the run that spends the last of
+// a token or cost budget may overshoot it; run budgets are never overshot.
+type QuotaEnforcer struct {
+	store   memory.UsageStore
+	onError func(error)
+
+	mu       sync.RWMutex
+	defaults Quota
+	quotas   map[quotaScope]Quota
+
+	pollInterval time.Duration
+	now          func() time.Time
+}
+
+// quotaScope is a tenant, for an empty key, or one of its keys
+type quotaScope struct {
+	tenantID string
+	key      string
+}
+
+// QuotaOption configures a QuotaEnforcer
+type QuotaOption func(*QuotaEnforcer)
+
+// WithQuotaPollInterval sets how often queued runs recheck their budget
+// (default one minute)
+func WithQuotaPollInterval(interval time.Duration) QuotaOption {
+	return func(e *QuotaEnforcer) {
+		e.pollInterval = interval
+	}
+}
+
+// WithQuotaErrorHandler receives failures to record a completion's usage.
+// They don't fail the run, whose completion has already been paid for, so
+// they are dropped without a handler.
+func WithQuotaErrorHandler(handler func(error)) QuotaOption {
+	return func(e *QuotaEnforcer) {
+		e.onError = handler
+	}
+}
+
+// NewQuotaEnforcer creates an enforcer recording usage in store and applying
+// defaults to every tenant without a quota of its own
+func NewQuotaEnforcer(store memory.UsageStore, defaults Quota, opts ...QuotaOption) *QuotaEnforcer {
+	e := &QuotaEnforcer{
+		store:        store,
+		defaults:     defaults,
+		quotas:       make(map[quotaScope]Quota),
+		pollInterval: time.Minute,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// SetQuota sets the budget of tenantID's key, or of the whole tenant when
+// key is empty
+func (e *QuotaEnforcer) SetQuota(tenantID, key string, quota Quota) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.quotas[quotaScope{tenantID: tenantID, key: key}] = quota
+}
+
+// quota returns the budget of scope and whether it has one
+func (e *QuotaEnforcer) quota(scope quotaScope) (Quota, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if quota, ok := e.quotas[scope]; ok {
+		return quota, true
+	}
+	if scope.key == "" {
+		return e.defaults, true
+	}
+	return Quota{}, false
+}
+
+// Remaining reports today's use of the budget of tenantID's key, or of the
+// whole tenant when key is empty
+func (e *QuotaEnforcer) Remaining(ctx context.Context, tenantID, key string) (QuotaStatus, error) {
+	quota, _ := e.quota(quotaScope{tenantID: tenantID, key: key})
+	return e.status(ctx, tenantID, key, quota)
+}
+
+// status reads today's usage against quota
+func (e *QuotaEnforcer) status(ctx context.Context, tenantID, key string, quota Quota) (QuotaStatus, error) {
+	today := memory.UsageDay(e.now())
+	used, err := e.store.Usage(ctx, memory.UsageQuery{TenantID: tenantID, Key: key, From: today, To: today})
+	if err != nil {
+		return QuotaStatus{}, err
+	}
+
+	status := QuotaStatus{
+		Quota:     quota,
+		Used:      used,
+		Remaining: memory.UsageTotals{Runs: -1, Tokens: -1, Cost: -1},
+		ResetsAt:  today.AddDate(0, 0, 1),
+	}
+	if quota.DailyRuns > 0 {
+		status.Remaining.Runs = max(quota.DailyRuns-used.Runs, 0)
+	}
+	if quota.DailyTokens > 0 {
+		status.Remaining.Tokens = max(quota.DailyTokens-used.Tokens, 0)
+	}
+	if quota.DailyCost > 0 {
+		status.Remaining.Cost = max(quota.DailyCost-used.Cost, 0)
+	}
+	return status, nil
+}
+
+// check returns a *QuotaExceededError when the tenant's or key's budget is
+// spent, along with the action the exhausted quota asks for
+func (e *QuotaEnforcer) check(ctx context.Context, tenantID, key string) (QuotaAction, error) {
+	scopes := []quotaScope{{tenantID: tenantID}}
+	if key != "" {
+		scopes = append(scopes, quotaScope{tenantID: tenantID, key: key})
+	}
+
+	for _, scope := range scopes {
+		quota, ok := e.quota(scope)
+		if !ok {
+			continue
+		}
+		status, err := e.status(ctx, scope.tenantID, scope.key, quota)
+		if err != nil {
+			return QuotaReject, fmt.Errorf("failed to check quota: %w", err)
+		}
+		if limit := status.exceeded(); limit != "" {
+			return quota.Exhausted, &QuotaExceededError{
+				Tenant:   tenantID,
+				Key:      scope.key,
+				Limit:    limit,
+				ResetsAt: status.ResetsAt,
+			}
+		}
+	}
+	return QuotaReject, nil
+}
+
+// admit waits for or rejects a run whose budget is spent, then counts it
+func (e *QuotaEnforcer) admit(ctx context.Context, tenantID, key string) error {
+	for {
+		action, err := e.check(ctx, tenantID, key)
+		if err == nil {
+			admitted, err := e.store.AddRun(ctx, tenantID, key, e.now(), e.runLimits(tenantID, key))
+			if err != nil || admitted {
+				return err
+			}
+			// A concurrent run took the last of the run budget; the next check
+			// reports it
+			continue
+		}
+		exceeded, ok := err.(*QuotaExceededError)
+		if !ok || action != QuotaQueue {
+			return err
+		}
+
+		wait := min(e.pollInterval, time.Until(exceeded.ResetsAt))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", err, context.Cause(ctx))
+		case <-time.After(wait):
+		}
+	}
+}
+
+// runLimits returns the run budgets of tenantID and its key
+func (e *QuotaEnforcer) runLimits(tenantID, key string) memory.RunLimits {
+	var limits memory.RunLimits
+	if quota, ok := e.quota(quotaScope{tenantID: tenantID}); ok {
+		limits.Tenant = quota.DailyRuns
+	}
+	if key != "" {
+		if quota, ok := e.quota(quotaScope{tenantID: tenantID, key: key}); ok {
+			limits.Key = quota.DailyRuns
+		}
+	}
+	return limits
+}
+
+// record adds a completion's tokens and cost, reporting failures to the
+// error handler
+func (e *QuotaEnforcer) record(ctx context.Context, tenantID, key string, turn TurnMetrics) {
+	err := e.store.AddUsage(ctx, tenantID, key, e.now(), memory.UsageTotals{Tokens: turn.TotalTokens, Cost: turn.Cost})
+	if err != nil && e.onError != nil {
+		e.onError(fmt.Errorf("failed to record usage of tenant %q: %w", tenantID, err))
+	}
+}
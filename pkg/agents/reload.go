@@ -0,0 +1,244 @@
+package agents
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AgentLoader parses a configuration file into agents keyed by name. Tools
+// are code, so a loader resolves the tool names a file refers to against
+// the tools the program registers.
+type AgentLoader func(data []byte) (map[string]*Agent, error)
+
+// AgentConfig is one loaded version of a configuration file
+type AgentConfig struct {
+	Version  int
+	LoadedAt time.Time
+	Agents   map[string]*Agent
+
+	sum [sha256.Size]byte
+}
+
+// ConfigWatcherOption configures a ConfigWatcher
+type ConfigWatcherOption func(*ConfigWatcher)
+
+// WithReloadInterval sets how often Watch checks the file, every two
+// seconds by default
+func WithReloadInterval(interval time.Duration) ConfigWatcherOption {
+	return func(w *ConfigWatcher) {
+		w.interval = interval
+	}
+}
+
+// WithReloadValidation checks new configurations with runner.ValidateGraph
+// instead of Agent.Validate, catching problems such as tools the runner's
+// provider can't call before the configuration goes live
+func WithReloadValidation(runner *Runner) ConfigWatcherOption {
+	return func(w *ConfigWatcher) {
+		w.runner = runner
+	}
+}
+
+// WithReloadHook calls fn whenever the file changes, with the configuration
+// now in use and the error that kept the new one out, if any
+func WithReloadHook(fn func(config *AgentConfig, err error)) ConfigWatcherOption {
+	return func(w *ConfigWatcher) {
+		w.hook = fn
+	}
+}
+
+// WithReloadLogger sets the logger for rejected configurations
+func WithReloadLogger(logger *slog.Logger) ConfigWatcherOption {
+	return func(w *ConfigWatcher) {
+		w.logger = logger
+	}
+}
+
+// ConfigWatcher keeps the agents defined in a configuration file current.
+// A changed file is loaded and validated, then swapped in as a whole, so a
+// run sees either the old definitions or the new ones and never a mix. A
+// file that fails to load or validate is rejected and the last good
+// configuration stays in use. Runs already in progress keep the agents they
+// started with; look agents up with Agent for each new run.
+type ConfigWatcher struct {
+	path     string
+	load     AgentLoader
+	runner   *Runner
+	interval time.Duration
+	hook     func(*AgentConfig, error)
+	logger   *slog.Logger
+
+	current atomic.Pointer[AgentConfig]
+
+	// mu serializes reloads and guards the fields below
+	mu       sync.Mutex
+	previous *AgentConfig
+	loaded   int
+
+	// rejected is the checksum of the last rejected file, so a bad file is
+	// reported once rather than on every check
+	rejected [sha256.Size]byte
+}
+
+// NewConfigWatcher loads the configuration at path. It fails if the initial
+// configuration doesn't load or validate.
+func NewConfigWatcher(path string, load AgentLoader, opts ...ConfigWatcherOption) (*ConfigWatcher, error) {
+	w := &ConfigWatcher{
+		path:     path,
+		load:     load,
+		interval: 2 * time.Second,
+		logger:   slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if _, err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Agent returns the current definition of the named agent
+func (w *ConfigWatcher) Agent(name string) (*Agent, bool) {
+	agent, ok := w.current.Load().Agents[name]
+	return agent, ok
+}
+
+// Config returns the configuration in use
+func (w *ConfigWatcher) Config() *AgentConfig {
+	return w.current.Load()
+}
+
+// Reload reads the file now and swaps in its agents if it changed and is
+// valid. It reports whether a new configuration went live.
+func (w *ConfigWatcher) Reload() (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	current := w.current.Load()
+	config, err := w.read(current)
+	if config == nil && err == nil {
+		return false, nil
+	}
+
+	if err == nil {
+		w.previous = current
+		w.current.Store(config)
+	} else if current != nil {
+		w.logger.Warn("rejected agent configuration", "path", w.path, "version", current.Version, "error", err)
+	}
+
+	if w.hook != nil && w.current.Load() != nil {
+		w.hook(w.current.Load(), err)
+	}
+	return err == nil, err
+}
+
+// Rollback returns to the configuration in use before the last reload, for
+// definitions that validated but misbehave. The file isn't touched, so an
+// edit to it is picked up again as usual.
+func (w *ConfigWatcher) Rollback() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.previous == nil {
+		return fmt.Errorf("%w: no earlier configuration of %s", ErrInvalidConfig, w.path)
+	}
+
+	// Keep the rolled-back file's checksum so an unchanged file isn't
+	// reloaded straight away
+	rolledBack := *w.previous
+	rolledBack.sum = w.current.Load().sum
+	w.previous = w.current.Load()
+	w.current.Store(&rolledBack)
+	return nil
+}
+
+// Watch checks the file for changes until ctx is done. Rejected
+// configurations are logged and reported to the reload hook; watching
+// continues with the last good one.
+func (w *ConfigWatcher) Watch(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.Reload()
+		}
+	}
+}
+
+// read loads and validates the file, returning nil without error when it
+// is unchanged since current or the last rejection
+func (w *ConfigWatcher) read(current *AgentConfig) (*AgentConfig, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	sum := sha256.Sum256(data)
+	if current != nil && (sum == current.sum || sum == w.rejected) {
+		return nil, nil
+	}
+
+	loaded, err := w.load(data)
+	if err == nil && len(loaded) == 0 {
+		err = errors.New("no agents defined")
+	}
+	if err == nil {
+		err = w.validate(loaded)
+	}
+	if err != nil {
+		w.rejected = sum
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidConfig, w.path, err)
+	}
+
+	w.loaded++
+	return &AgentConfig{
+		Version:  w.loaded,
+		LoadedAt: time.Now(),
+		Agents:   loaded,
+		sum:      sum,
+	}, nil
+}
+
+// validate checks every loaded agent, in name order so errors are stable
+func (w *ConfigWatcher) validate(loaded map[string]*Agent) error {
+	names := make([]string, 0, len(loaded))
+	for name := range loaded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		agent := loaded[name]
+		if agent == nil {
+			errs = append(errs, fmt.Errorf("agent %s: no definition", name))
+			continue
+		}
+
+		if w.runner != nil {
+			if err := w.runner.ValidateGraph(agent); err != nil {
+				errs = append(errs, err)
+			}
+		} else if err := agent.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("agent %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -2,12 +2,19 @@ package agents
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/ryanhill4L/agents-sdk/pkg/guardrails"
 	"github.com/ryanhill4L/agents-sdk/pkg/memory"
+	"github.com/ryanhill4L/agents-sdk/pkg/metrics"
 	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+	"github.com/ryanhill4L/agents-sdk/pkg/speech"
 	"github.com/ryanhill4L/agents-sdk/pkg/tools"
 	"github.com/ryanhill4L/agents-sdk/pkg/tracing"
 	"golang.org/x/sync/errgroup"
@@ -19,9 +26,48 @@ type Runner struct {
 	tracer   tracing.Tracer
 	session  memory.Session
 
+	sessionStore memory.SessionStore
+	sessionLocks sync.Map
+	runStore     memory.RunStore
+	auditLog     memory.AuditLog
+	stateStore   memory.StateStore
+	entityMemory *EntityMemory
+	stateLocks   sync.Map
+
+	// batchPersistence saves a run's messages only when it completes
+	batchPersistence bool
+
+	tenantLimiter *TenantLimiter
+	quotaEnforcer *QuotaEnforcer
+
 	maxTurns      int
 	timeout       time.Duration
 	parallelTools bool
+
+	maxHandoffs     int
+	maxHandoffDepth int
+
+	inputProcessors []InputProcessor
+	outputRepairs   int
+	finalAnswerTool bool
+	selfEvaluation  bool
+
+	toolErrorBehavior ToolErrorBehavior
+	toolErrorHandler  ToolErrorHandler
+	cacheToolCalls    bool
+	synthesizer       speech.Synthesizer
+	overflowPolicy    *ContextOverflowPolicy
+	stopConditions    []StopCondition
+	metrics           metrics.Recorder
+	traceContent      bool
+	validateToolArgs  bool
+	dryRun            bool
+	prefetchTools     bool
+	scratchpad        bool
+	toolApprover      ToolApprover
+	events            *EventBus
+
+	inflight runTracker
 }
 
 // RunResult contains the execution results
@@ -31,6 +77,24 @@ type RunResult struct {
 	Agent       *Agent         `json:"-"`
 	Traces      []tracing.Span `json:"traces,omitempty"`
 	Metrics     RunMetrics     `json:"metrics"`
+	Audio       *speech.Audio  `json:"audio,omitempty"`
+	Stopped     bool           `json:"stopped,omitempty"`
+
+	// Replayed is set when the result was loaded from the run store for a
+	// repeated idempotency key instead of being executed
+	Replayed bool `json:"replayed,omitempty"`
+
+	// Candidates holds every completion sampled with WithNSamples, across
+	// all turns, with the selected ones marked
+	Candidates []Candidate `json:"candidates,omitempty"`
+
+	// Evaluation is the model's assessment of its final answer, set with
+	// WithSelfEvaluation when the answer came through the final_answer tool
+	Evaluation *SelfEvaluation `json:"evaluation,omitempty"`
+
+	// Scratchpad holds the agent's working notes at the end of the run, with
+	// WithScratchpad. They aren't part of Messages or the session.
+	Scratchpad string `json:"scratchpad,omitempty"`
 }
 
 // RunMetrics contains execution metrics
@@ -40,14 +104,87 @@ type RunMetrics struct {
 	Duration    time.Duration `json:"duration"`
 	ToolCalls   int           `json:"tool_calls"`
 	Handoffs    int           `json:"handoffs"`
+	Turns       []TurnMetrics `json:"turns,omitempty"`
+
+	// Tools aggregates execution time, failures and payload sizes per tool
+	Tools map[string]metrics.ToolStats `json:"tools,omitempty"`
+
+	// Unauthorized lists tool calls blocked for missing permission scopes
+	Unauthorized []UnauthorizedError `json:"unauthorized,omitempty"`
+
+	// DryRunCalls lists the calls to mutating tools a dry run skipped
+	DryRunCalls []ToolCall `json:"dry_run_calls,omitempty"`
+
+	// TotalCost is the estimated price in US dollars of the turns whose
+	// model has known pricing (see providers.RegisterPricing)
+	TotalCost float64 `json:"total_cost,omitempty"`
+}
+
+// TurnMetrics records the usage of a single model call
+type TurnMetrics struct {
+	Turn             int           `json:"turn"`
+	Agent            string        `json:"agent"`
+	Model            string        `json:"model"`
+	PromptTokens     int           `json:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens"`
+	TotalTokens      int           `json:"total_tokens"`
+	Latency          time.Duration `json:"latency"`
+	ToolCalls        int           `json:"tool_calls"`
+
+	// CachedPromptTokens and ReasoningTokens detail the prompt and
+	// completion counts, for cache-hit monitoring and cost estimates
+	CachedPromptTokens int `json:"cached_prompt_tokens,omitempty"`
+	CacheWriteTokens   int `json:"cache_write_tokens,omitempty"`
+	ReasoningTokens    int `json:"reasoning_tokens,omitempty"`
+
+	// Cost is the estimated price in US dollars, zero for models without
+	// known pricing
+	Cost float64 `json:"cost,omitempty"`
+}
+
+// AgentUsage aggregates turn metrics for one agent
+type AgentUsage struct {
+	Turns              int           `json:"turns"`
+	PromptTokens       int           `json:"prompt_tokens"`
+	CompletionTokens   int           `json:"completion_tokens"`
+	TotalTokens        int           `json:"total_tokens"`
+	CachedPromptTokens int           `json:"cached_prompt_tokens,omitempty"`
+	CacheWriteTokens   int           `json:"cache_write_tokens,omitempty"`
+	ReasoningTokens    int           `json:"reasoning_tokens,omitempty"`
+	Cost               float64       `json:"cost,omitempty"`
+	Latency            time.Duration `json:"latency"`
+	ToolCalls          int           `json:"tool_calls"`
+}
+
+// ByAgent sums turn metrics per agent name for cost attribution in
+// multi-agent runs
+func (m RunMetrics) ByAgent() map[string]AgentUsage {
+	usage := make(map[string]AgentUsage)
+	for _, turn := range m.Turns {
+		u := usage[turn.Agent]
+		u.Turns++
+		u.PromptTokens += turn.PromptTokens
+		u.CompletionTokens += turn.CompletionTokens
+		u.TotalTokens += turn.TotalTokens
+		u.CachedPromptTokens += turn.CachedPromptTokens
+		u.CacheWriteTokens += turn.CacheWriteTokens
+		u.ReasoningTokens += turn.ReasoningTokens
+		u.Cost += turn.Cost
+		u.Latency += turn.Latency
+		u.ToolCalls += turn.ToolCalls
+		usage[turn.Agent] = u
+	}
+	return usage
 }
 
 // NewRunner creates a new runner with options
 func NewRunner(opts ...RunnerOption) *Runner {
 	r := &Runner{
-		maxTurns:      10,
-		timeout:       5 * time.Minute,
-		parallelTools: true,
+		maxTurns:         10,
+		timeout:          5 * time.Minute,
+		parallelTools:    true,
+		validateToolArgs: true,
+		events:           Events(),
 	}
 
 	for _, opt := range opts {
@@ -67,87 +204,491 @@ func NewRunner(opts ...RunnerOption) *Runner {
 }
 
 // Run executes the agent workflow asynchronously
-func (r *Runner) Run(ctx context.Context, agent *Agent, input string) (*RunResult, error) {
+func (r *Runner) Run(ctx context.Context, agent *Agent, input string, opts ...RunOption) (*RunResult, error) {
+	cfg := &runConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	ctx, done, err := r.inflight.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	release, err := r.acquireTenant(cfg.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if cfg.idempotencyKey != "" {
+		return r.runIdempotent(ctx, agent, input, cfg)
+	}
+	return r.run(ctx, agent, input, cfg)
+}
+
+// run executes one call to Run with its collected options
+func (r *Runner) run(ctx context.Context, agent *Agent, input string, cfg *runConfig) (result *RunResult, err error) {
+	ctx = providers.ContextWithRequestOptions(ctx, cfg.request)
+	if cfg.priority != providers.PriorityNormal {
+		ctx = providers.ContextWithPriority(ctx, cfg.priority)
+	}
+
+	if r.quotaEnforcer != nil {
+		if err := r.quotaEnforcer.admit(ctx, cfg.tenantID, cfg.usageKey); err != nil {
+			return nil, err
+		}
+	}
+
+	// Runs without a named session still get an ID for their events
+	sessionID := cfg.sessionID
+	if sessionID == "" {
+		sessionID = uuid.New().String()
+	}
+
 	// Create run context
 	runCtx := &RunContext{
 		Context:   ctx,
-		SessionID: uuid.New().String(),
+		SessionID: sessionID,
+		TenantID:  cfg.tenantID,
 		TraceID:   uuid.New().String(),
+		usageKey:  cfg.usageKey,
+		mutated:   &cfg.mutated,
 		MaxTurns:  r.maxTurns,
 		Variables: make(map[string]interface{}),
+		spans:     &spanRecorder{},
+
+		GrantedScopes: cfg.scopes,
+		turnContext:   cfg.turnContext,
+
+		samples:        cfg.samples,
+		sampleSelector: cfg.sampleSelector,
+	}
+
+	if r.cacheToolCalls {
+		runCtx.toolCache = newToolCallCache()
 	}
 
+	r.emit(runCtx, agent, Event{Type: EventRunStart, Input: input})
+	defer func() {
+		end := Event{Type: EventRunEnd, Result: result, Err: err}
+		finalAgent := agent
+		if result != nil && result.Agent != nil {
+			finalAgent = result.Agent
+		}
+		r.emit(runCtx, finalAgent, end)
+	}()
+
 	// Start tracing
-	ctx, rootSpan := r.tracer.StartSpan(ctx, "agent.run")
+	ctx, rootSpan := r.startSpan(runCtx, ctx, "agent.run")
+	rootSpan.SetAttribute("agent", agent.Name)
 	defer r.tracer.EndSpan(rootSpan)
 
 	// Apply timeout
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
+	if r.stateStore != nil {
+		// State is scoped to the tenant like the session's history
+		ctx = context.WithValue(ctx, stateSessionKey{}, memory.TenantKey(cfg.tenantID, sessionID))
+	}
+	if r.scratchpad {
+		runCtx.scratchpad = &scratchpad{}
+		ctx = context.WithValue(ctx, scratchpadKey{}, runCtx.scratchpad)
+	}
+
+	// Everything the loop starts nests under the root span
+	runCtx.Context = ctx
+
+	if cfg.history == nil {
+		input, err = r.processInput(runCtx, r.inputProcessors, input)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize messages
-	messages := []Message{
-		{
-			Role:      "user",
-			Content:   input,
-			Timestamp: time.Now(),
-		},
+	inputMessage := Message{
+		Role:      "user",
+		Content:   input,
+		Metadata:  cfg.metadata,
+		Timestamp: time.Now(),
+	}
+	for _, file := range cfg.files {
+		inputMessage.Parts = append(inputMessage.Parts, file.contentPart())
+	}
+	messages := []Message{inputMessage}
+	if cfg.history != nil {
+		messages = cfg.history
+	}
+
+	// Resolve the session for this call
+	session := r.session
+	if cfg.sessionID != "" {
+		if r.sessionStore == nil {
+			return nil, ErrNoSessionStore
+		}
+
+		// Serialize runs on the same session so load, run and save don't interleave
+		key := memory.TenantKey(cfg.tenantID, cfg.sessionID)
+		unlock := r.lockSession(key)
+		defer unlock()
+
+		s, err := r.sessionStore.Session(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open session: %w", err)
+		}
+		defer s.Close()
+
+		session = s
+		runCtx.SessionID = cfg.sessionID
+	}
+	if cfg.skipSession {
+		session = nil
+	}
+	if session != nil && cfg.tenantID != "" && cfg.sessionID == "" {
+		return nil, ErrTenantSession
+	}
+	if !r.batchPersistence {
+		runCtx.session = session
 	}
 
 	// Load session history if available
-	if r.session != nil {
-		history, err := r.session.GetItems(ctx, 100)
+	if session != nil {
+		history, err := session.GetItems(ctx, 100)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load session: %w", err)
 		}
 		messages = append(messagesFromMemory(history), messages...)
+
+		if r.traceContent && len(history) > 0 {
+			rootSpan.SetAttribute("history", history)
+		}
+	}
+	if r.traceContent {
+		rootSpan.SetAttribute("input", input)
+	}
+
+	// Recall what earlier conversations told about the entities involved
+	var entityOwnerKey string
+	var knownEntities []memory.Entity
+	if r.entityMemory != nil {
+		if entityOwnerKey = entityOwner(cfg); entityOwnerKey != "" {
+			knownEntities = r.retrieveEntities(ctx, entityOwnerKey, input)
+			rootSpan.SetAttribute("entity_cards", len(knownEntities))
+			runCtx.Context = context.WithValue(runCtx.Context, entityCardsKey{}, entityCards(knownEntities))
+		}
 	}
 
 	// Execute agent loop
-	result, err := r.executeLoop(runCtx, agent, messages)
+	result, err = r.executeLoop(runCtx, agent, messages)
 	if err != nil {
+		rootSpan.SetError(err)
+		r.checkpoint(ctx, session, err)
 		return nil, err
 	}
+	rootSpan.SetAttribute("turns", result.Metrics.TotalTurns)
+	rootSpan.SetAttribute("total_tokens", result.Metrics.TotalTokens)
+	if result.Metrics.TotalCost > 0 {
+		rootSpan.SetAttribute("total_cost_usd", result.Metrics.TotalCost)
+	}
+	if r.traceContent {
+		rootSpan.SetAttribute("final_output", result.FinalOutput)
+	}
+	result.Traces = runCtx.spans.list()
+	result.Candidates = runCtx.candidates
 
-	// Save to session
-	if r.session != nil {
-		if err := r.session.AddItems(ctx, messagesToMemory(result.Messages)); err != nil {
+	// Save the messages produced by this run
+	if session != nil {
+		if err := session.AddItems(ctx, messagesToMemory(unpersisted(result.Messages))); err != nil {
 			return nil, fmt.Errorf("failed to save session: %w", err)
 		}
 	}
 
+	if entityOwnerKey != "" {
+		r.extractEntities(ctx, entityOwnerKey, runMessages(result.Messages, input), knownEntities)
+	}
+
+	// Synthesize speech for the final output. The run has completed and
+	// been saved by now, so a failure returns the result without audio.
+	if r.synthesizer != nil {
+		if text, ok := result.FinalOutput.(string); ok && text != "" {
+			audio, err := r.synthesizer.Synthesize(ctx, text)
+			if err != nil {
+				rootSpan.SetError(err)
+				return result, fmt.Errorf("%w: %w", ErrSpeechSynthesis, err)
+			}
+			result.Audio = audio
+		}
+	}
+
 	return result, nil
 }
 
+// Continue runs agent on the stored conversation identified by sessionID,
+// loading its history and persisting the new messages
+func (r *Runner) Continue(ctx context.Context, sessionID string, agent *Agent, input string, opts ...RunOption) (*RunResult, error) {
+	return r.Run(ctx, agent, input, append(opts, WithSessionID(sessionID))...)
+}
+
+// lockSession acquires the per-session lock and returns its release function
+func (r *Runner) lockSession(sessionID string) func() {
+	mu, _ := r.sessionLocks.LoadOrStore(sessionID, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// persistTurn saves the messages not yet in the run's session before the
+// next completion, so a run that fails or times out keeps the turns it
+// completed. It does nothing with batch persistence.
+func (r *Runner) persistTurn(ctx *RunContext, messages []Message) error {
+	if ctx.session == nil {
+		return nil
+	}
+
+	var pending []int
+	for i := range messages {
+		if !messages[i].persisted {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	items := make([]Message, len(pending))
+	for j, i := range pending {
+		items[j] = messages[i]
+	}
+	if err := ctx.session.AddItems(ctx, messagesToMemory(items)); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	for _, i := range pending {
+		messages[i].persisted = true
+	}
+	return nil
+}
+
+// unpersisted returns the messages that did not come from session history
+func unpersisted(msgs []Message) []Message {
+	var result []Message
+	for _, msg := range msgs {
+		if !msg.persisted {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
 // executeLoop runs the main agent execution loop
-func (r *Runner) executeLoop(ctx *RunContext, agent *Agent, messages []Message) (*RunResult, error) {
+func (r *Runner) executeLoop(ctx *RunContext, agent *Agent, messages []Message) (result *RunResult, err error) {
 	startTime := time.Now()
 	metrics := RunMetrics{}
 	currentAgent := agent
 
+	// Cancel prefetched tool calls the last completion didn't make
+	defer func() { ctx.prefetch.stop() }()
+
+	defer func() {
+		if result != nil {
+			result.Scratchpad = ctx.scratchpad.String()
+		}
+	}()
+
+	// A run that hits its deadline or is interrupted by shutdown returns what
+	// it has so far
+	defer func() {
+		shutdown := errors.Is(context.Cause(ctx), ErrShutdown)
+		if err == nil || !shutdown && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return
+		}
+		if shutdown {
+			err = fmt.Errorf("%w: %w", ErrShutdown, err)
+		}
+
+		metrics.Duration = time.Since(startTime)
+		metrics.TotalTurns = len(metrics.Turns)
+		err = &TimeoutError{
+			Partial: &RunResult{
+				Messages: messages,
+				Agent:    currentAgent,
+				Metrics:  metrics,
+				Traces:   ctx.spans.list(),
+			},
+			Err: err,
+		}
+	}()
+
+	// Each turn gets a span; it is ended when the next turn starts or the loop exits
+	var turnSpan tracing.Span
+	endTurn := func() {
+		if turnSpan != nil {
+			r.tracer.EndSpan(turnSpan)
+			turnSpan = nil
+		}
+	}
+	defer endTurn()
+
+	// delegators are the agents that handed off on the way to the current
+	// one, which it may hand back to
+	var delegators []*Agent
+
+	outputRepairs := 0
 	for turn := 0; turn < ctx.MaxTurns; turn++ {
 		ctx.CurrentTurn = turn
 
+		endTurn()
+		var turnCtx context.Context
+		turnCtx, turnSpan = r.startSpan(ctx, ctx.Context, "agent.turn")
+		turnSpan.SetAttribute("turn", turn)
+		turnSpan.SetAttribute("agent", currentAgent.Name)
+		r.emit(ctx, currentAgent, Event{Type: EventTurnStart})
+
 		// Check context cancellation
 		if err := ctx.Err(); err != nil {
 			return nil, fmt.Errorf("context cancelled: %w", err)
 		}
 
+		if ctx.turnContext != nil {
+			injected, err := ctx.turnContext(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("turn context failed: %w", err)
+			}
+			messages = append(messages, injected...)
+		}
+
 		// Validate input with guardrails
-		if err := r.validateGuardrails(currentAgent, messages); err != nil {
+		_, guardrailSpan := r.startSpan(ctx, turnCtx, "guardrail.check")
+		guardrailSpan.SetAttribute("guardrails", len(currentAgent.Guardrails))
+		err := r.validateGuardrails(ctx, currentAgent, messages)
+		r.endSpan(guardrailSpan, err)
+		if err != nil {
 			return nil, fmt.Errorf("guardrail validation failed: %w", err)
 		}
 
+		// Fail fast if the provider can't serve this agent
+		if err := r.checkCapabilities(currentAgent, messages); err != nil {
+			return nil, err
+		}
+
+		if err := r.persistTurn(ctx, messages); err != nil {
+			return nil, err
+		}
+
 		// Get LLM completion
-		toolDefs := convertToolsToProviders(currentAgent.Tools)
-		completion, err := r.provider.Complete(ctx.Context, currentAgent, messagesToProviders(messages), toolDefs)
+		toolDefs := convertToolsToProviders(r.toolsFor(currentAgent))
+		transferDefs, transfers := r.handoffTools(currentAgent, delegators)
+		toolDefs = append(toolDefs, transferDefs...)
+		finalAnswer, offersFinalAnswer := r.finalAnswerFor(currentAgent)
+		if offersFinalAnswer {
+			toolDefs = append(toolDefs, finalAnswer.definition())
+		}
+		turnStart := time.Now()
+		completeCtx, completeSpan := r.startSpan(ctx, turnCtx, "provider.complete")
+		completeSpan.SetAttribute("model", currentAgent.Model)
+		ctx.prefetch.stop()
+		ctx.prefetch = nil
+		if r.prefetchTools {
+			ctx.prefetch = r.newToolPrefetch(turnCtx, ctx, currentAgent)
+			completeCtx = contextWithPrefetch(completeCtx, ctx.prefetch)
+		}
+		completions, usedAgent, usedMessages, err := r.complete(completeCtx, currentAgent, messages, toolDefs, ctx.samples)
+		latency := time.Since(turnStart)
+		var completion *providers.Completion
+		var cost float64
+		if err == nil {
+			completion, err = r.selectSample(ctx, usedMessages, completions)
+		}
+		if err == nil {
+			if len(completions) > 1 {
+				completeSpan.SetAttribute("samples", len(completions))
+			}
+			completeSpan.SetAttribute("model", usedAgent.Model)
+			completeSpan.SetAttribute("prompt_tokens", completion.Usage.PromptTokens)
+			completeSpan.SetAttribute("completion_tokens", completion.Usage.CompletionTokens)
+			completeSpan.SetAttribute("total_tokens", completion.Usage.TotalTokens)
+			if completion.Usage.CachedPromptTokens > 0 {
+				completeSpan.SetAttribute("cached_prompt_tokens", completion.Usage.CachedPromptTokens)
+			}
+			if completion.Usage.CacheWriteTokens > 0 {
+				completeSpan.SetAttribute("cache_write_tokens", completion.Usage.CacheWriteTokens)
+			}
+			if completion.Usage.ReasoningTokens > 0 {
+				completeSpan.SetAttribute("reasoning_tokens", completion.Usage.ReasoningTokens)
+			}
+			var priced bool
+			if cost, priced = providers.Cost(usedAgent.Model, completion.Usage); priced {
+				completeSpan.SetAttribute("cost_usd", cost)
+			}
+			completeSpan.SetAttribute("latency_ms", latency.Milliseconds())
+			if completion.TimeToFirstToken > 0 {
+				completeSpan.SetAttribute("ttft_ms", completion.TimeToFirstToken.Milliseconds())
+			}
+			completeSpan.SetAttribute("tool_calls", len(completion.ToolCalls))
+			if r.traceContent {
+				completeSpan.SetAttribute("response", completion.Message.Content)
+				if len(completion.ToolCalls) > 0 {
+					completeSpan.SetAttribute("requested_tools", toolCallsFromProviders(completion.ToolCalls))
+				}
+			}
+		}
+		r.endSpan(completeSpan, err)
 		if err != nil {
 			return nil, fmt.Errorf("completion failed: %w", err)
 		}
 
+		currentAgent, messages = usedAgent, usedMessages
+
+		if completion.Message.Content != "" {
+			if err := r.validateOutput(ctx, currentAgent, messageFromProviders(completion.Message)); err != nil {
+				return nil, fmt.Errorf("guardrail validation failed: %w", err)
+			}
+		}
+
+		turnMetrics := TurnMetrics{
+			Turn:             turn,
+			Agent:            currentAgent.Name,
+			Model:            currentAgent.Model,
+			PromptTokens:     completion.Usage.PromptTokens,
+			CompletionTokens: completion.Usage.CompletionTokens,
+			TotalTokens:      completion.Usage.TotalTokens,
+			Latency:          time.Since(turnStart),
+			ToolCalls:        len(completion.ToolCalls),
+
+			CachedPromptTokens: completion.Usage.CachedPromptTokens,
+			CacheWriteTokens:   completion.Usage.CacheWriteTokens,
+			ReasoningTokens:    completion.Usage.ReasoningTokens,
+			Cost:               cost,
+		}
 		metrics.TotalTokens += completion.Usage.TotalTokens
+		metrics.TotalCost += cost
+		metrics.Turns = append(metrics.Turns, turnMetrics)
+		r.recordUsage(ctx, turnMetrics)
+		r.emit(ctx, currentAgent, Event{Type: EventCompletion, Usage: &turnMetrics})
+		messages = append(messages, hostedCallMessages(completion.HostedCalls)...)
 		messages = append(messages, messageFromProviders(completion.Message))
 
+		turnResult := &TurnResult{
+			Turn:    turn,
+			Agent:   currentAgent,
+			Message: messageFromProviders(completion.Message),
+		}
+
+		// stopped builds the result for a run ended by a stop condition
+		stopped := func() *RunResult {
+			metrics.Duration = time.Since(startTime)
+			metrics.TotalTurns = turn + 1
+
+			return &RunResult{
+				FinalOutput: completion.Message.Content,
+				Messages:    messages,
+				Agent:       currentAgent,
+				Metrics:     metrics,
+				Stopped:     true,
+			}
+		}
+
 		// Check for final output
 		if currentAgent.OutputType != nil && completion.StructuredOutput != nil {
 			metrics.Duration = time.Since(startTime)
@@ -161,15 +702,89 @@ func (r *Runner) executeLoop(ctx *RunContext, agent *Agent, messages []Message)
 			}, nil
 		}
 
-		// Handle handoffs
+		// A call to the final_answer tool ends the run once its arguments
+		// match the output schema; otherwise the model is told what's wrong
+		if call, ok := finalAnswerCall(completion.ToolCalls); ok && offersFinalAnswer {
+			output, evaluation, err := finalAnswer.parse(call)
+			messages = append(messages, finalAnswerResults(completion.ToolCalls, err)...)
+			if err == nil {
+				metrics.Duration = time.Since(startTime)
+				metrics.TotalTurns = turn + 1
+
+				return &RunResult{
+					FinalOutput: output,
+					Messages:    messages,
+					Agent:       currentAgent,
+					Metrics:     metrics,
+					Evaluation:  evaluation,
+				}, nil
+			}
+			if outputRepairs >= r.outputRepairs {
+				return nil, err
+			}
+			outputRepairs++
+			continue
+		}
+
+		// Handle handoffs, requested directly or through a transfer tool
+		if completion.Handoff == nil {
+			completion.Handoff = transferHandoff(completion.ToolCalls, transfers)
+		}
 		if completion.Handoff != nil {
 			metrics.Handoffs++
 
-			newAgent, ok := currentAgent.GetHandoff(completion.Handoff.TargetAgent)
-			if !ok {
-				return nil, fmt.Errorf("handoff agent not found: %s", completion.Handoff.TargetAgent)
+			_, handoffSpan := r.startSpan(ctx, turnCtx, "agent.handoff")
+			handoffSpan.SetAttribute("from", currentAgent.Name)
+			handoffSpan.SetAttribute("to", completion.Handoff.TargetAgent)
+
+			handoffRecord := memory.AuditRecord{
+				Kind:   memory.AuditHandoff,
+				Agent:  currentAgent.Name,
+				Target: completion.Handoff.TargetAgent,
+				Status: memory.AuditOK,
+			}
+			newAgent, returning, err := r.resolveHandoff(currentAgent, completion.Handoff.TargetAgent, delegators, metrics.Handoffs)
+			if err != nil {
+				handoffRecord.Status, handoffRecord.Error = memory.AuditError, err.Error()
+				if auditErr := r.audit(ctx, handoffRecord); auditErr != nil {
+					err = errors.Join(err, auditErr)
+				}
+				r.endSpan(handoffSpan, err)
+				return nil, err
+			}
+			if err := r.audit(ctx, handoffRecord); err != nil {
+				r.endSpan(handoffSpan, err)
+				return nil, err
+			}
+			r.endSpan(handoffSpan, nil)
+			r.emit(ctx, currentAgent, Event{Type: EventHandoff, Target: newAgent.Name})
+			messages = append(messages, transferResults(completion.ToolCalls, transfers, newAgent.Name)...)
+
+			turnResult.Handoff = newAgent.Name
+			if r.shouldStop(ctx, turnResult) {
+				return stopped(), nil
 			}
 
+			handoff := handoffFromProviders(completion.Handoff, currentAgent.Name)
+			if returning {
+				handoff.TargetAgent, handoff.Returned = newAgent.Name, true
+				delegators = delegators[:len(delegators)-1]
+			} else {
+				delegators = append(delegators, currentAgent)
+			}
+			briefing := handoffMessage(handoff, messages)
+			if len(newAgent.HandoffInputFilters) > 0 {
+				// Save the history first so filtered messages still reach the session
+				if err := r.persistTurn(ctx, messages); err != nil {
+					return nil, err
+				}
+				if messages, err = filterHandoffInput(ctx, newAgent, messages); err != nil {
+					return nil, err
+				}
+			}
+			messages = append(messages, briefing)
+			ctx.Handoff = handoff
+
 			currentAgent = newAgent
 			continue
 		}
@@ -178,23 +793,53 @@ func (r *Runner) executeLoop(ctx *RunContext, agent *Agent, messages []Message)
 		if len(completion.ToolCalls) > 0 {
 			metrics.ToolCalls += len(completion.ToolCalls)
 
-			toolResponses, err := r.executeTools(ctx, currentAgent, toolCallsFromProviders(completion.ToolCalls))
+			toolCalls := toolCallsFromProviders(completion.ToolCalls)
+			toolResponses, err := r.executeTools(ctx, turnCtx, currentAgent, toolCalls)
+			ctx.prefetch.stop()
 			if err != nil {
 				return nil, fmt.Errorf("tool execution failed: %w", err)
 			}
 
 			// Add tool responses as messages
-			for _, resp := range toolResponses {
+			for i, resp := range toolResponses {
+				content, parts, err := r.toolResultContent(ctx, toolCalls[i], resp)
+				r.recordToolCall(&metrics, toolCalls[i], resp, len(content))
+				var unauthorized *UnauthorizedError
+				if errors.As(resp.Error, &unauthorized) {
+					metrics.Unauthorized = append(metrics.Unauthorized, *unauthorized)
+				}
+				if resp.DryRun {
+					metrics.DryRunCalls = append(metrics.DryRunCalls, toolCalls[i])
+				}
+				if err != nil {
+					return nil, fmt.Errorf("tool execution failed: %w", err)
+				}
+
+				metadata := map[string]interface{}{
+					"tool_call_id": resp.ToolCallID,
+				}
+				if resp.Error != nil {
+					metadata["is_error"] = true
+				}
+				if resp.DryRun {
+					metadata["dry_run"] = true
+				}
+
 				messages = append(messages, Message{
 					Role:      "tool",
-					Content:   fmt.Sprintf("%v", resp.Content),
+					Content:   content,
+					Parts:     parts,
 					Timestamp: time.Now(),
-					Metadata: map[string]interface{}{
-						"tool_call_id": resp.ToolCallID,
-					},
+					Metadata:  metadata,
 				})
 			}
 
+			turnResult.ToolCalls = toolCalls
+			turnResult.ToolResponses = toolResponses
+			if r.shouldStop(ctx, turnResult) {
+				return stopped(), nil
+			}
+
 			continue
 		}
 
@@ -210,37 +855,96 @@ func (r *Runner) executeLoop(ctx *RunContext, agent *Agent, messages []Message)
 				Metrics:     metrics,
 			}, nil
 		}
+
+		// A text response to an agent with an output schema is decoded after
+		// repairing its JSON; the model is asked to fix what can't be repaired
+		output, err := parseOutput(currentAgent.OutputType, completion.Message.Content)
+		if err == nil {
+			metrics.Duration = time.Since(startTime)
+			metrics.TotalTurns = turn + 1
+
+			return &RunResult{
+				FinalOutput: output,
+				Messages:    messages,
+				Agent:       currentAgent,
+				Metrics:     metrics,
+			}, nil
+		}
+		if outputRepairs >= r.outputRepairs {
+			return nil, err
+		}
+		outputRepairs++
+		messages = append(messages, repairRequest(currentAgent.OutputType, err))
 	}
 
 	return nil, ErrMaxTurnsExceeded
 }
 
+// recordToolCall adds a tool execution to the run's metrics and the
+// runner's metrics recorder
+func (r *Runner) recordToolCall(runMetrics *RunMetrics, call ToolCall, resp ToolResponse, outputBytes int) {
+	inputBytes := 0
+	if args, err := json.Marshal(call.Arguments); err == nil {
+		inputBytes = len(args)
+	}
+
+	measurement := metrics.ToolCall{
+		Tool:        call.Name,
+		Duration:    resp.Duration,
+		Err:         resp.Error,
+		Cached:      resp.Cached,
+		InputBytes:  inputBytes,
+		OutputBytes: outputBytes,
+	}
+
+	if runMetrics.Tools == nil {
+		runMetrics.Tools = make(map[string]metrics.ToolStats)
+	}
+	stats := runMetrics.Tools[call.Name]
+	stats.Add(measurement)
+	runMetrics.Tools[call.Name] = stats
+
+	if r.metrics != nil {
+		r.metrics.RecordToolCall(measurement)
+	}
+}
+
+// shouldStop reports whether any configured stop condition matches the turn
+func (r *Runner) shouldStop(ctx *RunContext, result *TurnResult) bool {
+	for _, condition := range r.stopConditions {
+		if condition(ctx, result) {
+			return true
+		}
+	}
+	return false
+}
+
 // executeTools runs tool calls in parallel or sequence
-func (r *Runner) executeTools(ctx *RunContext, agent *Agent, toolCalls []ToolCall) ([]ToolResponse, error) {
+func (r *Runner) executeTools(ctx *RunContext, parent context.Context, agent *Agent, toolCalls []ToolCall) ([]ToolResponse, error) {
 	responses := make([]ToolResponse, len(toolCalls))
 
 	if r.parallelTools && len(toolCalls) > 1 {
-		// Execute tools in parallel
-		g, gCtx := errgroup.WithContext(ctx.Context)
+		// Execute tools in parallel, except that calls to mutating tools run
+		// one at a time in the order the model made them
+		g, gCtx := errgroup.WithContext(parent)
 
+		var mutating []int
 		for i, call := range toolCalls {
 			i, call := i, call // capture loop variables
 
+			if tool := r.findTool(agent, call.Name); tool != nil && tools.IsMutating(tool) {
+				mutating = append(mutating, i)
+				continue
+			}
 			g.Go(func() error {
-				tool := r.findTool(agent, call.Name)
-				if tool == nil {
-					responses[i] = ToolResponse{
-						ToolCallID: call.ID,
-						Error:      fmt.Errorf("tool not found: %s", call.Name),
-					}
-					return nil
-				}
-
-				result, err := tool.Execute(gCtx, call.Arguments)
-				responses[i] = ToolResponse{
-					ToolCallID: call.ID,
-					Content:    result,
-					Error:      err,
+				responses[i] = r.executeTool(gCtx, ctx, agent, call)
+				return nil
+			})
+		}
+		if len(mutating) > 0 {
+			g.Go(func() error {
+				for _, i := range mutating {
+					responses[i] = r.executeTool(gCtx, ctx, agent, toolCalls[i])
 				}
 				return nil
 			})
@@ -252,30 +956,338 @@ func (r *Runner) executeTools(ctx *RunContext, agent *Agent, toolCalls []ToolCal
 	} else {
 		// Execute tools sequentially
 		for i, call := range toolCalls {
-			tool := r.findTool(agent, call.Name)
-			if tool == nil {
-				responses[i] = ToolResponse{
-					ToolCallID: call.ID,
-					Error:      fmt.Errorf("tool not found: %s", call.Name),
-				}
-				continue
+			responses[i] = r.executeTool(parent, ctx, agent, call)
+		}
+	}
+
+	return responses, nil
+}
+
+// executeTool runs a single tool call, serving idempotent tools from the
+// run's cache when enabled
+func (r *Runner) executeTool(ctx context.Context, runCtx *RunContext, agent *Agent, call ToolCall) (resp ToolResponse) {
+	ctx, span := r.startSpan(runCtx, ctx, "tool.execute")
+	span.SetAttribute("tool", call.Name)
+	if r.traceContent {
+		span.SetAttribute("arguments", call.Arguments)
+	}
+	r.emit(runCtx, agent, Event{Type: EventToolStart, ToolCall: &call})
+
+	var approvals *toolApprovals
+	if r.auditLog != nil {
+		approvals = &toolApprovals{}
+		ctx = context.WithValue(ctx, approvalsKey{}, approvals)
+	}
+
+	defer func() {
+		r.emit(runCtx, agent, Event{Type: EventToolEnd, ToolCall: &call, ToolResponse: &resp})
+		if err := r.auditToolCall(runCtx, agent, call, resp, approvals); err != nil {
+			span.SetAttribute("audit_error", err.Error())
+		}
+		span.SetAttribute("cached", resp.Cached)
+		if resp.DryRun {
+			span.SetAttribute("dry_run", true)
+		}
+		if r.traceContent && resp.Error == nil {
+			content, _ := formatToolContent(resp.Content)
+			span.SetAttribute("result", content)
+		}
+		r.endSpan(span, resp.Error)
+	}()
+
+	tool := r.findTool(agent, call.Name)
+	if tool == nil {
+		return ToolResponse{
+			ToolCallID: call.ID,
+			Error:      fmt.Errorf("tool not found: %s", call.Name),
+		}
+	}
+	if ns, ok := tool.(tools.NamespacedTool); ok {
+		span.SetAttribute("namespace", ns.Namespace())
+	}
+
+	if err := authorizeTool(runCtx, agent, tool); err != nil {
+		span.SetAttribute("unauthorized", true)
+		return ToolResponse{
+			ToolCallID: call.ID,
+			Error:      err,
+		}
+	}
+
+	// Reject malformed calls before they reach the tool so the model can retry
+	if r.validateToolArgs {
+		if err := tools.ValidateArguments(tool, call.Arguments); err != nil {
+			return ToolResponse{
+				ToolCallID: call.ID,
+				Error:      err,
 			}
+		}
+	}
 
-			result, err := tool.Execute(ctx.Context, call.Arguments)
-			responses[i] = ToolResponse{
+	// Dry runs record what a mutating tool would have been asked to do
+	if r.dryRun && tools.IsMutating(tool) {
+		return ToolResponse{
+			ToolCallID: call.ID,
+			Content:    fmt.Sprintf("Dry run: %s was not executed. Continue as if it succeeded.", call.Name),
+			DryRun:     true,
+		}
+	}
+
+	if r.toolApprover != nil {
+		if err := r.toolApprover(ctx, call, tools.CapabilitiesOf(tool)); err != nil {
+			span.SetAttribute("approved", false)
+			return ToolResponse{
+				ToolCallID: call.ID,
+				Error:      fmt.Errorf("%w: %s: %v", ErrNotApproved, call.Name, err),
+			}
+		}
+	}
+
+	cacheable := runCtx.toolCache != nil && tools.IsIdempotent(tool)
+	if cacheable {
+		if result, ok := runCtx.toolCache.get(call); ok {
+			return ToolResponse{
 				ToolCallID: call.ID,
 				Content:    result,
-				Error:      err,
+				Cached:     true,
 			}
 		}
 	}
 
-	return responses, nil
+	// A call that can't be audited doesn't run
+	if err := r.audit(runCtx, memory.AuditRecord{
+		Kind:          memory.AuditToolCall,
+		Agent:         agent.Name,
+		Tool:          call.Name,
+		ToolCallID:    call.ID,
+		ArgumentsHash: argumentsHash(call.Arguments),
+		Status:        memory.AuditStarted,
+	}); err != nil {
+		return ToolResponse{
+			ToolCallID: call.ID,
+			Error:      err,
+		}
+	}
+
+	// A call that started while the completion streamed is reused when the
+	// final arguments match the streamed ones
+	if prefetched, ok := runCtx.prefetch.take(call); ok {
+		span.SetAttribute("prefetched", true)
+		result, err := prefetched.wait(ctx)
+		if err == nil && cacheable {
+			runCtx.toolCache.put(call, result)
+		}
+		return ToolResponse{
+			ToolCallID: call.ID,
+			Content:    result,
+			Error:      err,
+			Duration:   prefetched.duration,
+			Prefetched: true,
+		}
+	}
+
+	// A call that may mutate counts even if it fails; it may have taken
+	// effect. Tools that declare nothing are assumed to have side effects.
+	if tools.MayMutate(tool) && runCtx.mutated != nil {
+		runCtx.mutated.Store(true)
+	}
+
+	start := time.Now()
+	result, err := executeWithContext(ctx, tool, call.Arguments)
+	if err == nil {
+		if text, ok := tools.FormatResult(tool, result); ok {
+			result = text
+		}
+	}
+	if err == nil && cacheable {
+		runCtx.toolCache.put(call, result)
+	}
+
+	return ToolResponse{
+		ToolCallID: call.ID,
+		Content:    result,
+		Error:      err,
+		Duration:   time.Since(start),
+	}
+}
+
+// executeWithContext runs the tool but returns as soon as ctx is done, so a
+// tool that ignores cancellation can't hold the run past its deadline. Such
+// a tool keeps running in the background until it returns.
+func executeWithContext(ctx context.Context, tool tools.Tool, args map[string]interface{}) (interface{}, error) {
+	type outcome struct {
+		result interface{}
+		err    error
+	}
+
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := tool.Execute(ctx, args)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("tool %s interrupted: %w", tool.Name(), ctx.Err())
+	}
+}
+
+// toolResultContent renders a tool response for the model, applying the
+// configured ToolErrorBehavior when the tool failed
+func (r *Runner) toolResultContent(ctx *RunContext, call ToolCall, resp ToolResponse) (string, []ContentPart, error) {
+	if resp.Error == nil {
+		content, parts := formatToolContent(resp.Content)
+		if resp.Cached {
+			content = fmt.Sprintf("%s\n(cached result of an identical earlier %s call)", content, call.Name)
+		}
+		return content, parts, nil
+	}
+
+	switch r.toolErrorBehavior {
+	case ToolErrorFailRun:
+		return "", nil, fmt.Errorf("%w: %s: %v", ErrToolExecution, call.Name, resp.Error)
+	case ToolErrorCustom:
+		if r.toolErrorHandler != nil {
+			content, err := r.toolErrorHandler(ctx, call, resp.Error)
+			return content, nil, err
+		}
+	}
+
+	return fmt.Sprintf("tool %s failed: %v", call.Name, resp.Error), nil, nil
+}
+
+// formatToolContent converts a tool result into message text and, for tools
+// returning typed content blocks, the corresponding content parts
+func formatToolContent(content interface{}) (string, []ContentPart) {
+	var blocks []tools.ContentBlock
+	switch v := content.(type) {
+	case *tools.Result:
+		if v == nil {
+			return fmt.Sprintf("%v", content), nil
+		}
+		blocks = v.Blocks
+	case tools.Result:
+		blocks = v.Blocks
+	case tools.ContentBlock:
+		blocks = []tools.ContentBlock{v}
+	default:
+		return fmt.Sprintf("%v", content), nil
+	}
+
+	texts := make([]string, 0, len(blocks))
+	parts := make([]ContentPart, len(blocks))
+	for i, block := range blocks {
+		texts = append(texts, block.String())
+		parts[i] = ContentPart{
+			Type:     string(block.Type),
+			Text:     block.Text,
+			Data:     block.Data,
+			MIMEType: block.MIMEType,
+			URI:      block.URI,
+		}
+	}
+
+	return strings.Join(texts, "\n"), parts
+}
+
+// hostedCallMessages records provider-executed tool calls as an assistant
+// tool call followed by its result, marked so providers can tell them apart
+// from locally executed tools
+func hostedCallMessages(calls []providers.HostedCall) []Message {
+	messages := make([]Message, 0, len(calls)*2)
+	for _, call := range calls {
+		now := time.Now()
+		messages = append(messages,
+			Message{
+				Role: "assistant",
+				ToolCalls: []ToolCall{{
+					ID:        call.ID,
+					Name:      call.Type,
+					Arguments: call.Arguments,
+				}},
+				Metadata:  map[string]interface{}{"hosted": true},
+				Timestamp: now,
+			},
+			Message{
+				Role:    "tool",
+				Content: call.Output,
+				Metadata: map[string]interface{}{
+					"tool_call_id": call.ID,
+					"hosted":       true,
+					"outcome":      call.Outcome,
+				},
+				Timestamp: now,
+			},
+		)
+	}
+	return messages
+}
+
+// authorizeTool checks the tool's required scopes against those granted to
+// the agent and, when restricted, the run
+func authorizeTool(runCtx *RunContext, agent *Agent, tool tools.Tool) error {
+	required := tools.RequiredScopes(tool)
+	if len(required) == 0 {
+		return nil
+	}
+
+	var missing []string
+	for _, scope := range required {
+		granted := len(tools.MissingScopes(agent.Scopes, []string{scope})) == 0
+		if runCtx.GrantedScopes != nil && len(tools.MissingScopes(runCtx.GrantedScopes, []string{scope})) > 0 {
+			granted = false
+		}
+		if !granted {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return &UnauthorizedError{Agent: agent.Name, Tool: tool.Name(), Missing: missing}
+}
+
+// runnerTools returns the tools the runner offers every agent
+func (r *Runner) runnerTools() []tools.Tool {
+	runnerTools := r.stateTools()
+	if r.scratchpad {
+		runnerTools = append(runnerTools, scratchpadTool{})
+	}
+	return runnerTools
+}
+
+// toolsFor returns the tools offered to agent: its own and the runner's.
+// An agent tool named like one of the runner's takes its place.
+func (r *Runner) toolsFor(agent *Agent) []tools.Tool {
+	runnerTools := r.runnerTools()
+	if len(runnerTools) == 0 {
+		return agent.Tools
+	}
+
+	offered := append([]tools.Tool(nil), agent.Tools...)
+	for _, tool := range runnerTools {
+		if !hasTool(agent.Tools, tool.Name()) {
+			offered = append(offered, tool)
+		}
+	}
+	return offered
+}
+
+func hasTool(agentTools []tools.Tool, name string) bool {
+	for _, tool := range agentTools {
+		if tool.Name() == name {
+			return true
+		}
+	}
+	return false
 }
 
 // findTool locates a tool by name
 func (r *Runner) findTool(agent *Agent, name string) tools.Tool {
-	for _, tool := range agent.Tools {
+	for _, tool := range r.toolsFor(agent) {
 		if tool.Name() == name {
 			return tool
 		}
@@ -283,23 +1295,125 @@ func (r *Runner) findTool(agent *Agent, name string) tools.Tool {
 	return nil
 }
 
-// validateGuardrails runs all guardrail checks
-func (r *Runner) validateGuardrails(agent *Agent, messages []Message) error {
+// checkCapabilities verifies the provider supports the agent's tools and
+// the content of the conversation
+func (r *Runner) checkCapabilities(agent *Agent, messages []Message) error {
+	caps := providers.CapabilitiesOf(r.provider)
+
+	for _, tool := range agent.Tools {
+		if hosted, ok := tool.(tools.HostedTool); ok {
+			if !caps.SupportsHostedTool(hosted.HostedType()) {
+				return fmt.Errorf("%w: hosted tool %s", ErrUnsupported, hosted.HostedType())
+			}
+			continue
+		}
+		if _, ok := tool.(tools.ComputerTool); ok && caps.ComputerUse {
+			continue
+		}
+		if !caps.Tools && r.toolProtocol(agent) == ToolProtocolNative {
+			return fmt.Errorf("%w: function tools (agent %s uses %s)", ErrUnsupported, agent.Name, tool.Name())
+		}
+	}
+
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			if part.Type == "image" && !caps.Vision {
+				return fmt.Errorf("%w: image input", ErrUnsupported)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateGuardrails checks the messages added since the model last spoke.
+// Plain guardrails see only the newest message; context-aware guardrails see
+// each of them with its stage, role and tool name.
+func (r *Runner) validateGuardrails(ctx *RunContext, agent *Agent, messages []Message) error {
 	if len(messages) == 0 || len(agent.Guardrails) == 0 {
 		return nil
 	}
 
+	start := len(messages) - 1
+	for start > 0 && messages[start-1].Role != "assistant" {
+		start--
+	}
+
+	// System and developer messages come from the application, not the user
 	lastMessage := messages[len(messages)-1]
+	for i := len(messages) - 1; i > 0 && isInstructionRole(lastMessage.Role); i-- {
+		lastMessage = messages[i-1]
+	}
 
 	for _, guardrail := range agent.Guardrails {
-		if err := guardrail.Validate(lastMessage.Content); err != nil {
-			return fmt.Errorf("guardrail %T failed: %w", guardrail, err)
+		g, ok := guardrail.(guardrails.ContextGuardrail)
+		if !ok {
+			if err := guardrail.Validate(lastMessage.Content); err != nil {
+				return fmt.Errorf("guardrail %T failed: %w", guardrail, err)
+			}
+			continue
+		}
+
+		// Model responses were checked as output when they arrived
+		for i := start; i < len(messages); i++ {
+			if messages[i].Role == "assistant" || isInstructionRole(messages[i].Role) {
+				continue
+			}
+			if err := g.ValidateCheck(ctx, guardrailCheck(ctx, agent, messages[:i], messages[i])); err != nil {
+				return fmt.Errorf("guardrail %T failed: %w", guardrail, err)
+			}
 		}
 	}
 
 	return nil
 }
 
+// validateOutput runs context-aware guardrails on a model response
+func (r *Runner) validateOutput(ctx *RunContext, agent *Agent, msg Message) error {
+	check := guardrails.Check{
+		Stage:    guardrails.StageOutput,
+		Role:     msg.Role,
+		Content:  msg.Content,
+		Agent:    agent.Name,
+		Turn:     ctx.CurrentTurn,
+		Metadata: msg.Metadata,
+	}
+	for _, guardrail := range agent.Guardrails {
+		if err := guardrails.Apply(ctx, guardrail, check); err != nil {
+			return fmt.Errorf("guardrail %T failed: %w", guardrail, err)
+		}
+	}
+	return nil
+}
+
+// guardrailCheck describes msg for guardrails, resolving the tool name of a
+// tool result from the assistant message that requested it
+func guardrailCheck(ctx *RunContext, agent *Agent, earlier []Message, msg Message) guardrails.Check {
+	check := guardrails.Check{
+		Stage:    guardrails.StageInput,
+		Role:     msg.Role,
+		Content:  msg.Content,
+		Agent:    agent.Name,
+		Turn:     ctx.CurrentTurn,
+		Metadata: msg.Metadata,
+	}
+	if msg.Role != "tool" {
+		return check
+	}
+
+	check.Stage = guardrails.StageToolResult
+	callID, _ := msg.Metadata["tool_call_id"].(string)
+	for i := len(earlier) - 1; i >= 0 && callID != ""; i-- {
+		for _, call := range earlier[i].ToolCalls {
+			if call.ID == callID {
+				check.ToolName = call.Name
+				return check
+			}
+		}
+	}
+	return check
+}
+
 // RunSync provides a synchronous interface
 func RunSync(ctx context.Context, agent *Agent, input string, opts ...RunnerOption) (*RunResult, error) {
 	runner := NewRunner(opts...)
@@ -315,6 +1429,7 @@ func messagesToProviders(msgs []Message) []providers.Message {
 		result[i] = providers.Message{
 			Role:      msg.Role,
 			Content:   msg.Content,
+			Parts:     partsToProviders(msg.Parts),
 			ToolCalls: toolCallsToProviders(msg.ToolCalls),
 			Metadata:  msg.Metadata,
 			Timestamp: msg.Timestamp,
@@ -323,6 +1438,30 @@ func messagesToProviders(msgs []Message) []providers.Message {
 	return result
 }
 
+// partsToProviders converts agents.ContentPart to providers.ContentPart
+func partsToProviders(parts []ContentPart) []providers.ContentPart {
+	if len(parts) == 0 {
+		return nil
+	}
+	result := make([]providers.ContentPart, len(parts))
+	for i, part := range parts {
+		result[i] = providers.ContentPart(part)
+	}
+	return result
+}
+
+// partsFromProviders converts providers.ContentPart to agents.ContentPart
+func partsFromProviders(parts []providers.ContentPart) []ContentPart {
+	if len(parts) == 0 {
+		return nil
+	}
+	result := make([]ContentPart, len(parts))
+	for i, part := range parts {
+		result[i] = ContentPart(part)
+	}
+	return result
+}
+
 // toolCallsToProviders converts agents.ToolCall to providers.ToolCall
 func toolCallsToProviders(calls []ToolCall) []providers.ToolCall {
 	result := make([]providers.ToolCall, len(calls))
@@ -354,6 +1493,7 @@ func messageFromProviders(msg providers.Message) Message {
 	return Message{
 		Role:      msg.Role,
 		Content:   msg.Content,
+		Parts:     partsFromProviders(msg.Parts),
 		ToolCalls: toolCallsFromProviders(msg.ToolCalls),
 		Metadata:  msg.Metadata,
 		Timestamp: msg.Timestamp,
@@ -370,6 +1510,13 @@ func messagesToMemory(msgs []Message) []memory.Message {
 			Metadata:  msg.Metadata,
 			Timestamp: msg.Timestamp,
 		}
+		for _, part := range msg.Parts {
+			result[i].Parts = append(result[i].Parts, memory.ContentPart(part))
+		}
+		for _, call := range msg.ToolCalls {
+			result[i].ToolCalls = append(result[i].ToolCalls, memory.ToolCall(call))
+		}
+		result[i].ToolCallID, _ = msg.Metadata["tool_call_id"].(string)
 	}
 	return result
 }
@@ -383,15 +1530,32 @@ func messagesFromMemory(msgs []memory.Message) []Message {
 			Content:   msg.Content,
 			Metadata:  msg.Metadata,
 			Timestamp: msg.Timestamp,
+			persisted: true,
+		}
+		for _, part := range msg.Parts {
+			result[i].Parts = append(result[i].Parts, ContentPart(part))
+		}
+		for _, call := range msg.ToolCalls {
+			result[i].ToolCalls = append(result[i].ToolCalls, ToolCall(call))
+		}
+
+		// Sessions that keep the ID outside the metadata still link results
+		if _, ok := msg.Metadata["tool_call_id"]; msg.ToolCallID != "" && !ok {
+			metadata := make(map[string]interface{}, len(msg.Metadata)+1)
+			for key, value := range msg.Metadata {
+				metadata[key] = value
+			}
+			metadata["tool_call_id"] = msg.ToolCallID
+			result[i].Metadata = metadata
 		}
 	}
 	return result
 }
 
 // convertToolsToProviders converts agents tools to provider tool definitions
-func convertToolsToProviders(tools []tools.Tool) []providers.ToolDefinition {
-	result := make([]providers.ToolDefinition, len(tools))
-	for i, tool := range tools {
+func convertToolsToProviders(agentTools []tools.Tool) []providers.ToolDefinition {
+	result := make([]providers.ToolDefinition, len(agentTools))
+	for i, tool := range agentTools {
 		schema := tool.Schema()
 		result[i] = providers.ToolDefinition{
 			Name:        tool.Name(),
@@ -402,6 +1566,14 @@ func convertToolsToProviders(tools []tools.Tool) []providers.ToolDefinition {
 				Required:   schema.Required,
 			},
 		}
+		if hosted, ok := tool.(tools.HostedTool); ok {
+			result[i].Hosted = hosted.HostedType()
+			result[i].Config = hosted.HostedConfig()
+		}
+		if computer, ok := tool.(tools.ComputerTool); ok {
+			width, height := computer.DisplaySize()
+			result[i].Display = &providers.Display{Width: width, Height: height}
+		}
 	}
 	return result
 }
@@ -410,33 +1582,104 @@ func convertToolsToProviders(tools []tools.Tool) []providers.ToolDefinition {
 func convertProperties(props map[string]tools.PropertySchema) map[string]providers.PropertySchema {
 	result := make(map[string]providers.PropertySchema)
 	for name, prop := range props {
-		result[name] = providers.PropertySchema{
-			Type:        prop.Type,
-			Description: prop.Description,
-		}
+		result[name] = convertProperty(prop)
 	}
 	return result
 }
 
-// RunAsync provides a channel-based async interface
-func RunAsync(ctx context.Context, agent *Agent, input string, opts ...RunnerOption) <-chan *RunResult {
-	resultChan := make(chan *RunResult, 1)
+// convertProperty converts a tools property schema, including nested items
+// and fields, to the provider form
+func convertProperty(prop tools.PropertySchema) providers.PropertySchema {
+	result := providers.PropertySchema{
+		Type:        prop.Type,
+		Description: prop.Description,
+		Required:    prop.Required,
+		Default:     prop.Default,
+		Enum:        prop.Enum,
+		Minimum:     prop.Minimum,
+		Maximum:     prop.Maximum,
+		Pattern:     prop.Pattern,
+		Format:      prop.Format,
+	}
 
+	if prop.Items != nil {
+		items := convertProperty(*prop.Items)
+		result.Items = &items
+	}
+	if len(prop.Properties) > 0 {
+		result.Properties = convertProperties(prop.Properties)
+	}
+	if prop.AdditionalProperties != nil {
+		values := convertProperty(*prop.AdditionalProperties)
+		result.AdditionalProperties = &values
+	}
+
+	return result
+}
+
+// RunOutcome is the result of an asynchronous run; exactly one of Result
+// and Err is set
+type RunOutcome struct {
+	Result *RunResult
+	Err    error
+}
+
+// RunHandle tracks a run started by RunAsync
+type RunHandle struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	outcome RunOutcome
+}
+
+// Done is closed when the run finishes
+func (h *RunHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait blocks until the run finishes and returns its result
+func (h *RunHandle) Wait() (*RunResult, error) {
+	<-h.done
+	return h.outcome.Result, h.outcome.Err
+}
+
+// Outcome returns a channel that receives the run's outcome once
+func (h *RunHandle) Outcome() <-chan RunOutcome {
+	ch := make(chan RunOutcome, 1)
 	go func() {
-		defer close(resultChan)
+		<-h.done
+		ch <- h.outcome
+		close(ch)
+	}()
+	return ch
+}
 
-		runner := NewRunner(opts...)
-		result, err := runner.Run(ctx, agent, input)
+// Cancel stops the run; Wait then returns the cancellation error
+func (h *RunHandle) Cancel() {
+	h.cancel()
+}
 
-		if err != nil {
-			// Include error in result
-			result = &RunResult{
-				FinalOutput: fmt.Sprintf("Error: %v", err),
-			}
-		}
+// RunAsync starts the run in the background. The run stops when ctx is
+// done or the handle is cancelled.
+func (r *Runner) RunAsync(ctx context.Context, agent *Agent, input string, opts ...RunOption) *RunHandle {
+	ctx, cancel := context.WithCancel(ctx)
+	h := &RunHandle{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	go func() {
+		defer close(h.done)
+		defer cancel()
 
-		resultChan <- result
+		result, err := r.Run(ctx, agent, input, opts...)
+		h.outcome = RunOutcome{Result: result, Err: err}
 	}()
 
-	return resultChan
+	return h
+}
+
+// RunAsync starts the agent on a new runner in the background. Failures are
+// reported through the handle, never in FinalOutput.
+func RunAsync(ctx context.Context, agent *Agent, input string, opts ...RunnerOption) *RunHandle {
+	return NewRunner(opts...).RunAsync(ctx, agent, input)
 }
@@ -0,0 +1,222 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+	"golang.org/x/sync/errgroup"
+)
+
+// Candidate is one of the alternative completions sampled for a turn with
+// WithNSamples
+type Candidate struct {
+	Turn      int        `json:"turn"`
+	Index     int        `json:"index"`
+	Content   string     `json:"content"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	Tokens    int        `json:"tokens"`
+
+	// Score is set by selectors that score candidates
+	Score float64 `json:"score,omitempty"`
+
+	// Selected marks the candidate the run continued with
+	Selected bool `json:"selected,omitempty"`
+}
+
+// SampleSelector picks the best of a turn's candidates. It may set the
+// candidates' scores, which are kept in RunResult.Candidates.
+type SampleSelector interface {
+	Select(ctx *RunContext, messages []Message, candidates []Candidate) (int, error)
+}
+
+// SampleSelectorFunc adapts a function to SampleSelector
+type SampleSelectorFunc func(ctx *RunContext, messages []Message, candidates []Candidate) (int, error)
+
+// Select implements SampleSelector
+func (f SampleSelectorFunc) Select(ctx *RunContext, messages []Message, candidates []Candidate) (int, error) {
+	return f(ctx, messages, candidates)
+}
+
+// ScoreSelector selects the candidate with the highest score, the first on
+// ties
+func ScoreSelector(score func(ctx *RunContext, candidate Candidate) (float64, error)) SampleSelector {
+	return SampleSelectorFunc(func(ctx *RunContext, _ []Message, candidates []Candidate) (int, error) {
+		best := 0
+		for i := range candidates {
+			s, err := score(ctx, candidates[i])
+			if err != nil {
+				return 0, fmt.Errorf("failed to score candidate %d: %w", i, err)
+			}
+			candidates[i].Score = s
+			if s > candidates[best].Score {
+				best = i
+			}
+		}
+		return best, nil
+	})
+}
+
+// judgeChoice is the reply JudgeSelector asks the judge for
+type judgeChoice struct {
+	Best   int       `json:"best"`
+	Scores []float64 `json:"scores"`
+}
+
+// JudgeSelector asks judge, run on runner, to compare the candidates and
+// pick the best. The judge's instructions should say what "best" means; the
+// request, candidates and reply format are supplied in its input.
+func JudgeSelector(runner *Runner, judge *Agent) SampleSelector {
+	return SampleSelectorFunc(func(ctx *RunContext, messages []Message, candidates []Candidate) (int, error) {
+		var prompt strings.Builder
+		if request := lastUserMessage(messages); request != "" {
+			fmt.Fprintf(&prompt, "Request:\n%s\n\n", request)
+		}
+		for i, candidate := range candidates {
+			fmt.Fprintf(&prompt, "Response %d:\n%s\n", i, candidate.Content)
+			for _, call := range candidate.ToolCalls {
+				fmt.Fprintf(&prompt, "[calls tool %s with %v]\n", call.Name, call.Arguments)
+			}
+			prompt.WriteString("\n")
+		}
+		fmt.Fprintf(&prompt, `Compare the %d responses and pick the best. Reply with only a JSON object: {"best": <index>, "scores": [<0.0-1.0 for each response>]}`, len(candidates))
+
+		result, err := runner.Run(ctx, judge, prompt.String(), withoutSession())
+		if err != nil {
+			return 0, fmt.Errorf("judge failed: %w", err)
+		}
+
+		var choice judgeChoice
+		if err := DecodeJSON(fmt.Sprint(result.FinalOutput), &choice); err != nil {
+			return 0, fmt.Errorf("judge reply: %w", err)
+		}
+		if choice.Best < 0 || choice.Best >= len(candidates) {
+			return 0, fmt.Errorf("judge picked response %d of %d", choice.Best, len(candidates))
+		}
+		for i := range candidates {
+			if i < len(choice.Scores) {
+				candidates[i].Score = choice.Scores[i]
+			}
+		}
+		return choice.Best, nil
+	})
+}
+
+// lastUserMessage returns the content of the latest user message
+func lastUserMessage(messages []Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// sample requests n completions in a single call when the provider
+// implements providers.Sampler, and otherwise with n parallel calls.
+// Parallel calls that fail are dropped as long as one succeeds. Agents using
+// an emulated tool protocol have their tool calls parsed from the text.
+func (r *Runner) sample(ctx context.Context, agent *Agent, messages []Message, toolDefs []providers.ToolDefinition, n int) ([]*providers.Completion, error) {
+	messages = withEntityCards(ctx, withScratchpad(ctx, messages))
+
+	protocol := r.toolProtocol(agent)
+	if protocol == ToolProtocolNative || !hasFunctionTools(toolDefs) {
+		return r.sampleN(ctx, agent, messages, toolDefs, n)
+	}
+
+	prompted, emulated, native := emulateTools(protocol, agent, messages, toolDefs)
+	completions, err := r.sampleN(ctx, prompted, emulated, native, n)
+	if err != nil {
+		return nil, err
+	}
+	for _, completion := range completions {
+		parseEmulatedCalls(protocol, completion, toolDefs)
+	}
+	return completions, nil
+}
+
+// sampleN requests n completions as they are
+func (r *Runner) sampleN(ctx context.Context, agent *Agent, messages []Message, toolDefs []providers.ToolDefinition, n int) ([]*providers.Completion, error) {
+	if n <= 1 {
+		if prefetch := prefetchFromContext(ctx); prefetch != nil {
+			if streamer, ok := r.provider.(providers.ToolCallStreamer); ok {
+				completion, err := streamer.CompleteStreaming(ctx, agent, messagesToProviders(messages), toolDefs, prefetch.start)
+				if err != nil {
+					return nil, err
+				}
+				return []*providers.Completion{completion}, nil
+			}
+		}
+
+		completion, err := r.provider.Complete(ctx, agent, messagesToProviders(messages), toolDefs)
+		if err != nil {
+			return nil, err
+		}
+		return []*providers.Completion{completion}, nil
+	}
+
+	if sampler, ok := r.provider.(providers.Sampler); ok {
+		return sampler.CompleteN(ctx, agent, messagesToProviders(messages), toolDefs, n)
+	}
+
+	completions := make([]*providers.Completion, n)
+	errs := make([]error, n)
+	var g errgroup.Group
+	for i := 0; i < n; i++ {
+		g.Go(func() error {
+			completions[i], errs[i] = r.provider.Complete(ctx, agent, messagesToProviders(messages), toolDefs)
+			return nil
+		})
+	}
+	g.Wait()
+
+	succeeded := completions[:0]
+	for i, completion := range completions {
+		if errs[i] == nil {
+			succeeded = append(succeeded, completion)
+		}
+	}
+	if len(succeeded) == 0 {
+		return nil, errs[0]
+	}
+	return succeeded, nil
+}
+
+// selectSample picks the completion the run continues with, recording every
+// candidate. The selected completion carries the usage of all candidates.
+func (r *Runner) selectSample(ctx *RunContext, messages []Message, completions []*providers.Completion) (*providers.Completion, error) {
+	if len(completions) == 1 {
+		return completions[0], nil
+	}
+
+	candidates := make([]Candidate, len(completions))
+	var usage providers.Usage
+	for i, completion := range completions {
+		candidates[i] = Candidate{
+			Turn:      ctx.CurrentTurn,
+			Index:     i,
+			Content:   completion.Message.Content,
+			ToolCalls: toolCallsFromProviders(completion.ToolCalls),
+			Tokens:    completion.Usage.TotalTokens,
+		}
+		usage.Add(completion.Usage)
+	}
+
+	best := 0
+	if ctx.sampleSelector != nil {
+		var err error
+		if best, err = ctx.sampleSelector.Select(ctx, messages, candidates); err != nil {
+			return nil, fmt.Errorf("sample selection failed: %w", err)
+		}
+		if best < 0 || best >= len(candidates) {
+			return nil, fmt.Errorf("sample selection failed: candidate %d of %d", best, len(candidates))
+		}
+	}
+	candidates[best].Selected = true
+	ctx.candidates = append(ctx.candidates, candidates...)
+
+	selected := *completions[best]
+	selected.Usage = usage
+	return &selected, nil
+}
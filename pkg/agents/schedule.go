@@ -0,0 +1,430 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/memory"
+)
+
+// Schedule statuses recorded after each run
+const (
+	ScheduleSucceeded = "succeeded"
+	ScheduleFailed    = "failed"
+)
+
+// InputFunc builds the input of a scheduled run; at is the time the run was
+// scheduled for, so "yesterday" can be computed without clock skew
+type InputFunc func(ctx context.Context, at time.Time) (string, error)
+
+// StaticInput returns an InputFunc that always produces input
+func StaticInput(input string) InputFunc {
+	return func(context.Context, time.Time) (string, error) {
+		return input, nil
+	}
+}
+
+// ScheduledJob is a recurring agent run created by Schedule
+type ScheduledJob struct {
+	Name  string
+	Cron  string
+	Agent *Agent
+	Input InputFunc
+
+	schedule   CronSchedule
+	runOptions []RunOption
+	catchUp    bool
+	timeout    time.Duration
+}
+
+// ScheduleOption configures a ScheduledJob
+type ScheduleOption func(*scheduleConfig)
+
+type scheduleConfig struct {
+	name       string
+	location   *time.Location
+	runOptions []RunOption
+	catchUp    bool
+	timeout    time.Duration
+}
+
+// WithScheduleName names the schedule; names identify persisted status and
+// default to the agent name
+func WithScheduleName(name string) ScheduleOption {
+	return func(c *scheduleConfig) {
+		c.name = name
+	}
+}
+
+// WithScheduleLocation evaluates the cron expression in loc instead of the
+// local time zone
+func WithScheduleLocation(loc *time.Location) ScheduleOption {
+	return func(c *scheduleConfig) {
+		c.location = loc
+	}
+}
+
+// WithScheduleRunOptions applies options to every scheduled run
+func WithScheduleRunOptions(opts ...RunOption) ScheduleOption {
+	return func(c *scheduleConfig) {
+		c.runOptions = append(c.runOptions, opts...)
+	}
+}
+
+// WithCatchUp runs a schedule once at startup when a fire time was missed
+// while no scheduler was running
+func WithCatchUp(enabled bool) ScheduleOption {
+	return func(c *scheduleConfig) {
+		c.catchUp = enabled
+	}
+}
+
+// WithScheduleTimeout bounds each scheduled run
+func WithScheduleTimeout(timeout time.Duration) ScheduleOption {
+	return func(c *scheduleConfig) {
+		c.timeout = timeout
+	}
+}
+
+// Schedule defines a recurring run of agent at the times given by a cron
+// expression (see ParseCron). Add it to a Scheduler to run it.
+func Schedule(cronExpr string, agent *Agent, input InputFunc, opts ...ScheduleOption) (*ScheduledJob, error) {
+	if agent == nil {
+		return nil, fmt.Errorf("schedule requires an agent")
+	}
+	if input == nil {
+		return nil, fmt.Errorf("schedule requires an input function")
+	}
+
+	cfg := &scheduleConfig{name: agent.Name}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	schedule, err := ParseCron(cronExpr, cfg.location)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScheduledJob{
+		Name:       cfg.name,
+		Cron:       cronExpr,
+		Agent:      agent,
+		Input:      input,
+		schedule:   schedule,
+		runOptions: cfg.runOptions,
+		catchUp:    cfg.catchUp,
+		timeout:    cfg.timeout,
+	}, nil
+}
+
+// SchedulerOption configures a Scheduler
+type SchedulerOption func(*Scheduler)
+
+// WithScheduleStore persists schedules and their last-run status
+func WithScheduleStore(store memory.ScheduleStore) SchedulerOption {
+	return func(s *Scheduler) {
+		s.store = store
+	}
+}
+
+// WithSchedulerLogger sets the logger for failed scheduled runs
+func WithSchedulerLogger(logger *slog.Logger) SchedulerOption {
+	return func(s *Scheduler) {
+		s.logger = logger
+	}
+}
+
+// Scheduler runs scheduled jobs on a runner. A job still running when it is
+// due again is skipped rather than run twice.
+type Scheduler struct {
+	runner *Runner
+	store  memory.ScheduleStore
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	jobs    map[string]*ScheduledJob
+	records map[string]memory.ScheduleRecord
+	running map[string]bool
+	changed chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler running jobs on runner. Without a
+// schedule store, status is kept in memory.
+func NewScheduler(runner *Runner, opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{
+		runner:  runner,
+		logger:  slog.Default(),
+		jobs:    make(map[string]*ScheduledJob),
+		records: make(map[string]memory.ScheduleRecord),
+		running: make(map[string]bool),
+		changed: make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.store == nil {
+		s.store = memory.NewInMemoryScheduleStore()
+	}
+
+	return s
+}
+
+// Add registers jobs, restoring their status from the store. A stored
+// record is kept across restarts as long as the job keeps its name.
+func (s *Scheduler) Add(ctx context.Context, jobs ...*ScheduledJob) error {
+	for _, job := range jobs {
+		s.mu.Lock()
+		_, exists := s.jobs[job.Name]
+		s.mu.Unlock()
+		if exists {
+			return fmt.Errorf("%w: %s", ErrScheduleExists, job.Name)
+		}
+
+		now := time.Now()
+		record := memory.ScheduleRecord{
+			Name:      job.Name,
+			Cron:      job.Cron,
+			Agent:     job.Agent.Name,
+			CreatedAt: now,
+		}
+		stored, err := s.store.Schedule(ctx, job.Name)
+		switch {
+		case err == nil:
+			if stored.Cron != job.Cron {
+				stored.Cron, stored.NextRun = job.Cron, time.Time{}
+			}
+			stored.Agent = job.Agent.Name
+			record = *stored
+		case !errors.Is(err, memory.ErrScheduleNotFound):
+			return fmt.Errorf("failed to load schedule %s: %w", job.Name, err)
+		}
+
+		// A fire time that passed while nothing was running is either run
+		// now or skipped
+		if record.NextRun.IsZero() || record.NextRun.Before(now) && !job.catchUp {
+			record.NextRun = job.schedule.Next(now)
+		}
+
+		if err := s.store.SaveSchedule(ctx, record); err != nil {
+			return fmt.Errorf("failed to save schedule %s: %w", job.Name, err)
+		}
+
+		s.mu.Lock()
+		s.jobs[job.Name] = job
+		s.records[job.Name] = record
+		s.mu.Unlock()
+	}
+
+	s.notify()
+	return nil
+}
+
+// Remove unregisters a job and deletes its stored status
+func (s *Scheduler) Remove(ctx context.Context, name string) error {
+	s.mu.Lock()
+	delete(s.jobs, name)
+	delete(s.records, name)
+	s.mu.Unlock()
+
+	s.notify()
+	return s.store.DeleteSchedule(ctx, name)
+}
+
+// Pause stops a job from firing until Resume, persisting the state
+func (s *Scheduler) Pause(ctx context.Context, name string) error {
+	return s.setPaused(ctx, name, true)
+}
+
+// Resume lets a paused job fire again from its next scheduled time
+func (s *Scheduler) Resume(ctx context.Context, name string) error {
+	return s.setPaused(ctx, name, false)
+}
+
+func (s *Scheduler) setPaused(ctx context.Context, name string, paused bool) error {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, name)
+	}
+	record := s.records[name]
+	record.Paused = paused
+	if !paused {
+		record.NextRun = job.schedule.Next(time.Now())
+	}
+	s.records[name] = record
+	s.mu.Unlock()
+
+	s.notify()
+	return s.store.SaveSchedule(ctx, record)
+}
+
+// Status returns the status of every registered job ordered by name
+func (s *Scheduler) Status() []memory.ScheduleRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]memory.ScheduleRecord, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	return records
+}
+
+// RunNow runs the named job immediately, outside its schedule
+func (s *Scheduler) RunNow(ctx context.Context, name string) (*RunResult, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, name)
+	}
+
+	return s.execute(ctx, job, time.Now())
+}
+
+// Start fires jobs as they come due until ctx is done, then waits for runs
+// in progress to finish
+func (s *Scheduler) Start(ctx context.Context) error {
+	defer s.wg.Wait()
+
+	for {
+		now := time.Now()
+		next := s.dispatchDue(ctx, now)
+
+		var timer *time.Timer
+		var wait <-chan time.Time
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			wait = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-s.changed:
+		case <-wait:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// dispatchDue starts every job due at now and returns the earliest upcoming
+// fire time
+func (s *Scheduler) dispatchDue(ctx context.Context, now time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for name, job := range s.jobs {
+		record := s.records[name]
+		if record.Paused || record.NextRun.IsZero() {
+			continue
+		}
+
+		if !record.NextRun.After(now) {
+			at := record.NextRun
+			record.NextRun = job.schedule.Next(now)
+			s.records[name] = record
+
+			if s.running[name] {
+				s.logger.Warn("skipping scheduled run still in progress", "schedule", name)
+			} else {
+				s.running[name] = true
+				s.wg.Add(1)
+				go func() {
+					defer s.wg.Done()
+					defer func() {
+						s.mu.Lock()
+						delete(s.running, job.Name)
+						s.mu.Unlock()
+					}()
+					// Runs finish even when the scheduler is stopping
+					ctx := context.WithoutCancel(ctx)
+
+					// The next fire time is saved before running, so a crash
+					// mid-run doesn't fire the job again through catch-up
+					if err := s.store.SaveSchedule(ctx, record); err != nil {
+						s.logger.Error("failed to save schedule status", "schedule", job.Name, "error", err)
+					}
+					s.execute(ctx, job, at)
+				}()
+			}
+		}
+
+		if next.IsZero() || record.NextRun.Before(next) {
+			next = record.NextRun
+		}
+	}
+	return next
+}
+
+// execute runs a job and records its outcome
+func (s *Scheduler) execute(ctx context.Context, job *ScheduledJob, at time.Time) (*RunResult, error) {
+	// The outcome is saved even when the run timed out or was cancelled
+	saveCtx := context.WithoutCancel(ctx)
+	if job.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	result, err := func() (*RunResult, error) {
+		input, err := job.Input(ctx, at)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build input: %w", err)
+		}
+		return s.runner.Run(ctx, job.Agent, input, job.runOptions...)
+	}()
+
+	s.mu.Lock()
+	record, ok := s.records[job.Name]
+	if ok {
+		record.LastRun = start
+		record.LastDuration = time.Since(start)
+		record.Runs++
+		record.LastStatus = ScheduleSucceeded
+		record.LastError = ""
+		if err != nil {
+			record.LastStatus = ScheduleFailed
+			record.LastError = err.Error()
+			record.Failures++
+		}
+		s.records[job.Name] = record
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		s.logger.Error("scheduled run failed", "schedule", job.Name, "error", err)
+	}
+	if ok {
+		if saveErr := s.store.SaveSchedule(saveCtx, record); saveErr != nil {
+			s.logger.Error("failed to save schedule status", "schedule", job.Name, "error", saveErr)
+		}
+	}
+
+	return result, err
+}
+
+// notify wakes Start to recompute the next fire time
+func (s *Scheduler) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
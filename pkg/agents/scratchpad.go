@@ -0,0 +1,123 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/tools"
+)
+
+// ScratchpadTool names the tool WithScratchpad offers agents
+const ScratchpadTool = "update_scratchpad"
+
+// scratchpadLimit caps the scratchpad's length, so notes can't crowd out
+// the conversation in the prompt
+const scratchpadLimit = 8000
+
+// scratchpad holds an agent's working notes for one run
+type scratchpad struct {
+	mu    sync.Mutex
+	notes string
+}
+
+type scratchpadKey struct{}
+
+// scratchpadFromContext returns the run's scratchpad, nil without one
+func scratchpadFromContext(ctx context.Context) *scratchpad {
+	pad, _ := ctx.Value(scratchpadKey{}).(*scratchpad)
+	return pad
+}
+
+// String returns the notes
+func (s *scratchpad) String() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.notes
+}
+
+// update appends to or replaces the notes
+func (s *scratchpad) update(notes string, replace bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updated := strings.TrimSpace(notes)
+	if !replace && s.notes != "" && updated != "" {
+		updated = s.notes + "\n" + updated
+	}
+	if len(updated) > scratchpadLimit {
+		return "", fmt.Errorf("scratchpad would hold %d characters, over the limit of %d; condense it with mode replace", len(updated), scratchpadLimit)
+	}
+	s.notes = updated
+	return s.notes, nil
+}
+
+// withScratchpad adds the run's notes to the end of the prompt as a system
+// message. The message is only sent, never added to the history.
+func withScratchpad(ctx context.Context, messages []Message) []Message {
+	notes := scratchpadFromContext(ctx).String()
+	if notes == "" {
+		return messages
+	}
+
+	prompt := make([]Message, len(messages), len(messages)+1)
+	copy(prompt, messages)
+	return append(prompt, SystemMessage("Your scratchpad, private working notes for this task that aren't part of the conversation. Update them with "+ScratchpadTool+".\n\n"+notes))
+}
+
+// scratchpadTool writes to the run's scratchpad
+type scratchpadTool struct{}
+
+func (scratchpadTool) Name() string { return ScratchpadTool }
+
+func (scratchpadTool) Description() string {
+	return "Write working notes, such as your plan, intermediate results and open questions, to your scratchpad. The scratchpad is shown to you on every turn of this task but isn't saved to the conversation, so use it for notes the user doesn't need to see."
+}
+
+func (scratchpadTool) Schema() tools.ParameterSchema {
+	return tools.ParameterSchema{
+		Type: "object",
+		Properties: map[string]tools.PropertySchema{
+			"notes": {
+				Type:        "string",
+				Description: "The notes to write",
+			},
+			"mode": {
+				Type:        "string",
+				Description: "append adds the notes to the scratchpad, replace overwrites it; replace with empty notes to clear it",
+				Enum:        []interface{}{"append", "replace"},
+				Default:     "append",
+			},
+		},
+		Required: []string{"notes"},
+	}
+}
+
+func (scratchpadTool) Validate() error { return nil }
+
+func (scratchpadTool) Capabilities() tools.Capabilities {
+	return tools.Capabilities{Cost: tools.CostFree, Latency: tools.LatencyFast}
+}
+
+func (scratchpadTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	pad := scratchpadFromContext(ctx)
+	if pad == nil {
+		return nil, fmt.Errorf("the scratchpad is only available within a run")
+	}
+
+	notes, _ := args["notes"].(string)
+	mode, _ := args["mode"].(string)
+	updated, err := pad.update(notes, mode == "replace")
+	if err != nil {
+		return nil, err
+	}
+	// The result stays in the history, so it doesn't repeat the notes
+	if updated == "" {
+		return "The scratchpad is empty.", nil
+	}
+	return fmt.Sprintf("Scratchpad updated; it holds %d characters.", len(updated)), nil
+}
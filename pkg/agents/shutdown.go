@@ -0,0 +1,119 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/memory"
+)
+
+// runTracker counts the runs in progress so Shutdown can drain them
+type runTracker struct {
+	mu      sync.Mutex
+	closed  bool
+	next    uint64
+	cancels map[uint64]context.CancelCauseFunc
+	wg      sync.WaitGroup
+}
+
+// start registers a run and returns its context and completion function,
+// or ErrShutdown once the runner is shutting down
+func (t *runTracker) start(ctx context.Context) (context.Context, func(), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil, nil, ErrShutdown
+	}
+	if t.cancels == nil {
+		t.cancels = make(map[uint64]context.CancelCauseFunc)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	id := t.next
+	t.next++
+	t.cancels[id] = cancel
+	t.wg.Add(1)
+
+	return ctx, func() {
+		t.mu.Lock()
+		delete(t.cancels, id)
+		t.mu.Unlock()
+		cancel(nil)
+		t.wg.Done()
+	}, nil
+}
+
+// Shutdown stops the runner from accepting new runs and waits for the runs
+// in progress. If ctx ends first, the remaining runs are interrupted: they
+// fail with a *TimeoutError wrapping ErrShutdown, and their messages so far
+// are saved to their session as a checkpoint that Continue can resume from.
+// Finally the provider, session and stores are closed when they implement
+// io.Closer.
+func (r *Runner) Shutdown(ctx context.Context) error {
+	t := &r.inflight
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		t.mu.Lock()
+		for _, cancel := range t.cancels {
+			cancel(ErrShutdown)
+		}
+		t.mu.Unlock()
+
+		// Interrupted runs return promptly once their context is cancelled
+		<-drained
+		err = fmt.Errorf("runs interrupted by shutdown: %w", ctx.Err())
+	}
+
+	return errors.Join(err, r.closeResources())
+}
+
+// checkpoint saves the partial messages of a run interrupted by shutdown
+func (r *Runner) checkpoint(ctx context.Context, session memory.Session, err error) {
+	var timeout *TimeoutError
+	if session == nil || !errors.Is(err, ErrShutdown) || !errors.As(err, &timeout) {
+		return
+	}
+
+	// The run's context is cancelled, but the checkpoint must still be written
+	_ = session.AddItems(context.WithoutCancel(ctx), messagesToMemory(unpersisted(timeout.Partial.Messages)))
+}
+
+// closeResources closes the provider, session and stores, each once
+func (r *Runner) closeResources() error {
+	var errs []error
+	closed := make(map[interface{}]bool)
+	for _, resource := range []interface{}{r.provider, r.session, r.sessionStore, r.runStore} {
+		closer, ok := resource.(io.Closer)
+		if !ok {
+			continue
+		}
+		// The same store often serves as session store and run store
+		if reflect.TypeOf(closer).Comparable() {
+			if closed[closer] {
+				continue
+			}
+			closed[closer] = true
+		}
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
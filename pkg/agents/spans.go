@@ -0,0 +1,56 @@
+package agents
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/tracing"
+)
+
+// spanRecorder collects the spans started during one run for RunResult.Traces
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []tracing.Span
+}
+
+func (s *spanRecorder) add(span tracing.Span) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.spans = append(s.spans, span)
+}
+
+func (s *spanRecorder) list() []tracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spans := make([]tracing.Span, len(s.spans))
+	copy(spans, s.spans)
+	return spans
+}
+
+// startSpan starts a span as a child of the span active in ctx and records
+// it on the run
+func (r *Runner) startSpan(runCtx *RunContext, ctx context.Context, name string) (context.Context, tracing.Span) {
+	ctx, span := r.tracer.StartSpan(ctx, name)
+	if runCtx.spans != nil {
+		runCtx.spans.add(span)
+	}
+	return ctx, span
+}
+
+// endSpan records err (if any) on span and ends it
+func (r *Runner) endSpan(span tracing.Span, err error) {
+	if err != nil {
+		span.SetError(err)
+	}
+	r.tracer.EndSpan(span)
+}
+
+// SpanContext returns the context of the run's active span for propagating
+// the trace to other services. Tools should prefer the context they are
+// called with, whose active span is their own tool.execute span (see
+// tracing.InjectHTTP).
+func (c *RunContext) SpanContext() (tracing.SpanContext, bool) {
+	return tracing.SpanContextFromContext(c.Context)
+}
@@ -0,0 +1,158 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/tools"
+)
+
+// Names of the tools WithSessionState offers agents
+const (
+	GetStateTool   = "get_state"
+	PatchStateTool = "patch_state"
+)
+
+type stateSessionKey struct{}
+
+// stateTools returns the session state tools offered to agents, nil unless
+// the runner has a state store
+func (r *Runner) stateTools() []tools.Tool {
+	if r.stateStore == nil {
+		return nil
+	}
+	return []tools.Tool{getStateTool{r}, patchStateTool{r}}
+}
+
+// sessionState loads the state document of the run's session, an empty
+// object if it has none
+func (r *Runner) sessionState(ctx context.Context) (string, json.RawMessage, error) {
+	sessionID, _ := ctx.Value(stateSessionKey{}).(string)
+	if sessionID == "" {
+		return "", nil, errors.New("session state is only available within a run")
+	}
+
+	state, err := r.stateStore.GetState(ctx, sessionID)
+	if err != nil {
+		return "", nil, err
+	}
+	if state == nil {
+		state = json.RawMessage("{}")
+	}
+	return sessionID, state, nil
+}
+
+// getStateTool reads the session's state document
+type getStateTool struct {
+	r *Runner
+}
+
+func (t getStateTool) Name() string { return GetStateTool }
+
+func (t getStateTool) Description() string {
+	return "Read the state document of this conversation: a JSON document for tracking task state, such as plans, progress and facts, that persists across turns and runs. Update it with " + PatchStateTool + "."
+}
+
+func (t getStateTool) Schema() tools.ParameterSchema {
+	return tools.ParameterSchema{Type: "object", Properties: map[string]tools.PropertySchema{}}
+}
+
+func (t getStateTool) Validate() error { return nil }
+
+func (t getStateTool) Capabilities() tools.Capabilities {
+	return tools.Capabilities{Cost: tools.CostFree, Latency: tools.LatencyFast}
+}
+
+func (t getStateTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	_, state, err := t.r.sessionState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return string(state), nil
+}
+
+// patchStateTool updates the session's state document with a JSON Patch
+type patchStateTool struct {
+	r *Runner
+}
+
+func (t patchStateTool) Name() string { return PatchStateTool }
+
+func (t patchStateTool) Description() string {
+	return "Update the state document of this conversation with a JSON Patch (RFC 6902). Operations apply in order and all or not at all; use a test operation to check a value before changing it. Returns the updated document."
+}
+
+func (t patchStateTool) Schema() tools.ParameterSchema {
+	return tools.ParameterSchema{
+		Type: "object",
+		Properties: map[string]tools.PropertySchema{
+			"operations": {
+				Type:        "array",
+				Description: "The patch operations",
+				Items: &tools.PropertySchema{
+					Type: "object",
+					Properties: map[string]tools.PropertySchema{
+						"op": {
+							Type:        "string",
+							Description: "The operation",
+							Enum:        []interface{}{"add", "remove", "replace", "move", "copy", "test"},
+						},
+						"path": {
+							Type:        "string",
+							Description: `JSON Pointer to the target, e.g. "/tasks/0/done"; "/tasks/-" appends to the tasks array`,
+						},
+						"from": {
+							Type:        "string",
+							Description: "JSON Pointer to the source of move and copy",
+						},
+						"value": {
+							Description: "The value of add, replace and test",
+						},
+					},
+					Required: []string{"op", "path"},
+				},
+			},
+		},
+		Required: []string{"operations"},
+	}
+}
+
+func (t patchStateTool) Validate() error { return nil }
+
+func (t patchStateTool) Capabilities() tools.Capabilities {
+	return tools.Capabilities{Mutating: true, Cost: tools.CostFree, Latency: tools.LatencyFast}
+}
+
+func (t patchStateTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	var patch struct {
+		Operations []PatchOperation `json:"operations"`
+	}
+	data, err := json.Marshal(args)
+	if err == nil {
+		err = json.Unmarshal(data, &patch)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+
+	sessionID, _ := ctx.Value(stateSessionKey{}).(string)
+	mu, _ := t.r.stateLocks.LoadOrStore(sessionID, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	sessionID, state, err := t.r.sessionState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := ApplyPatch(state, patch.Operations)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.r.stateStore.SetState(ctx, sessionID, patched); err != nil {
+		return nil, err
+	}
+	return string(patched), nil
+}
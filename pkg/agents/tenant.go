@@ -0,0 +1,207 @@
+package agents
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/metrics"
+)
+
+// TenantLimits bounds the runs of one tenant. Zero fields are unlimited.
+type TenantLimits struct {
+	RunsPerMinute     int
+	TokensPerMinute   int
+	MaxConcurrentRuns int
+}
+
+// TenantLimiter enforces TenantLimits per tenant over a sliding one-minute
+// window, so one tenant's traffic can't exhaust the provider quota shared by
+// all of them. Tokens are counted as completions finish, so a run admitted
+// under the token limit may still take the tenant over it; the next run is
+// then rejected until the window has room again.
+type TenantLimiter struct {
+	mu       sync.Mutex
+	defaults TenantLimits
+	limits   map[string]TenantLimits
+	tenants  map[string]*tenantWindow
+	swept    time.Time
+	now      func() time.Time
+}
+
+// tenantWindow holds one tenant's activity in the current window
+type tenantWindow struct {
+	runs   []time.Time
+	tokens []tokenSpend
+	active int
+}
+
+// tokenSpend is one completion's tokens
+type tokenSpend struct {
+	at     time.Time
+	tokens int
+}
+
+// NewTenantLimiter creates a limiter applying defaults to every tenant
+// without limits of its own
+func NewTenantLimiter(defaults TenantLimits) *TenantLimiter {
+	return &TenantLimiter{
+		defaults: defaults,
+		limits:   make(map[string]TenantLimits),
+		tenants:  make(map[string]*tenantWindow),
+		now:      time.Now,
+	}
+}
+
+// SetLimits overrides the default limits for tenantID, e.g. for a plan with
+// a higher quota
+func (l *TenantLimiter) SetLimits(tenantID string, limits TenantLimits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limits[tenantID] = limits
+}
+
+// limitsFor returns the limits in force for tenantID
+func (l *TenantLimiter) limitsFor(tenantID string) TenantLimits {
+	if limits, ok := l.limits[tenantID]; ok {
+		return limits
+	}
+	return l.defaults
+}
+
+// window returns tenantID's activity with entries older than a minute dropped
+func (l *TenantLimiter) window(tenantID string, now time.Time) *tenantWindow {
+	w, ok := l.tenants[tenantID]
+	if !ok {
+		w = &tenantWindow{}
+		l.tenants[tenantID] = w
+	}
+
+	cutoff := now.Add(-time.Minute)
+	for len(w.runs) > 0 && !w.runs[0].After(cutoff) {
+		w.runs = w.runs[1:]
+	}
+	for len(w.tokens) > 0 && !w.tokens[0].at.After(cutoff) {
+		w.tokens = w.tokens[1:]
+	}
+	return w
+}
+
+// sweep drops, at most once a minute, the windows of tenants with no active
+// runs and nothing in the window, so tenants that stop sending runs don't
+// accumulate
+func (l *TenantLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+	l.swept = now
+
+	cutoff := now.Add(-time.Minute)
+	for tenantID, w := range l.tenants {
+		idle := w.active == 0 &&
+			(len(w.runs) == 0 || !w.runs[len(w.runs)-1].After(cutoff)) &&
+			(len(w.tokens) == 0 || !w.tokens[len(w.tokens)-1].at.After(cutoff))
+		if idle {
+			delete(l.tenants, tenantID)
+		}
+	}
+}
+
+// acquire admits a run for tenantID or reports the limit it exceeds. The
+// returned function ends the run.
+func (l *TenantLimiter) acquire(tenantID string) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	limits := l.limitsFor(tenantID)
+	w := l.window(tenantID, now)
+
+	if limits.MaxConcurrentRuns > 0 && w.active >= limits.MaxConcurrentRuns {
+		return nil, &TenantLimitError{Tenant: tenantID, Limit: "concurrent_runs"}
+	}
+	if limits.RunsPerMinute > 0 && len(w.runs) >= limits.RunsPerMinute {
+		return nil, &TenantLimitError{
+			Tenant:     tenantID,
+			Limit:      "runs_per_minute",
+			RetryAfter: w.runs[len(w.runs)-limits.RunsPerMinute].Add(time.Minute).Sub(now),
+		}
+	}
+	if limits.TokensPerMinute > 0 {
+		spent := 0
+		for _, spend := range w.tokens {
+			spent += spend.tokens
+		}
+		if spent >= limits.TokensPerMinute {
+			// Wait until enough spends leave the window to bring it under the limit
+			retryAt := now.Add(time.Minute)
+			for _, spend := range w.tokens {
+				spent -= spend.tokens
+				if spent < limits.TokensPerMinute {
+					retryAt = spend.at.Add(time.Minute)
+					break
+				}
+			}
+			return nil, &TenantLimitError{Tenant: tenantID, Limit: "tokens_per_minute", RetryAfter: retryAt.Sub(now)}
+		}
+	}
+
+	w.runs = append(w.runs, now)
+	w.active++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			w.active--
+		})
+	}, nil
+}
+
+// recordTokens counts a completion's tokens against tenantID
+func (l *TenantLimiter) recordTokens(tenantID string, tokens int) {
+	if tokens <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	w := l.window(tenantID, now)
+	w.tokens = append(w.tokens, tokenSpend{at: now, tokens: tokens})
+}
+
+// acquireTenant applies the runner's TenantLimiter to a run. Runs without a
+// tenant aren't limited.
+func (r *Runner) acquireTenant(tenantID string) (func(), error) {
+	if r.tenantLimiter == nil || tenantID == "" {
+		return func() {}, nil
+	}
+	return r.tenantLimiter.acquire(tenantID)
+}
+
+// recordUsage counts a completion against the run's tenant in the limiter,
+// the quota enforcer and the runner's metrics recorder
+func (r *Runner) recordUsage(ctx *RunContext, turn TurnMetrics) {
+	if r.tenantLimiter != nil && ctx.TenantID != "" {
+		r.tenantLimiter.recordTokens(ctx.TenantID, turn.TotalTokens)
+	}
+	if recorder, ok := r.metrics.(metrics.UsageRecorder); ok {
+		recorder.RecordUsage(metrics.Usage{
+			Tenant:           ctx.TenantID,
+			Agent:            turn.Agent,
+			Model:            turn.Model,
+			PromptTokens:     turn.PromptTokens,
+			CompletionTokens: turn.CompletionTokens,
+			TotalTokens:      turn.TotalTokens,
+			Cost:             turn.Cost,
+		})
+	}
+	if r.quotaEnforcer != nil {
+		r.quotaEnforcer.record(context.WithoutCancel(ctx), ctx.TenantID, ctx.usageKey, turn)
+	}
+}
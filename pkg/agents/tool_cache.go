@@ -0,0 +1,61 @@
+package agents
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// toolCallCache stores successful results of idempotent tool calls for the
+// duration of a single run
+type toolCallCache struct {
+	mu      sync.Mutex
+	results map[string]interface{}
+}
+
+func newToolCallCache() *toolCallCache {
+	return &toolCallCache{
+		results: make(map[string]interface{}),
+	}
+}
+
+// key builds a stable cache key from the tool name and its arguments.
+// json.Marshal sorts map keys, so equal argument maps produce equal keys.
+func (c *toolCallCache) key(call ToolCall) (string, bool) {
+	return callKey(call)
+}
+
+// callKey identifies a call by tool name and arguments
+func callKey(call ToolCall) (string, bool) {
+	args, err := json.Marshal(call.Arguments)
+	if err != nil {
+		return "", false
+	}
+	return call.Name + ":" + string(args), true
+}
+
+// get returns the cached result for an identical earlier call
+func (c *toolCallCache) get(call ToolCall) (interface{}, bool) {
+	key, ok := c.key(call)
+	if !ok {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result, ok := c.results[key]
+	return result, ok
+}
+
+// put records the result of a successful call
+func (c *toolCallCache) put(call ToolCall, result interface{}) {
+	key, ok := c.key(call)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.results[key] = result
+}
@@ -0,0 +1,325 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// ToolProtocol selects how an agent's function tools are offered to the
+// model. The emulated protocols describe the tools in the instructions and
+// parse calls from the reply text, for models without native function
+// calling. Tool calls and results are kept in the conversation as usual and
+// only rewritten into text for the provider, so sessions and traces don't
+// depend on the protocol.
+type ToolProtocol string
+
+const (
+	// ToolProtocolNative uses the provider's function calling (the default)
+	ToolProtocolNative ToolProtocol = ""
+
+	// ToolProtocolReAct asks for Thought, Action and Action Input lines and
+	// returns results as Observation messages, ending with a Final Answer
+	ToolProtocolReAct ToolProtocol = "react"
+
+	// ToolProtocolXML asks for <tool_call> elements holding JSON and returns
+	// results in <tool_result> elements
+	ToolProtocolXML ToolProtocol = "xml"
+
+	// ToolProtocolAuto uses native calling when the provider supports it and
+	// ToolProtocolReAct otherwise
+	ToolProtocolAuto ToolProtocol = "auto"
+)
+
+// toolProtocol resolves the protocol used for agent's next completion
+func (r *Runner) toolProtocol(agent *Agent) ToolProtocol {
+	protocol := agent.ToolProtocol
+	if protocol == ToolProtocolAuto {
+		protocol = ToolProtocolNative
+		if !providers.CapabilitiesOf(r.provider).Tools {
+			protocol = ToolProtocolReAct
+		}
+	}
+	return protocol
+}
+
+// emulateTools prepares a request for an emulated protocol: function tools
+// move into the instructions and tool calls and results in the history
+// become text. Hosted tools are still passed natively.
+func emulateTools(protocol ToolProtocol, agent *Agent, messages []Message, toolDefs []providers.ToolDefinition) (*Agent, []Message, []providers.ToolDefinition) {
+	var emulated, hosted []providers.ToolDefinition
+	for _, def := range toolDefs {
+		if def.Hosted != "" {
+			hosted = append(hosted, def)
+		} else {
+			emulated = append(emulated, def)
+		}
+	}
+	instructions := toolProtocolInstructions(protocol, emulated)
+	if existing, ok := agent.InstructionSection(SectionToolProtocol); ok {
+		instructions += "\n\n" + existing
+	}
+	prompted := agent.With(WithInstructionSections(Section(SectionToolProtocol, instructions)))
+
+	return prompted, emulateToolMessages(protocol, messages), hosted
+}
+
+// hasFunctionTools reports whether any of the tools runs locally
+func hasFunctionTools(defs []providers.ToolDefinition) bool {
+	for _, def := range defs {
+		if def.Hosted == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// toolProtocolInstructions describes the tools and the reply format
+func toolProtocolInstructions(protocol ToolProtocol, defs []providers.ToolDefinition) string {
+	var b strings.Builder
+	b.WriteString("You can use the following tools. Each takes a JSON object of arguments matching its schema.\n\n")
+	for _, def := range defs {
+		schema, _ := json.Marshal(def.Schema)
+		fmt.Fprintf(&b, "- %s: %s\n  Arguments: %s\n", def.Name, def.Description, schema)
+	}
+
+	if protocol == ToolProtocolXML {
+		b.WriteString(`
+To use a tool, reply with one or more elements like this and then stop:
+
+<tool_call>{"name": "<tool name>", "arguments": {<JSON arguments>}}</tool_call>
+
+Each result is returned to you as <tool_result name="<tool name>">...</tool_result>. When you can answer without a tool, reply with the answer only.`)
+		return b.String()
+	}
+
+	b.WriteString(`
+To use a tool, reply in exactly this format and then stop:
+
+Thought: <your reasoning>
+Action: <tool name>
+Action Input: <JSON arguments>
+
+The result is returned to you as "Observation: <result>". Use tools as many times as needed. When you can answer without a tool, reply:
+
+Thought: <your reasoning>
+Final Answer: <your answer>`)
+	return b.String()
+}
+
+// emulateToolMessages rewrites tool calls as the assistant text the
+// protocol expects and tool results as user messages. Results of calls made
+// in one reply share a message.
+func emulateToolMessages(protocol ToolProtocol, messages []Message) []Message {
+	result := make([]Message, 0, len(messages))
+	names := make(map[string]string)
+	grouped := false
+
+	for _, msg := range messages {
+		if hosted, _ := msg.Metadata["hosted"].(bool); hosted {
+			result = append(result, msg)
+			grouped = false
+			continue
+		}
+
+		switch {
+		case msg.Role == "assistant" && len(msg.ToolCalls) > 0:
+			for _, call := range msg.ToolCalls {
+				names[call.ID] = call.Name
+			}
+			msg.Content = renderToolCalls(protocol, msg.Content, msg.ToolCalls)
+			msg.ToolCalls = nil
+		case msg.Role == "tool":
+			id, _ := msg.Metadata["tool_call_id"].(string)
+			observation := renderToolResult(protocol, names[id], msg.Content)
+			if grouped {
+				last := &result[len(result)-1]
+				last.Content += "\n\n" + observation
+				last.Parts = append(last.Parts, msg.Parts...)
+				continue
+			}
+			msg = Message{
+				Role:      "user",
+				Content:   observation,
+				Parts:     msg.Parts,
+				Timestamp: msg.Timestamp,
+			}
+			result = append(result, msg)
+			grouped = true
+			continue
+		}
+
+		result = append(result, msg)
+		grouped = false
+	}
+	return result
+}
+
+// renderToolCalls writes calls the way the protocol asks the model to
+func renderToolCalls(protocol ToolProtocol, content string, calls []ToolCall) string {
+	var b strings.Builder
+	content = strings.TrimSpace(content)
+
+	if protocol == ToolProtocolXML {
+		if content != "" {
+			b.WriteString(content + "\n")
+		}
+		for _, call := range calls {
+			encoded, _ := json.Marshal(map[string]interface{}{"name": call.Name, "arguments": call.Arguments})
+			fmt.Fprintf(&b, "<tool_call>%s</tool_call>\n", encoded)
+		}
+		return strings.TrimSpace(b.String())
+	}
+
+	if content != "" {
+		if !strings.HasPrefix(content, "Thought:") {
+			b.WriteString("Thought: ")
+		}
+		b.WriteString(content + "\n")
+	}
+	for _, call := range calls {
+		arguments, _ := json.Marshal(call.Arguments)
+		fmt.Fprintf(&b, "Action: %s\nAction Input: %s\n", call.Name, arguments)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// renderToolResult writes a tool result the way the protocol returns it
+func renderToolResult(protocol ToolProtocol, name, content string) string {
+	if protocol == ToolProtocolXML {
+		if name == "" {
+			return fmt.Sprintf("<tool_result>\n%s\n</tool_result>", content)
+		}
+		return fmt.Sprintf("<tool_result name=%q>\n%s\n</tool_result>", name, content)
+	}
+	return "Observation: " + content
+}
+
+var (
+	reactAction      = regexp.MustCompile(`(?m)^[ \t]*Action:[ \t]*(.*)$`)
+	reactActionInput = regexp.MustCompile(`^\s*Action Input:`)
+	reactFinalAnswer = regexp.MustCompile(`(?m)^[ \t]*Final Answer:`)
+	reactObservation = regexp.MustCompile(`(?m)^[ \t]*Observation:`)
+	reactThought     = regexp.MustCompile(`^\s*Thought:`)
+	xmlToolCall      = regexp.MustCompile(`(?s)<tool_call>(.*?)(?:</tool_call>|$)`)
+	xmlToolResult    = regexp.MustCompile(`(?m)^[ \t]*<tool_result`)
+)
+
+// parseEmulatedCalls reads tool calls from a completion's text, replacing
+// its content with the text around them or, for ReAct, the final answer.
+// The calls are also recorded on the message so later requests can render
+// them back into the history.
+func parseEmulatedCalls(protocol ToolProtocol, completion *providers.Completion, defs []providers.ToolDefinition) {
+	var content string
+	var calls []providers.ToolCall
+	if protocol == ToolProtocolXML {
+		content, calls = parseXMLCalls(completion.Message.Content)
+	} else {
+		content, calls = parseReActCalls(completion.Message.Content, defs)
+	}
+
+	completion.Message.Content = content
+	completion.Message.ToolCalls = append(completion.Message.ToolCalls, calls...)
+	completion.ToolCalls = append(completion.ToolCalls, calls...)
+}
+
+// parseReActCalls reads Action and Action Input pairs. Anything after an
+// Observation line is dropped, since the model has started imagining the
+// result.
+func parseReActCalls(text string, defs []providers.ToolDefinition) (string, []providers.ToolCall) {
+	if loc := reactObservation.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]]
+	}
+
+	actions := reactAction.FindAllStringSubmatchIndex(text, -1)
+	final := reactFinalAnswer.FindStringIndex(text)
+	if final != nil && (len(actions) == 0 || final[0] < actions[0][0]) {
+		return strings.TrimSpace(text[final[1]:]), nil
+	}
+	if len(actions) == 0 {
+		return strings.TrimSpace(text), nil
+	}
+
+	thought := reactThought.ReplaceAllString(text[:actions[0][0]], "")
+	calls := make([]providers.ToolCall, 0, len(actions))
+	for i, action := range actions {
+		end := len(text)
+		if i+1 < len(actions) {
+			end = actions[i+1][0]
+		}
+		name := strings.Trim(text[action[2]:action[3]], " `'\"[]")
+		input := strings.TrimSpace(reactActionInput.ReplaceAllString(text[action[1]:end], ""))
+
+		calls = append(calls, providers.ToolCall{
+			ID:        "call_" + uuid.New().String(),
+			Name:      name,
+			Arguments: emulatedArguments(name, input, defs),
+		})
+	}
+	return strings.TrimSpace(thought), calls
+}
+
+// emulatedArguments decodes a ReAct action input. Input that isn't a JSON
+// object is passed as the tool's only parameter when it has one, so plain
+// "Action Input: weather in Paris" works for single-argument tools.
+func emulatedArguments(name, input string, defs []providers.ToolDefinition) map[string]interface{} {
+	arguments := make(map[string]interface{})
+	if input == "" {
+		return arguments
+	}
+	if err := DecodeJSON(input, &arguments); err == nil {
+		return arguments
+	}
+
+	arguments = map[string]interface{}{"input": input}
+	for _, def := range defs {
+		if def.Name == name && len(def.Schema.Properties) == 1 {
+			for property := range def.Schema.Properties {
+				arguments = map[string]interface{}{property: strings.Trim(input, `"`)}
+			}
+		}
+	}
+	return arguments
+}
+
+// parseXMLCalls reads <tool_call> elements; an unterminated element at the
+// end of a truncated reply still counts. Elements that don't decode are left
+// in the text.
+func parseXMLCalls(text string) (string, []providers.ToolCall) {
+	if loc := xmlToolResult.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]]
+	}
+
+	var calls []providers.ToolCall
+	content := xmlToolCall.ReplaceAllStringFunc(text, func(element string) string {
+		body := xmlToolCall.FindStringSubmatch(element)[1]
+
+		var call struct {
+			Name      string      `json:"name"`
+			Arguments interface{} `json:"arguments"`
+		}
+		if err := DecodeJSON(body, &call); err != nil || call.Name == "" {
+			return element
+		}
+
+		arguments, ok := call.Arguments.(map[string]interface{})
+		if encoded, isString := call.Arguments.(string); isString {
+			ok = DecodeJSON(encoded, &arguments) == nil
+		}
+		if !ok {
+			arguments = make(map[string]interface{})
+		}
+
+		calls = append(calls, providers.ToolCall{
+			ID:        "call_" + uuid.New().String(),
+			Name:      call.Name,
+			Arguments: arguments,
+		})
+		return ""
+	})
+	return strings.TrimSpace(content), calls
+}
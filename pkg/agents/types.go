@@ -3,18 +3,65 @@ package agents
 import (
 	"context"
 	"encoding/json"
+	"sync/atomic"
 	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/memory"
+	"github.com/ryanhill4L/agents-sdk/pkg/tools"
 )
 
+// ContentPart is a typed piece of message content such as an image, file
+// reference, or JSON payload
+type ContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+	MIMEType string `json:"mime_type,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Name     string `json:"name,omitempty"`
+
+	// Citations asks the provider to cite the file; see WithCitedFiles
+	Citations bool `json:"citations,omitempty"`
+}
+
 // Message represents a conversation message
 type Message struct {
 	Role      string                 `json:"role"`
 	Content   string                 `json:"content"`
+	Parts     []ContentPart          `json:"parts,omitempty"`
 	ToolCalls []ToolCall             `json:"tool_calls,omitempty"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 	Timestamp time.Time              `json:"timestamp"`
+
+	// persisted marks messages loaded from session history or already saved
+	// to it
+	persisted bool
+}
+
+// SystemMessage creates a system message for the conversation history, such
+// as a context update injected between turns
+func SystemMessage(content string) Message {
+	return Message{Role: "system", Content: content, Timestamp: time.Now()}
+}
+
+// DeveloperMessage creates a developer message, which OpenAI models treat
+// as instructions from the application; other providers treat it like a
+// system message
+func DeveloperMessage(content string) Message {
+	return Message{Role: "developer", Content: content, Timestamp: time.Now()}
 }
 
+// isInstructionRole reports whether role carries application instructions
+// rather than conversation turns
+func isInstructionRole(role string) bool {
+	return role == "system" || role == "developer"
+}
+
+// TurnContextFunc returns messages to add to the conversation before a
+// turn's completion, typically a SystemMessage with updated context. It
+// runs before every turn; returning no messages adds nothing.
+type TurnContextFunc func(ctx *RunContext) ([]Message, error)
+
 // ToolCall represents a tool invocation
 type ToolCall struct {
 	ID        string                 `json:"id"`
@@ -24,9 +71,27 @@ type ToolCall struct {
 
 // ToolResponse represents a tool execution result
 type ToolResponse struct {
-	ToolCallID string      `json:"tool_call_id"`
-	Content    interface{} `json:"content"`
-	Error      error       `json:"error,omitempty"`
+	ToolCallID string        `json:"tool_call_id"`
+	Content    interface{}   `json:"content"`
+	Error      error         `json:"error,omitempty"`
+	Cached     bool          `json:"cached,omitempty"`
+	Duration   time.Duration `json:"duration"`
+
+	// DryRun is set for calls to mutating tools that dry-run mode skipped
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// Prefetched is set when the call started while the completion was
+	// still streaming; see WithToolPrefetch
+	Prefetched bool `json:"prefetched,omitempty"`
+}
+
+// MarshalJSON encodes Error as its message
+func (r ToolResponse) MarshalJSON() ([]byte, error) {
+	type toolResponse ToolResponse
+	return json.Marshal(struct {
+		toolResponse
+		Error string `json:"error,omitempty"`
+	}{toolResponse: toolResponse(r), Error: errorMessage(r.Error)})
 }
 
 // HandoffRequest represents an agent handoff
@@ -34,20 +99,90 @@ type HandoffRequest struct {
 	TargetAgent string                 `json:"target_agent"`
 	Context     map[string]interface{} `json:"context,omitempty"`
 	Reason      string                 `json:"reason,omitempty"`
+
+	// Summary is the handing-off agent's account of the conversation so far
+	Summary string `json:"summary,omitempty"`
+
+	// From names the agent that handed off; the runner sets it
+	From string `json:"from,omitempty"`
+
+	// Returned is set when the agent handed control back to the one that
+	// delegated to it
+	Returned bool `json:"returned,omitempty"`
 }
 
 // RunContext holds runtime information
 type RunContext struct {
 	context.Context
 	SessionID   string
+	TenantID    string
 	TraceID     string
 	CurrentTurn int
 	MaxTurns    int
 	Variables   map[string]interface{}
+
+	// GrantedScopes restricts scoped tools for this run; nil leaves the
+	// agent's scopes as the only restriction
+	GrantedScopes []string
+
+	// Handoff is the handoff that gave the current agent control, or nil
+	// while the starting agent runs
+	Handoff *HandoffRequest
+
+	usageKey    string
+	mutated     *atomic.Bool
+	session     memory.Session
+	toolCache   *toolCallCache
+	prefetch    *toolPrefetch
+	scratchpad  *scratchpad
+	spans       *spanRecorder
+	turnContext TurnContextFunc
+
+	samples        int
+	sampleSelector SampleSelector
+	candidates     []Candidate
+}
+
+// TurnResult describes a completed turn of the run loop
+type TurnResult struct {
+	Turn          int            `json:"turn"`
+	Agent         *Agent         `json:"-"`
+	Message       Message        `json:"message"`
+	ToolCalls     []ToolCall     `json:"tool_calls,omitempty"`
+	ToolResponses []ToolResponse `json:"tool_responses,omitempty"`
+	Handoff       string         `json:"handoff,omitempty"`
 }
 
+// StopCondition ends a run early when it returns true for a turn
+type StopCondition func(ctx *RunContext, result *TurnResult) bool
+
 // OutputSchema defines structured output types
 type OutputSchema interface {
 	Validate(interface{}) error
 	Schema() json.RawMessage
 }
+
+// ToolErrorBehavior controls how the runner reacts to a failed tool call
+type ToolErrorBehavior int
+
+const (
+	// ToolErrorReturnToModel reports the failure to the model as the tool result
+	// so it can retry or recover
+	ToolErrorReturnToModel ToolErrorBehavior = iota
+
+	// ToolErrorFailRun aborts the run on the first tool failure
+	ToolErrorFailRun
+
+	// ToolErrorCustom delegates to the configured ToolErrorHandler
+	ToolErrorCustom
+)
+
+// ToolErrorHandler decides what the model sees when a tool fails. Returning a
+// non-nil error aborts the run.
+type ToolErrorHandler func(ctx *RunContext, call ToolCall, err error) (string, error)
+
+// ToolApprover decides whether a tool call may run, given the capabilities
+// the tool declares. Returning an error blocks the call and reports the
+// error to the model. Approvers that ask a person may record who signed off
+// with RecordApproval.
+type ToolApprover func(ctx context.Context, call ToolCall, caps tools.Capabilities) error
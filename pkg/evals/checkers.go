@@ -0,0 +1,140 @@
+package evals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/agents"
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// CheckResult is the verdict of one checker
+type CheckResult struct {
+	Name   string  `json:"name"`
+	Passed bool    `json:"passed"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason,omitempty"`
+}
+
+// Checker scores a completed run
+type Checker interface {
+	Check(ctx context.Context, c Case, result *agents.RunResult) (CheckResult, error)
+}
+
+// CheckerFunc adapts a function to Checker
+type CheckerFunc func(ctx context.Context, c Case, result *agents.RunResult) (CheckResult, error)
+
+// Check implements Checker
+func (f CheckerFunc) Check(ctx context.Context, c Case, result *agents.RunResult) (CheckResult, error) {
+	return f(ctx, c, result)
+}
+
+// verdict builds a pass/fail CheckResult
+func verdict(name string, passed bool, reason string) CheckResult {
+	score := 0.0
+	if passed {
+		score = 1
+	}
+	return CheckResult{Name: name, Passed: passed, Score: score, Reason: reason}
+}
+
+// outputText renders the final output as text
+func outputText(result *agents.RunResult) string {
+	if s, ok := result.FinalOutput.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(result.FinalOutput)
+	if err != nil {
+		return fmt.Sprintf("%v", result.FinalOutput)
+	}
+	return string(data)
+}
+
+// Contains passes when the output contains substr (case-insensitive)
+func Contains(substr string) Checker {
+	return CheckerFunc(func(ctx context.Context, c Case, result *agents.RunResult) (CheckResult, error) {
+		ok := strings.Contains(strings.ToLower(outputText(result)), strings.ToLower(substr))
+		return verdict("contains", ok, fmt.Sprintf("output does not contain %q", substr)), nil
+	})
+}
+
+// Equals passes when the trimmed output equals expected
+func Equals(expected string) Checker {
+	return CheckerFunc(func(ctx context.Context, c Case, result *agents.RunResult) (CheckResult, error) {
+		got := strings.TrimSpace(outputText(result))
+		return verdict("equals", got == expected, fmt.Sprintf("got %q, want %q", got, expected)), nil
+	})
+}
+
+// Matches passes when the output matches the regular expression
+func Matches(pattern string) Checker {
+	re, err := regexp.Compile(pattern)
+	return CheckerFunc(func(ctx context.Context, c Case, result *agents.RunResult) (CheckResult, error) {
+		if err != nil {
+			return CheckResult{Name: "matches"}, err
+		}
+		return verdict("matches", re.MatchString(outputText(result)), fmt.Sprintf("output does not match %s", pattern)), nil
+	})
+}
+
+// ValidJSON passes when the output is (or encodes to) valid JSON
+func ValidJSON() Checker {
+	return CheckerFunc(func(ctx context.Context, c Case, result *agents.RunResult) (CheckResult, error) {
+		return verdict("valid_json", json.Valid([]byte(outputText(result))), "output is not valid JSON"), nil
+	})
+}
+
+// ToolsCalled passes when every named tool was called at least once
+func ToolsCalled(names ...string) Checker {
+	return CheckerFunc(func(ctx context.Context, c Case, result *agents.RunResult) (CheckResult, error) {
+		var missing []string
+		for _, name := range names {
+			if result.Metrics.Tools[name].Calls == 0 {
+				missing = append(missing, name)
+			}
+		}
+
+		called := make([]string, 0, len(result.Metrics.Tools))
+		for name := range result.Metrics.Tools {
+			called = append(called, name)
+		}
+		sort.Strings(called)
+
+		reason := fmt.Sprintf("tools not called: %s (called: %s)", strings.Join(missing, ", "), strings.Join(called, ", "))
+		return verdict("tools_called", len(missing) == 0, reason), nil
+	})
+}
+
+// RoutedTo passes when the named agent produced the final output
+func RoutedTo(agent string) Checker {
+	return CheckerFunc(func(ctx context.Context, c Case, result *agents.RunResult) (CheckResult, error) {
+		got := ""
+		if result.Agent != nil {
+			got = result.Agent.Name
+		}
+		return verdict("routed_to", got == agent, fmt.Sprintf("final agent %q, want %q", got, agent)), nil
+	})
+}
+
+// LLMJudge asks a model to grade the output against rubric. The judge must
+// answer with a JSON object holding a 0-1 score and a rationale; the check
+// passes when the score reaches threshold. It is shorthand for a Judge's
+// Checker.
+func LLMJudge(provider providers.Provider, judge *agents.Agent, rubric string, threshold float64) Checker {
+	return NewJudge(judge, rubric, WithJudgeProvider(provider), WithJudgeThreshold(threshold)).Checker()
+}
+
+// extractJSONObject returns the outermost {...} in s, tolerating prose or
+// code fences around it
+func extractJSONObject(s string) string {
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start < 0 || end < start {
+		return s
+	}
+	return s[start : end+1]
+}
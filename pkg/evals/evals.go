@@ -0,0 +1,229 @@
+package evals
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/agents"
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// Case is a single evaluation scenario
+type Case struct {
+	Name  string `json:"name"`
+	Input string `json:"input"`
+
+	// ExpectedTools must all be called during the run
+	ExpectedTools []string `json:"expected_tools,omitempty"`
+
+	// ExpectedAgent is the agent expected to produce the final output,
+	// checking multi-agent routing
+	ExpectedAgent string `json:"expected_agent,omitempty"`
+
+	// Checkers score the run's output
+	Checkers []Checker `json:"-"`
+}
+
+// Suite is a named set of cases run against the same agent
+type Suite struct {
+	Name  string
+	Agent *agents.Agent
+	Cases []Case
+
+	// Concurrency bounds parallel runs per target (default 4)
+	Concurrency int
+}
+
+// Target is a provider configuration to evaluate the suite against
+type Target struct {
+	Name     string
+	Provider providers.Provider
+
+	// RunnerOptions are applied to the target's runner before its provider
+	RunnerOptions []agents.RunnerOption
+}
+
+// CaseResult is the outcome of one case on one target
+type CaseResult struct {
+	Case     string        `json:"case"`
+	Target   string        `json:"target"`
+	Passed   bool          `json:"passed"`
+	Score    float64       `json:"score"`
+	Checks   []CheckResult `json:"checks"`
+	Error    string        `json:"error,omitempty"`
+	Output   interface{}   `json:"output,omitempty"`
+	Agent    string        `json:"agent,omitempty"`
+	Tokens   int           `json:"tokens"`
+	Duration time.Duration `json:"duration"`
+}
+
+// TargetSummary aggregates a target's results
+type TargetSummary struct {
+	Target   string  `json:"target"`
+	Cases    int     `json:"cases"`
+	Passed   int     `json:"passed"`
+	Failed   int     `json:"failed"`
+	Score    float64 `json:"score"`
+	PassRate float64 `json:"pass_rate"`
+	Tokens   int     `json:"tokens"`
+}
+
+// Report is the scored outcome of running a suite
+type Report struct {
+	Suite     string          `json:"suite"`
+	Results   []CaseResult    `json:"results"`
+	Summaries []TargetSummary `json:"summaries"`
+	Duration  time.Duration   `json:"duration"`
+}
+
+// Run executes every case of suite on every target and scores the results.
+// Run failures are recorded as failed cases rather than returned.
+func Run(ctx context.Context, suite Suite, targets ...Target) (*Report, error) {
+	if suite.Agent == nil {
+		return nil, fmt.Errorf("suite %q has no agent", suite.Name)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("suite %q has no targets", suite.Name)
+	}
+
+	start := time.Now()
+	report := &Report{Suite: suite.Name}
+
+	inputs := make([]string, len(suite.Cases))
+	for i, c := range suite.Cases {
+		inputs[i] = c.Input
+	}
+
+	for _, target := range targets {
+		opts := append(append([]agents.RunnerOption(nil), target.RunnerOptions...), agents.WithProvider(target.Provider))
+		runner := agents.NewRunner(opts...)
+
+		batch, err := runner.RunBatch(ctx, suite.Agent, inputs, agents.BatchOptions{
+			Concurrency: suite.Concurrency,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", target.Name, err)
+		}
+
+		summary := TargetSummary{Target: target.Name, Cases: len(suite.Cases)}
+		for i, c := range suite.Cases {
+			result := scoreCase(ctx, c, target.Name, batch.Results[i], batch.Errors[i])
+
+			summary.Tokens += result.Tokens
+			summary.Score += result.Score
+			if result.Passed {
+				summary.Passed++
+			} else {
+				summary.Failed++
+			}
+
+			report.Results = append(report.Results, result)
+		}
+
+		if summary.Cases > 0 {
+			summary.Score /= float64(summary.Cases)
+			summary.PassRate = float64(summary.Passed) / float64(summary.Cases)
+		}
+		report.Summaries = append(report.Summaries, summary)
+	}
+
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// scoreCase applies the case's expectations and checkers to a run
+func scoreCase(ctx context.Context, c Case, target string, result *agents.RunResult, runErr error) CaseResult {
+	cr := CaseResult{Case: c.Name, Target: target}
+
+	if runErr != nil {
+		cr.Error = runErr.Error()
+		return cr
+	}
+
+	cr.Output = result.FinalOutput
+	cr.Tokens = result.Metrics.TotalTokens
+	cr.Duration = result.Metrics.Duration
+	if result.Agent != nil {
+		cr.Agent = result.Agent.Name
+	}
+
+	checkers := append([]Checker(nil), c.Checkers...)
+	if len(c.ExpectedTools) > 0 {
+		checkers = append([]Checker{ToolsCalled(c.ExpectedTools...)}, checkers...)
+	}
+	if c.ExpectedAgent != "" {
+		checkers = append([]Checker{RoutedTo(c.ExpectedAgent)}, checkers...)
+	}
+
+	cr.Passed = true
+	for _, checker := range checkers {
+		check, err := checker.Check(ctx, c, result)
+		if err != nil {
+			check = CheckResult{Name: check.Name, Reason: fmt.Sprintf("checker failed: %v", err)}
+		}
+
+		cr.Checks = append(cr.Checks, check)
+		cr.Score += check.Score
+		if !check.Passed {
+			cr.Passed = false
+		}
+	}
+
+	if len(cr.Checks) > 0 {
+		cr.Score /= float64(len(cr.Checks))
+	} else {
+		cr.Score = 1
+	}
+
+	return cr
+}
+
+// Passed reports whether every case passed on every target
+func (r *Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Failures returns the failed case results
+func (r *Report) Failures() []CaseResult {
+	var failures []CaseResult
+	for _, result := range r.Results {
+		if !result.Passed {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// WriteText writes a human-readable summary of the report
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Suite %s (%s)\n", r.Suite, r.Duration.Round(time.Millisecond))
+
+	summaries := append([]TargetSummary(nil), r.Summaries...)
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Score > summaries[j].Score
+	})
+	for _, s := range summaries {
+		fmt.Fprintf(w, "  %-20s %d/%d passed  score %.2f  tokens %d\n", s.Target, s.Passed, s.Cases, s.Score, s.Tokens)
+	}
+
+	for _, result := range r.Failures() {
+		fmt.Fprintf(w, "\nFAIL %s [%s]\n", result.Case, result.Target)
+		if result.Error != "" {
+			fmt.Fprintf(w, "  error: %s\n", result.Error)
+		}
+		for _, check := range result.Checks {
+			if !check.Passed {
+				fmt.Fprintf(w, "  %s: %s\n", check.Name, strings.TrimSpace(check.Reason))
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package evals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/agents"
+	"github.com/ryanhill4L/agents-sdk/pkg/guardrails"
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// ErrBelowThreshold is returned when output scores under a judge's threshold
+var ErrBelowThreshold = errors.New("output scored below the judge's threshold")
+
+// Judgment is a judge's grade of one output
+type Judgment struct {
+	Score     float64 `json:"score"`
+	Passed    bool    `json:"passed"`
+	Rationale string  `json:"rationale"`
+}
+
+// JudgeError reports output a judge rejected
+type JudgeError struct {
+	Judgment Judgment
+}
+
+func (e *JudgeError) Error() string {
+	return fmt.Sprintf("%v: score %.2f: %s", ErrBelowThreshold, e.Judgment.Score, e.Judgment.Rationale)
+}
+
+func (e *JudgeError) Unwrap() error {
+	return ErrBelowThreshold
+}
+
+// Judge grades outputs against a rubric with a model, for eval suites and
+// for quality gates on live runs
+type Judge struct {
+	agent     *agents.Agent
+	rubric    string
+	provider  providers.Provider
+	threshold float64
+}
+
+// JudgeOption configures a Judge
+type JudgeOption func(*Judge)
+
+// WithJudgeProvider sets the provider the judge's model is called on. It is
+// required.
+func WithJudgeProvider(provider providers.Provider) JudgeOption {
+	return func(j *Judge) {
+		j.provider = provider
+	}
+}
+
+// WithJudgeThreshold sets the score from 0 to 1 that output needs to pass
+// (default 0.7)
+func WithJudgeThreshold(threshold float64) JudgeOption {
+	return func(j *Judge) {
+		j.threshold = threshold
+	}
+}
+
+// NewJudge creates a judge that asks agent to grade outputs against rubric.
+// The agent's instructions may describe the grader's role; the rubric,
+// output and reply format are supplied in its input.
+func NewJudge(agent *agents.Agent, rubric string, opts ...JudgeOption) *Judge {
+	j := &Judge{agent: agent, rubric: rubric, threshold: 0.7}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// Score grades output, the response to input. Input may be empty when it
+// isn't known.
+func (j *Judge) Score(ctx context.Context, input, output string) (Judgment, error) {
+	if j.provider == nil {
+		return Judgment{}, errors.New("judge has no provider")
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Grade the response below against the rubric.\n\nRubric:\n%s\n\n", j.rubric)
+	if input != "" {
+		fmt.Fprintf(&prompt, "User input:\n%s\n\n", input)
+	}
+	fmt.Fprintf(&prompt, "Response:\n%s\n\n", output)
+	prompt.WriteString(`Reply with only a JSON object: {"score": <number from 0 to 1>, "rationale": "<one or two sentences>"}`)
+
+	completion, err := j.provider.Complete(ctx, j.agent, []providers.Message{{Role: "user", Content: prompt.String()}}, nil)
+	if err != nil {
+		return Judgment{}, fmt.Errorf("judge call failed: %w", err)
+	}
+
+	var grade struct {
+		Score     float64 `json:"score"`
+		Rationale string  `json:"rationale"`
+		Reason    string  `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(extractJSONObject(completion.Message.Content)), &grade); err != nil {
+		return Judgment{}, fmt.Errorf("invalid judge response: %w", err)
+	}
+	if grade.Rationale == "" {
+		grade.Rationale = grade.Reason
+	}
+
+	score := min(max(grade.Score, 0), 1)
+	return Judgment{Score: score, Passed: score >= j.threshold, Rationale: grade.Rationale}, nil
+}
+
+// Checker returns the judge as an eval checker named "llm_judge"
+func (j *Judge) Checker() Checker {
+	return CheckerFunc(func(ctx context.Context, c Case, result *agents.RunResult) (CheckResult, error) {
+		judgment, err := j.Score(ctx, c.Input, outputText(result))
+		if err != nil {
+			return CheckResult{Name: "llm_judge"}, err
+		}
+		return CheckResult{
+			Name:   "llm_judge",
+			Passed: judgment.Passed,
+			Score:  judgment.Score,
+			Reason: judgment.Rationale,
+		}, nil
+	})
+}
+
+// Guardrail returns the judge as an output guardrail, failing runs whose
+// responses score below the threshold with a *JudgeError. Every response
+// with text is graded, so it suits agents that answer in one turn.
+func (j *Judge) Guardrail() guardrails.Guardrail {
+	return &judgeGuardrail{judge: j}
+}
+
+// judgeGuardrail is the guardrail returned by Judge.Guardrail
+type judgeGuardrail struct {
+	judge *Judge
+}
+
+// Validate passes input and tool results, which the judge doesn't grade
+func (g *judgeGuardrail) Validate(content string) error {
+	return nil
+}
+
+// ValidateCheck grades model output
+func (g *judgeGuardrail) ValidateCheck(ctx context.Context, check guardrails.Check) error {
+	if check.Stage != guardrails.StageOutput {
+		return nil
+	}
+	judgment, err := g.judge.Score(ctx, "", check.Content)
+	if err != nil {
+		return err
+	}
+	if !judgment.Passed {
+		return &JudgeError{Judgment: judgment}
+	}
+	return nil
+}
+
+// Name returns the guardrail name
+func (g *judgeGuardrail) Name() string {
+	return "llm_judge"
+}
+
+// Description describes the guardrail
+func (g *judgeGuardrail) Description() string {
+	return "Rejects responses an LLM judge scores below its threshold"
+}
+
+// Run runs agent on input up to attempts times until the judge passes the
+// final output, and returns the best-scoring attempt with its judgment.
+// When no attempt passes, that attempt is returned with a *JudgeError.
+// Attempts are independent runs, so avoid options that persist them, such
+// as sessions.
+func (j *Judge) Run(ctx context.Context, runner *agents.Runner, agent *agents.Agent, input string, attempts int, opts ...agents.RunOption) (*agents.RunResult, Judgment, error) {
+	var best *agents.RunResult
+	var bestJudgment Judgment
+	for attempt := 0; attempt < max(attempts, 1); attempt++ {
+		result, err := runner.Run(ctx, agent, input, opts...)
+		if err != nil {
+			return nil, Judgment{}, err
+		}
+
+		judgment, err := j.Score(ctx, input, outputText(result))
+		if err != nil {
+			return nil, Judgment{}, err
+		}
+		if best == nil || judgment.Score > bestJudgment.Score {
+			best, bestJudgment = result, judgment
+		}
+		if judgment.Passed {
+			return result, judgment, nil
+		}
+	}
+	return best, bestJudgment, &JudgeError{Judgment: bestJudgment}
+}
@@ -0,0 +1,265 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/agents"
+)
+
+// UpdateEnv is the environment variable that, when set, makes Assert write
+// snapshots instead of comparing against them
+const UpdateEnv = "GOLDEN_UPDATE"
+
+// Snapshot is the stable form of a RunResult compared by Assert: the
+// conversation, the tools called and the final output, with timestamps,
+// UUIDs and tool call IDs normalized so reruns produce identical snapshots
+type Snapshot struct {
+	Agent       string      `json:"agent,omitempty"`
+	FinalOutput interface{} `json:"final_output"`
+
+	// Tools lists the names of the tools called, in order
+	Tools    []string  `json:"tools,omitempty"`
+	Messages []Message `json:"messages"`
+}
+
+// Message is a snapshotted conversation message
+type Message struct {
+	Role       string                 `json:"role"`
+	Content    string                 `json:"content,omitempty"`
+	Parts      []Part                 `json:"parts,omitempty"`
+	ToolCalls  []ToolCall             `json:"tool_calls,omitempty"`
+	ToolCallID string                 `json:"tool_call_id,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Part is a snapshotted content part. Binary data is recorded by size only.
+type Part struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	MIMEType string `json:"mime_type,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Bytes    int    `json:"bytes,omitempty"`
+}
+
+// ToolCall is a snapshotted tool call
+type ToolCall struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	uuidPattern      = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+)
+
+// options holds the settings of Take and Assert
+type options struct {
+	dir         string
+	normalizers []func(string) string
+	metadata    []string
+}
+
+// Option configures Take and Assert
+type Option func(*options)
+
+// WithDir sets the directory snapshots are stored in (default
+// testdata/golden)
+func WithDir(dir string) Option {
+	return func(o *options) {
+		o.dir = dir
+	}
+}
+
+// WithNormalizer adds a function applied to every string in the snapshot,
+// after the built-in timestamp and UUID normalization
+func WithNormalizer(normalize func(string) string) Option {
+	return func(o *options) {
+		o.normalizers = append(o.normalizers, normalize)
+	}
+}
+
+// WithReplace replaces matches of the regular expression pattern in every
+// string, e.g. WithReplace(`req_[a-z0-9]+`, "<request>")
+func WithReplace(pattern, replacement string) Option {
+	re := regexp.MustCompile(pattern)
+	return WithNormalizer(func(s string) string {
+		return re.ReplaceAllString(s, replacement)
+	})
+}
+
+// WithMetadata keeps the named message metadata keys, which are left out by
+// default
+func WithMetadata(keys ...string) Option {
+	return func(o *options) {
+		o.metadata = append(o.metadata, keys...)
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{dir: filepath.Join("testdata", "golden")}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// normalize applies the built-in and custom normalizers to s
+func (o *options) normalize(s string) string {
+	s = timestampPattern.ReplaceAllString(s, "<time>")
+	s = uuidPattern.ReplaceAllString(s, "<uuid>")
+	for _, normalize := range o.normalizers {
+		s = normalize(s)
+	}
+	return s
+}
+
+// normalizeValue normalizes the strings of decoded JSON
+func (o *options) normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return o.normalize(v)
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			normalized[key] = o.normalizeValue(item)
+		}
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for i, item := range v {
+			normalized[i] = o.normalizeValue(item)
+		}
+		return normalized
+	default:
+		return value
+	}
+}
+
+// jsonValue converts v to its decoded JSON form, so typed structured
+// outputs and arguments snapshot like the JSON they'd be sent as
+func jsonValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return string(data)
+	}
+	return decoded
+}
+
+// Take builds the snapshot of result. Tool call IDs become call_1, call_2,
+// ... in order of appearance, and tool results refer to them likewise.
+func Take(result *agents.RunResult, opts ...Option) Snapshot {
+	o := newOptions(opts)
+
+	snapshot := Snapshot{FinalOutput: o.normalizeValue(jsonValue(result.FinalOutput))}
+	if result.Agent != nil {
+		snapshot.Agent = result.Agent.Name
+	}
+
+	ids := make(map[string]string)
+	callID := func(id string) string {
+		if id == "" {
+			return ""
+		}
+		if _, ok := ids[id]; !ok {
+			ids[id] = fmt.Sprintf("call_%d", len(ids)+1)
+		}
+		return ids[id]
+	}
+
+	for _, msg := range result.Messages {
+		m := Message{Role: msg.Role, Content: o.normalize(msg.Content)}
+		for _, part := range msg.Parts {
+			m.Parts = append(m.Parts, Part{
+				Type:     part.Type,
+				Text:     o.normalize(part.Text),
+				MIMEType: part.MIMEType,
+				URI:      o.normalize(part.URI),
+				Name:     part.Name,
+				Bytes:    len(part.Data),
+			})
+		}
+		for _, call := range msg.ToolCalls {
+			args, _ := o.normalizeValue(jsonValue(call.Arguments)).(map[string]interface{})
+			m.ToolCalls = append(m.ToolCalls, ToolCall{ID: callID(call.ID), Name: call.Name, Arguments: args})
+			snapshot.Tools = append(snapshot.Tools, call.Name)
+		}
+		if id, ok := msg.Metadata["tool_call_id"].(string); ok {
+			m.ToolCallID = callID(id)
+		}
+		for _, key := range o.metadata {
+			if value, ok := msg.Metadata[key]; ok {
+				if m.Metadata == nil {
+					m.Metadata = make(map[string]interface{})
+				}
+				m.Metadata[key] = o.normalizeValue(jsonValue(value))
+			}
+		}
+		snapshot.Messages = append(snapshot.Messages, m)
+	}
+
+	return snapshot
+}
+
+// JSON encodes the snapshot as indented JSON with a trailing newline, the
+// form stored on disk
+func (s Snapshot) JSON() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s); err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Assert compares the snapshot of result with the stored snapshot name and
+// fails t with a diff when they differ. With GOLDEN_UPDATE set, it writes
+// the snapshot instead:
+//
+//	GOLDEN_UPDATE=1 go test ./...
+func Assert(t testing.TB, name string, result *agents.RunResult, opts ...Option) {
+	t.Helper()
+
+	o := newOptions(opts)
+	got, err := Take(result, opts...).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(o.dir, name+".json")
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create snapshot directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to write snapshot: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("snapshot %s does not exist; run with %s=1 to create it", path, UpdateEnv)
+	}
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+
+	if !bytes.Equal(want, got) {
+		t.Errorf("snapshot %s differs (-want +got):\n%s\nrun with %s=1 to accept the change", path, Diff(string(want), string(got)), UpdateEnv)
+	}
+}
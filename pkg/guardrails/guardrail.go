@@ -1,5 +1,7 @@
 package guardrails
 
+import "context"
+
 // Guardrail represents a safety or validation check
 type Guardrail interface {
 	// Validate checks if the content passes the guardrail
@@ -10,4 +12,59 @@ type Guardrail interface {
 
 	// Description returns a description of what this guardrail validates
 	Description() string
-}
\ No newline at end of file
+}
+
+// Stage identifies which part of a run a guardrail is checking
+type Stage string
+
+const (
+	// StageInput is a user message before it reaches the model
+	StageInput Stage = "input"
+
+	// StageToolResult is a tool result before it reaches the model
+	StageToolResult Stage = "tool_result"
+
+	// StageOutput is a model response before the run acts on it
+	StageOutput Stage = "output"
+)
+
+// Check describes the content under review and where it came from
+type Check struct {
+	Stage   Stage
+	Role    string
+	Content string
+
+	// Agent is the name of the agent handling the turn
+	Agent string
+	Turn  int
+
+	// ToolName is set for tool results
+	ToolName string
+
+	// Metadata is the message metadata, if any
+	Metadata map[string]interface{}
+}
+
+// ContextGuardrail is implemented by guardrails that apply different
+// policies depending on the stage and origin of the content. The runner
+// calls ValidateCheck instead of Validate for them; ctx is the run's
+// *agents.RunContext.
+type ContextGuardrail interface {
+	Guardrail
+
+	// ValidateCheck checks the content described by check
+	ValidateCheck(ctx context.Context, check Check) error
+}
+
+// Apply runs guardrail against check. Guardrails that only implement
+// Validate see input and tool results, as they always have, but not model
+// output.
+func Apply(ctx context.Context, guardrail Guardrail, check Check) error {
+	if g, ok := guardrail.(ContextGuardrail); ok {
+		return g.ValidateCheck(ctx, check)
+	}
+	if check.Stage == StageOutput {
+		return nil
+	}
+	return guardrail.Validate(check.Content)
+}
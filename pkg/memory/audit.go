@@ -0,0 +1,227 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Audit record kinds
+const (
+	AuditToolCall = "tool_call"
+	AuditHandoff  = "handoff"
+)
+
+// Audit record statuses
+const (
+	// AuditStarted is recorded before a tool runs, so a call interrupted by
+	// a crash still leaves a trace
+	AuditStarted = "started"
+	AuditOK      = "ok"
+	AuditError   = "error"
+	AuditDenied  = "denied"
+	AuditCached  = "cached"
+
+	// AuditDryRun marks calls to mutating tools skipped by a dry run
+	AuditDryRun = "dry_run"
+)
+
+// AuditRecord is one entry of the audit log: who caused which tool call or
+// handoff, in which session, and how it ended. Tool arguments are only
+// stored as a hash so the log doesn't duplicate sensitive data.
+type AuditRecord struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"kind"`
+	SessionID string    `json:"session_id"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	TraceID   string    `json:"trace_id"`
+
+	// Actor is the end user the run acts for, when known
+	Actor string `json:"actor,omitempty"`
+	Agent string `json:"agent"`
+
+	// Tool, ToolCallID and ArgumentsHash are set for tool calls
+	Tool          string `json:"tool,omitempty"`
+	ToolCallID    string `json:"tool_call_id,omitempty"`
+	ArgumentsHash string `json:"arguments_hash,omitempty"`
+
+	// Target is the agent handed off to
+	Target string `json:"target,omitempty"`
+
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+
+	// ApprovedBy lists who approved the call before it ran
+	ApprovedBy []string `json:"approved_by,omitempty"`
+}
+
+// AuditLog is an append-only sink for audit records
+type AuditLog interface {
+	Append(ctx context.Context, record AuditRecord) error
+}
+
+// AuditFilter selects audit records; zero fields match everything
+type AuditFilter struct {
+	TenantID  string
+	SessionID string
+	Tool      string
+	Actor     string
+	Since     time.Time
+}
+
+// matches reports whether record passes the filter
+func (f AuditFilter) matches(record AuditRecord) bool {
+	return (f.TenantID == "" || record.TenantID == f.TenantID) &&
+		(f.SessionID == "" || record.SessionID == f.SessionID) &&
+		(f.Tool == "" || record.Tool == f.Tool) &&
+		(f.Actor == "" || record.Actor == f.Actor) &&
+		(f.Since.IsZero() || !record.Time.Before(f.Since))
+}
+
+// InMemoryAuditLog keeps audit records in memory, for tests and development
+type InMemoryAuditLog struct {
+	mu      sync.RWMutex
+	records []AuditRecord
+}
+
+// NewInMemoryAuditLog creates an empty in-memory audit log
+func NewInMemoryAuditLog() *InMemoryAuditLog {
+	return &InMemoryAuditLog{}
+}
+
+// Append implements AuditLog
+func (l *InMemoryAuditLog) Append(ctx context.Context, record AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.records = append(l.records, record)
+	return nil
+}
+
+// Records returns the matching records in the order they were appended
+func (l *InMemoryAuditLog) Records(filter AuditFilter) []AuditRecord {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var records []AuditRecord
+	for _, record := range l.records {
+		if filter.matches(record) {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// FileAuditLog appends audit records to a file as JSON lines, syncing each
+// record to disk before returning
+type FileAuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileAuditLog opens path for appending, creating it if needed
+func NewFileAuditLog(path string) (*FileAuditLog, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &FileAuditLog{file: file}, nil
+}
+
+// Append implements AuditLog
+func (l *FileAuditLog) Append(ctx context.Context, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return l.file.Sync()
+}
+
+// Close closes the file
+func (l *FileAuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.Close()
+}
+
+// WebhookAuditLog posts each audit record as JSON to an HTTP endpoint, such
+// as a SIEM's event collector
+type WebhookAuditLog struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookAuditLog creates a log posting to url with the given headers,
+// e.g. an authorization token
+func NewWebhookAuditLog(url string, headers map[string]string) *WebhookAuditLog {
+	return &WebhookAuditLog{
+		url:     url,
+		headers: headers,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Append implements AuditLog. Responses other than 2xx are errors.
+func (l *WebhookAuditLog) Append(ctx context.Context, record AuditRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create audit request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range l.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send audit record: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// MultiAuditLog appends every record to all of logs, e.g. a local file and
+// a SIEM webhook. It attempts every log and joins their errors.
+func MultiAuditLog(logs ...AuditLog) AuditLog {
+	return multiAuditLog(logs)
+}
+
+type multiAuditLog []AuditLog
+
+// Append implements AuditLog
+func (m multiAuditLog) Append(ctx context.Context, record AuditRecord) error {
+	var errs []error
+	for _, log := range m {
+		if err := log.Append(ctx, record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entity is something a conversation is about, such as a person, account or
+// venue, with the facts learned about it. Entities belong to an owner, such
+// as the end user whose conversations mention them, and are identified by
+// type and name, ignoring case.
+type Entity struct {
+	Owner      string            `json:"owner"`
+	Type       string            `json:"type"`
+	Name       string            `json:"name"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// Mentions counts the extractions that found the entity
+	Mentions  int       `json:"mentions"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// EntityQuery selects an owner's entities, most recently seen first
+type EntityQuery struct {
+	Owner string
+
+	// Type restricts results to entities of this type (all if empty)
+	Type string
+
+	// Name restricts results to the entity with this name, ignoring case
+	Name string
+
+	// Text restricts results to entities whose name or attribute values
+	// contain it, ignoring case
+	Text string
+
+	// Limit caps the number of results (all if <= 0)
+	Limit int
+}
+
+// EntityStore keeps the entities extracted from conversations
+type EntityStore interface {
+	// UpsertEntities merges entities into those stored for owner. A known
+	// entity keeps its attributes and gains or updates the new ones; its
+	// mentions are counted and LastSeen set to at.
+	UpsertEntities(ctx context.Context, owner string, entities []Entity, at time.Time) error
+
+	// Entities returns the entities matching query
+	Entities(ctx context.Context, query EntityQuery) ([]Entity, error)
+
+	// DeleteEntity removes an owner's entity, for corrections and erasure
+	// requests
+	DeleteEntity(ctx context.Context, owner, entityType, name string) error
+}
+
+// entityKey identifies an owner's entity
+type entityKey struct {
+	owner string
+	kind  string
+	name  string
+}
+
+func keyOf(owner, entityType, name string) entityKey {
+	return entityKey{
+		owner: owner,
+		kind:  strings.ToLower(strings.TrimSpace(entityType)),
+		name:  strings.ToLower(strings.TrimSpace(name)),
+	}
+}
+
+// mergeEntity folds an extracted entity into the stored one, which is nil
+// for new entities
+func mergeEntity(stored *Entity, extracted Entity, at time.Time) Entity {
+	if stored == nil {
+		merged := extracted
+		merged.Type = strings.ToLower(strings.TrimSpace(extracted.Type))
+		merged.Name = strings.TrimSpace(extracted.Name)
+		merged.Attributes = copyAttributes(extracted.Attributes)
+		merged.Mentions = 1
+		merged.FirstSeen = at
+		merged.LastSeen = at
+		return merged
+	}
+
+	merged := *stored
+	merged.Attributes = copyAttributes(stored.Attributes)
+	for k, v := range extracted.Attributes {
+		merged.Attributes[k] = v
+	}
+	merged.Mentions++
+	merged.LastSeen = at
+	return merged
+}
+
+func copyAttributes(attributes map[string]string) map[string]string {
+	copied := make(map[string]string, len(attributes))
+	for k, v := range attributes {
+		copied[k] = v
+	}
+	return copied
+}
+
+// matches reports whether the entity is selected by query, ignoring its
+// owner and limit
+func (q EntityQuery) matches(entity Entity) bool {
+	if q.Type != "" && !strings.EqualFold(q.Type, entity.Type) {
+		return false
+	}
+	if q.Name != "" && !strings.EqualFold(strings.TrimSpace(q.Name), entity.Name) {
+		return false
+	}
+	if q.Text == "" {
+		return true
+	}
+
+	text := strings.ToLower(q.Text)
+	if strings.Contains(strings.ToLower(entity.Name), text) {
+		return true
+	}
+	for _, v := range entity.Attributes {
+		if strings.Contains(strings.ToLower(v), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// InMemoryEntityStore is an EntityStore for a single process
+type InMemoryEntityStore struct {
+	mu       sync.RWMutex
+	entities map[entityKey]Entity
+}
+
+// NewInMemoryEntityStore creates an empty in-memory entity store
+func NewInMemoryEntityStore() *InMemoryEntityStore {
+	return &InMemoryEntityStore{entities: make(map[entityKey]Entity)}
+}
+
+// UpsertEntities implements EntityStore
+func (s *InMemoryEntityStore) UpsertEntities(ctx context.Context, owner string, entities []Entity, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entity := range entities {
+		key := keyOf(owner, entity.Type, entity.Name)
+		if key.name == "" {
+			continue
+		}
+
+		var stored *Entity
+		if existing, ok := s.entities[key]; ok {
+			stored = &existing
+		}
+		merged := mergeEntity(stored, entity, at)
+		merged.Owner = owner
+		s.entities[key] = merged
+	}
+	return nil
+}
+
+// Entities implements EntityStore
+func (s *InMemoryEntityStore) Entities(ctx context.Context, query EntityQuery) ([]Entity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entities []Entity
+	for key, entity := range s.entities {
+		if key.owner == query.Owner && query.matches(entity) {
+			entity.Attributes = copyAttributes(entity.Attributes)
+			entities = append(entities, entity)
+		}
+	}
+
+	sort.Slice(entities, func(i, j int) bool {
+		if !entities[i].LastSeen.Equal(entities[j].LastSeen) {
+			return entities[i].LastSeen.After(entities[j].LastSeen)
+		}
+		return entities[i].Name < entities[j].Name
+	})
+	if query.Limit > 0 && len(entities) > query.Limit {
+		entities = entities[:query.Limit]
+	}
+	return entities, nil
+}
+
+// DeleteEntity implements EntityStore
+func (s *InMemoryEntityStore) DeleteEntity(ctx context.Context, owner, entityType, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entities, keyOf(owner, entityType, name))
+	return nil
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Export writes every message in session to w as JSONL, one message per line
+// in insertion order:
+//
+//	{"role":"user","content":"hi","timestamp":"2024-01-02T15:04:05Z"}
+//	{"role":"assistant","content":"","tool_calls":[{"id":"call_1","name":"lookup","arguments":{"q":"hi"}}],"timestamp":"..."}
+//	{"role":"tool","content":"result","metadata":{"tool_call_id":"call_1"},"timestamp":"..."}
+//
+// Fields are role, content, parts, tool_calls, metadata and timestamp
+// (RFC 3339); empty parts, tool_calls and metadata are omitted. Attachment
+// data in parts is base64 encoded. Storage IDs are not exported.
+func Export(ctx context.Context, session Session, w io.Writer) error {
+	messages, err := session.GetItems(ctx, 0)
+	if err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+
+	enc := json.NewEncoder(w)
+	for _, msg := range messages {
+		if err := enc.Encode(exportRecord(msg)); err != nil {
+			return fmt.Errorf("failed to write message: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Import reads JSONL in the Export format from r and appends the messages to
+// session in a single AddItems call. Messages without a timestamp are stamped
+// with the import time.
+func Import(ctx context.Context, r io.Reader, session Session) error {
+	dec := json.NewDecoder(r)
+
+	var messages []Message
+	for line := 1; ; line++ {
+		var record Message
+		if err := dec.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("invalid record %d: %w", line, err)
+		}
+
+		if record.Role == "" {
+			return fmt.Errorf("invalid record %d: missing role", line)
+		}
+		if record.Timestamp.IsZero() {
+			record.Timestamp = time.Now()
+		}
+
+		record.ID = 0
+		messages = append(messages, record)
+	}
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	if err := session.AddItems(ctx, messages); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+
+	return nil
+}
+
+// exportRecord strips backend-specific fields from msg
+func exportRecord(msg Message) Message {
+	msg.ID = 0
+	return msg
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrSchemaTooNew is returned when a database was migrated by a newer
+// version of this package than the running one
+var ErrSchemaTooNew = errors.New("database schema is newer than this version supports")
+
+// Migration is one versioned change to a database schema. Each migration
+// runs once, in its own transaction, in order of Version.
+type Migration struct {
+	Version int
+	Name    string
+
+	// Statements are executed in order
+	Statements []string
+
+	// Apply runs after Statements, for changes that depend on the current
+	// schema, such as adding a column only where it's missing
+	Apply func(ctx context.Context, tx *sql.Tx) error
+}
+
+// Dialect describes the SQL differences migrations care about, so the same
+// bookkeeping serves SQLite and Postgres backends
+type Dialect struct {
+	Name string
+
+	// Placeholder returns the nth (1-based) bind parameter
+	Placeholder func(n int) string
+}
+
+var (
+	// SQLiteDialect binds parameters with ?
+	SQLiteDialect = Dialect{Name: "sqlite", Placeholder: func(int) string { return "?" }}
+
+	// PostgresDialect binds parameters with $1, $2, ...
+	PostgresDialect = Dialect{Name: "postgres", Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) }}
+)
+
+// migrationsSchema records the applied migrations. It's plain enough SQL
+// for every dialect.
+const migrationsSchema = `
+    CREATE TABLE IF NOT EXISTS schema_migrations (
+        version INTEGER PRIMARY KEY,
+        name TEXT NOT NULL,
+        applied_at TIMESTAMP NOT NULL
+    )`
+
+// SchemaVersion returns the version of the newest migration applied to db,
+// or 0 if none have been
+func SchemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	if _, err := db.ExecContext(ctx, migrationsSchema); err != nil {
+		return 0, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return schemaVersion(ctx, db)
+}
+
+func schemaVersion(ctx context.Context, q querier) (int, error) {
+	var version sql.NullInt64
+	if err := q.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+// Migrate brings db up to date by applying the migrations newer than its
+// schema version. A migration that fails is rolled back and stops the
+// upgrade, leaving the earlier ones applied. A database already past the
+// newest migration is rejected with ErrSchemaTooNew.
+func Migrate(ctx context.Context, db *sql.DB, dialect Dialect, migrations []Migration) error {
+	current, err := SchemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	if latest := len(sorted); latest > 0 && current > sorted[latest-1].Version {
+		return fmt.Errorf("%w: database is at version %d, latest known is %d", ErrSchemaTooNew, current, sorted[latest-1].Version)
+	}
+
+	insert := fmt.Sprintf("INSERT INTO schema_migrations (version, name, applied_at) VALUES (%s, %s, %s)",
+		dialect.Placeholder(1), dialect.Placeholder(2), dialect.Placeholder(3))
+
+	for _, migration := range sorted {
+		if migration.Version <= current {
+			continue
+		}
+		if err := applyMigration(ctx, db, migration, insert); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+		current = migration.Version
+	}
+	return nil
+}
+
+// applyMigration runs one migration and records it in the same transaction.
+// A migration applied concurrently by another process is skipped.
+func applyMigration(ctx context.Context, db *sql.DB, migration Migration, insert string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if version, err := schemaVersion(ctx, tx); err != nil {
+		return err
+	} else if version >= migration.Version {
+		return nil
+	}
+
+	for _, statement := range migration.Statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	if migration.Apply != nil {
+		if err := migration.Apply(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, insert, migration.Version, migration.Name, time.Now().UTC()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy bounds how long and how much conversation history is kept
+type RetentionPolicy struct {
+	// MaxAge expires sessions whose newest message is older than this (0 disables)
+	MaxAge time.Duration
+
+	// MaxMessages keeps only the newest messages of each session (0 disables)
+	MaxMessages int
+
+	// Archiver, if set, receives history before it is deleted
+	Archiver Archiver
+}
+
+// Archiver stores history removed by pruning. Archive is called before the
+// deletion commits, so history may be archived again if the commit fails.
+type Archiver interface {
+	Archive(ctx context.Context, sessionID string, messages []Message) error
+}
+
+// PruneStats reports what a prune pass removed
+type PruneStats struct {
+	ExpiredSessions int `json:"expired_sessions"`
+	DeletedMessages int `json:"deleted_messages"`
+}
+
+// Pruner is implemented by session stores that can enforce a RetentionPolicy
+type Pruner interface {
+	Prune(ctx context.Context, policy RetentionPolicy) (PruneStats, error)
+}
+
+// DefaultPruneInterval is how often RunPruner prunes when given no interval
+const DefaultPruneInterval = time.Hour
+
+// RunPruner prunes store every interval, DefaultPruneInterval if it isn't
+// positive, until ctx is cancelled. Errors are passed to onError (if
+// non-nil) and don't stop the loop.
+func RunPruner(ctx context.Context, store Pruner, policy RetentionPolicy, interval time.Duration, onError func(error)) {
+	if interval <= 0 {
+		interval = DefaultPruneInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := store.Prune(ctx, policy); err != nil && onError != nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DirArchiver writes archived history as JSONL files (see Export) under a
+// directory, one file per session per prune. Files are named by the message
+// IDs they hold, so archiving the same messages again overwrites the file.
+type DirArchiver struct {
+	dir string
+}
+
+// NewDirArchiver creates dir if needed and returns an archiver writing to it
+func NewDirArchiver(dir string) (*DirArchiver, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &DirArchiver{dir: dir}, nil
+}
+
+// Archive writes messages to <dir>/<sessionID>-<first ID>-<last ID>.jsonl,
+// or <dir>/<sessionID>-<unix nanos>.jsonl when they have no IDs
+func (a *DirArchiver) Archive(ctx context.Context, sessionID string, messages []Message) error {
+	name := fmt.Sprintf("%s-%d.jsonl", sanitizeFilename(sessionID), time.Now().UnixNano())
+	if len(messages) > 0 && messages[0].ID != 0 {
+		name = fmt.Sprintf("%s-%d-%d.jsonl", sanitizeFilename(sessionID), messages[0].ID, messages[len(messages)-1].ID)
+	}
+
+	f, err := os.Create(filepath.Join(a.dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	if err := Export(ctx, staticSession(messages), f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// sanitizeFilename replaces path separators so session IDs can't escape the
+// archive directory
+func sanitizeFilename(name string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(name)
+}
+
+// staticSession is a read-only Session over a fixed set of messages
+type staticSession []Message
+
+func (s staticSession) GetItems(ctx context.Context, limit int) ([]Message, error) {
+	if limit > 0 && len(s) > limit {
+		return s[:limit], nil
+	}
+	return s, nil
+}
+
+func (s staticSession) AddItems(ctx context.Context, items []Message) error {
+	return fmt.Errorf("session is read-only")
+}
+
+func (s staticSession) PopItem(ctx context.Context) (*Message, error) {
+	return nil, fmt.Errorf("session is read-only")
+}
+
+func (s staticSession) Clear(ctx context.Context) error {
+	return fmt.Errorf("session is read-only")
+}
+
+func (s staticSession) Close() error {
+	return nil
+}
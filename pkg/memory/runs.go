@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRunInProgress is returned when another run holds the idempotency key
+var ErrRunInProgress = errors.New("a run with this idempotency key is in progress")
+
+// DefaultRunLease is how long a reservation stays valid when Reserve is given
+// no lease
+const DefaultRunLease = 10 * time.Minute
+
+// RunStore records run outcomes by idempotency key so a retried request gets
+// the stored result instead of executing tools with side effects again
+type RunStore interface {
+	// Reserve claims key for a new run and returns nil. If a run with the key
+	// completed, its stored result is returned instead; if one is still
+	// running, ErrRunInProgress. The reservation lasts for lease, after which
+	// a run that neither completed nor released it, e.g. because its process
+	// crashed, is presumed dead and the key can be claimed again.
+	Reserve(ctx context.Context, key string, lease time.Duration) ([]byte, error)
+
+	// Complete stores the encoded result of the run holding key
+	Complete(ctx context.Context, key string, result []byte) error
+
+	// Release gives up a reservation after a failed run so it can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// runEntry is a reservation or completed run
+type runEntry struct {
+	result     []byte
+	reservedAt time.Time
+}
+
+// InMemoryRunStore is a RunStore for a single process
+type InMemoryRunStore struct {
+	mu   sync.Mutex
+	runs map[string]*runEntry
+}
+
+// NewInMemoryRunStore creates an empty in-memory run store
+func NewInMemoryRunStore() *InMemoryRunStore {
+	return &InMemoryRunStore{runs: make(map[string]*runEntry)}
+}
+
+// Reserve implements RunStore
+func (s *InMemoryRunStore) Reserve(ctx context.Context, key string, lease time.Duration) ([]byte, error) {
+	if lease <= 0 {
+		lease = DefaultRunLease
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.runs[key]; ok {
+		if entry.result != nil {
+			return entry.result, nil
+		}
+		if time.Since(entry.reservedAt) < lease {
+			return nil, ErrRunInProgress
+		}
+	}
+
+	s.runs[key] = &runEntry{reservedAt: time.Now()}
+	return nil, nil
+}
+
+// Complete implements RunStore
+func (s *InMemoryRunStore) Complete(ctx context.Context, key string, result []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runs[key] = &runEntry{result: result, reservedAt: time.Now()}
+	return nil
+}
+
+// Release implements RunStore
+func (s *InMemoryRunStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.runs[key]; ok && entry.result == nil {
+		delete(s.runs, key)
+	}
+	return nil
+}
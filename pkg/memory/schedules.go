@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrScheduleNotFound is returned for a schedule without a stored record
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// ScheduleRecord is the persisted definition and last-run status of a
+// recurring agent run
+type ScheduleRecord struct {
+	Name      string    `json:"name"`
+	Cron      string    `json:"cron"`
+	Agent     string    `json:"agent"`
+	Paused    bool      `json:"paused,omitempty"`
+	NextRun   time.Time `json:"next_run"`
+	CreatedAt time.Time `json:"created_at"`
+
+	LastRun      time.Time     `json:"last_run,omitempty"`
+	LastStatus   string        `json:"last_status,omitempty"`
+	LastError    string        `json:"last_error,omitempty"`
+	LastDuration time.Duration `json:"last_duration,omitempty"`
+	Runs         int           `json:"runs"`
+	Failures     int           `json:"failures"`
+}
+
+// ScheduleStore persists schedules so their status survives restarts
+type ScheduleStore interface {
+	// SaveSchedule creates or replaces the record with the same name
+	SaveSchedule(ctx context.Context, record ScheduleRecord) error
+
+	// Schedule returns the named record, or ErrScheduleNotFound
+	Schedule(ctx context.Context, name string) (*ScheduleRecord, error)
+
+	// ListSchedules returns every record ordered by name
+	ListSchedules(ctx context.Context) ([]ScheduleRecord, error)
+
+	// DeleteSchedule removes the named record
+	DeleteSchedule(ctx context.Context, name string) error
+}
+
+// InMemoryScheduleStore is a ScheduleStore for a single process
+type InMemoryScheduleStore struct {
+	mu        sync.RWMutex
+	schedules map[string]ScheduleRecord
+}
+
+// NewInMemoryScheduleStore creates an empty in-memory schedule store
+func NewInMemoryScheduleStore() *InMemoryScheduleStore {
+	return &InMemoryScheduleStore{schedules: make(map[string]ScheduleRecord)}
+}
+
+// SaveSchedule implements ScheduleStore
+func (s *InMemoryScheduleStore) SaveSchedule(ctx context.Context, record ScheduleRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules[record.Name] = record
+	return nil
+}
+
+// Schedule implements ScheduleStore
+func (s *InMemoryScheduleStore) Schedule(ctx context.Context, name string) (*ScheduleRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.schedules[name]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	return &record, nil
+}
+
+// ListSchedules implements ScheduleStore
+func (s *InMemoryScheduleStore) ListSchedules(ctx context.Context) ([]ScheduleRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]ScheduleRecord, 0, len(s.schedules))
+	for _, record := range s.schedules {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	return records, nil
+}
+
+// DeleteSchedule implements ScheduleStore
+func (s *InMemoryScheduleStore) DeleteSchedule(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.schedules, name)
+	return nil
+}
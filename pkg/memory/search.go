@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrNoEmbedder is returned by Search when the store has no Embedder
+var ErrNoEmbedder = errors.New("no embedder configured")
+
+// Embedder turns text into vectors. It matches providers.Embedder, which is
+// redeclared here to avoid importing the providers package.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// SearchFilter narrows a semantic search
+type SearchFilter struct {
+	// TenantID restricts results to the tenant's sessions. SessionIDs are
+	// then the tenant's own IDs, as are the IDs in the results.
+	TenantID string
+
+	// SessionIDs restricts results to these sessions (all if empty),
+	// including the history a fork inherits; such hits are reported under
+	// the fork's ID
+	SessionIDs []string
+
+	// Roles restricts results to messages with these roles (all if empty)
+	Roles []string
+
+	// Since and Until bound message timestamps (ignored when zero)
+	Since time.Time
+	Until time.Time
+
+	// Limit caps the number of results (default 10)
+	Limit int
+
+	// MinScore drops results with a lower cosine similarity
+	MinScore float32
+
+	// Context includes this many neighbouring messages on each side of a hit
+	Context int
+}
+
+// SearchResult is a message matching a search, with its surrounding snippet
+type SearchResult struct {
+	SessionID string    `json:"session_id"`
+	Message   Message   `json:"message"`
+	Score     float32   `json:"score"`
+	Snippet   []Message `json:"snippet,omitempty"`
+}
+
+// Searcher is implemented by session stores that support semantic search
+// across sessions
+type Searcher interface {
+	Search(ctx context.Context, query string, filter SearchFilter) ([]SearchResult, error)
+}
+
+// Search runs a semantic search over every session in store
+func Search(ctx context.Context, store Searcher, query string, filter SearchFilter) ([]SearchResult, error) {
+	return store.Search(ctx, query, filter)
+}
+
+// matches reports whether a message passes the filter's role and time bounds
+func (f SearchFilter) matches(msg Message) bool {
+	if len(f.Roles) > 0 && !contains(f.Roles, msg.Role) {
+		return false
+	}
+	if !f.Since.IsZero() && msg.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && msg.Timestamp.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// cosine returns the cosine similarity of a and b (0 if either is empty or
+// their lengths differ)
+func cosine(a, b []float32) float32 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -11,13 +11,37 @@ type Message struct {
 	ID        int64                  `json:"id,omitempty"`
 	Role      string                 `json:"role"`
 	Content   string                 `json:"content"`
+	Parts     []ContentPart          `json:"parts,omitempty"`
+	ToolCalls []ToolCall             `json:"tool_calls,omitempty"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 	Timestamp time.Time              `json:"timestamp"`
+
+	// ToolCallID links a tool result to the call it answers
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// ContentPart is a typed piece of message content, such as an attached
+// image or document
+type ContentPart struct {
+	Type      string `json:"type"`
+	Text      string `json:"text,omitempty"`
+	Data      []byte `json:"data,omitempty"`
+	MIMEType  string `json:"mime_type,omitempty"`
+	URI       string `json:"uri,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Citations bool   `json:"citations,omitempty"`
+}
+
+// ToolCall records a tool invocation requested in a message
+type ToolCall struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
 }
 
 // Session manages conversation history and state
 type Session interface {
-	// GetItems retrieves messages from the session
+	// GetItems retrieves messages from the session; limit <= 0 returns all
 	GetItems(ctx context.Context, limit int) ([]Message, error)
 
 	// AddItems adds messages to the session
@@ -31,4 +55,11 @@ type Session interface {
 
 	// Close closes the session and cleans up resources
 	Close() error
-}
\ No newline at end of file
+}
+
+// Forker is implemented by sessions that can branch their history
+type Forker interface {
+	// Fork creates a new session holding the first upTo messages of this
+	// one (all of them if upTo <= 0) without modifying the original
+	Fork(ctx context.Context, newSessionID string, upTo int) (Session, error)
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Append implements AuditLog
+func (s *SQLiteStore) Append(ctx context.Context, record AuditRecord) error {
+	approvedBy, err := json.Marshal(record.ApprovedBy)
+	if err != nil {
+		return fmt.Errorf("failed to encode approvals: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+        INSERT INTO audit_log (id, time, kind, session_id, tenant_id, trace_id, actor, agent, tool, tool_call_id,
+            arguments_hash, target, status, error, duration_ns, approved_by)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `, record.ID, record.Time, record.Kind, record.SessionID, record.TenantID, record.TraceID, record.Actor, record.Agent,
+		record.Tool, record.ToolCallID, record.ArgumentsHash, record.Target, record.Status, record.Error,
+		int64(record.Duration), string(approvedBy))
+	if err != nil {
+		return fmt.Errorf("failed to append audit record: %w", err)
+	}
+	return nil
+}
+
+// AuditRecords returns the matching records, oldest first
+func (s *SQLiteStore) AuditRecords(ctx context.Context, filter AuditFilter) ([]AuditRecord, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.TenantID != "" {
+		conditions = append(conditions, "tenant_id = ?")
+		args = append(args, filter.TenantID)
+	}
+	if filter.SessionID != "" {
+		conditions = append(conditions, "session_id = ?")
+		args = append(args, filter.SessionID)
+	}
+	if filter.Tool != "" {
+		conditions = append(conditions, "tool = ?")
+		args = append(args, filter.Tool)
+	}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, filter.Actor)
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "time >= ?")
+		args = append(args, filter.Since)
+	}
+
+	query := `SELECT id, time, kind, session_id, tenant_id, trace_id, actor, agent, tool, tool_call_id,
+        arguments_hash, target, status, error, duration_ns, approved_by FROM audit_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY time, rowid"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var records []AuditRecord
+	for rows.Next() {
+		var record AuditRecord
+		var duration int64
+		var approvedBy string
+		if err := rows.Scan(&record.ID, &record.Time, &record.Kind, &record.SessionID, &record.TenantID, &record.TraceID,
+			&record.Actor, &record.Agent, &record.Tool, &record.ToolCallID, &record.ArgumentsHash,
+			&record.Target, &record.Status, &record.Error, &duration, &approvedBy); err != nil {
+			return nil, fmt.Errorf("failed to read audit record: %w", err)
+		}
+		record.Duration = time.Duration(duration)
+		if err := json.Unmarshal([]byte(approvedBy), &record.ApprovedBy); err != nil {
+			return nil, fmt.Errorf("failed to decode approvals: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
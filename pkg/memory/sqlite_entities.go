@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const entityColumns = `owner, entity_type, name, attributes, mentions, first_seen, last_seen`
+
+// UpsertEntities implements EntityStore
+func (s *SQLiteStore) UpsertEntities(ctx context.Context, owner string, entities []Entity, at time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, entity := range entities {
+		key := keyOf(owner, entity.Type, entity.Name)
+		if key.name == "" {
+			continue
+		}
+
+		stored, err := scanEntity(tx.QueryRowContext(ctx,
+			"SELECT "+entityColumns+" FROM entities WHERE owner = ? AND entity_type = ? AND name_key = ?",
+			key.owner, key.kind, key.name,
+		))
+		if errors.Is(err, sql.ErrNoRows) {
+			stored, err = nil, nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load entity: %w", err)
+		}
+
+		merged := mergeEntity(stored, entity, at)
+		attributes, err := json.Marshal(merged.Attributes)
+		if err != nil {
+			return fmt.Errorf("failed to encode entity attributes: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+            INSERT OR REPLACE INTO entities (owner, entity_type, name_key, name, attributes, mentions, first_seen, last_seen)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        `, key.owner, key.kind, key.name, merged.Name, string(attributes), merged.Mentions,
+			merged.FirstSeen, merged.LastSeen); err != nil {
+			return fmt.Errorf("failed to save entity: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Entities implements EntityStore
+func (s *SQLiteStore) Entities(ctx context.Context, query EntityQuery) ([]Entity, error) {
+	conditions := []string{"owner = ?"}
+	args := []interface{}{query.Owner}
+	if query.Type != "" {
+		conditions = append(conditions, "entity_type = ?")
+		args = append(args, strings.ToLower(strings.TrimSpace(query.Type)))
+	}
+	if query.Name != "" {
+		conditions = append(conditions, "name_key = ?")
+		args = append(args, strings.ToLower(strings.TrimSpace(query.Name)))
+	}
+
+	// Text is matched against decoded attributes below, so the limit can
+	// only go into the query without it
+	statement := "SELECT " + entityColumns + " FROM entities WHERE " +
+		strings.Join(conditions, " AND ") + " ORDER BY last_seen DESC, name"
+	if query.Limit > 0 && query.Text == "" {
+		statement += fmt.Sprintf(" LIMIT %d", query.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entities: %w", err)
+	}
+	defer rows.Close()
+
+	var entities []Entity
+	for rows.Next() {
+		entity, err := scanEntity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load entity: %w", err)
+		}
+		if !query.matches(*entity) {
+			continue
+		}
+		entities = append(entities, *entity)
+		if query.Limit > 0 && len(entities) == query.Limit {
+			break
+		}
+	}
+	return entities, rows.Err()
+}
+
+// DeleteEntity implements EntityStore
+func (s *SQLiteStore) DeleteEntity(ctx context.Context, owner, entityType, name string) error {
+	key := keyOf(owner, entityType, name)
+	if _, err := s.db.ExecContext(ctx,
+		"DELETE FROM entities WHERE owner = ? AND entity_type = ? AND name_key = ?",
+		key.owner, key.kind, key.name,
+	); err != nil {
+		return fmt.Errorf("failed to delete entity: %w", err)
+	}
+	return nil
+}
+
+func scanEntity(row interface{ Scan(...interface{}) error }) (*Entity, error) {
+	var (
+		entity     Entity
+		attributes string
+	)
+	if err := row.Scan(&entity.Owner, &entity.Type, &entity.Name, &attributes,
+		&entity.Mentions, &entity.FirstSeen, &entity.LastSeen); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(attributes), &entity.Attributes); err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
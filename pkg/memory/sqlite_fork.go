@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Fork creates newSessionID holding the first upTo messages of this session
+// (all of them if upTo <= 0). The original history is left untouched; later
+// changes to either session are not visible in the other.
+//
+// A fork stores only its own messages and reads the parent's up to its fork
+// point, so forking is cheap and never copies history.
+func (s *SQLiteSession) Fork(ctx context.Context, newSessionID string, upTo int) (Session, error) {
+	if newSessionID == "" || newSessionID == s.sessionID {
+		return nil, fmt.Errorf("invalid fork session ID: %q", newSessionID)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRowContext(ctx, `
+        SELECT EXISTS(SELECT 1 FROM messages WHERE session_id = ?)
+            OR EXISTS(SELECT 1 FROM session_forks WHERE session_id = ?)
+    `, newSessionID, newSessionID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if exists != 0 {
+		return nil, fmt.Errorf("session already exists: %s", newSessionID)
+	}
+
+	messages, err := history(ctx, tx, s.sessionID, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+	if upTo > 0 && upTo < len(messages) {
+		messages = messages[:upTo]
+	}
+
+	if len(messages) > 0 {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO session_forks (session_id, parent_id, fork_point) VALUES (?, ?, ?)",
+			newSessionID, s.sessionID, messages[len(messages)-1].ID,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteSession{
+		sessionID: newSessionID,
+		db:        s.db,
+		shared:    true,
+	}, nil
+}
+
+// history returns the messages visible to sessionID with IDs up to upToID:
+// the inherited prefix of its parent followed by its own messages. IDs grow
+// monotonically, so the result is in insertion order.
+func history(ctx context.Context, q querier, sessionID string, upToID int64) ([]Message, error) {
+	var messages []Message
+
+	var parentID string
+	var forkPoint int64
+	err := q.QueryRowContext(ctx,
+		"SELECT parent_id, fork_point FROM session_forks WHERE session_id = ?", sessionID,
+	).Scan(&parentID, &forkPoint)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return nil, err
+	default:
+		if forkPoint > upToID {
+			forkPoint = upToID
+		}
+		messages, err = history(ctx, q, parentID, forkPoint)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := q.QueryContext(ctx, `
+    SELECT `+messageColumns+` 
+    FROM messages 
+    WHERE session_id = ? AND id <= ? 
+    ORDER BY id ASC`, sessionID, upToID)
+	if err != nil {
+		return nil, err
+	}
+	own, err := scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(messages, own...), nil
+}
+
+// messageColumns are the messages columns read by scanMessages
+const messageColumns = "id, role, content, parts, tool_calls, tool_call_id, metadata, created_at"
+
+// scanMessages reads rows of messageColumns and closes them
+func scanMessages(rows *sql.Rows) ([]Message, error) {
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var msg Message
+		var partsJSON, toolCallsJSON, toolCallID, metadataJSON sql.NullString
+
+		err := rows.Scan(&msg.ID, &msg.Role, &msg.Content, &partsJSON, &toolCallsJSON, &toolCallID, &metadataJSON, &msg.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		if partsJSON.Valid {
+			json.Unmarshal([]byte(partsJSON.String), &msg.Parts)
+		}
+		if toolCallsJSON.Valid {
+			json.Unmarshal([]byte(toolCallsJSON.String), &msg.ToolCalls)
+		}
+		msg.ToolCallID = toolCallID.String
+		if metadataJSON.Valid {
+			json.Unmarshal([]byte(metadataJSON.String), &msg.Metadata)
+		}
+
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// trimForkPoint moves a fork's inherited prefix back so it ends at the last
+// of remaining, dropping the fork link when nothing is inherited any more
+func trimForkPoint(ctx context.Context, tx *sql.Tx, sessionID string, remaining []Message) error {
+	if len(remaining) == 0 {
+		_, err := tx.ExecContext(ctx, "DELETE FROM session_forks WHERE session_id = ?", sessionID)
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx,
+		"UPDATE session_forks SET fork_point = ? WHERE session_id = ?",
+		remaining[len(remaining)-1].ID, sessionID,
+	)
+	return err
+}
+
+// detachForks gives every fork of sessionID its own copy of the history it
+// inherits, so the parent can delete messages without affecting its forks
+func detachForks(ctx context.Context, tx *sql.Tx, sessionID string) error {
+	rows, err := tx.QueryContext(ctx, "SELECT session_id FROM session_forks WHERE parent_id = ?", sessionID)
+	if err != nil {
+		return err
+	}
+
+	var children []string
+	for rows.Next() {
+		var child string
+		if err := rows.Scan(&child); err != nil {
+			rows.Close()
+			return err
+		}
+		children = append(children, child)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		// Grandchildren read the child's rows, so they must be detached first
+		if err := detachForks(ctx, tx, child); err != nil {
+			return err
+		}
+
+		messages, err := history(ctx, tx, child, math.MaxInt64)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM messages WHERE session_id = ?", child); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM session_forks WHERE session_id = ?", child); err != nil {
+			return err
+		}
+
+		if err := insertMessages(ctx, tx, child, messages); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// sqliteMigrations are the schema versions of a SQLite database. Append new
+// migrations; never edit one that has shipped. Their SQL is written out in
+// full rather than shared with the code, so it stays as it shipped.
+var sqliteMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create tables",
+		Statements: []string{
+			// messages stores session messages in insertion order
+			`CREATE TABLE IF NOT EXISTS messages (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				session_id TEXT NOT NULL,
+				role TEXT NOT NULL,
+				content TEXT NOT NULL,
+				tool_calls TEXT,
+				tool_call_id TEXT,
+				metadata TEXT,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+			// session_forks records which session each fork branched from. A fork
+			// stores only its own messages and reads the parent's up to fork_point,
+			// so forking is cheap and never copies history.
+			`CREATE TABLE IF NOT EXISTS session_forks (
+				session_id TEXT PRIMARY KEY,
+				parent_id TEXT NOT NULL,
+				fork_point INTEGER NOT NULL
+			)`,
+			// message_embeddings caches one vector per message so each message is
+			// only embedded once
+			`CREATE TABLE IF NOT EXISTS message_embeddings (
+				message_id INTEGER PRIMARY KEY,
+				embedding BLOB NOT NULL
+			)`,
+			// runs stores run reservations and results by idempotency key. A NULL
+			// result marks a run in progress.
+			`CREATE TABLE IF NOT EXISTS runs (
+				idempotency_key TEXT PRIMARY KEY,
+				result BLOB,
+				reserved_at DATETIME NOT NULL,
+				completed_at DATETIME
+			);`,
+			// schedules stores recurring runs and the outcome of their last run
+			`CREATE TABLE IF NOT EXISTS schedules (
+				name TEXT PRIMARY KEY,
+				cron TEXT NOT NULL,
+				agent TEXT NOT NULL,
+				paused BOOLEAN NOT NULL DEFAULT 0,
+				next_run DATETIME,
+				created_at DATETIME NOT NULL,
+				last_run DATETIME,
+				last_status TEXT NOT NULL DEFAULT '',
+				last_error TEXT NOT NULL DEFAULT '',
+				last_duration INTEGER NOT NULL DEFAULT 0,
+				runs INTEGER NOT NULL DEFAULT 0,
+				failures INTEGER NOT NULL DEFAULT 0
+			);`,
+			// audit_log stores the audit log. Rows are only ever inserted.
+			`CREATE TABLE IF NOT EXISTS audit_log (
+				id TEXT PRIMARY KEY,
+				time DATETIME NOT NULL,
+				kind TEXT NOT NULL,
+				session_id TEXT NOT NULL,
+				tenant_id TEXT NOT NULL DEFAULT '',
+				trace_id TEXT NOT NULL,
+				actor TEXT NOT NULL DEFAULT '',
+				agent TEXT NOT NULL,
+				tool TEXT NOT NULL DEFAULT '',
+				tool_call_id TEXT NOT NULL DEFAULT '',
+				arguments_hash TEXT NOT NULL DEFAULT '',
+				target TEXT NOT NULL DEFAULT '',
+				status TEXT NOT NULL,
+				error TEXT NOT NULL DEFAULT '',
+				duration_ns INTEGER NOT NULL DEFAULT 0,
+				approved_by TEXT NOT NULL DEFAULT '[]'
+			);
+			CREATE INDEX IF NOT EXISTS idx_audit_log_session ON audit_log(session_id, time);
+			CREATE INDEX IF NOT EXISTS idx_audit_log_tenant ON audit_log(tenant_id, time);`,
+			// usage accumulates usage per tenant, key and UTC day
+			`CREATE TABLE IF NOT EXISTS usage (
+				tenant_id TEXT NOT NULL,
+				usage_key TEXT NOT NULL,
+				day TEXT NOT NULL,
+				runs INTEGER NOT NULL DEFAULT 0,
+				tokens INTEGER NOT NULL DEFAULT 0,
+				cost REAL NOT NULL DEFAULT 0,
+				PRIMARY KEY (tenant_id, usage_key, day)
+			);`,
+		},
+	},
+	{
+		// Databases created before tool calls were persisted lack these
+		Version: 2,
+		Name:    "add message tool call columns",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			return addMissingColumns(ctx, tx, "messages", map[string]string{
+				"tool_calls":   "TEXT",
+				"tool_call_id": "TEXT",
+			})
+		},
+	},
+	{
+		Version: 3,
+		Name:    "index messages",
+		Statements: []string{
+			`CREATE INDEX IF NOT EXISTS idx_messages_session ON messages(session_id, id)`,
+			`CREATE INDEX IF NOT EXISTS idx_messages_created ON messages(created_at)`,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "create session state table",
+		Statements: []string{
+			// session_state stores one state document per session
+			`CREATE TABLE IF NOT EXISTS session_state (
+				session_id TEXT PRIMARY KEY,
+				state TEXT NOT NULL,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "create entities table",
+		Statements: []string{
+			// entities stores extracted entities, keyed by owner, type and lower-
+			// cased name
+			`CREATE TABLE IF NOT EXISTS entities (
+				owner TEXT NOT NULL,
+				entity_type TEXT NOT NULL,
+				name_key TEXT NOT NULL,
+				name TEXT NOT NULL,
+				attributes TEXT NOT NULL DEFAULT '{}',
+				mentions INTEGER NOT NULL DEFAULT 0,
+				first_seen DATETIME NOT NULL,
+				last_seen DATETIME NOT NULL,
+				PRIMARY KEY (owner, entity_type, name_key)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_entities_seen ON entities(owner, last_seen)`,
+		},
+	},
+	{
+		// parts holds attached images and documents as JSON
+		Version: 6,
+		Name:    "add message parts column",
+		Apply: func(ctx context.Context, tx *sql.Tx) error {
+			return addMissingColumns(ctx, tx, "messages", map[string]string{
+				"parts": "TEXT",
+			})
+		},
+	},
+}
+
+// migrateSQLite brings a SQLite database up to the current schema
+func migrateSQLite(db *sql.DB) error {
+	if err := Migrate(context.Background(), db, SQLiteDialect, sqliteMigrations); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
+
+// addMissingColumns adds the columns of table that don't exist yet
+func addMissingColumns(ctx context.Context, tx *sql.Tx, table string, columns map[string]string) error {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid        int
+			name, kind string
+			notNull    int
+			dflt       sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &dflt, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for name, kind := range columns {
+		if existing[name] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, name, kind)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Reserve implements RunStore. Each step is a single statement, so
+// concurrent reservers can't both claim a key.
+func (s *SQLiteStore) Reserve(ctx context.Context, key string, lease time.Duration) ([]byte, error) {
+	if lease <= 0 {
+		lease = DefaultRunLease
+	}
+
+	// A key released between the steps below is claimed on the next attempt
+	for attempt := 0; attempt < 3; attempt++ {
+		now := time.Now()
+		claimed, err := s.claimRun(ctx, `
+            INSERT INTO runs (idempotency_key, reserved_at) VALUES (?, ?)
+            ON CONFLICT(idempotency_key) DO NOTHING
+        `, key, now)
+		if err != nil || claimed {
+			return nil, err
+		}
+
+		// Take over reservations whose lease expired
+		claimed, err = s.claimRun(ctx, `
+            UPDATE runs SET reserved_at = ?
+            WHERE idempotency_key = ? AND result IS NULL AND reserved_at < ?
+        `, now, key, now.Add(-lease))
+		if err != nil || claimed {
+			return nil, err
+		}
+
+		var result []byte
+		err = s.db.QueryRowContext(ctx,
+			"SELECT result FROM runs WHERE idempotency_key = ?", key,
+		).Scan(&result)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to look up run: %w", err)
+		case result != nil:
+			return result, nil
+		default:
+			return nil, ErrRunInProgress
+		}
+	}
+	return nil, ErrRunInProgress
+}
+
+// claimRun executes a reserving statement and reports whether it took the key
+func (s *SQLiteStore) claimRun(ctx context.Context, statement string, args ...interface{}) (bool, error) {
+	res, err := s.db.ExecContext(ctx, statement, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve run: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve run: %w", err)
+	}
+	return n == 1, nil
+}
+
+// Complete implements RunStore
+func (s *SQLiteStore) Complete(ctx context.Context, key string, result []byte) error {
+	now := time.Now()
+	_, err := s.db.ExecContext(ctx, `
+        INSERT INTO runs (idempotency_key, result, reserved_at, completed_at) VALUES (?, ?, ?, ?)
+        ON CONFLICT(idempotency_key) DO UPDATE SET result = excluded.result, completed_at = excluded.completed_at
+    `, key, result, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to store run result: %w", err)
+	}
+	return nil
+}
+
+// Release implements RunStore
+func (s *SQLiteStore) Release(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM runs WHERE idempotency_key = ? AND result IS NULL", key,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release run: %w", err)
+	}
+	return nil
+}
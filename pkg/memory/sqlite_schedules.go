@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const scheduleColumns = `name, cron, agent, paused, next_run, created_at, last_run,
+    last_status, last_error, last_duration, runs, failures`
+
+// SaveSchedule implements ScheduleStore
+func (s *SQLiteStore) SaveSchedule(ctx context.Context, record ScheduleRecord) error {
+	_, err := s.db.ExecContext(ctx, `
+        INSERT OR REPLACE INTO schedules (`+scheduleColumns+`)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `, record.Name, record.Cron, record.Agent, record.Paused, nullTime(record.NextRun),
+		record.CreatedAt, nullTime(record.LastRun), record.LastStatus, record.LastError,
+		int64(record.LastDuration), record.Runs, record.Failures)
+	if err != nil {
+		return fmt.Errorf("failed to save schedule: %w", err)
+	}
+	return nil
+}
+
+// Schedule implements ScheduleStore
+func (s *SQLiteStore) Schedule(ctx context.Context, name string) (*ScheduleRecord, error) {
+	row := s.db.QueryRowContext(ctx,
+		"SELECT "+scheduleColumns+" FROM schedules WHERE name = ?", name,
+	)
+
+	record, err := scanSchedule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schedule: %w", err)
+	}
+	return record, nil
+}
+
+// ListSchedules implements ScheduleStore
+func (s *SQLiteStore) ListSchedules(ctx context.Context) ([]ScheduleRecord, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+scheduleColumns+" FROM schedules ORDER BY name",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var records []ScheduleRecord
+	for rows.Next() {
+		record, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load schedule: %w", err)
+		}
+		records = append(records, *record)
+	}
+	return records, rows.Err()
+}
+
+// DeleteSchedule implements ScheduleStore
+func (s *SQLiteStore) DeleteSchedule(ctx context.Context, name string) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM schedules WHERE name = ?", name); err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	return nil
+}
+
+// scanSchedule reads a schedules row selected with scheduleColumns
+func scanSchedule(row interface{ Scan(...interface{}) error }) (*ScheduleRecord, error) {
+	var record ScheduleRecord
+	var nextRun, lastRun sql.NullTime
+	var duration int64
+
+	err := row.Scan(&record.Name, &record.Cron, &record.Agent, &record.Paused, &nextRun,
+		&record.CreatedAt, &lastRun, &record.LastStatus, &record.LastError, &duration,
+		&record.Runs, &record.Failures)
+	if err != nil {
+		return nil, err
+	}
+
+	record.NextRun = nextRun.Time
+	record.LastRun = lastRun.Time
+	record.LastDuration = time.Duration(duration)
+	return &record, nil
+}
+
+// nullTime stores the zero time as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// embedBatchSize bounds how many messages are sent to the embedder at once
+const embedBatchSize = 100
+
+// Search embeds any messages not yet indexed, then ranks stored messages by
+// cosine similarity to query. Scoring is brute force, which suits stores of
+// up to a few hundred thousand messages.
+func (s *SQLiteStore) Search(ctx context.Context, query string, filter SearchFilter) ([]SearchResult, error) {
+	if s.embedder == nil {
+		return nil, ErrNoEmbedder
+	}
+
+	if err := s.indexPending(ctx); err != nil {
+		return nil, err
+	}
+
+	vectors, err := s.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for 1 query", len(vectors))
+	}
+	queryVector := vectors[0]
+
+	// Session IDs are compared as stored, so an untenanted filter can't
+	// name a tenant's sessions
+	sessionKeys := make([]string, len(filter.SessionIDs))
+	for i, id := range filter.SessionIDs {
+		sessionKeys[i] = TenantKey(filter.TenantID, id)
+	}
+	visible, err := s.visibleMessages(ctx, sessionKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+    SELECT m.id, m.session_id, m.role, m.content, m.metadata, m.created_at, e.embedding 
+    FROM messages m 
+    JOIN message_embeddings e ON e.message_id = m.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var result SearchResult
+		var metadataJSON sql.NullString
+		var blob []byte
+
+		msg := &result.Message
+		err := rows.Scan(&msg.ID, &result.SessionID, &msg.Role, &msg.Content, &metadataJSON, &msg.Timestamp, &blob)
+		if err != nil {
+			return nil, err
+		}
+
+		if filter.TenantID != "" {
+			if tenantID, _ := SplitTenantKey(result.SessionID); tenantID != filter.TenantID {
+				continue
+			}
+		}
+		if len(sessionKeys) > 0 {
+			session, ok := visibleIn(visible[result.SessionID], msg.ID)
+			if !ok {
+				continue
+			}
+			result.SessionID = session
+		}
+		if !filter.matches(*msg) {
+			continue
+		}
+
+		result.Score = cosine(queryVector, decodeVector(blob))
+		if result.Score < filter.MinScore {
+			continue
+		}
+
+		if metadataJSON.Valid {
+			json.Unmarshal([]byte(metadataJSON.String), &msg.Metadata)
+		}
+
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	if filter.Context > 0 {
+		for i := range results {
+			snippet, err := s.snippet(ctx, results[i], filter.Context)
+			if err != nil {
+				return nil, err
+			}
+			results[i].Snippet = snippet
+		}
+	}
+
+	if filter.TenantID != "" {
+		for i := range results {
+			_, results[i].SessionID = SplitTenantKey(results[i].SessionID)
+		}
+	}
+
+	return results, nil
+}
+
+// forkRange makes the messages of a stored session with IDs up to upTo
+// visible to session, which is the stored session itself or a fork of it
+type forkRange struct {
+	session string
+	upTo    int64
+}
+
+// visibleMessages maps each stored session holding messages visible to one
+// of sessionIDs to the ranges that make them visible, resolving forks to the
+// history they inherit. A session's own messages come first, so a message is
+// attributed to the session that stored it when both are searched.
+func (s *SQLiteStore) visibleMessages(ctx context.Context, sessionIDs []string) (map[string][]forkRange, error) {
+	visible := make(map[string][]forkRange)
+	for _, id := range sessionIDs {
+		visible[id] = append(visible[id], forkRange{session: id, upTo: math.MaxInt64})
+	}
+
+	for _, id := range sessionIDs {
+		current, upTo := id, int64(math.MaxInt64)
+		for {
+			var parentID string
+			var forkPoint int64
+			err := s.db.QueryRowContext(ctx,
+				"SELECT parent_id, fork_point FROM session_forks WHERE session_id = ?", current,
+			).Scan(&parentID, &forkPoint)
+			if err == sql.ErrNoRows {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if forkPoint < upTo {
+				upTo = forkPoint
+			}
+			visible[parentID] = append(visible[parentID], forkRange{session: id, upTo: upTo})
+			current = parentID
+		}
+	}
+	return visible, nil
+}
+
+// visibleIn returns the session a message with the given ID is visible to,
+// if any of ranges covers it
+func visibleIn(ranges []forkRange, id int64) (string, bool) {
+	for _, r := range ranges {
+		if id <= r.upTo {
+			return r.session, true
+		}
+	}
+	return "", false
+}
+
+// indexPending embeds and stores vectors for messages that have none
+func (s *SQLiteStore) indexPending(ctx context.Context) error {
+	for {
+		rows, err := s.db.QueryContext(ctx, `
+        SELECT m.id, m.content 
+        FROM messages m 
+        LEFT JOIN message_embeddings e ON e.message_id = m.id 
+        WHERE e.message_id IS NULL AND m.content != '' 
+        ORDER BY m.id 
+        LIMIT ?`, embedBatchSize)
+		if err != nil {
+			return err
+		}
+
+		var ids []int64
+		var texts []string
+		for rows.Next() {
+			var id int64
+			var text string
+			if err := rows.Scan(&id, &text); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+			texts = append(texts, text)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		vectors, err := s.embedder.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to embed messages: %w", err)
+		}
+		if len(vectors) != len(ids) {
+			return fmt.Errorf("embedder returned %d vectors for %d messages", len(vectors), len(ids))
+		}
+
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for i, id := range ids {
+			_, err := tx.ExecContext(ctx,
+				"INSERT OR REPLACE INTO message_embeddings (message_id, embedding) VALUES (?, ?)",
+				id, encodeVector(vectors[i]),
+			)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+
+		if len(ids) < embedBatchSize {
+			return nil
+		}
+	}
+}
+
+// snippet returns the hit with up to n messages either side from its session
+func (s *SQLiteStore) snippet(ctx context.Context, result SearchResult, n int) ([]Message, error) {
+	messages, err := history(ctx, s.db, result.SessionID, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, msg := range messages {
+		if msg.ID != result.Message.ID {
+			continue
+		}
+
+		start, end := i-n, i+n+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(messages) {
+			end = len(messages)
+		}
+		return messages[start:end], nil
+	}
+
+	return nil, nil
+}
+
+// encodeVector stores v as little-endian float32s
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+// decodeVector reverses encodeVector
+func decodeVector(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"math"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -13,6 +14,9 @@ import (
 type SQLiteSession struct {
 	sessionID string
 	db        *sql.DB
+
+	// shared is set when the database belongs to a SQLiteStore
+	shared bool
 }
 
 // NewSQLiteSession creates a new SQLite-backed session
@@ -31,7 +35,7 @@ func NewSQLiteSession(sessionID, dbPath string) (*SQLiteSession, error) {
 		db:        db,
 	}
 
-	if err := session.initialize(); err != nil {
+	if err := migrateSQLite(db); err != nil {
 		db.Close()
 		return nil, err
 	}
@@ -39,57 +43,19 @@ func NewSQLiteSession(sessionID, dbPath string) (*SQLiteSession, error) {
 	return session, nil
 }
 
-// initialize creates the necessary tables
-func (s *SQLiteSession) initialize() error {
-	query := `
-    CREATE TABLE IF NOT EXISTS messages (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        session_id TEXT NOT NULL,
-        role TEXT NOT NULL,
-        content TEXT NOT NULL,
-        metadata TEXT,
-        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-        INDEX idx_session_id (session_id),
-        INDEX idx_created_at (created_at)
-    )`
-
-	_, err := s.db.Exec(query)
-	return err
-}
-
-// GetItems retrieves messages from the session
+// GetItems retrieves messages from the session, including any history
+// inherited from the session it was forked from
 func (s *SQLiteSession) GetItems(ctx context.Context, limit int) ([]Message, error) {
-	query := `
-    SELECT role, content, metadata, created_at 
-    FROM messages 
-    WHERE session_id = ? 
-    ORDER BY created_at ASC 
-    LIMIT ?`
-
-	rows, err := s.db.QueryContext(ctx, query, s.sessionID, limit)
+	messages, err := history(ctx, s.db, s.sessionID, math.MaxInt64)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var messages []Message
-	for rows.Next() {
-		var msg Message
-		var metadataJSON sql.NullString
 
-		err := rows.Scan(&msg.Role, &msg.Content, &metadataJSON, &msg.Timestamp)
-		if err != nil {
-			return nil, err
-		}
-
-		if metadataJSON.Valid {
-			json.Unmarshal([]byte(metadataJSON.String), &msg.Metadata)
-		}
-
-		messages = append(messages, msg)
+	if limit > 0 && len(messages) > limit {
+		messages = messages[:limit]
 	}
 
-	return messages, rows.Err()
+	return messages, nil
 }
 
 // AddItems adds messages to the session
@@ -100,9 +66,18 @@ func (s *SQLiteSession) AddItems(ctx context.Context, items []Message) error {
 	}
 	defer tx.Rollback()
 
+	if err := insertMessages(ctx, tx, s.sessionID, items); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertMessages writes items to sessionID within tx
+func insertMessages(ctx context.Context, tx *sql.Tx, sessionID string, items []Message) error {
 	stmt, err := tx.PrepareContext(ctx, `
-        INSERT INTO messages (session_id, role, content, metadata, created_at)
-        VALUES (?, ?, ?, ?, ?)
+        INSERT INTO messages (session_id, role, content, parts, tool_calls, tool_call_id, metadata, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
     `)
 	if err != nil {
 		return err
@@ -110,15 +85,28 @@ func (s *SQLiteSession) AddItems(ctx context.Context, items []Message) error {
 	defer stmt.Close()
 
 	for _, msg := range items {
-		var metadataJSON []byte
+		var metadataJSON, partsJSON, toolCallsJSON []byte
 		if msg.Metadata != nil {
 			metadataJSON, _ = json.Marshal(msg.Metadata)
 		}
+		if len(msg.Parts) > 0 {
+			partsJSON, _ = json.Marshal(msg.Parts)
+		}
+		if len(msg.ToolCalls) > 0 {
+			toolCallsJSON, _ = json.Marshal(msg.ToolCalls)
+		}
+		var toolCallID sql.NullString
+		if msg.ToolCallID != "" {
+			toolCallID = sql.NullString{String: msg.ToolCallID, Valid: true}
+		}
 
 		_, err := stmt.ExecContext(ctx,
-			s.sessionID,
+			sessionID,
 			msg.Role,
 			msg.Content,
+			partsJSON,
+			toolCallsJSON,
+			toolCallID,
 			metadataJSON,
 			msg.Timestamp,
 		)
@@ -127,10 +115,11 @@ func (s *SQLiteSession) AddItems(ctx context.Context, items []Message) error {
 		}
 	}
 
-	return tx.Commit()
+	return nil
 }
 
-// PopItem removes and returns the most recent message
+// PopItem removes and returns the most recent message. Popping an inherited
+// message moves the fork point back instead of touching the parent session.
 func (s *SQLiteSession) PopItem(ctx context.Context) (*Message, error) {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -138,44 +127,62 @@ func (s *SQLiteSession) PopItem(ctx context.Context) (*Message, error) {
 	}
 	defer tx.Rollback()
 
-	var msg Message
-	var metadataJSON sql.NullString
-
-	err = tx.QueryRowContext(ctx, `
-        SELECT id, role, content, metadata, created_at 
-        FROM messages 
-        WHERE session_id = ? 
-        ORDER BY created_at DESC 
-        LIMIT 1
-    `, s.sessionID).Scan(&msg.ID, &msg.Role, &msg.Content, &metadataJSON, &msg.Timestamp)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
+	if err := detachForks(ctx, tx, s.sessionID); err != nil {
+		return nil, err
 	}
+
+	messages, err := history(ctx, tx, s.sessionID, math.MaxInt64)
 	if err != nil {
 		return nil, err
 	}
-
-	if metadataJSON.Valid {
-		json.Unmarshal([]byte(metadataJSON.String), &msg.Metadata)
+	if len(messages) == 0 {
+		return nil, nil
 	}
 
+	msg := messages[len(messages)-1]
+
 	// Delete the message
-	_, err = tx.ExecContext(ctx, "DELETE FROM messages WHERE id = ?", msg.ID)
+	res, err := tx.ExecContext(ctx, "DELETE FROM messages WHERE id = ? AND session_id = ?", msg.ID, s.sessionID)
 	if err != nil {
 		return nil, err
 	}
 
+	if n, _ := res.RowsAffected(); n == 0 {
+		if err := trimForkPoint(ctx, tx, s.sessionID, messages[:len(messages)-1]); err != nil {
+			return nil, err
+		}
+	}
+
 	return &msg, tx.Commit()
 }
 
 // Clear removes all messages from the session
 func (s *SQLiteSession) Clear(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, "DELETE FROM messages WHERE session_id = ?", s.sessionID)
-	return err
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := detachForks(ctx, tx, s.sessionID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM messages WHERE session_id = ?", s.sessionID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM session_forks WHERE session_id = ?", s.sessionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// Close closes the database connection
+// Close closes the database connection unless it is shared with a store
 func (s *SQLiteSession) Close() error {
+	if s.shared {
+		return nil
+	}
 	return s.db.Close()
 }
@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// GetState implements StateStore
+func (s *SQLiteStore) GetState(ctx context.Context, sessionID string) (json.RawMessage, error) {
+	var state string
+	err := s.db.QueryRowContext(ctx,
+		"SELECT state FROM session_state WHERE session_id = ?", sessionID,
+	).Scan(&state)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session state: %w", err)
+	}
+	return json.RawMessage(state), nil
+}
+
+// SetState implements StateStore
+func (s *SQLiteStore) SetState(ctx context.Context, sessionID string, state json.RawMessage) error {
+	var err error
+	if state == nil {
+		_, err = s.db.ExecContext(ctx, "DELETE FROM session_state WHERE session_id = ?", sessionID)
+	} else {
+		_, err = s.db.ExecContext(ctx, `
+        INSERT INTO session_state (session_id, state, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+        ON CONFLICT(session_id) DO UPDATE SET state = excluded.state, updated_at = excluded.updated_at
+    `, sessionID, string(state))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save session state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// usageDayFormat stores days as sortable text
+const usageDayFormat = "2006-01-02"
+
+// AddUsage implements UsageStore
+func (s *SQLiteStore) AddUsage(ctx context.Context, tenantID, key string, at time.Time, delta UsageTotals) error {
+	_, err := s.db.ExecContext(ctx, `
+        INSERT INTO usage (tenant_id, usage_key, day, runs, tokens, cost) VALUES (?, ?, ?, ?, ?, ?)
+        ON CONFLICT(tenant_id, usage_key, day) DO UPDATE SET
+            runs = runs + excluded.runs, tokens = tokens + excluded.tokens, cost = cost + excluded.cost
+    `, tenantID, key, UsageDay(at).Format(usageDayFormat), delta.Runs, delta.Tokens, delta.Cost)
+	if err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+	return nil
+}
+
+// AddRun implements UsageStore with a single conditional upsert, which
+// SQLite executes atomically
+func (s *SQLiteStore) AddRun(ctx context.Context, tenantID, key string, at time.Time, limits RunLimits) (bool, error) {
+	day := UsageDay(at).Format(usageDayFormat)
+	res, err := s.db.ExecContext(ctx, `
+        INSERT INTO usage (tenant_id, usage_key, day, runs)
+        SELECT ?, ?, ?, 1
+        WHERE (? <= 0 OR (SELECT COALESCE(SUM(runs), 0) FROM usage WHERE tenant_id = ? AND day = ?) < ?)
+          AND (? <= 0 OR (SELECT COALESCE(SUM(runs), 0) FROM usage WHERE tenant_id = ? AND usage_key = ? AND day = ?) < ?)
+        ON CONFLICT(tenant_id, usage_key, day) DO UPDATE SET runs = runs + 1
+    `, tenantID, key, day,
+		limits.Tenant, tenantID, day, limits.Tenant,
+		limits.Key, tenantID, key, day, limits.Key)
+	if err != nil {
+		return false, fmt.Errorf("failed to record run: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record run: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Usage implements UsageStore
+func (s *SQLiteStore) Usage(ctx context.Context, query UsageQuery) (UsageTotals, error) {
+	conditions := []string{"tenant_id = ?", "day >= ?", "day <= ?"}
+	args := []interface{}{
+		query.TenantID,
+		UsageDay(query.From).Format(usageDayFormat),
+		UsageDay(query.To).Format(usageDayFormat),
+	}
+	if query.Key != "" {
+		conditions = append(conditions, "usage_key = ?")
+		args = append(args, query.Key)
+	}
+
+	var totals UsageTotals
+	err := s.db.QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(runs), 0), COALESCE(SUM(tokens), 0), COALESCE(SUM(cost), 0) FROM usage WHERE "+
+			strings.Join(conditions, " AND "),
+		args...,
+	).Scan(&totals.Runs, &totals.Tokens, &totals.Cost)
+	if err != nil {
+		return UsageTotals{}, fmt.Errorf("failed to query usage: %w", err)
+	}
+	return totals, nil
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// StateStore keeps a JSON state document per session, for agents to record
+// task state, such as a plan or collected facts, in a structured form that
+// outlives the conversation history
+type StateStore interface {
+	// GetState returns the session's state document, nil if it has none
+	GetState(ctx context.Context, sessionID string) (json.RawMessage, error)
+
+	// SetState replaces the session's state document; nil deletes it
+	SetState(ctx context.Context, sessionID string, state json.RawMessage) error
+}
+
+// InMemoryStateStore is a StateStore for a single process
+type InMemoryStateStore struct {
+	mu     sync.RWMutex
+	states map[string]json.RawMessage
+}
+
+// NewInMemoryStateStore creates an empty in-memory state store
+func NewInMemoryStateStore() *InMemoryStateStore {
+	return &InMemoryStateStore{states: make(map[string]json.RawMessage)}
+}
+
+// GetState implements StateStore
+func (s *InMemoryStateStore) GetState(ctx context.Context, sessionID string) (json.RawMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.states[sessionID]
+	if !ok {
+		return nil, nil
+	}
+	return append(json.RawMessage(nil), state...), nil
+}
+
+// SetState implements StateStore
+func (s *InMemoryStateStore) SetState(ctx context.Context, sessionID string, state json.RawMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state == nil {
+		delete(s.states, sessionID)
+		return nil
+	}
+	s.states[sessionID] = append(json.RawMessage(nil), state...)
+	return nil
+}
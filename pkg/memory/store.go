@@ -0,0 +1,266 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+// SessionStore opens sessions by ID so a single runner can serve many
+// conversations
+type SessionStore interface {
+	// Session returns the session with the given ID, creating it if needed
+	Session(ctx context.Context, sessionID string) (Session, error)
+
+	// Close releases resources held by the store
+	Close() error
+}
+
+// SQLiteStore implements SessionStore on a shared SQLite database
+type SQLiteStore struct {
+	db       *sql.DB
+	embedder Embedder
+}
+
+// SQLiteStoreOption configures a SQLiteStore
+type SQLiteStoreOption func(*SQLiteStore)
+
+// WithEmbedder enables semantic search over stored messages
+func WithEmbedder(embedder Embedder) SQLiteStoreOption {
+	return func(s *SQLiteStore) {
+		s.embedder = embedder
+	}
+}
+
+// NewSQLiteStore opens (or creates) a SQLite database holding many sessions
+func NewSQLiteStore(dbPath string, opts ...SQLiteStoreOption) (*SQLiteStore, error) {
+	if dbPath == "" {
+		dbPath = "sessions.db"
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	store := &SQLiteStore{db: db}
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	return store, nil
+}
+
+// Session returns a session backed by the store's database. Closing the
+// session leaves the database open for other sessions.
+func (s *SQLiteStore) Session(ctx context.Context, sessionID string) (Session, error) {
+	if sessionID == "" {
+		return nil, fmt.Errorf("session ID cannot be empty")
+	}
+
+	return &SQLiteSession{
+		sessionID: sessionID,
+		db:        s.db,
+		shared:    true,
+	}, nil
+}
+
+// Close closes the database connection
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Prune enforces policy across every session in the store. Expired sessions
+// are cleared; sessions over MaxMessages lose their oldest own messages
+// (history inherited from a fork parent is not counted). Forks of pruned
+// sessions keep their view of the history.
+func (s *SQLiteStore) Prune(ctx context.Context, policy RetentionPolicy) (PruneStats, error) {
+	var stats PruneStats
+
+	if policy.MaxAge > 0 {
+		expired, err := s.expiredSessions(ctx, time.Now().Add(-policy.MaxAge))
+		if err != nil {
+			return stats, err
+		}
+
+		for _, sessionID := range expired {
+			deleted, err := s.expireSession(ctx, sessionID, policy.Archiver)
+			if err != nil {
+				return stats, err
+			}
+
+			stats.ExpiredSessions++
+			stats.DeletedMessages += deleted
+		}
+	}
+
+	if policy.MaxMessages > 0 {
+		deleted, err := s.trimSessions(ctx, policy)
+		stats.DeletedMessages += deleted
+		if err != nil {
+			return stats, err
+		}
+	}
+
+	// Drop search vectors of deleted messages
+	_, err := s.db.ExecContext(ctx, "DELETE FROM message_embeddings WHERE message_id NOT IN (SELECT id FROM messages)")
+	return stats, err
+}
+
+// expiredSessions lists sessions whose newest message is older than cutoff
+func (s *SQLiteStore) expiredSessions(ctx context.Context, cutoff time.Time) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+    SELECT session_id, created_at 
+    FROM messages 
+    WHERE id IN (SELECT MAX(id) FROM messages GROUP BY session_id)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expired []string
+	for rows.Next() {
+		var sessionID string
+		var last time.Time
+		if err := rows.Scan(&sessionID, &last); err != nil {
+			return nil, err
+		}
+		if last.Before(cutoff) {
+			expired = append(expired, sessionID)
+		}
+	}
+
+	return expired, rows.Err()
+}
+
+// expireSession deletes the messages of sessionID and returns how many it
+// held. The history is archived only once the deletes have succeeded and the
+// transaction is still open, so a failed delete doesn't archive it twice.
+func (s *SQLiteStore) expireSession(ctx context.Context, sessionID string, archiver Archiver) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	messages, err := history(ctx, tx, sessionID, math.MaxInt64)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := detachForks(ctx, tx, sessionID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM messages WHERE session_id = ?", sessionID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM session_forks WHERE session_id = ?", sessionID); err != nil {
+		return 0, err
+	}
+
+	if archiver != nil {
+		if err := archiver.Archive(ctx, sessionID, messages); err != nil {
+			return 0, fmt.Errorf("failed to archive session %s: %w", sessionID, err)
+		}
+	}
+
+	return len(messages), tx.Commit()
+}
+
+// trimSessions deletes the oldest own messages of sessions holding more than
+// policy.MaxMessages and returns how many were removed
+func (s *SQLiteStore) trimSessions(ctx context.Context, policy RetentionPolicy) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+    SELECT session_id, COUNT(*) 
+    FROM messages 
+    GROUP BY session_id 
+    HAVING COUNT(*) > ?`, policy.MaxMessages)
+	if err != nil {
+		return 0, err
+	}
+
+	excess := make(map[string]int)
+	for rows.Next() {
+		var sessionID string
+		var count int
+		if err := rows.Scan(&sessionID, &count); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		excess[sessionID] = count - policy.MaxMessages
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for sessionID, n := range excess {
+		if err := s.trimSession(ctx, sessionID, n, policy.Archiver); err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+
+	return deleted, nil
+}
+
+// trimSession removes the n oldest own messages of sessionID
+func (s *SQLiteStore) trimSession(ctx context.Context, sessionID string, n int, archiver Archiver) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := detachForks(ctx, tx, sessionID); err != nil {
+		return err
+	}
+
+	var oldest []Message
+	if archiver != nil {
+		rows, err := tx.QueryContext(ctx, `
+        SELECT `+messageColumns+` 
+        FROM messages 
+        WHERE session_id = ? 
+        ORDER BY id ASC 
+        LIMIT ?`, sessionID, n)
+		if err != nil {
+			return err
+		}
+
+		if oldest, err = scanMessages(rows); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        DELETE FROM messages 
+        WHERE id IN (SELECT id FROM messages WHERE session_id = ? ORDER BY id ASC LIMIT ?)
+    `, sessionID, n)
+	if err != nil {
+		return err
+	}
+
+	// Archive only once the delete has succeeded, so a failed delete doesn't
+	// archive the same messages again on the next pass
+	if archiver != nil {
+		if err := archiver.Archive(ctx, sessionID, oldest); err != nil {
+			return fmt.Errorf("failed to archive session %s: %w", sessionID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SchemaVersion returns the migration version of the store's database
+func (s *SQLiteStore) SchemaVersion(ctx context.Context) (int, error) {
+	return SchemaVersion(ctx, s.db)
+}
@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"net/url"
+	"strings"
+)
+
+// tenantPrefix starts the storage keys of tenant-scoped sessions and runs
+const tenantPrefix = "tenant/"
+
+// TenantKey returns the storage key of a tenant's session ID or idempotency
+// key. Each tenant's keys live under their own escaped prefix, so equal IDs
+// of different tenants never share storage. Keys without a tenant are
+// returned unchanged, except that those which look tenant-scoped get an
+// empty tenant segment, so an untenanted caller can't reach a tenant's keys.
+func TenantKey(tenantID, key string) string {
+	if tenantID == "" {
+		if strings.HasPrefix(key, tenantPrefix) {
+			return tenantPrefix + "/" + key
+		}
+		return key
+	}
+	return tenantPrefix + url.PathEscape(tenantID) + "/" + key
+}
+
+// SplitTenantKey reverses TenantKey, returning an empty tenant for keys
+// stored without one
+func SplitTenantKey(storageKey string) (tenantID, key string) {
+	rest, ok := strings.CutPrefix(storageKey, tenantPrefix)
+	if !ok {
+		return "", storageKey
+	}
+	escaped, key, ok := strings.Cut(rest, "/")
+	if !ok {
+		return "", storageKey
+	}
+	tenantID, err := url.PathUnescape(escaped)
+	if err != nil {
+		return "", storageKey
+	}
+	return tenantID, key
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// UsageDay returns the UTC day t falls on, the granularity usage is stored at
+func UsageDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// UsageTotals is accumulated usage
+type UsageTotals struct {
+	Runs   int     `json:"runs"`
+	Tokens int     `json:"tokens"`
+	Cost   float64 `json:"cost"`
+}
+
+// Add folds other into the totals
+func (u *UsageTotals) Add(other UsageTotals) {
+	u.Runs += other.Runs
+	u.Tokens += other.Tokens
+	u.Cost += other.Cost
+}
+
+// UsageQuery selects the usage of a tenant's key, or of all its keys when
+// Key is empty, on the days from From through To
+type UsageQuery struct {
+	TenantID string
+	Key      string
+	From     time.Time
+	To       time.Time
+}
+
+// RunLimits caps the runs AddRun counts on a day. Zero fields are unlimited.
+type RunLimits struct {
+	// Tenant caps the runs of all the tenant's keys together
+	Tenant int
+
+	// Key caps the runs of the key
+	Key int
+}
+
+// UsageStore accumulates usage per tenant, key (such as an API key or end
+// user) and UTC day, for enforcing and reporting budgets
+type UsageStore interface {
+	// AddUsage adds delta to the totals of tenantID's key on the day of at
+	AddUsage(ctx context.Context, tenantID, key string, at time.Time, delta UsageTotals) error
+
+	// AddRun counts a run of tenantID's key on the day of at unless the
+	// day's runs have reached limits, and reports whether it did. The check
+	// and the count are atomic, so concurrent runs can't overshoot a limit.
+	AddRun(ctx context.Context, tenantID, key string, at time.Time, limits RunLimits) (bool, error)
+
+	// Usage returns the summed totals matching query
+	Usage(ctx context.Context, query UsageQuery) (UsageTotals, error)
+}
+
+// usageKey identifies one row of accumulated usage
+type usageKey struct {
+	tenantID string
+	key      string
+	day      time.Time
+}
+
+// InMemoryUsageStore is a UsageStore for a single process
+type InMemoryUsageStore struct {
+	mu     sync.Mutex
+	totals map[usageKey]UsageTotals
+}
+
+// NewInMemoryUsageStore creates an empty in-memory usage store
+func NewInMemoryUsageStore() *InMemoryUsageStore {
+	return &InMemoryUsageStore{totals: make(map[usageKey]UsageTotals)}
+}
+
+// AddUsage implements UsageStore
+func (s *InMemoryUsageStore) AddUsage(ctx context.Context, tenantID, key string, at time.Time, delta UsageTotals) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := usageKey{tenantID: tenantID, key: key, day: UsageDay(at)}
+	totals := s.totals[k]
+	totals.Add(delta)
+	s.totals[k] = totals
+	return nil
+}
+
+// AddRun implements UsageStore
+func (s *InMemoryUsageStore) AddRun(ctx context.Context, tenantID, key string, at time.Time, limits RunLimits) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	day := UsageDay(at)
+	k := usageKey{tenantID: tenantID, key: key, day: day}
+	if limits.Tenant > 0 {
+		runs := 0
+		for other, t := range s.totals {
+			if other.tenantID == tenantID && other.day.Equal(day) {
+				runs += t.Runs
+			}
+		}
+		if runs >= limits.Tenant {
+			return false, nil
+		}
+	}
+	if limits.Key > 0 && s.totals[k].Runs >= limits.Key {
+		return false, nil
+	}
+
+	totals := s.totals[k]
+	totals.Runs++
+	s.totals[k] = totals
+	return true, nil
+}
+
+// Usage implements UsageStore
+func (s *InMemoryUsageStore) Usage(ctx context.Context, query UsageQuery) (UsageTotals, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, to := UsageDay(query.From), UsageDay(query.To)
+	var totals UsageTotals
+	for k, t := range s.totals {
+		if k.tenantID != query.TenantID || query.Key != "" && k.key != query.Key {
+			continue
+		}
+		if k.day.Before(from) || k.day.After(to) {
+			continue
+		}
+		totals.Add(t)
+	}
+	return totals, nil
+}
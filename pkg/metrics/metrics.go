@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// ToolCall describes a single tool execution
+type ToolCall struct {
+	Tool        string
+	Duration    time.Duration
+	Err         error
+	Cached      bool
+	InputBytes  int
+	OutputBytes int
+}
+
+// Recorder receives measurements from the runner
+type Recorder interface {
+	// RecordToolCall is called once per executed tool call
+	RecordToolCall(call ToolCall)
+}
+
+// Usage describes the tokens and cost of one model completion
+type Usage struct {
+	Tenant           string
+	Agent            string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Cost             float64
+}
+
+// UsageRecorder is implemented by recorders that also aggregate model usage,
+// e.g. for billing the tenants of a multi-tenant application
+type UsageRecorder interface {
+	// RecordUsage is called once per model completion
+	RecordUsage(usage Usage)
+}
+
+// UsageStats aggregates the completions of one tenant
+type UsageStats struct {
+	Completions      int     `json:"completions"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// Add folds usage into the stats
+func (s *UsageStats) Add(usage Usage) {
+	s.Completions++
+	s.PromptTokens += usage.PromptTokens
+	s.CompletionTokens += usage.CompletionTokens
+	s.TotalTokens += usage.TotalTokens
+	s.Cost += usage.Cost
+}
+
+// ToolStats aggregates calls to one tool
+type ToolStats struct {
+	Calls         int           `json:"calls"`
+	Failures      int           `json:"failures"`
+	CacheHits     int           `json:"cache_hits"`
+	TotalDuration time.Duration `json:"total_duration"`
+	MaxDuration   time.Duration `json:"max_duration"`
+	InputBytes    int           `json:"input_bytes"`
+	OutputBytes   int           `json:"output_bytes"`
+}
+
+// Add folds call into the stats
+func (s *ToolStats) Add(call ToolCall) {
+	s.Calls++
+	if call.Err != nil {
+		s.Failures++
+	}
+	if call.Cached {
+		s.CacheHits++
+	}
+	s.TotalDuration += call.Duration
+	if call.Duration > s.MaxDuration {
+		s.MaxDuration = call.Duration
+	}
+	s.InputBytes += call.InputBytes
+	s.OutputBytes += call.OutputBytes
+}
+
+// MeanDuration returns the average execution time
+func (s ToolStats) MeanDuration() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Calls)
+}
+
+// InMemoryRecorder aggregates measurements in process, e.g. for exposing on
+// an admin endpoint
+type InMemoryRecorder struct {
+	mu      sync.Mutex
+	tools   map[string]*ToolStats
+	tenants map[string]*UsageStats
+}
+
+// NewInMemoryRecorder creates an empty recorder
+func NewInMemoryRecorder() *InMemoryRecorder {
+	return &InMemoryRecorder{
+		tools:   make(map[string]*ToolStats),
+		tenants: make(map[string]*UsageStats),
+	}
+}
+
+// RecordToolCall implements Recorder
+func (r *InMemoryRecorder) RecordToolCall(call ToolCall) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, ok := r.tools[call.Tool]
+	if !ok {
+		stats = &ToolStats{}
+		r.tools[call.Tool] = stats
+	}
+	stats.Add(call)
+}
+
+// ToolStats returns a snapshot of the per-tool aggregates
+func (r *InMemoryRecorder) ToolStats() map[string]ToolStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := make(map[string]ToolStats, len(r.tools))
+	for name, stats := range r.tools {
+		snapshot[name] = *stats
+	}
+	return snapshot
+}
+
+// RecordUsage implements UsageRecorder
+func (r *InMemoryRecorder) RecordUsage(usage Usage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, ok := r.tenants[usage.Tenant]
+	if !ok {
+		stats = &UsageStats{}
+		r.tenants[usage.Tenant] = stats
+	}
+	stats.Add(usage)
+}
+
+// UsageByTenant returns a snapshot of the per-tenant usage aggregates. Runs
+// without a tenant are counted under "".
+func (r *InMemoryRecorder) UsageByTenant() map[string]UsageStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := make(map[string]UsageStats, len(r.tenants))
+	for tenant, stats := range r.tenants {
+		snapshot[tenant] = *stats
+	}
+	return snapshot
+}
+
+// Reset discards all recorded measurements
+func (r *InMemoryRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tools = make(map[string]*ToolStats)
+	r.tenants = make(map[string]*UsageStats)
+}
+
+// NoOpRecorder discards all measurements
+type NoOpRecorder struct{}
+
+// RecordToolCall implements Recorder
+func (NoOpRecorder) RecordToolCall(call ToolCall) {}
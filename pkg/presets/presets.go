@@ -0,0 +1,266 @@
+// Package presets provides ready-made agents for common roles: a triage
+// router, a summarizer, a structured extractor, a SQL analyst and a judge.
+// Each preset is an ordinary *agents.Agent whose instructions are built from
+// sections, so a single section can be replaced with Agent.With and
+// agents.WithInstructionSections.
+package presets
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/agents"
+	"github.com/ryanhill4L/agents-sdk/pkg/tools"
+)
+
+// Option configures a preset
+type Option func(*config)
+
+type config struct {
+	name         string
+	model        string
+	tools        []tools.Tool
+	guidance     string
+	agentOptions []agents.AgentOption
+}
+
+// WithName overrides the preset's default agent name
+func WithName(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
+// WithModel sets the LLM model
+func WithModel(model string) Option {
+	return func(c *config) {
+		c.model = model
+	}
+}
+
+// WithTools gives the preset tools to use
+func WithTools(tools ...tools.Tool) Option {
+	return func(c *config) {
+		c.tools = append(c.tools, tools...)
+	}
+}
+
+// WithGuidance appends domain-specific guidance to the preset's instructions
+func WithGuidance(guidance string) Option {
+	return func(c *config) {
+		c.guidance = guidance
+	}
+}
+
+// WithAgentOptions applies agent options after the preset's own, for
+// settings such as guardrails or max tokens
+func WithAgentOptions(opts ...agents.AgentOption) Option {
+	return func(c *config) {
+		c.agentOptions = append(c.agentOptions, opts...)
+	}
+}
+
+// build creates the agent for a preset with its default name, instruction
+// sections and temperature
+func build(name string, sections []agents.InstructionSection, temperature float32, opts []Option, extra ...agents.AgentOption) *agents.Agent {
+	cfg := &config{name: name}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.guidance != "" {
+		sections = append(sections, agents.Section("guidance", cfg.guidance))
+	}
+
+	agentOpts := []agents.AgentOption{
+		agents.WithInstructionSections(sections...),
+		agents.WithTemperature(temperature),
+	}
+	if cfg.model != "" {
+		agentOpts = append(agentOpts, agents.WithModel(cfg.model))
+	}
+	if len(cfg.tools) > 0 {
+		agentOpts = append(agentOpts, agents.WithTools(cfg.tools...))
+	}
+	agentOpts = append(agentOpts, extra...)
+	agentOpts = append(agentOpts, cfg.agentOptions...)
+
+	return agents.NewAgent(cfg.name, agentOpts...)
+}
+
+// Route is a specialist a triage agent can hand off to
+type Route struct {
+	Agent *agents.Agent
+
+	// When describes the requests the specialist handles
+	When string
+}
+
+// Triage creates a router that answers nothing itself and hands each
+// request off to the best matching specialist
+func Triage(routes []Route, opts ...Option) (*agents.Agent, error) {
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("triage requires at least one route")
+	}
+
+	var list strings.Builder
+	handoffs := make([]*agents.Agent, 0, len(routes))
+	for _, route := range routes {
+		if route.Agent == nil {
+			return nil, agents.ErrMissingHandoff
+		}
+		fmt.Fprintf(&list, "- %s: %s\n", route.Agent.Name, route.When)
+		handoffs = append(handoffs, route.Agent)
+	}
+
+	sections := []agents.InstructionSection{
+		agents.Section(agents.SectionRole,
+			"You are a triage agent. Your only job is to route each request to the specialist best suited to handle it."),
+		agents.Section("specialists", list.String()),
+		agents.Section(agents.SectionProcess,
+			"1. Read the request and identify what the user needs.",
+			"2. Hand off to exactly one specialist whose description matches.",
+			"3. If the request is ambiguous, ask one short clarifying question instead of guessing.",
+			"4. Never answer the request yourself and never invent specialists that aren't listed."),
+	}
+
+	return build("Triage", sections, 0.2, opts, agents.WithHandoffs(handoffs...)), nil
+}
+
+// Summarizer creates an agent that condenses the input into a faithful
+// summary
+func Summarizer(opts ...Option) *agents.Agent {
+	sections := []agents.InstructionSection{
+		agents.Section(agents.SectionRole, "You summarize text accurately and concisely."),
+		agents.Section("rules",
+			"- Keep the key facts, decisions, numbers and names; drop repetition and filler.",
+			"- Never add information that isn't in the source, and don't editorialize.",
+			"- Preserve the source's uncertainty: if something is tentative, say so."),
+		agents.Section(agents.SectionOutputFormat,
+			"Start with a one-sentence overview, then list the main points as short bullets.",
+			"If the input is too short to summarize, restate it in one sentence."),
+	}
+
+	return build("Summarizer", sections, 0.3, opts)
+}
+
+// Extractor creates an agent that extracts structured data matching schema
+// from unstructured text. schema is a JSON schema as json.RawMessage or
+// string, or a Go value whose type the schema is derived from.
+func Extractor(schema interface{}, opts ...Option) (*agents.Agent, error) {
+	schemaJSON, err := schemaText(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := []agents.InstructionSection{
+		agents.Section(agents.SectionRole, "You extract structured data from the text you are given."),
+		agents.Section("rules",
+			"- Only use information stated in the text; never guess.",
+			"- Use null for optional fields the text doesn't mention and empty arrays for lists with no items.",
+			"- Normalize values to the types in the schema (numbers as numbers, booleans as true or false)."),
+		agents.Section(agents.SectionOutputFormat,
+			"Respond with a single JSON object matching this schema and nothing else: no prose, no code fences.",
+			"",
+			schemaJSON),
+	}
+
+	return build("Extractor", sections, 0.1, opts), nil
+}
+
+// schemaText renders a schema argument as indented JSON
+func schemaText(schema interface{}) (string, error) {
+	var raw []byte
+	switch s := schema.(type) {
+	case json.RawMessage:
+		raw = s
+	case []byte:
+		raw = s
+	case string:
+		raw = []byte(s)
+	default:
+		derived, err := tools.SchemaOf(schema)
+		if err != nil {
+			return "", fmt.Errorf("failed to derive schema: %w", err)
+		}
+		if raw, err = json.Marshal(derived); err != nil {
+			return "", fmt.Errorf("failed to encode schema: %w", err)
+		}
+	}
+
+	var indented strings.Builder
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return "", fmt.Errorf("invalid schema: %w", err)
+	}
+	encoder := json.NewEncoder(&indented)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(decoded); err != nil {
+		return "", fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return strings.TrimSpace(indented.String()), nil
+}
+
+// SQLAnalyst creates an agent that answers questions by querying a
+// database. The caller supplies the query tool with WithTools; schemaDDL
+// describes the tables and dialect names the SQL dialect, e.g. "PostgreSQL".
+func SQLAnalyst(dialect, schemaDDL string, opts ...Option) *agents.Agent {
+	sections := []agents.InstructionSection{
+		agents.Section(agents.SectionRole,
+			"You are a data analyst who answers questions by writing and running "+dialect+" queries."),
+		agents.Section(agents.SectionContext, "Database schema:", strings.TrimSpace(schemaDDL)),
+		agents.Section(agents.SectionProcess,
+			"1. Work out which tables and columns answer the question; only use those in the schema.",
+			"2. Write a single read-only SELECT query.",
+			"3. Run it with the query tool. If it fails, read the error, fix the query and try again.",
+			"4. Answer in plain language, quoting the numbers from the results, and show the final query."),
+		agents.Section(agents.SectionSafety,
+			"- Never modify data or the schema: no INSERT, UPDATE, DELETE or DDL.",
+			"- Limit exploratory queries to 100 rows.",
+			"- Never make up results; if the data can't answer the question, say so."),
+	}
+
+	return build("SQL Analyst", sections, 0.1, opts)
+}
+
+// Verdict is the JSON object a Judge responds with
+type Verdict struct {
+	Score     float64 `json:"score"`
+	Pass      bool    `json:"pass"`
+	Reasoning string  `json:"reasoning"`
+}
+
+// Judge creates an agent that grades a response against rubric, replying
+// with a Verdict as JSON. Give it the original request and the response to
+// grade as input.
+func Judge(rubric string, opts ...Option) *agents.Agent {
+	sections := []agents.InstructionSection{
+		agents.Section(agents.SectionRole, "You are an impartial judge grading a response against a rubric."),
+		agents.Section("rubric", strings.TrimSpace(rubric)),
+		agents.Section(agents.SectionProcess,
+			"1. Read the request and the response you are given.",
+			"2. Check the response against each rubric criterion; judge substance, not length or style.",
+			"3. Score it from 0.0 (fails every criterion) to 1.0 (meets every criterion) and decide whether it passes."),
+		agents.Section(agents.SectionOutputFormat,
+			"Respond with a single JSON object and nothing else:",
+			`{"score": <0.0-1.0>, "pass": <true|false>, "reasoning": "<one or two sentences>"}`),
+	}
+
+	return build("Judge", sections, 0.1, opts)
+}
+
+// ParseVerdict decodes a Judge's output, tolerating surrounding text
+func ParseVerdict(output string) (*Verdict, error) {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no verdict in judge output: %q", output)
+	}
+
+	var verdict Verdict
+	if err := json.Unmarshal([]byte(output[start:end+1]), &verdict); err != nil {
+		return nil, fmt.Errorf("invalid verdict: %w", err)
+	}
+	return &verdict, nil
+}
@@ -0,0 +1,80 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// CompleteBatch implements BatchProvider using Anthropic's Message Batches API
+func (p *AnthropicProvider) CompleteBatch(ctx context.Context, agent Agent, requests []BatchRequest, tools []ToolDefinition) ([]BatchResponse, error) {
+	type batchItem struct {
+		CustomID string                     `json:"custom_id"`
+		Params   anthropic.MessageNewParams `json:"params"`
+	}
+
+	items := make([]batchItem, len(requests))
+	for i, req := range requests {
+		items[i] = batchItem{
+			CustomID: strconv.Itoa(i),
+			Params:   p.buildParams(agent, req.Messages, tools),
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"requests": items})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Anthropic batch: %w", err)
+	}
+
+	batch, err := p.client.Messages.Batches.New(ctx, anthropic.MessageBatchNewParams{},
+		option.WithRequestBody("application/json", body))
+	if err != nil {
+		return nil, anthropicError("create_batch", err)
+	}
+
+	for batch.ProcessingStatus != anthropic.MessageBatchProcessingStatusEnded {
+		if err := waitForBatch(ctx, p.config.BatchPollInterval); err != nil {
+			return nil, fmt.Errorf("Anthropic batch %s not finished: %w", batch.ID, err)
+		}
+
+		batch, err = p.client.Messages.Batches.Get(ctx, batch.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to poll Anthropic batch: %w", err)
+		}
+	}
+
+	responses := make([]BatchResponse, len(requests))
+	for i := range responses {
+		responses[i].Err = fmt.Errorf("no result returned for batch request %d", i)
+	}
+
+	stream := p.client.Messages.Batches.ResultsStreaming(ctx, batch.ID)
+	defer stream.Close()
+
+	for stream.Next() {
+		item := stream.Current()
+		idx, err := strconv.Atoi(item.CustomID)
+		if err != nil || idx < 0 || idx >= len(responses) {
+			continue
+		}
+
+		switch item.Result.Type {
+		case "succeeded":
+			message := item.Result.Message
+			responses[idx] = BatchResponse{Completion: completionFromAnthropic(&message)}
+		case "errored":
+			responses[idx].Err = fmt.Errorf("Anthropic batch request failed: %s", item.Result.Error.Error.Message)
+		default:
+			responses[idx].Err = fmt.Errorf("Anthropic batch request %s", item.Result.Type)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Anthropic batch results: %w", err)
+	}
+
+	return responses, nil
+}
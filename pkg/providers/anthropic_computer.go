@@ -0,0 +1,35 @@
+package providers
+
+import (
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/packages/param"
+)
+
+// Anthropic's computer-use tool version and the beta enabling it
+const (
+	anthropicComputerToolType = "computer_20250124"
+	anthropicComputerUseBeta  = "computer-use-2025-01-24"
+)
+
+// hasComputerTool reports whether any tool is a computer-use tool
+func hasComputerTool(tools []ToolDefinition) bool {
+	for _, tool := range tools {
+		if tool.Display != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// anthropicComputerTool declares a computer-use tool in Anthropic's native
+// format. The SDK's non-beta tool union has no computer tool, so its JSON is
+// given directly.
+func anthropicComputerTool(tool ToolDefinition) anthropic.ToolUnionParam {
+	computer := param.Override[anthropic.ToolParam](map[string]interface{}{
+		"type":              anthropicComputerToolType,
+		"name":              tool.Name,
+		"display_width_px":  tool.Display.Width,
+		"display_height_px": tool.Display.Height,
+	})
+	return anthropic.ToolUnionParam{OfTool: &computer}
+}
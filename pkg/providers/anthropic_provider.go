@@ -2,12 +2,16 @@ package providers
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/anthropics/anthropic-sdk-go/packages/param"
 	"github.com/anthropics/anthropic-sdk-go/shared/constant"
 )
 
@@ -24,7 +28,10 @@ func NewAnthropicProvider(config *AnthropicConfig) (*AnthropicProvider, error) {
 	}
 
 	// Initialize Anthropic client with official SDK
-	var opts []option.RequestOption
+	opts := []option.RequestOption{
+		option.WithRequestTimeout(config.Timeout),
+		option.WithMaxRetries(config.MaxRetries),
+	}
 	if config.APIKey != "" {
 		opts = append(opts, option.WithAPIKey(config.APIKey))
 	}
@@ -32,6 +39,10 @@ func NewAnthropicProvider(config *AnthropicConfig) (*AnthropicProvider, error) {
 		opts = append(opts, option.WithBaseURL(config.BaseURL))
 	}
 	
+	if httpClient := debugHTTPClient(&config.ProviderConfig, ProviderTypeAnthropic.String()); httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(httpClient))
+	}
+
 	client := anthropic.NewClient(opts...)
 
 	return &AnthropicProvider{
@@ -42,27 +53,146 @@ func NewAnthropicProvider(config *AnthropicConfig) (*AnthropicProvider, error) {
 
 // Complete implements the Provider interface for Anthropic
 func (p *AnthropicProvider) Complete(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (*Completion, error) {
+	params, callOpts := p.request(ctx, agent, messages, tools)
+
+	// Make API call
+	response, err := p.client.Messages.New(ctx, params, callOpts...)
+	if err != nil {
+		return nil, anthropicError("complete", err)
+	}
+
+	return completionFromAnthropic(response), nil
+}
+
+// CompleteStreaming implements ToolCallStreamer for Anthropic, reporting a
+// tool call when its content block ends
+func (p *AnthropicProvider) CompleteStreaming(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition, onToolCall func(ToolCall)) (*Completion, error) {
+	params, callOpts := p.request(ctx, agent, messages, tools)
+
+	start := time.Now()
+	var firstToken time.Duration
+	stream := p.client.Messages.NewStreaming(ctx, params, callOpts...)
+	defer stream.Close()
+
+	var message anthropic.Message
+	for stream.Next() {
+		event := stream.Current()
+		if err := message.Accumulate(event); err != nil {
+			return nil, anthropicError("complete", err)
+		}
+
+		switch event.AsAny().(type) {
+		case anthropic.ContentBlockDeltaEvent:
+			if firstToken == 0 {
+				firstToken = time.Since(start)
+			}
+		case anthropic.ContentBlockStopEvent:
+			block := message.Content[len(message.Content)-1]
+			if block.Type != "tool_use" {
+				continue
+			}
+			var arguments map[string]interface{}
+			if err := json.Unmarshal(block.Input, &arguments); err == nil {
+				onToolCall(ToolCall{ID: block.ID, Name: block.Name, Arguments: arguments})
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, anthropicError("complete", err)
+	}
+
+	completion := completionFromAnthropic(&message)
+	completion.TimeToFirstToken = firstToken
+	return completion, nil
+}
+
+// request builds the parameters and per-call options of a request
+func (p *AnthropicProvider) request(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (anthropic.MessageNewParams, []option.RequestOption) {
+	params := p.buildParams(agent, messages, tools)
+
+	reqOpts := p.config.requestOptions(ctx)
+	if reqOpts.User != "" {
+		params.Metadata = anthropic.MetadataParam{UserID: anthropic.String(reqOpts.User)}
+	}
+	var callOpts []option.RequestOption
+	for name, value := range reqOpts.Headers {
+		callOpts = append(callOpts, option.WithHeader(name, value))
+	}
+	for _, beta := range p.betas(ctx, tools) {
+		callOpts = append(callOpts, option.WithHeaderAdd("anthropic-beta", beta))
+	}
+	return params, callOpts
+}
+
+// betas returns the beta features a request opts into: the configured ones,
+// those enabled by feature flags and those its tools need
+func (p *AnthropicProvider) betas(ctx context.Context, tools []ToolDefinition) []string {
+	seen := make(map[string]bool)
+	var betas []string
+	add := func(beta string) {
+		if beta != "" && !seen[beta] {
+			seen[beta] = true
+			betas = append(betas, beta)
+		}
+	}
+
+	for _, beta := range p.config.Beta {
+		add(beta)
+	}
+	for _, beta := range featureNames(p.config.features(ctx, ProviderTypeAnthropic)) {
+		add(beta)
+	}
+	if hasComputerTool(tools) {
+		add(anthropicComputerUseBeta)
+	}
+	return betas
+}
+
+// buildParams converts the agent, conversation, and tools into a request
+func (p *AnthropicProvider) buildParams(agent Agent, messages []Message, tools []ToolDefinition) anthropic.MessageNewParams {
 	// Convert messages to Anthropic format
 	var systemPrompt *string
+	var systemCached bool
+	var cached []int
 	claudeMessages := make([]anthropic.MessageParam, 0, len(messages))
 	
 	// Extract system instructions
-	if instructions := agent.GetInstructions(); instructions != "" {
+	if instructions := instructionsFor(agent, InstructionsXML); instructions != "" {
 		systemPrompt = &instructions
 	}
 	
-	// Convert messages (skip system messages as they're handled separately)
+	// Convert messages. The Messages API only takes a system prompt up front:
+	// system messages leading the history extend it, and later ones are sent
+	// as user turns wrapped in <system> tags so their position is kept.
 	for _, msg := range messages {
+		if isHostedMessage(msg) {
+			continue
+		}
+
+		converted := len(claudeMessages)
 		switch msg.Role {
-		case "user":
+		case "system", "developer":
+			if len(claudeMessages) == 0 {
+				prompt := msg.Content
+				if systemPrompt != nil {
+					prompt = *systemPrompt + "\n\n" + prompt
+				}
+				systemPrompt = &prompt
+				systemCached = systemCached || MessageCached(msg)
+				continue
+			}
 			claudeMessages = append(claudeMessages, anthropic.NewUserMessage(
-				anthropic.NewTextBlock(msg.Content),
+				anthropic.NewTextBlock("<system>\n"+msg.Content+"\n</system>"),
 			))
-		case "assistant":
-			// Handle assistant messages with potential tool calls
-			content := []anthropic.ContentBlockParamUnion{
+		case "user":
+			blocks := []anthropic.ContentBlockParamUnion{
 				anthropic.NewTextBlock(msg.Content),
 			}
+			blocks = append(blocks, anthropicContentBlocks(msg.Parts)...)
+			claudeMessages = append(claudeMessages, anthropic.NewUserMessage(blocks...))
+		case "assistant":
+			// Handle assistant messages with potential tool calls
+			content := anthropicAssistantText(msg)
 			
 			// Add tool calls if present
 			for _, toolCall := range msg.ToolCalls {
@@ -77,13 +207,34 @@ func (p *AnthropicProvider) Complete(ctx context.Context, agent Agent, messages
 		case "tool":
 			// Handle tool result messages properly for Anthropic
 			if msg.Metadata != nil {
-				if toolCallID, ok := msg.Metadata["tool_call_id"].(string); ok {
+				if toolCallID, ok := msg.Metadata[MetadataToolCallID].(string); ok {
+					isError, _ := msg.Metadata[MetadataIsError].(bool)
 					claudeMessages = append(claudeMessages, anthropic.NewUserMessage(
-						anthropic.NewToolResultBlock(toolCallID, msg.Content, false),
+						anthropicToolResultBlock(toolCallID, msg, isError),
 					))
 				}
 			}
 		}
+
+		if MessageCached(msg) && len(claudeMessages) > converted {
+			cached = append(cached, len(claudeMessages)-1)
+		}
+	}
+
+	// Anthropic allows four cache breakpoints; the latest ones are kept
+	limit := anthropicMaxCacheBreakpoints
+	if systemCached {
+		limit--
+	}
+	if len(cached) > limit {
+		cached = cached[len(cached)-limit:]
+	}
+	for _, i := range cached {
+		if content := claudeMessages[i].Content; len(content) > 0 {
+			if cacheControl := content[len(content)-1].GetCacheControl(); cacheControl != nil {
+				*cacheControl = anthropic.NewCacheControlEphemeralParam()
+			}
+		}
 	}
 	
 	// Prepare request parameters
@@ -108,6 +259,9 @@ func (p *AnthropicProvider) Complete(ctx context.Context, agent Agent, messages
 		params.System = []anthropic.TextBlockParam{
 			{Type: "text", Text: *systemPrompt},
 		}
+		if systemCached {
+			params.System[0].CacheControl = anthropic.NewCacheControlEphemeralParam()
+		}
 	}
 	
 	// Set temperature if specified
@@ -124,6 +278,15 @@ func (p *AnthropicProvider) Complete(ctx context.Context, agent Agent, messages
 	if len(tools) > 0 {
 		anthropicTools := make([]anthropic.ToolUnionParam, 0, len(tools))
 		for _, tool := range tools {
+			// Hosted tools belong to other providers
+			if tool.Hosted != "" {
+				continue
+			}
+			if tool.Display != nil {
+				anthropicTools = append(anthropicTools, anthropicComputerTool(tool))
+				continue
+			}
+
 			// Convert our schema to Anthropic's expected format
 			inputSchema := anthropic.ToolInputSchemaParam{
 				Type:       constant.Object("object"), // Always "object" for function tools
@@ -131,32 +294,59 @@ func (p *AnthropicProvider) Complete(ctx context.Context, agent Agent, messages
 				Required:   tool.Schema.Required,
 			}
 			
-			anthropicTools = append(anthropicTools, anthropic.ToolUnionParamOfTool(
+			anthropicTool := anthropic.ToolUnionParamOfTool(
 				inputSchema,
 				tool.Name,
-			))
+			)
+			if tool.Description != "" {
+				anthropicTool.OfTool.Description = anthropic.String(tool.Description)
+			}
+			anthropicTools = append(anthropicTools, anthropicTool)
 		}
 		params.Tools = anthropicTools
+
+		// Limit the model to one tool call per turn if requested
+		if parallel := parallelToolCalls(agent); parallel != nil && len(anthropicTools) > 0 {
+			params.ToolChoice = anthropic.ToolChoiceUnionParam{
+				OfAuto: &anthropic.ToolChoiceAutoParam{
+					DisableParallelToolUse: anthropic.Bool(!*parallel),
+				},
+			}
+		}
 	}
 	
-	// Make API call
-	response, err := p.client.Messages.New(ctx, params)
-	if err != nil {
-		return nil, fmt.Errorf("Anthropic API call failed: %w", err)
-	}
-	
+	return params
+}
+
+// completionFromAnthropic converts an Anthropic message into a Completion
+func completionFromAnthropic(response *anthropic.Message) *Completion {
 	// Extract content and tool calls from response
 	var content string
 	var toolCalls []ToolCall
-	
+	var citations []Citation
+
+	// Cited responses are split into text blocks at citation boundaries, so
+	// their blocks are joined as they are
+	separator := "\n"
+	for _, contentBlock := range response.Content {
+		if len(contentBlock.Citations) > 0 {
+			separator = ""
+			break
+		}
+	}
+
 	for _, contentBlock := range response.Content {
 		switch contentBlock.Type {
 		case "text":
 			if contentBlock.Text != "" {
 				if content != "" {
-					content += "\n"
+					content += separator
 				}
+				start := len(content)
 				content += contentBlock.Text
+				for _, citation := range contentBlock.Citations {
+					citations = append(citations, citationFromAnthropic(citation, start, len(content)))
+				}
 			}
 		case "tool_use":
 			// Parse the JSON input to map[string]interface{}
@@ -181,15 +371,291 @@ func (p *AnthropicProvider) Complete(ctx context.Context, agent Agent, messages
 			ToolCalls: toolCalls,
 			Timestamp: time.Now(),
 		},
-		Usage: Usage{
-			PromptTokens:     int(response.Usage.InputTokens),
-			CompletionTokens: int(response.Usage.OutputTokens),
-			TotalTokens:      int(response.Usage.InputTokens + response.Usage.OutputTokens),
-		},
+		Usage:     usageFromAnthropic(response.Usage),
 		ToolCalls: toolCalls,
+		Citations: citations,
+	}
+	if len(citations) > 0 {
+		result.Message.Metadata = map[string]interface{}{MetadataCitations: citations}
+	}
+
+	return result
+}
+
+// citationFromAnthropic converts a citation of the text block spanning
+// content[start:end]
+func citationFromAnthropic(citation anthropic.TextCitationUnion, start, end int) Citation {
+	result := Citation{
+		Type:          citation.Type,
+		Title:         citation.DocumentTitle,
+		Text:          citation.CitedText,
+		StartIndex:    start,
+		EndIndex:      end,
+		DocumentIndex: int(citation.DocumentIndex),
+		FileID:        citation.FileID,
+	}
+
+	switch citation.Type {
+	case "char_location":
+		result.SourceStart, result.SourceEnd = int(citation.StartCharIndex), int(citation.EndCharIndex)
+	case "page_location":
+		result.SourceStart, result.SourceEnd = int(citation.StartPageNumber), int(citation.EndPageNumber)
+	case "content_block_location":
+		result.SourceStart, result.SourceEnd = int(citation.StartBlockIndex), int(citation.EndBlockIndex)
+	case "web_search_result_location":
+		result.URL, result.Title = citation.URL, citation.Title
+	}
+	return result
+}
+
+// usageFromAnthropic converts message usage. Anthropic's input tokens
+// exclude cache reads and writes, which are added back into PromptTokens.
+func usageFromAnthropic(usage anthropic.Usage) Usage {
+	prompt := usage.InputTokens + usage.CacheReadInputTokens + usage.CacheCreationInputTokens
+	return Usage{
+		PromptTokens:       int(prompt),
+		CompletionTokens:   int(usage.OutputTokens),
+		TotalTokens:        int(prompt + usage.OutputTokens),
+		CachedPromptTokens: int(usage.CacheReadInputTokens),
+		CacheWriteTokens:   int(usage.CacheCreationInputTokens),
 	}
-	
-	return result, nil
 }
 
+// anthropicMaxCacheBreakpoints is the number of cache_control blocks a
+// request may have
+const anthropicMaxCacheBreakpoints = 4
 
+// anthropicAssistantText converts the text of an assistant message. Text
+// that cites request documents is split into blocks at the cited spans, so
+// the citations are replayed as Anthropic returned them.
+func anthropicAssistantText(msg Message) []anthropic.ContentBlockParamUnion {
+	type span struct {
+		start, end int
+		citations  []anthropic.TextCitationParamUnion
+	}
+
+	var spans []span
+	for _, citation := range CitationsOf(msg.Metadata) {
+		cited, ok := anthropicCitationParam(citation)
+		if !ok || citation.StartIndex >= citation.EndIndex || citation.EndIndex > len(msg.Content) {
+			continue
+		}
+		if n := len(spans); n > 0 && spans[n-1].start == citation.StartIndex && spans[n-1].end == citation.EndIndex {
+			spans[n-1].citations = append(spans[n-1].citations, cited)
+			continue
+		}
+		spans = append(spans, span{start: citation.StartIndex, end: citation.EndIndex, citations: []anthropic.TextCitationParamUnion{cited}})
+	}
+	if len(spans) == 0 {
+		return []anthropic.ContentBlockParamUnion{anthropic.NewTextBlock(msg.Content)}
+	}
+
+	var blocks []anthropic.ContentBlockParamUnion
+	pos := 0
+	for _, s := range spans {
+		// Overlapping or out-of-order spans can't be split out
+		if s.start < pos {
+			continue
+		}
+		if s.start > pos {
+			blocks = append(blocks, anthropic.NewTextBlock(msg.Content[pos:s.start]))
+		}
+		blocks = append(blocks, anthropic.ContentBlockParamUnion{OfText: &anthropic.TextBlockParam{
+			Text:      msg.Content[s.start:s.end],
+			Citations: s.citations,
+		}})
+		pos = s.end
+	}
+	if pos < len(msg.Content) {
+		blocks = append(blocks, anthropic.NewTextBlock(msg.Content[pos:]))
+	}
+	return blocks
+}
+
+// anthropicCitationParam converts a citation of a request document back to
+// Anthropic's form. Web search citations can't be, since Anthropic requires
+// an encrypted index that isn't kept.
+func anthropicCitationParam(citation Citation) (anthropic.TextCitationParamUnion, bool) {
+	var title param.Opt[string]
+	if citation.Title != "" {
+		title = anthropic.String(citation.Title)
+	}
+	index := int64(citation.DocumentIndex)
+	start, end := int64(citation.SourceStart), int64(citation.SourceEnd)
+
+	switch citation.Type {
+	case "char_location":
+		return anthropic.TextCitationParamUnion{OfCharLocation: &anthropic.CitationCharLocationParam{
+			DocumentTitle: title, CitedText: citation.Text, DocumentIndex: index, StartCharIndex: start, EndCharIndex: end,
+		}}, true
+	case "page_location":
+		return anthropic.TextCitationParamUnion{OfPageLocation: &anthropic.CitationPageLocationParam{
+			DocumentTitle: title, CitedText: citation.Text, DocumentIndex: index, StartPageNumber: start, EndPageNumber: end,
+		}}, true
+	case "content_block_location":
+		return anthropic.TextCitationParamUnion{OfContentBlockLocation: &anthropic.CitationContentBlockLocationParam{
+			DocumentTitle: title, CitedText: citation.Text, DocumentIndex: index, StartBlockIndex: start, EndBlockIndex: end,
+		}}, true
+	default:
+		return anthropic.TextCitationParamUnion{}, false
+	}
+}
+
+// anthropicContentBlocks converts non-text content parts into Anthropic blocks
+func anthropicContentBlocks(parts []ContentPart) []anthropic.ContentBlockParamUnion {
+	var blocks []anthropic.ContentBlockParamUnion
+	for _, part := range parts {
+		switch part.Type {
+		case ContentPartImage:
+			if len(part.Data) > 0 {
+				blocks = append(blocks, anthropic.NewImageBlockBase64(part.MIMEType, base64.StdEncoding.EncodeToString(part.Data)))
+			} else if part.URI != "" {
+				blocks = append(blocks, anthropic.NewImageBlock(anthropic.URLImageSourceParam{URL: part.URI}))
+			}
+		case ContentPartFile:
+			blocks = append(blocks, anthropicDocumentBlock(part))
+		}
+	}
+	return blocks
+}
+
+// anthropicDocumentBlock sends PDFs and text files as native document blocks
+// and falls back to locally extracted text for anything else
+func anthropicDocumentBlock(part ContentPart) anthropic.ContentBlockParamUnion {
+	var block anthropic.ContentBlockParamUnion
+	switch {
+	case len(part.Data) > 0 && strings.EqualFold(part.MIMEType, MIMETypePDF):
+		block = anthropic.NewDocumentBlock(anthropic.Base64PDFSourceParam{
+			Data: base64.StdEncoding.EncodeToString(part.Data),
+		})
+	case len(part.Data) > 0 && IsTextMIMEType(part.MIMEType):
+		block = anthropic.NewDocumentBlock(anthropic.PlainTextSourceParam{
+			Data: string(part.Data),
+		})
+	case part.URI != "" && strings.EqualFold(part.MIMEType, MIMETypePDF):
+		block = anthropic.NewDocumentBlock(anthropic.URLPDFSourceParam{
+			URL: part.URI,
+		})
+	case len(part.Data) > 0:
+		return anthropic.NewTextBlock(documentText(part))
+	default:
+		return anthropic.NewTextBlock(fmt.Sprintf("[file %s]", part.URI))
+	}
+
+	if part.Name != "" {
+		block.OfDocument.Title = anthropic.String(part.Name)
+	}
+	if part.Citations {
+		block.OfDocument.Citations = anthropic.CitationsConfigParam{Enabled: anthropic.Bool(true)}
+	}
+	return block
+}
+
+// anthropicToolResultBlock builds a tool result block, carrying image parts
+// as native image content
+func anthropicToolResultBlock(toolCallID string, msg Message, isError bool) anthropic.ContentBlockParamUnion {
+	if len(msg.Parts) == 0 {
+		return anthropic.NewToolResultBlock(toolCallID, msg.Content, isError)
+	}
+
+	content := make([]anthropic.ToolResultBlockParamContentUnion, 0, len(msg.Parts))
+	for _, part := range msg.Parts {
+		switch {
+		case part.Type == ContentPartImage && len(part.Data) > 0:
+			content = append(content, anthropic.ToolResultBlockParamContentUnion{
+				OfImage: &anthropic.ImageBlockParam{
+					Source: anthropic.ImageBlockParamSourceUnion{
+						OfBase64: &anthropic.Base64ImageSourceParam{
+							Data:      base64.StdEncoding.EncodeToString(part.Data),
+							MediaType: anthropic.Base64ImageSourceMediaType(part.MIMEType),
+						},
+					},
+				},
+			})
+		case part.Type == ContentPartImage && part.URI != "":
+			content = append(content, anthropic.ToolResultBlockParamContentUnion{
+				OfImage: &anthropic.ImageBlockParam{
+					Source: anthropic.ImageBlockParamSourceUnion{
+						OfURL: &anthropic.URLImageSourceParam{URL: part.URI},
+					},
+				},
+			})
+		case part.Type == ContentPartFile:
+			content = append(content, anthropic.ToolResultBlockParamContentUnion{
+				OfText: &anthropic.TextBlockParam{Text: fmt.Sprintf("[file %s]", part.URI)},
+			})
+		default:
+			content = append(content, anthropic.ToolResultBlockParamContentUnion{
+				OfText: &anthropic.TextBlockParam{Text: part.Text},
+			})
+		}
+	}
+
+	return anthropic.ContentBlockParamUnion{
+		OfToolResult: &anthropic.ToolResultBlockParam{
+			ToolUseID: toolCallID,
+			Content:   content,
+			IsError:   anthropic.Bool(isError),
+		},
+	}
+}
+
+// Capabilities implements CapabilityProvider for Anthropic
+func (p *AnthropicProvider) Capabilities() Capabilities {
+	return Capabilities{
+		Tools:            true,
+		Streaming:        true,
+		Vision:           true,
+		Documents:        true,
+		StructuredOutput: false,
+		MaxContextTokens: 200000,
+		ComputerUse:      true,
+	}
+}
+
+// Ping implements Pinger for Anthropic by listing a single model
+func (p *AnthropicProvider) Ping(ctx context.Context) error {
+	if _, err := p.client.Models.List(ctx, anthropic.ModelListParams{Limit: anthropic.Int(1)}); err != nil {
+		return anthropicError("ping", err)
+	}
+	return nil
+}
+
+// ListModels implements ModelLister for Anthropic
+func (p *AnthropicProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	var models []ModelInfo
+
+	iter := p.client.Models.ListAutoPaging(ctx, anthropic.ModelListParams{})
+	for iter.Next() {
+		model := iter.Current()
+		models = append(models, withKnownLimits(ModelInfo{
+			ID:          model.ID,
+			DisplayName: model.DisplayName,
+			Created:     model.CreatedAt,
+		}))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, anthropicError("list_models", err)
+	}
+
+	return models, nil
+}
+
+// anthropicError converts an Anthropic SDK error into a ProviderError
+func anthropicError(op string, err error) error {
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return NewProviderError(ProviderTypeAnthropic.String(), op, err)
+	}
+
+	// The body looks like {"type":"error","error":{"type":"...","message":"..."}}
+	var body struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal([]byte(apiErr.RawJSON()), &body)
+
+	return newAPIError(ProviderTypeAnthropic.String(), op, apiErr.StatusCode, body.Error.Type, body.Error.Message, apiErr.RequestID, err)
+}
@@ -0,0 +1,45 @@
+package providers
+
+import (
+	"context"
+	"time"
+)
+
+// BatchRequest is a single conversation submitted to a provider batch API
+type BatchRequest struct {
+	Messages []Message
+}
+
+// BatchResponse is the outcome of one request in a provider batch
+type BatchResponse struct {
+	Completion *Completion
+	Err        error
+}
+
+// BatchProvider is implemented by providers with an asynchronous batch API.
+// Batches trade latency (up to 24 hours) for a lower per-token price.
+type BatchProvider interface {
+	// CompleteBatch submits all requests as one batch and waits for results.
+	// Responses are returned in request order.
+	CompleteBatch(ctx context.Context, agent Agent, requests []BatchRequest, tools []ToolDefinition) ([]BatchResponse, error)
+}
+
+// defaultBatchPollInterval is how often batch status is checked
+const defaultBatchPollInterval = 30 * time.Second
+
+// waitForBatch sleeps for the poll interval or until the context is done
+func waitForBatch(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultBatchPollInterval
+	}
+
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
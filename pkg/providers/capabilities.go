@@ -0,0 +1,62 @@
+package providers
+
+// Capabilities describes the features a provider supports
+type Capabilities struct {
+	// Tools reports native function calling support
+	Tools bool `json:"tools"`
+
+	// Streaming reports incremental response support, i.e. that the
+	// provider implements ToolCallStreamer
+	Streaming bool `json:"streaming"`
+
+	// Vision reports image input support
+	Vision bool `json:"vision"`
+
+	// Documents reports native document (e.g. PDF) input support
+	Documents bool `json:"documents"`
+
+	// StructuredOutput reports schema-constrained output support; without
+	// it an agent's output type is parsed from text
+	StructuredOutput bool `json:"structured_output"`
+
+	// MaxContextTokens is the largest context window offered by the
+	// provider's current models; individual models may be smaller
+	MaxContextTokens int `json:"max_context_tokens"`
+
+	// HostedTools lists the provider-executed tool types available
+	HostedTools []string `json:"hosted_tools,omitempty"`
+
+	// ComputerUse reports native computer-use tool support
+	ComputerUse bool `json:"computer_use,omitempty"`
+}
+
+// SupportsHostedTool reports whether the hosted tool type is available
+func (c Capabilities) SupportsHostedTool(hostedType string) bool {
+	for _, t := range c.HostedTools {
+		if t == hostedType {
+			return true
+		}
+	}
+	return false
+}
+
+// CapabilityProvider is implemented by providers that report their features
+type CapabilityProvider interface {
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf returns the provider's declared capabilities. Providers that
+// don't implement CapabilityProvider are assumed to support tools and vision
+// so that existing integrations keep working, and streaming when they
+// implement ToolCallStreamer.
+func CapabilitiesOf(provider Provider) Capabilities {
+	if cp, ok := provider.(CapabilityProvider); ok {
+		return cp.Capabilities()
+	}
+	_, streams := provider.(ToolCallStreamer)
+	return Capabilities{
+		Tools:     true,
+		Streaming: streams,
+		Vision:    true,
+	}
+}
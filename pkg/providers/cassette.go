@@ -0,0 +1,251 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ErrCassetteMiss is returned by a ReplayProvider when no recorded
+// interaction matches a request
+var ErrCassetteMiss = errors.New("no recorded completion for request")
+
+// cassetteVersion is bumped when the cassette format changes incompatibly
+const cassetteVersion = 1
+
+// Cassette is the on-disk format shared by RecordingProvider and ReplayProvider
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and the completion it produced
+type Interaction struct {
+	Key        string          `json:"key"`
+	Request    CassetteRequest `json:"request"`
+	Completion json.RawMessage `json:"completion"`
+}
+
+// CassetteRequest is the part of a completion request used for matching.
+// Timestamps are left out so recordings replay regardless of when they run.
+type CassetteRequest struct {
+	Agent        string            `json:"agent"`
+	Model        string            `json:"model"`
+	Instructions string            `json:"instructions,omitempty"`
+	Messages     []CassetteMessage `json:"messages"`
+	Tools        []string          `json:"tools,omitempty"`
+}
+
+// CassetteMessage is a request message as recorded
+type CassetteMessage struct {
+	Role      string                 `json:"role"`
+	Content   string                 `json:"content"`
+	Parts     []ContentPart          `json:"parts,omitempty"`
+	ToolCalls []ToolCall             `json:"tool_calls,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// newCassetteRequest captures the matching fields of a request and its key
+func newCassetteRequest(agent Agent, messages []Message, tools []ToolDefinition) (CassetteRequest, string, error) {
+	req := CassetteRequest{
+		Agent:        agent.GetName(),
+		Model:        agent.GetModel(),
+		Instructions: agent.GetInstructions(),
+		Messages:     make([]CassetteMessage, len(messages)),
+	}
+
+	for i, msg := range messages {
+		req.Messages[i] = CassetteMessage{
+			Role:      msg.Role,
+			Content:   msg.Content,
+			Parts:     msg.Parts,
+			ToolCalls: msg.ToolCalls,
+			Metadata:  msg.Metadata,
+		}
+	}
+
+	for _, tool := range tools {
+		req.Tools = append(req.Tools, tool.Name)
+	}
+
+	// encoding/json sorts map keys, so equal requests encode identically
+	data, err := json.Marshal(req)
+	if err != nil {
+		return req, "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+
+	return req, hex.EncodeToString(sum[:]), nil
+}
+
+// RecordingProvider passes requests to another provider and writes every
+// completion to a cassette file for later replay
+type RecordingProvider struct {
+	inner Provider
+	path  string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecordingProvider records the completions of inner to cassettePath,
+// replacing any existing cassette
+func NewRecordingProvider(inner Provider, cassettePath string) (*RecordingProvider, error) {
+	p := &RecordingProvider{
+		inner:    inner,
+		path:     cassettePath,
+		cassette: Cassette{Version: cassetteVersion},
+	}
+
+	if err := p.save(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Complete implements the Provider interface
+func (p *RecordingProvider) Complete(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (*Completion, error) {
+	req, key, err := newCassetteRequest(agent, messages, tools)
+	if err != nil {
+		return nil, err
+	}
+
+	completion, err := p.inner.Complete(ctx, agent, messages, tools)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(completion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode completion: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.cassette.Interactions = append(p.cassette.Interactions, Interaction{
+		Key:        key,
+		Request:    req,
+		Completion: data,
+	})
+	if err := p.save(); err != nil {
+		return nil, err
+	}
+
+	return completion, nil
+}
+
+// Capabilities reports the capabilities of the recorded provider
+func (p *RecordingProvider) Capabilities() Capabilities {
+	caps := CapabilitiesOf(p.inner)
+	// Completions pass through Complete, so they aren't streamed
+	caps.Streaming = false
+	return caps
+}
+
+// Ping implements Pinger for the recorded provider; pings aren't recorded
+func (p *RecordingProvider) Ping(ctx context.Context) error {
+	return Ping(ctx, p.inner)
+}
+
+// ListModels implements ModelLister for the recorded provider
+func (p *RecordingProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return listModels(ctx, p.inner)
+}
+
+// save writes the cassette; callers other than the constructor hold p.mu
+func (p *RecordingProvider) save() error {
+	data, err := json.MarshalIndent(p.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if err := os.WriteFile(p.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// LoadCassette reads a cassette written by RecordingProvider
+func LoadCassette(cassettePath string) (*Cassette, error) {
+	data, err := os.ReadFile(cassettePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("invalid cassette: %w", err)
+	}
+	if cassette.Version != cassetteVersion {
+		return nil, fmt.Errorf("unsupported cassette version: %d", cassette.Version)
+	}
+	return &cassette, nil
+}
+
+// ReplayProvider serves completions from a cassette without calling any API.
+// Requests are matched on agent, model, instructions, messages and tool
+// names; identical requests replay their recordings in order.
+type ReplayProvider struct {
+	mu      sync.Mutex
+	pending map[string][]json.RawMessage
+}
+
+// NewReplayProvider loads the cassette at cassettePath
+func NewReplayProvider(cassettePath string) (*ReplayProvider, error) {
+	cassette, err := LoadCassette(cassettePath)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ReplayProvider{pending: make(map[string][]json.RawMessage)}
+	for _, interaction := range cassette.Interactions {
+		p.pending[interaction.Key] = append(p.pending[interaction.Key], interaction.Completion)
+	}
+
+	return p, nil
+}
+
+// Complete implements the Provider interface
+func (p *ReplayProvider) Complete(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (*Completion, error) {
+	_, key, err := newCassetteRequest(agent, messages, tools)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	queue := p.pending[key]
+	if len(queue) == 0 {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w (agent %s, model %s, %d messages)", ErrCassetteMiss, agent.GetName(), agent.GetModel(), len(messages))
+	}
+	data := queue[0]
+	p.pending[key] = queue[1:]
+	p.mu.Unlock()
+
+	var completion Completion
+	if err := json.Unmarshal(data, &completion); err != nil {
+		return nil, fmt.Errorf("invalid recorded completion: %w", err)
+	}
+
+	return &completion, nil
+}
+
+// Remaining returns how many recorded completions have not been replayed,
+// useful for asserting a test exercised the whole cassette
+func (p *ReplayProvider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := 0
+	for _, queue := range p.pending {
+		n += len(queue)
+	}
+	return n
+}
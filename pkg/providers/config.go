@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"log/slog"
 	"os"
 	"time"
 )
@@ -23,6 +24,9 @@ func (p ProviderType) String() string {
 	case ProviderTypeGemini:
 		return "gemini"
 	default:
+		if registered, ok := registeredFactory(p); ok {
+			return registered.name
+		}
 		return "unknown"
 	}
 }
@@ -32,17 +36,49 @@ type ProviderConfig struct {
 	// APIKey for the provider service
 	APIKey string
 
+	// APIKeySecret names the secret holding the API key; the ProviderFactory
+	// resolves it from its SecretsSource
+	APIKeySecret string
+
 	// BaseURL for custom API endpoints (optional)
 	BaseURL string
 
-	// Timeout for API requests
+	// Timeout for API requests. It bounds each attempt, so a call that is
+	// retried can take up to (MaxRetries+1)*Timeout plus backoff; the
+	// runner's WithTimeout still bounds the whole run.
 	Timeout time.Duration
 
-	// MaxRetries for failed requests
+	// MaxRetries for rate-limited, overloaded or unavailable requests
 	MaxRetries int
 
-	// Debug enables detailed logging
-	Debug bool
+	// Debug logs every request and response at debug level. API keys, auth
+	// headers and personal data are redacted, as are the values of any JSON
+	// fields named in RedactFields (e.g. "content" to hide prompts).
+	Debug        bool
+	Logger       *slog.Logger
+	RedactFields []string
+
+	// BatchPollInterval controls how often batch API jobs are polled
+	BatchPollInterval time.Duration
+
+	// EmbeddingModel is the model used by Embed (optional)
+	EmbeddingModel string
+
+	// Headers, Metadata and User are attached to every request; see
+	// RequestOptions. Options set on the request context take precedence.
+	Headers  map[string]string
+	Metadata map[string]string
+	User     string
+
+	// Features toggles experimental features per request (optional)
+	Features FeatureFlags
+}
+
+// Base returns the config itself. Provider configs embedding ProviderConfig
+// inherit it, which lets the common ProviderOptions such as WithAPIKey and
+// WithBaseURL apply to them.
+func (c *ProviderConfig) Base() *ProviderConfig {
+	return c
 }
 
 // OpenAIConfig holds OpenAI-specific configuration
@@ -63,7 +99,8 @@ type AnthropicConfig struct {
 	// Version specifies the API version (optional)
 	Version string
 	
-	// Beta features to enable (optional)
+	// Beta features to enable on every request (optional); see
+	// ProviderConfig.Features for toggling them at runtime
 	Beta []string
 }
 
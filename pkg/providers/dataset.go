@@ -0,0 +1,286 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// DatasetExample is one line of an OpenAI fine-tuning dataset: a
+// conversation ending in the assistant's reply, with the tools it could call
+type DatasetExample struct {
+	Messages []DatasetMessage `json:"messages"`
+	Tools    []DatasetTool    `json:"tools,omitempty"`
+
+	// Agent and Model identify the recorded request for filters; they are
+	// not part of the dataset format
+	Agent string `json:"-"`
+	Model string `json:"-"`
+}
+
+// DatasetMessage is a message in the fine-tuning chat format
+type DatasetMessage struct {
+	Role       string            `json:"role"`
+	Content    *string           `json:"content"`
+	ToolCalls  []DatasetToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string            `json:"tool_call_id,omitempty"`
+}
+
+// DatasetToolCall is a function call of an assistant message
+type DatasetToolCall struct {
+	ID       string              `json:"id"`
+	Type     string              `json:"type"`
+	Function DatasetFunctionCall `json:"function"`
+}
+
+// DatasetFunctionCall names the called function and its JSON arguments
+type DatasetFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// DatasetTool declares a function the assistant could call
+type DatasetTool struct {
+	Type     string          `json:"type"`
+	Function DatasetFunction `json:"function"`
+}
+
+// DatasetFunction describes a function by name and JSON schema
+type DatasetFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  ParameterSchema `json:"parameters"`
+}
+
+// DatasetRecorder passes requests to another provider and appends each
+// completed request and its reply to a dataset in OpenAI's fine-tuning
+// JSONL format, so production traffic can seed fine-tuning. Only text is
+// recorded; images and files are left out.
+//
+// Text is redacted with RedactSecrets unless WithDatasetRedactor replaces
+// it. Failing to write an example is logged; the completion is still
+// returned.
+type DatasetRecorder struct {
+	inner  Provider
+	redact func(string) string
+	filter func(*DatasetExample) bool
+	logger *slog.Logger
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// DatasetOption configures a DatasetRecorder
+type DatasetOption func(*DatasetRecorder)
+
+// WithDatasetRedactor sets the function applied to message contents and the
+// strings in tool-call arguments before an example is written (default
+// RedactSecrets). nil disables redaction.
+func WithDatasetRedactor(redact func(string) string) DatasetOption {
+	return func(r *DatasetRecorder) {
+		r.redact = redact
+	}
+}
+
+// WithDatasetFilter sets a hook that sees each redacted example before it is
+// written. It may edit the example, or return false to drop it, e.g. for
+// tenants that haven't consented to their data being used.
+func WithDatasetFilter(filter func(*DatasetExample) bool) DatasetOption {
+	return func(r *DatasetRecorder) {
+		r.filter = filter
+	}
+}
+
+// WithDatasetLogger sets the logger for examples that can't be written
+func WithDatasetLogger(logger *slog.Logger) DatasetOption {
+	return func(r *DatasetRecorder) {
+		r.logger = logger
+	}
+}
+
+// NewDatasetRecorder records the completions of inner to w, one JSON
+// example per line
+func NewDatasetRecorder(inner Provider, w io.Writer, opts ...DatasetOption) *DatasetRecorder {
+	r := &DatasetRecorder{
+		inner:  inner,
+		redact: RedactSecrets,
+		logger: slog.Default(),
+		w:      w,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.redact == nil {
+		r.redact = func(text string) string { return text }
+	}
+	return r
+}
+
+// NewDatasetFile records the completions of inner to the dataset at path,
+// appending to it if it exists. Close the returned file when done.
+func NewDatasetFile(inner Provider, path string, opts ...DatasetOption) (*DatasetRecorder, *os.File, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	return NewDatasetRecorder(inner, file, opts...), file, nil
+}
+
+// Complete implements the Provider interface
+func (r *DatasetRecorder) Complete(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (*Completion, error) {
+	completion, err := r.inner.Complete(ctx, agent, messages, tools)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.record(agent, messages, tools, completion); err != nil {
+		r.logger.Warn("failed to record dataset example", "agent", agent.GetName(), "error", err)
+	}
+	return completion, nil
+}
+
+// Capabilities reports the capabilities of the recorded provider
+func (r *DatasetRecorder) Capabilities() Capabilities {
+	caps := CapabilitiesOf(r.inner)
+	// Completions pass through Complete, so they aren't streamed
+	caps.Streaming = false
+	return caps
+}
+
+// Ping implements Pinger for the recorded provider; pings aren't recorded
+func (r *DatasetRecorder) Ping(ctx context.Context) error {
+	return Ping(ctx, r.inner)
+}
+
+// ListModels implements ModelLister for the recorded provider
+func (r *DatasetRecorder) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return listModels(ctx, r.inner)
+}
+
+// record writes the example of one completed request
+func (r *DatasetRecorder) record(agent Agent, messages []Message, tools []ToolDefinition, completion *Completion) error {
+	example := &DatasetExample{Agent: agent.GetName(), Model: agent.GetModel()}
+
+	if instructions := instructionsFor(agent, InstructionsMarkdown); instructions != "" {
+		example.Messages = append(example.Messages, r.message("system", instructions))
+	}
+	for _, msg := range messages {
+		if isHostedMessage(msg) {
+			continue
+		}
+		example.Messages = append(example.Messages, r.datasetMessage(msg))
+	}
+
+	reply := completion.Message
+	if len(reply.ToolCalls) == 0 {
+		reply.ToolCalls = completion.ToolCalls
+	}
+	reply.Role = "assistant"
+	example.Messages = append(example.Messages, r.datasetMessage(reply))
+
+	for _, tool := range tools {
+		// Hosted and computer-use tools aren't functions
+		if tool.Hosted != "" || tool.Display != nil {
+			continue
+		}
+		parameters := tool.Schema
+		if parameters.Properties == nil {
+			parameters.Properties = make(map[string]PropertySchema)
+		}
+		if parameters.Required == nil {
+			parameters.Required = []string{}
+		}
+		example.Tools = append(example.Tools, DatasetTool{
+			Type: "function",
+			Function: DatasetFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  parameters,
+			},
+		})
+	}
+
+	if r.filter != nil && !r.filter(example) {
+		return nil
+	}
+
+	line, err := json.Marshal(example)
+	if err != nil {
+		return fmt.Errorf("failed to encode dataset example: %w", err)
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.w.Write(line); err != nil {
+		return fmt.Errorf("failed to write dataset example: %w", err)
+	}
+	return nil
+}
+
+// datasetMessage converts a conversation message, redacting its text
+func (r *DatasetRecorder) datasetMessage(msg Message) DatasetMessage {
+	role := msg.Role
+	if role == "developer" {
+		role = "system"
+	}
+	out := r.message(role, msg.Content)
+
+	switch role {
+	case "assistant":
+		if msg.Content == "" && len(msg.ToolCalls) > 0 {
+			out.Content = nil
+		}
+		for _, call := range msg.ToolCalls {
+			arguments, err := json.Marshal(r.redactValue(call.Arguments))
+			if err != nil || call.Arguments == nil {
+				arguments = []byte("{}")
+			}
+			out.ToolCalls = append(out.ToolCalls, DatasetToolCall{
+				ID:   call.ID,
+				Type: "function",
+				Function: DatasetFunctionCall{
+					Name:      call.Name,
+					Arguments: string(arguments),
+				},
+			})
+		}
+	case "tool":
+		out.ToolCallID, _ = msg.Metadata[MetadataToolCallID].(string)
+	}
+	return out
+}
+
+// message creates a message with redacted content
+func (r *DatasetRecorder) message(role, content string) DatasetMessage {
+	content = r.redact(content)
+	return DatasetMessage{Role: role, Content: &content}
+}
+
+// redactValue redacts the strings in decoded JSON, leaving its structure
+// intact
+func (r *DatasetRecorder) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.redact(v)
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			redacted[key] = r.redactValue(item)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactValue(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
@@ -0,0 +1,234 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// redacted replaces secret values in debug logs
+const redacted = "[REDACTED]"
+
+// maxLoggedBody caps the size of bodies written to debug logs
+const maxLoggedBody = 64 << 10
+
+// sensitiveHeaders carry credentials and are never logged
+var sensitiveHeaders = map[string]bool{
+	"authorization":        true,
+	"proxy-authorization":  true,
+	"x-api-key":            true,
+	"api-key":              true,
+	"x-goog-api-key":       true,
+	"cookie":               true,
+	"set-cookie":           true,
+	"openai-organization":  true,
+	"x-amz-security-token": true,
+}
+
+// secretPatterns match API keys and personal data in free text
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-(?:ant-|proj-)?[A-Za-z0-9_\-]{16,}`),
+	regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`),
+	regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._\-]{16,}`),
+	regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+}
+
+// cardPattern matches candidate payment card numbers, which are redacted
+// only when they pass the Luhn check and aren't part of a decimal number
+var cardPattern = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+
+// RedactSecrets replaces API keys, bearer tokens, email addresses, US social
+// security numbers and payment card numbers in text
+func RedactSecrets(text string) string {
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllString(text, redacted)
+	}
+
+	matches := cardPattern.FindAllStringIndex(text, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		start, end := matches[i][0], matches[i][1]
+		if start > 0 && text[start-1] == '.' || end < len(text) && text[end] == '.' {
+			continue
+		}
+		if luhnValid(text[start:end]) {
+			text = text[:start] + redacted + text[end:]
+		}
+	}
+	return text
+}
+
+// luhnValid reports whether the digits in number pass the Luhn checksum
+func luhnValid(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// debugTransport logs requests and responses with secrets redacted
+type debugTransport struct {
+	base   http.RoundTripper
+	logger *slog.Logger
+	fields map[string]bool
+}
+
+// debugHTTPClient returns an HTTP client that logs traffic when config.Debug
+// is set, or nil when debugging is off
+func debugHTTPClient(config *ProviderConfig, provider string) *http.Client {
+	if !config.Debug {
+		return nil
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+
+	fields := make(map[string]bool, len(config.RedactFields))
+	for _, field := range config.RedactFields {
+		fields[field] = true
+	}
+
+	return &http.Client{
+		Transport: &debugTransport{
+			base:   http.DefaultTransport,
+			logger: logger.With("provider", provider),
+			fields: fields,
+		},
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		reqBody, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	t.logger.Debug("provider request",
+		"method", req.Method,
+		"url", redactURL(req),
+		"headers", redactHeaders(req.Header),
+		"body", t.redactBody(reqBody),
+	)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.logger.Debug("provider request failed", "error", RedactSecrets(err.Error()), "duration", time.Since(start))
+		return nil, err
+	}
+
+	// Streams are passed through untouched
+	body := "(stream)"
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		respBody, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+		if readErr != nil {
+			return nil, readErr
+		}
+		body = t.redactBody(respBody)
+	}
+
+	t.logger.Debug("provider response",
+		"status", resp.StatusCode,
+		"duration", time.Since(start),
+		"headers", redactHeaders(resp.Header),
+		"body", body,
+	)
+
+	return resp, nil
+}
+
+// redactBody redacts the configured JSON fields and any secrets in body
+func (t *debugTransport) redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if len(t.fields) > 0 {
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err == nil {
+			if data, err := json.Marshal(redactFields(value, t.fields)); err == nil {
+				body = data
+			}
+		}
+	}
+
+	text := RedactSecrets(string(body))
+	if len(text) > maxLoggedBody {
+		text = text[:maxLoggedBody] + "...(truncated)"
+	}
+	return text
+}
+
+// redactFields replaces the values of the named fields at any depth
+func redactFields(value interface{}, fields map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if fields[key] {
+				v[key] = redacted
+			} else {
+				v[key] = redactFields(item, fields)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactFields(item, fields)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// redactHeaders returns the headers with credentials removed
+func redactHeaders(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+	for name, values := range header {
+		if sensitiveHeaders[strings.ToLower(name)] {
+			result[name] = redacted
+			continue
+		}
+		result[name] = RedactSecrets(strings.Join(values, ", "))
+	}
+	return result
+}
+
+// redactURL returns the request URL with key query parameters removed
+func redactURL(req *http.Request) string {
+	u := *req.URL
+	query := u.Query()
+	for name := range query {
+		if lower := strings.ToLower(name); lower == "key" || strings.Contains(lower, "token") {
+			query.Set(name, redacted)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
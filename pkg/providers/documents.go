@@ -0,0 +1,131 @@
+package providers
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MIMETypePDF is the media type of PDF documents
+const MIMETypePDF = "application/pdf"
+
+// IsTextMIMEType reports whether the media type holds plain, readable text
+func IsTextMIMEType(mimeType string) bool {
+	mimeType = strings.ToLower(strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0]))
+	if strings.HasPrefix(mimeType, "text/") {
+		return true
+	}
+	switch mimeType {
+	case "application/json", "application/xml", "application/x-yaml", "application/yaml":
+		return true
+	}
+	return false
+}
+
+// ExtractText produces a text rendering of a file part for providers that
+// cannot consume the file natively. PDF extraction is best-effort and only
+// recovers text drawn with simple string operators.
+func ExtractText(part ContentPart) (string, error) {
+	switch {
+	case IsTextMIMEType(part.MIMEType):
+		if !utf8.Valid(part.Data) {
+			return "", fmt.Errorf("file %s is not valid UTF-8", part.Name)
+		}
+		return string(part.Data), nil
+	case strings.EqualFold(part.MIMEType, MIMETypePDF):
+		return extractPDFText(part.Data)
+	default:
+		return "", fmt.Errorf("cannot extract text from %s", part.MIMEType)
+	}
+}
+
+// documentText renders a file part as inline text, wrapped with its name so
+// the model can tell attachments apart
+func documentText(part ContentPart) string {
+	text, err := ExtractText(part)
+	if err != nil {
+		return fmt.Sprintf("[attachment %s could not be read: %v]", part.Name, err)
+	}
+	return fmt.Sprintf("<document name=%q>\n%s\n</document>", part.Name, text)
+}
+
+var (
+	pdfStreamPattern = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n(.*?)\r?\nendstream`)
+	pdfTextPattern   = regexp.MustCompile(`(?s)\[(.*?)\]\s*TJ|\((.*?)\)\s*(?:Tj|'|")`)
+	pdfStringPattern = regexp.MustCompile(`\(((?:\\.|[^\\)])*)\)`)
+)
+
+// extractPDFText pulls text-showing operands out of the document's content
+// streams, inflating FlateDecode streams along the way
+func extractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		return "", fmt.Errorf("not a PDF document")
+	}
+
+	var out strings.Builder
+	for _, match := range pdfStreamPattern.FindAllSubmatch(data, -1) {
+		dict, stream := match[1], match[2]
+
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			r, err := zlib.NewReader(bytes.NewReader(stream))
+			if err != nil {
+				continue
+			}
+			inflated, err := io.ReadAll(r)
+			r.Close()
+			if err != nil && len(inflated) == 0 {
+				continue
+			}
+			stream = inflated
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			// Other filters (images, fonts) carry no extractable text
+			continue
+		}
+
+		for _, op := range pdfTextPattern.FindAllSubmatch(stream, -1) {
+			if op[1] != nil {
+				for _, str := range pdfStringPattern.FindAllSubmatch(op[1], -1) {
+					out.WriteString(unescapePDFString(str[1]))
+				}
+			} else {
+				out.WriteString(unescapePDFString(op[2]))
+			}
+			out.WriteString(" ")
+		}
+		out.WriteString("\n")
+	}
+
+	text := strings.TrimSpace(out.String())
+	if text == "" {
+		return "", fmt.Errorf("no extractable text found in PDF")
+	}
+	return text, nil
+}
+
+// unescapePDFString resolves the backslash escapes of a PDF literal string
+func unescapePDFString(s []byte) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			out.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			out.WriteByte('\n')
+		case 'r':
+			out.WriteByte('\r')
+		case 't':
+			out.WriteByte('\t')
+		case 'b', 'f':
+		default:
+			out.WriteByte(s[i])
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,15 @@
+package providers
+
+import "context"
+
+// Embedder turns text into vectors for semantic search and retrieval
+type Embedder interface {
+	// Embed returns one vector per input text, in input order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Default embedding models, used when ProviderConfig.EmbeddingModel is empty
+const (
+	DefaultOpenAIEmbeddingModel = "text-embedding-3-small"
+	DefaultGeminiEmbeddingModel = "gemini-embedding-001"
+)
@@ -3,6 +3,7 @@ package providers
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // Provider configuration errors
@@ -21,17 +22,38 @@ var (
 	ErrContextTooLong      = errors.New("context length exceeds model limit")
 	ErrInvalidToolCall     = errors.New("invalid tool call format")
 	ErrToolNotFound        = errors.New("requested tool not found")
+	ErrAuthentication      = errors.New("authentication failed")
+	ErrPermissionDenied    = errors.New("permission denied")
+	ErrInvalidRequest      = errors.New("invalid request")
 )
 
-// ProviderError wraps provider-specific errors with context
+// ProviderError wraps provider-specific errors with context. For API errors
+// Err wraps both one of the errors above and the SDK error, so callers can
+// use errors.Is for the category and errors.As for the SDK details.
 type ProviderError struct {
 	Provider string
 	Op       string
 	Err      error
+
+	// StatusCode is the HTTP status of a failed API call
+	StatusCode int
+
+	// Code is the provider's error code or type, such as
+	// "context_length_exceeded" or "overloaded_error"
+	Code string
+
+	// RequestID identifies the request for provider support
+	RequestID string
 }
 
 func (e *ProviderError) Error() string {
-	return fmt.Sprintf("%s provider error in %s: %v", e.Provider, e.Op, e.Err)
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s provider error in %s: %v", e.Provider, e.Op, e.Err)
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s provider error in %s (status %d, request %s): %v", e.Provider, e.Op, e.StatusCode, e.RequestID, e.Err)
+	}
+	return fmt.Sprintf("%s provider error in %s (status %d): %v", e.Provider, e.Op, e.StatusCode, e.Err)
 }
 
 func (e *ProviderError) Unwrap() error {
@@ -47,6 +69,44 @@ func NewProviderError(provider, operation string, err error) error {
 	}
 }
 
+// newAPIError builds a ProviderError for a failed API call, classifying it
+// by status code and the provider's error code and message
+func newAPIError(provider, op string, status int, code, message, requestID string, err error) error {
+	return &ProviderError{
+		Provider:   provider,
+		Op:         op,
+		Err:        fmt.Errorf("%w: %w", classifyAPIError(status, code, message), err),
+		StatusCode: status,
+		Code:       code,
+		RequestID:  requestID,
+	}
+}
+
+// classifyAPIError maps an API failure to one of the provider errors
+func classifyAPIError(status int, code, message string) error {
+	lower := strings.ToLower(code + " " + message)
+	for _, marker := range contextTooLongMarkers {
+		if strings.Contains(lower, marker) {
+			return ErrContextTooLong
+		}
+	}
+
+	switch {
+	case status == 401:
+		return ErrAuthentication
+	case status == 403:
+		return ErrPermissionDenied
+	case status == 404:
+		return ErrInvalidModel
+	case status == 429:
+		return ErrRateLimited
+	case status == 408 || status >= 500 || strings.Contains(lower, "overloaded"):
+		return ErrProviderUnavailable
+	default:
+		return ErrInvalidRequest
+	}
+}
+
 // IsRateLimitError checks if an error is a rate limit error
 func IsRateLimitError(err error) bool {
 	var provErr *ProviderError
@@ -56,20 +116,37 @@ func IsRateLimitError(err error) bool {
 	return errors.Is(err, ErrRateLimited)
 }
 
-// IsTemporaryError checks if an error is temporary and retryable
-func IsTemporaryError(err error) bool {
-	var provErr *ProviderError
-	if errors.As(err, &provErr) {
-		switch provErr.Err {
-		case ErrProviderUnavailable, ErrRateLimited:
-			return true
-		}
+// IsContextTooLongError checks if an error reports that the prompt exceeded
+// the model's context window
+func IsContextTooLongError(err error) bool {
+	if err == nil {
+		return false
 	}
-	
-	switch err {
-	case ErrProviderUnavailable, ErrRateLimited:
+	if errors.Is(err, ErrContextTooLong) {
 		return true
 	}
-	
+
+	// Fall back to the messages providers use for overflow
+	msg := strings.ToLower(err.Error())
+	for _, marker := range contextTooLongMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
 	return false
-}
\ No newline at end of file
+}
+
+// contextTooLongMarkers are substrings of provider context overflow errors
+var contextTooLongMarkers = []string{
+	"context_length_exceeded",
+	"maximum context length",
+	"prompt is too long",
+	"input is too long",
+	"exceeds the maximum number of tokens",
+	"context window",
+}
+
+// IsTemporaryError checks if an error is temporary and retryable
+func IsTemporaryError(err error) bool {
+	return errors.Is(err, ErrProviderUnavailable) || errors.Is(err, ErrRateLimited)
+}
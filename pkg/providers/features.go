@@ -0,0 +1,176 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FeatureEnvPrefix prefixes the environment variables read by EnvFeatures
+// when no other prefix is given, e.g. AGENTS_FEATURES_ANTHROPIC
+const FeatureEnvPrefix = "AGENTS_FEATURES_"
+
+// FeatureFlags decides which experimental provider features are on. It is
+// consulted on every request, so flags backed by a config service take
+// effect without a restart.
+//
+// Anthropic features are beta names sent in the anthropic-beta header, e.g.
+// "interleaved-thinking-2025-05-14". OpenAI features are request fields
+// the SDK doesn't know yet, set from their value: "service_tier=flex".
+// Values are parsed as JSON when they can be and sent as strings otherwise;
+// a feature without a value is sent as true. Gemini takes no features.
+type FeatureFlags interface {
+	// Features returns the enabled features of the named provider
+	// ("openai", "anthropic") with their values, "" for plain toggles
+	Features(ctx context.Context, provider string) map[string]string
+}
+
+// ParseFeatures parses a comma-separated feature list such as
+// "service_tier=flex,store" into names and values
+func ParseFeatures(spec string) (map[string]string, error) {
+	features := make(map[string]string)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid feature %q: missing name", item)
+		}
+		features[name] = strings.TrimSpace(value)
+	}
+	return features, nil
+}
+
+// EnvFeatures reads each provider's features from an environment variable
+// named prefix plus the upper-cased provider name, AGENTS_FEATURES_OPENAI by
+// default, in the format of ParseFeatures. Invalid entries are ignored.
+func EnvFeatures(prefix string) FeatureFlags {
+	if prefix == "" {
+		prefix = FeatureEnvPrefix
+	}
+	return envFeatures(prefix)
+}
+
+type envFeatures string
+
+// Features implements FeatureFlags
+func (e envFeatures) Features(_ context.Context, provider string) map[string]string {
+	features, _ := ParseFeatures(os.Getenv(string(e) + strings.ToUpper(provider)))
+	return features
+}
+
+// FeatureSet holds feature flags in memory for configuration to update at
+// runtime, e.g. from an admin endpoint or a watched config file. It is safe
+// for concurrent use.
+type FeatureSet struct {
+	mu       sync.RWMutex
+	features map[string]map[string]string
+}
+
+// NewFeatureSet creates an empty feature set
+func NewFeatureSet() *FeatureSet {
+	return &FeatureSet{features: make(map[string]map[string]string)}
+}
+
+// Enable turns a feature of provider on with value, "" for plain toggles
+func (s *FeatureSet) Enable(provider, name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.features[provider] == nil {
+		s.features[provider] = make(map[string]string)
+	}
+	s.features[provider][name] = value
+}
+
+// Disable turns a feature of provider off
+func (s *FeatureSet) Disable(provider, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.features[provider], name)
+}
+
+// Set replaces the features of provider with those in spec, in the format
+// of ParseFeatures
+func (s *FeatureSet) Set(provider, spec string) error {
+	features, err := ParseFeatures(spec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.features[provider] = features
+	return nil
+}
+
+// Features implements FeatureFlags
+func (s *FeatureSet) Features(_ context.Context, provider string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	features := make(map[string]string, len(s.features[provider]))
+	for name, value := range s.features[provider] {
+		features[name] = value
+	}
+	return features
+}
+
+// features returns the enabled features of provider, or nil without flags
+func (c *ProviderConfig) features(ctx context.Context, provider ProviderType) map[string]string {
+	if c.Features == nil {
+		return nil
+	}
+	return c.Features.Features(ctx, provider.String())
+}
+
+// featureNames returns the names of features in order, so requests are
+// built the same way every time
+func featureNames(features map[string]string) []string {
+	names := make([]string, 0, len(features))
+	for name := range features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// featureValue converts a feature value to the JSON value sent for it
+func featureValue(value string) interface{} {
+	if value == "" {
+		return true
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+		return parsed
+	}
+	return value
+}
+
+// WithFeatureFlags turns experimental features of the provider on and off
+// per request; see FeatureFlags
+func WithFeatureFlags(flags FeatureFlags) ProviderOption {
+	return featureFlagsOption{flags}
+}
+
+type featureFlagsOption struct {
+	flags FeatureFlags
+}
+
+func (o featureFlagsOption) Apply(config interface{}) error {
+	c, err := baseConfig(config)
+	if err != nil {
+		return err
+	}
+	c.Features = o.flags
+	return nil
+}
@@ -0,0 +1,47 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+// geminiEmbedBatchSize is the most texts the Gemini API embeds per request
+const geminiEmbedBatchSize = 100
+
+// Embed implements Embedder using Gemini's text embedding models
+func (p *GeminiProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	model := p.config.EmbeddingModel
+	if model == "" {
+		model = DefaultGeminiEmbeddingModel
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += geminiEmbedBatchSize {
+		batch := texts[start:min(start+geminiEmbedBatchSize, len(texts))]
+
+		contents := make([]*genai.Content, len(batch))
+		for i, text := range batch {
+			contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+		}
+
+		resp, err := p.client.Models.EmbedContent(ctx, model, contents, nil)
+		if err != nil {
+			return nil, geminiError("embed", err)
+		}
+		if len(resp.Embeddings) != len(batch) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(batch), len(resp.Embeddings))
+		}
+
+		for _, embedding := range resp.Embeddings {
+			vectors = append(vectors, embedding.Values)
+		}
+	}
+
+	return vectors, nil
+}
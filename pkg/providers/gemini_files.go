@@ -0,0 +1,99 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// GeminiInlineLimit is the largest file sent inline with a Gemini request.
+// Larger images and documents are uploaded through the Files API and
+// referenced by URI; the API rejects requests over 20MB in total.
+const GeminiInlineLimit = 15 << 20
+
+// geminiFilePoll is how often an uploaded file is checked until it has been
+// processed
+const geminiFilePoll = time.Second
+
+// mediaParts converts image and file content parts into Gemini parts.
+// Images, PDFs, and text files are sent inline, or uploaded when larger than
+// GeminiInlineLimit; other documents fall back to locally extracted text.
+func (p *GeminiProvider) mediaParts(ctx context.Context, parts []ContentPart) ([]*genai.Part, error) {
+	var result []*genai.Part
+	for _, part := range parts {
+		if part.Type != ContentPartImage && part.Type != ContentPartFile {
+			continue
+		}
+
+		switch {
+		case len(part.Data) > 0 && (part.Type == ContentPartImage || geminiSupportsDocument(part.MIMEType)):
+			if len(part.Data) <= GeminiInlineLimit || p.client.ClientConfig().Backend != genai.BackendGeminiAPI {
+				result = append(result, genai.NewPartFromBytes(part.Data, part.MIMEType))
+				continue
+			}
+			file, err := p.upload(ctx, part)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, genai.NewPartFromFile(*file))
+		case len(part.Data) > 0:
+			result = append(result, genai.NewPartFromText(documentText(part)))
+		case part.URI != "":
+			result = append(result, genai.NewPartFromURI(part.URI, part.MIMEType))
+		}
+	}
+	return result, nil
+}
+
+// upload stores a file part with the Files API and waits until it can be
+// used, reusing an earlier upload of the same content until it expires
+func (p *GeminiProvider) upload(ctx context.Context, part ContentPart) (*genai.File, error) {
+	sum := sha256.Sum256(part.Data)
+	key := hex.EncodeToString(sum[:]) + "/" + part.MIMEType
+
+	p.uploadsMu.Lock()
+	file, ok := p.uploads[key]
+	p.uploadsMu.Unlock()
+	if ok && (file.ExpirationTime.IsZero() || time.Until(file.ExpirationTime) > time.Hour) {
+		return file, nil
+	}
+
+	file, err := p.client.Files.Upload(ctx, bytes.NewReader(part.Data), &genai.UploadFileConfig{
+		MIMEType:    part.MIMEType,
+		DisplayName: part.Name,
+	})
+	if err != nil {
+		return nil, geminiError("upload", err)
+	}
+
+	for file.State == genai.FileStateProcessing {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(geminiFilePoll):
+		}
+		if file, err = p.client.Files.Get(ctx, file.Name, nil); err != nil {
+			return nil, geminiError("upload", err)
+		}
+	}
+	if file.State == genai.FileStateFailed {
+		message := "processing failed"
+		if file.Error != nil && file.Error.Message != "" {
+			message = file.Error.Message
+		}
+		return nil, NewProviderError(ProviderTypeGemini.String(), "upload", fmt.Errorf("file %s: %s", file.Name, message))
+	}
+
+	p.uploadsMu.Lock()
+	if p.uploads == nil {
+		p.uploads = make(map[string]*genai.File)
+	}
+	p.uploads[key] = file
+	p.uploadsMu.Unlock()
+	return file, nil
+}
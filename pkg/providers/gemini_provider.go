@@ -2,7 +2,10 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"google.golang.org/genai"
@@ -12,6 +15,11 @@ import (
 type GeminiProvider struct {
 	config *GeminiConfig
 	client *genai.Client
+
+	// uploads caches files uploaded to the Files API by content hash, so a
+	// file attached to a run is uploaded once rather than on every turn
+	uploadsMu sync.Mutex
+	uploads   map[string]*genai.File
 }
 
 // NewGeminiProvider creates a new Gemini provider instance
@@ -26,6 +34,12 @@ func NewGeminiProvider(config *GeminiConfig) (*GeminiProvider, error) {
 		Backend: genai.BackendGeminiAPI,
 	}
 
+	// Timeout bounds each attempt; retries are handled by withRetries
+	if config.Timeout > 0 {
+		timeout := config.Timeout
+		clientConfig.HTTPOptions.Timeout = &timeout
+	}
+
 	// Use Vertex AI backend if project ID is provided
 	if config.ProjectID != "" {
 		clientConfig.Project = config.ProjectID
@@ -33,6 +47,12 @@ func NewGeminiProvider(config *GeminiConfig) (*GeminiProvider, error) {
 		clientConfig.Backend = genai.BackendVertexAI
 	}
 
+	// Vertex AI without an API key authenticates through its own HTTP client,
+	// so request logging is only available with API keys
+	if clientConfig.APIKey != "" {
+		clientConfig.HTTPClient = debugHTTPClient(&config.ProviderConfig, ProviderTypeGemini.String())
+	}
+
 	ctx := context.Background()
 	client, err := genai.NewClient(ctx, clientConfig)
 	if err != nil {
@@ -58,9 +78,10 @@ func (p *GeminiProvider) Complete(ctx context.Context, agent Agent, messages []M
 
 	// Build the conversation history
 	var allText string
+	var mediaParts []*genai.Part
 
 	// Add system instructions if present
-	if instructions := agent.GetInstructions(); instructions != "" {
+	if instructions := instructionsFor(agent, InstructionsMarkdown); instructions != "" {
 		allText += "System: " + instructions + "\n\n"
 	}
 
@@ -69,6 +90,13 @@ func (p *GeminiProvider) Complete(ctx context.Context, agent Agent, messages []M
 		switch msg.Role {
 		case "user":
 			allText += "User: " + msg.Content + "\n"
+			parts, err := p.mediaParts(ctx, msg.Parts)
+			if err != nil {
+				return nil, err
+			}
+			mediaParts = append(mediaParts, parts...)
+		case "system", "developer":
+			allText += "System: " + msg.Content + "\n"
 		case "assistant":
 			allText += "Assistant: " + msg.Content + "\n"
 
@@ -81,9 +109,14 @@ func (p *GeminiProvider) Complete(ctx context.Context, agent Agent, messages []M
 		case "tool":
 			// Handle tool responses
 			if msg.Metadata != nil {
-				if toolCallID, ok := msg.Metadata["tool_call_id"].(string); ok {
+				if toolCallID, ok := msg.Metadata[MetadataToolCallID].(string); ok {
 					toolResponseText := fmt.Sprintf("Tool Result [%s]: %s\n", toolCallID, msg.Content)
 					allText += toolResponseText
+					parts, err := p.mediaParts(ctx, msg.Parts)
+					if err != nil {
+						return nil, err
+					}
+					mediaParts = append(mediaParts, parts...)
 				}
 			}
 		}
@@ -94,15 +127,29 @@ func (p *GeminiProvider) Complete(ctx context.Context, agent Agent, messages []M
 
 	// Create content with text part
 	content := &genai.Content{
-		Parts: []*genai.Part{
+		Parts: append([]*genai.Part{
 			{Text: allText},
-		},
+		}, mediaParts...),
 	}
 	contents = append(contents, content)
 
 	// Create generation options
 	opts := &genai.GenerateContentConfig{}
 
+	reqOpts := p.config.requestOptions(ctx)
+	if len(reqOpts.Headers) > 0 {
+		opts.HTTPOptions = &genai.HTTPOptions{Headers: reqOpts.httpHeader()}
+		if p.config.Timeout > 0 {
+			timeout := p.config.Timeout
+			opts.HTTPOptions.Timeout = &timeout
+		}
+	}
+
+	// Labels are only accepted by Vertex AI
+	if len(reqOpts.Metadata) > 0 && p.config.ProjectID != "" {
+		opts.Labels = reqOpts.Metadata
+	}
+
 	// Set temperature if specified
 	if temp := agent.GetTemperature(); temp > 0 {
 		opts.Temperature = &temp
@@ -119,10 +166,28 @@ func (p *GeminiProvider) Complete(ctx context.Context, agent Agent, messages []M
 		opts.TopP = &topP
 	}
 
-	// Make API call to generate content
-	response, err := p.client.Models.GenerateContent(ctx, model, contents, opts)
+	// Enable hosted tools
+	for _, tool := range tools {
+		switch tool.Hosted {
+		case HostedToolGoogleSearch:
+			opts.Tools = append(opts.Tools, &genai.Tool{GoogleSearch: &genai.GoogleSearch{}})
+		case HostedToolCodeExecution:
+			opts.Tools = append(opts.Tools, &genai.Tool{CodeExecution: &genai.ToolCodeExecution{}})
+		}
+	}
+
+	// Make API call to generate content; the SDK doesn't retry on its own
+	var response *genai.GenerateContentResponse
+	err := withRetries(ctx, p.config.MaxRetries, func() error {
+		var callErr error
+		response, callErr = p.client.Models.GenerateContent(ctx, model, contents, opts)
+		if callErr != nil {
+			return geminiError("complete", callErr)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("Gemini API call failed: %w", err)
+		return nil, err
 	}
 
 	// Extract content from response
@@ -136,6 +201,7 @@ func (p *GeminiProvider) Complete(ctx context.Context, agent Agent, messages []M
 	candidate := response.Candidates[0]
 
 	// Process content parts
+	var hostedCalls []HostedCall
 	for _, part := range candidate.Content.Parts {
 		if part.Text != "" {
 			if responseContent != "" {
@@ -143,6 +209,23 @@ func (p *GeminiProvider) Complete(ctx context.Context, agent Agent, messages []M
 			}
 			responseContent += part.Text
 		}
+
+		// Capture code execution as structured calls
+		if part.ExecutableCode != nil {
+			hostedCalls = append(hostedCalls, HostedCall{
+				ID:   fmt.Sprintf("code_execution_%d", len(hostedCalls)+1),
+				Type: HostedToolCodeExecution,
+				Arguments: map[string]interface{}{
+					"language": string(part.ExecutableCode.Language),
+					"code":     part.ExecutableCode.Code,
+				},
+			})
+		}
+		if part.CodeExecutionResult != nil && len(hostedCalls) > 0 {
+			last := &hostedCalls[len(hostedCalls)-1]
+			last.Output = part.CodeExecutionResult.Output
+			last.Outcome = string(part.CodeExecutionResult.Outcome)
+		}
 		// Note: Tool calling would be handled differently in production
 		// This is a simplified implementation
 	}
@@ -153,11 +236,7 @@ func (p *GeminiProvider) Complete(ctx context.Context, agent Agent, messages []M
 	// Extract usage information if available
 	var usage Usage
 	if response.UsageMetadata != nil {
-		usage = Usage{
-			PromptTokens:     int(response.UsageMetadata.PromptTokenCount),
-			CompletionTokens: int(response.UsageMetadata.CandidatesTokenCount),
-			TotalTokens:      int(response.UsageMetadata.TotalTokenCount),
-		}
+		usage = usageFromGemini(response.UsageMetadata)
 	}
 
 	// Convert response
@@ -168,8 +247,20 @@ func (p *GeminiProvider) Complete(ctx context.Context, agent Agent, messages []M
 			ToolCalls: toolCalls,
 			Timestamp: time.Now(),
 		},
-		Usage:     usage,
-		ToolCalls: toolCalls,
+		Usage:       usage,
+		ToolCalls:   toolCalls,
+		HostedCalls: hostedCalls,
+	}
+
+	// Surface search grounding
+	if candidate.GroundingMetadata != nil {
+		result.Grounding, result.Citations = geminiGrounding(candidate.GroundingMetadata)
+		result.Message.Metadata = map[string]interface{}{
+			"grounding": result.Grounding,
+		}
+		if len(result.Citations) > 0 {
+			result.Message.Metadata[MetadataCitations] = result.Citations
+		}
 	}
 
 	return result, nil
@@ -192,8 +283,152 @@ func (p *GeminiProvider) parseToolCallsFromText(content string) []ToolCall {
 	return toolCalls
 }
 
+// usageFromGemini converts usage metadata. Gemini counts thinking tokens
+// separately from the candidates, so they are added to CompletionTokens.
+func usageFromGemini(meta *genai.GenerateContentResponseUsageMetadata) Usage {
+	return Usage{
+		PromptTokens:         int(meta.PromptTokenCount + meta.ToolUsePromptTokenCount),
+		CompletionTokens:     int(meta.CandidatesTokenCount + meta.ThoughtsTokenCount),
+		TotalTokens:          int(meta.TotalTokenCount),
+		CachedPromptTokens:   int(meta.CachedContentTokenCount),
+		ReasoningTokens:      int(meta.ThoughtsTokenCount),
+		PromptModalities:     geminiModalities(meta.PromptTokensDetails, meta.ToolUsePromptTokensDetails),
+		CompletionModalities: geminiModalities(meta.CandidatesTokensDetails),
+	}
+}
+
+// geminiModalities sums token counts by lowercased modality
+func geminiModalities(details ...[]*genai.ModalityTokenCount) map[string]int {
+	var counts map[string]int
+	for _, list := range details {
+		for _, detail := range list {
+			if detail == nil || detail.TokenCount == 0 {
+				continue
+			}
+			if counts == nil {
+				counts = make(map[string]int)
+			}
+			counts[strings.ToLower(string(detail.Modality))] += int(detail.TokenCount)
+		}
+	}
+	return counts
+}
+
+// geminiSupportsDocument reports whether Gemini accepts the document type inline
+func geminiSupportsDocument(mimeType string) bool {
+	return strings.EqualFold(mimeType, MIMETypePDF) || IsTextMIMEType(mimeType)
+}
+
+// Hosted tool types supported by Gemini
+const (
+	HostedToolGoogleSearch  = "google_search"
+	HostedToolCodeExecution = "code_execution"
+)
+
+// geminiGrounding converts Gemini grounding metadata into sources and
+// per-segment citations
+func geminiGrounding(meta *genai.GroundingMetadata) (*GroundingMetadata, []Citation) {
+	grounding := &GroundingMetadata{
+		SearchQueries: meta.WebSearchQueries,
+	}
+	if meta.SearchEntryPoint != nil {
+		grounding.RenderedContent = meta.SearchEntryPoint.RenderedContent
+	}
+
+	for _, chunk := range meta.GroundingChunks {
+		switch {
+		case chunk.Web != nil:
+			grounding.Sources = append(grounding.Sources, Citation{
+				Type:  "web",
+				URL:   chunk.Web.URI,
+				Title: chunk.Web.Title,
+			})
+		case chunk.RetrievedContext != nil:
+			grounding.Sources = append(grounding.Sources, Citation{
+				Type:  "retrieved_context",
+				URL:   chunk.RetrievedContext.URI,
+				Title: chunk.RetrievedContext.Title,
+			})
+		default:
+			grounding.Sources = append(grounding.Sources, Citation{Type: "unknown"})
+		}
+	}
+
+	var citations []Citation
+	for _, support := range meta.GroundingSupports {
+		if support.Segment == nil {
+			continue
+		}
+		for _, idx := range support.GroundingChunkIndices {
+			if int(idx) >= len(grounding.Sources) {
+				continue
+			}
+			citation := grounding.Sources[idx]
+			citation.Text = support.Segment.Text
+			citation.StartIndex = int(support.Segment.StartIndex)
+			citation.EndIndex = int(support.Segment.EndIndex)
+			citations = append(citations, citation)
+		}
+	}
+
+	return grounding, citations
+}
+
+// Capabilities implements CapabilityProvider for Gemini. Function calling is
+// not yet wired up; tool use is limited to hosted tools.
+func (p *GeminiProvider) Capabilities() Capabilities {
+	return Capabilities{
+		Tools:            false,
+		Streaming:        false,
+		Vision:           true,
+		Documents:        true,
+		StructuredOutput: false,
+		MaxContextTokens: 1048576,
+		HostedTools:      []string{HostedToolGoogleSearch, HostedToolCodeExecution},
+	}
+}
+
+// Ping implements Pinger for Gemini by listing a single model
+func (p *GeminiProvider) Ping(ctx context.Context) error {
+	if _, err := p.client.Models.List(ctx, &genai.ListModelsConfig{PageSize: 1}); err != nil {
+		return geminiError("ping", err)
+	}
+	return nil
+}
+
+// ListModels implements ModelLister for Gemini
+func (p *GeminiProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	var models []ModelInfo
+
+	for model, err := range p.client.Models.All(ctx) {
+		if err != nil {
+			return nil, geminiError("list_models", err)
+		}
+
+		models = append(models, ModelInfo{
+			ID:               strings.TrimPrefix(model.Name, "models/"),
+			DisplayName:      model.DisplayName,
+			ContextWindow:    int(model.InputTokenLimit),
+			MaxOutputTokens:  int(model.OutputTokenLimit),
+			InputModalities:  []string{ModalityText, ModalityImage, ModalityAudio, ModalityPDF},
+			OutputModalities: []string{ModalityText},
+		})
+	}
+
+	return models, nil
+}
+
 // Close cleans up the provider resources
 func (p *GeminiProvider) Close() error {
 	// Gemini client doesn't require explicit cleanup
 	return nil
 }
+
+// geminiError converts a Gemini SDK error into a ProviderError
+func geminiError(op string, err error) error {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return NewProviderError(ProviderTypeGemini.String(), op, err)
+	}
+	return newAPIError(ProviderTypeGemini.String(), op, apiErr.Code, apiErr.Status, apiErr.Message, "", err)
+}
@@ -0,0 +1,56 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pinger is implemented by providers that can check they are reachable and
+// accept their credentials without running a completion
+type Pinger interface {
+	// Ping makes the cheapest authenticated request the API offers, such as
+	// listing a single model
+	Ping(ctx context.Context) error
+}
+
+// Ping checks that provider is reachable. Providers without a Ping method
+// are checked by listing their models; providers that can do neither
+// report ErrUnsupportedProvider, and PingCompletion can check them instead.
+func Ping(ctx context.Context, provider Provider) error {
+	if pinger, ok := provider.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	if lister, ok := provider.(ModelLister); ok {
+		_, err := lister.ListModels(ctx)
+		return err
+	}
+	return fmt.Errorf("%w: %T cannot be pinged", ErrUnsupportedProvider, provider)
+}
+
+// PingCompletion returns a Pinger that requests a one-token completion from
+// model, for providers that can't be pinged otherwise or to check that a
+// particular model is being served
+func PingCompletion(provider Provider, model string) Pinger {
+	return completionPinger{provider: provider, model: model}
+}
+
+type completionPinger struct {
+	provider Provider
+	model    string
+}
+
+// Ping implements Pinger
+func (p completionPinger) Ping(ctx context.Context) error {
+	_, err := p.provider.Complete(ctx, pingAgent(p.model), []Message{{Role: "user", Content: "ping"}}, nil)
+	return err
+}
+
+// pingAgent is the minimal agent a completion ping runs as
+type pingAgent string
+
+func (a pingAgent) GetName() string         { return "ping" }
+func (a pingAgent) GetInstructions() string { return "" }
+func (a pingAgent) GetModel() string        { return string(a) }
+func (a pingAgent) GetTemperature() float32 { return 0 }
+func (a pingAgent) GetMaxTokens() int       { return 1 }
+func (a pingAgent) GetTopP() float32        { return 1 }
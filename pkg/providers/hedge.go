@@ -0,0 +1,267 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultHedgeDelay is how long a HedgedProvider waits for the primary
+// before sending the request to the backup as well
+const DefaultHedgeDelay = 2 * time.Second
+
+// HedgeOption configures a HedgedProvider
+type HedgeOption func(*HedgedProvider)
+
+// WithHedgeDelay sets how long to wait for the primary before hedging. Set
+// it near the primary's p95 latency: lower hedges more requests and pays
+// for more duplicate completions.
+func WithHedgeDelay(delay time.Duration) HedgeOption {
+	return func(p *HedgedProvider) {
+		p.delay = delay
+	}
+}
+
+// WithHedgeModel sends hedged requests to model instead of the agent's, for
+// backups on another provider whose models are named differently
+func WithHedgeModel(model string) HedgeOption {
+	return func(p *HedgedProvider) {
+		p.backupModel = model
+	}
+}
+
+// HedgeStats counts how requests to a HedgedProvider were served
+type HedgeStats struct {
+	Requests int `json:"requests"`
+
+	// Hedged counts requests also sent to the backup, after the delay or
+	// because the primary failed
+	Hedged int `json:"hedged"`
+
+	// BackupWins counts requests answered by the backup
+	BackupWins int `json:"backup_wins"`
+}
+
+// HedgedProvider sends a request to its primary and, if no answer arrives
+// within the hedge delay, to its backup as well, returning the first
+// success and cancelling the other request. A primary that fails before the
+// delay is hedged straight away. The backup can be another key or region of
+// the same provider, or another provider with WithHedgeModel.
+//
+// Cancelled requests may still be billed, and usage and cost are estimated
+// for the agent's model whichever request wins.
+type HedgedProvider struct {
+	primary     Provider
+	backup      Provider
+	delay       time.Duration
+	backupModel string
+
+	mu    sync.Mutex
+	stats HedgeStats
+}
+
+// NewHedgedProvider hedges requests to primary with backup
+func NewHedgedProvider(primary, backup Provider, opts ...HedgeOption) *HedgedProvider {
+	p := &HedgedProvider{
+		primary: primary,
+		backup:  backup,
+		delay:   DefaultHedgeDelay,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// hedgeResult is the outcome of one of the hedged requests
+type hedgeResult struct {
+	completion *Completion
+	err        error
+	backup     bool
+}
+
+// Complete implements the Provider interface
+func (p *HedgedProvider) Complete(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (*Completion, error) {
+	return p.hedge(ctx, agent, messages, tools, nil)
+}
+
+// CompleteStreaming implements ToolCallStreamer, hedging like Complete. Tool
+// calls are reported as the primary streams them, and only until the hedged
+// request returns; the completion holds the calls of whichever request won.
+func (p *HedgedProvider) CompleteStreaming(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition, onToolCall func(ToolCall)) (*Completion, error) {
+	var mu sync.Mutex
+	done := false
+	defer func() {
+		mu.Lock()
+		done = true
+		mu.Unlock()
+	}()
+
+	return p.hedge(ctx, agent, messages, tools, func(call ToolCall) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !done {
+			onToolCall(call)
+		}
+	})
+}
+
+// hedge sends the request to the primary, streaming its tool calls to
+// onToolCall if set, and to the backup once the hedge delay passes or the
+// primary fails
+func (p *HedgedProvider) hedge(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition, onToolCall func(ToolCall)) (*Completion, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	send := func(provider Provider, agent Agent, backup bool) {
+		go func() {
+			var completion *Completion
+			var err error
+			if streamer, ok := provider.(ToolCallStreamer); ok && onToolCall != nil && !backup {
+				completion, err = streamer.CompleteStreaming(ctx, agent, messages, tools, onToolCall)
+			} else {
+				completion, err = provider.Complete(ctx, agent, messages, tools)
+			}
+			results <- hedgeResult{completion: completion, err: err, backup: backup}
+		}()
+	}
+
+	hedge := func() {
+		backupAgent := agent
+		if p.backupModel != "" {
+			backupAgent = modelAgent{Agent: agent, model: p.backupModel}
+		}
+		send(p.backup, backupAgent, true)
+	}
+
+	p.record(func(s *HedgeStats) { s.Requests++ })
+	send(p.primary, agent, false)
+
+	timer := time.NewTimer(p.delay)
+	defer timer.Stop()
+
+	hedged, pending := false, 1
+	var primaryErr error
+	for {
+		select {
+		case <-timer.C:
+			if !hedged {
+				hedged, pending = true, pending+1
+				p.record(func(s *HedgeStats) { s.Hedged++ })
+				hedge()
+			}
+
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				if result.backup {
+					p.record(func(s *HedgeStats) { s.BackupWins++ })
+				}
+				return result.completion, nil
+			}
+
+			if !result.backup {
+				primaryErr = result.err
+				if !hedged && ctx.Err() == nil {
+					hedged, pending = true, pending+1
+					p.record(func(s *HedgeStats) { s.Hedged++ })
+					hedge()
+					continue
+				}
+			}
+			if pending == 0 {
+				// Report the primary's error; the backup's is only a second
+				// opinion
+				if primaryErr != nil {
+					return nil, primaryErr
+				}
+				return nil, result.err
+			}
+		}
+	}
+}
+
+// Stats reports how requests were served so far
+func (p *HedgedProvider) Stats() HedgeStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+// Capabilities reports the features both providers support, since either
+// may serve a request
+func (p *HedgedProvider) Capabilities() Capabilities {
+	a, b := CapabilitiesOf(p.primary), CapabilitiesOf(p.backup)
+
+	c := Capabilities{
+		Tools:            a.Tools && b.Tools,
+		Streaming:        a.Streaming, // tool calls stream from the primary
+		Vision:           a.Vision && b.Vision,
+		Documents:        a.Documents && b.Documents,
+		StructuredOutput: a.StructuredOutput && b.StructuredOutput,
+		MaxContextTokens: min(a.MaxContextTokens, b.MaxContextTokens),
+		ComputerUse:      a.ComputerUse && b.ComputerUse,
+	}
+	for _, hosted := range a.HostedTools {
+		if b.SupportsHostedTool(hosted) {
+			c.HostedTools = append(c.HostedTools, hosted)
+		}
+	}
+	return c
+}
+
+// Ping implements Pinger by checking the primary, which serves requests
+// unless it is slow or failing
+func (p *HedgedProvider) Ping(ctx context.Context) error {
+	return Ping(ctx, p.primary)
+}
+
+// ListModels implements ModelLister with the primary's models
+func (p *HedgedProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return listModels(ctx, p.primary)
+}
+
+// Embed implements Embedder with the primary, so vectors stay comparable
+// across requests
+func (p *HedgedProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embedder, ok := p.primary.(Embedder)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T does not support embeddings", ErrUnsupportedProvider, p.primary)
+	}
+	return embedder.Embed(ctx, texts)
+}
+
+// record updates the stats under the lock
+func (p *HedgedProvider) record(fn func(*HedgeStats)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fn(&p.stats)
+}
+
+// modelAgent runs an agent on another model
+type modelAgent struct {
+	Agent
+	model string
+}
+
+// GetModel implements Agent
+func (a modelAgent) GetModel() string {
+	return a.model
+}
+
+// GetInstructionSections keeps the sections of a SectionedAgent
+func (a modelAgent) GetInstructionSections() []InstructionSection {
+	if sectioned, ok := a.Agent.(SectionedAgent); ok {
+		return sectioned.GetInstructionSections()
+	}
+	return nil
+}
+
+// GetParallelToolCalls keeps the setting of a ParallelToolCallsAgent
+func (a modelAgent) GetParallelToolCalls() *bool {
+	return parallelToolCalls(a.Agent)
+}
@@ -0,0 +1,73 @@
+package providers
+
+import (
+	"strings"
+)
+
+// InstructionSection is a named part of an agent's instructions. A section
+// without a name is a preamble rendered without a heading.
+type InstructionSection struct {
+	Name    string `json:"name,omitempty"`
+	Content string `json:"content"`
+}
+
+// SectionedAgent is implemented by agents whose instructions are assembled
+// from sections, letting each provider lay them out the way its models
+// follow best
+type SectionedAgent interface {
+	GetInstructionSections() []InstructionSection
+}
+
+// InstructionFormat is a layout for assembling instruction sections
+type InstructionFormat int
+
+const (
+	// InstructionsMarkdown renders each section under a "## Title" heading
+	InstructionsMarkdown InstructionFormat = iota
+
+	// InstructionsXML wraps each section in a <name> tag, which Claude
+	// models are trained to attend to
+	InstructionsXML
+)
+
+// FormatInstructions assembles sections in order, skipping empty ones
+func FormatInstructions(sections []InstructionSection, format InstructionFormat) string {
+	parts := make([]string, 0, len(sections))
+	for _, section := range sections {
+		content := strings.TrimSpace(section.Content)
+		if content == "" {
+			continue
+		}
+
+		switch {
+		case section.Name == "":
+			parts = append(parts, content)
+		case format == InstructionsXML:
+			tag := strings.ReplaceAll(strings.ToLower(section.Name), " ", "_")
+			parts = append(parts, "<"+tag+">\n"+content+"\n</"+tag+">")
+		default:
+			parts = append(parts, "## "+sectionTitle(section.Name)+"\n"+content)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// sectionTitle turns a section name like "tool_protocol" into "Tool Protocol"
+func sectionTitle(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == ' ' })
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// instructionsFor returns the agent's instructions, assembling sections in
+// the provider's preferred format when the agent has them
+func instructionsFor(agent Agent, format InstructionFormat) string {
+	if sectioned, ok := agent.(SectionedAgent); ok {
+		if sections := sectioned.GetInstructionSections(); len(sections) > 0 {
+			return FormatInstructions(sections, format)
+		}
+	}
+	return agent.GetInstructions()
+}
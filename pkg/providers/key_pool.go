@@ -0,0 +1,326 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNoKeys is returned by a key pool without any API keys
+var ErrNoKeys = errors.New("key pool has no API keys")
+
+// KeyStrategy selects the key a KeyPoolProvider uses for a request
+type KeyStrategy int
+
+const (
+	// KeyRoundRobin cycles through the keys in order
+	KeyRoundRobin KeyStrategy = iota
+
+	// KeyLeastLoaded picks the key with the fewest requests in flight
+	KeyLeastLoaded
+)
+
+// DefaultKeyCooldown is how long a rate-limited key is skipped
+const DefaultKeyCooldown = time.Minute
+
+// KeyFactory creates a provider bound to one API key. The provider must not
+// retry rate-limited requests itself, or the pool only moves to another key
+// once its retries are spent; NewKeyFactory creates such providers.
+type KeyFactory func(apiKey string) (Provider, error)
+
+// NewKeyFactory returns a KeyFactory creating providers of providerType
+// with options and retries disabled, leaving rate limits to the pool
+func NewKeyFactory(providerType ProviderType, options ...ProviderOption) KeyFactory {
+	factory := NewProviderFactory()
+	return func(apiKey string) (Provider, error) {
+		opts := append([]ProviderOption{WithAPIKey(apiKey)}, options...)
+		return factory.CreateProvider(providerType, append(opts, WithMaxRetries(0))...)
+	}
+}
+
+// KeyPoolOption configures a KeyPoolProvider
+type KeyPoolOption func(*KeyPoolProvider)
+
+// WithKeyStrategy sets how keys are selected
+func WithKeyStrategy(strategy KeyStrategy) KeyPoolOption {
+	return func(p *KeyPoolProvider) {
+		p.strategy = strategy
+	}
+}
+
+// WithKeyCooldown sets how long a key is demoted after hitting a rate limit
+func WithKeyCooldown(cooldown time.Duration) KeyPoolOption {
+	return func(p *KeyPoolProvider) {
+		p.cooldown = cooldown
+	}
+}
+
+// KeyStatus reports the state of one key in a pool
+type KeyStatus struct {
+	// Key is the last four characters of the API key
+	Key          string    `json:"key"`
+	InFlight     int       `json:"in_flight"`
+	Requests     int       `json:"requests"`
+	RateLimited  int       `json:"rate_limited"`
+	DemotedUntil time.Time `json:"demoted_until,omitempty"`
+}
+
+// pooledKey is a key with its provider and load
+type pooledKey struct {
+	key          string
+	provider     Provider
+	inFlight     int
+	requests     int
+	rateLimited  int
+	demotedUntil time.Time
+}
+
+// KeyPoolProvider spreads requests over several API keys of the same
+// provider. A key that is rate limited is demoted for a cooldown and the
+// request is retried on another key.
+type KeyPoolProvider struct {
+	factory  KeyFactory
+	strategy KeyStrategy
+	cooldown time.Duration
+
+	mu   sync.Mutex
+	keys []*pooledKey
+	next int
+}
+
+// NewKeyPoolProvider creates a provider for each key using factory
+func NewKeyPoolProvider(keys []string, factory KeyFactory, opts ...KeyPoolOption) (*KeyPoolProvider, error) {
+	p := &KeyPoolProvider{
+		factory:  factory,
+		cooldown: DefaultKeyCooldown,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	for _, key := range keys {
+		if err := p.AddKey(key); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	return p, nil
+}
+
+// AddKey adds a key to the pool, for rotating in a new key without a restart
+func (p *KeyPoolProvider) AddKey(key string) error {
+	if key == "" {
+		return ErrMissingAPIKey
+	}
+
+	provider, err := p.factory(key)
+	if err != nil {
+		return fmt.Errorf("key ...%s: %w", keySuffix(key), err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, &pooledKey{key: key, provider: provider})
+	return nil
+}
+
+// RemoveKey takes a key out of the pool; requests in flight finish normally
+func (p *KeyPoolProvider) RemoveKey(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, k := range p.keys {
+		if k.key == key {
+			p.keys = append(p.keys[:i], p.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// Keys reports the status of every key
+func (p *KeyPoolProvider) Keys() []KeyStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make([]KeyStatus, len(p.keys))
+	for i, k := range p.keys {
+		result[i] = KeyStatus{
+			Key:          keySuffix(k.key),
+			InFlight:     k.inFlight,
+			Requests:     k.requests,
+			RateLimited:  k.rateLimited,
+			DemotedUntil: k.demotedUntil,
+		}
+	}
+	return result
+}
+
+// Complete implements the Provider interface
+func (p *KeyPoolProvider) Complete(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (*Completion, error) {
+	var completion *Completion
+	err := p.do(ctx, func(provider Provider) error {
+		var err error
+		completion, err = provider.Complete(ctx, agent, messages, tools)
+		return err
+	})
+	return completion, err
+}
+
+// CompleteStreaming implements ToolCallStreamer, streaming when the pooled
+// provider does
+func (p *KeyPoolProvider) CompleteStreaming(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition, onToolCall func(ToolCall)) (*Completion, error) {
+	var completion *Completion
+	err := p.do(ctx, func(provider Provider) error {
+		var err error
+		if streamer, ok := provider.(ToolCallStreamer); ok {
+			completion, err = streamer.CompleteStreaming(ctx, agent, messages, tools, onToolCall)
+		} else {
+			completion, err = provider.Complete(ctx, agent, messages, tools)
+		}
+		return err
+	})
+	return completion, err
+}
+
+// Embed implements Embedder when the pooled provider does
+func (p *KeyPoolProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var vectors [][]float32
+	err := p.do(ctx, func(provider Provider) error {
+		embedder, ok := provider.(Embedder)
+		if !ok {
+			return fmt.Errorf("%T does not support embeddings", provider)
+		}
+		var err error
+		vectors, err = embedder.Embed(ctx, texts)
+		return err
+	})
+	return vectors, err
+}
+
+// ListModels implements ModelLister when the pooled provider does
+func (p *KeyPoolProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	var models []ModelInfo
+	err := p.do(ctx, func(provider Provider) error {
+		var err error
+		models, err = listModels(ctx, provider)
+		return err
+	})
+	return models, err
+}
+
+// Ping implements Pinger, checking keys until one answers so a rate-limited
+// key doesn't fail the check
+func (p *KeyPoolProvider) Ping(ctx context.Context) error {
+	return p.do(ctx, func(provider Provider) error {
+		return Ping(ctx, provider)
+	})
+}
+
+// Capabilities reports the capabilities of the pooled provider
+func (p *KeyPoolProvider) Capabilities() Capabilities {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.keys) == 0 {
+		return Capabilities{}
+	}
+	return CapabilitiesOf(p.keys[0].provider)
+}
+
+// do runs fn with a selected key, moving on to the next key while requests
+// are rate limited
+func (p *KeyPoolProvider) do(ctx context.Context, fn func(Provider) error) error {
+	tried := make(map[*pooledKey]bool)
+	for {
+		key := p.acquire(tried)
+		if key == nil {
+			return ErrNoKeys
+		}
+
+		err := fn(key.provider)
+		limited := IsRateLimitError(err)
+		p.release(key, limited)
+
+		tried[key] = true
+		if !limited || ctx.Err() != nil || len(tried) >= p.size() {
+			return err
+		}
+	}
+}
+
+// acquire selects a key not yet tried for this request, preferring keys
+// that aren't demoted, and marks it in flight
+func (p *KeyPoolProvider) acquire(tried map[*pooledKey]bool) *pooledKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var best *pooledKey
+	for i := range p.keys {
+		// Round robin starts the scan at the next key in turn
+		k := p.keys[(p.next+i)%len(p.keys)]
+		if tried[k] {
+			continue
+		}
+		if best == nil || better(k, best, now, p.strategy) {
+			best = k
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	p.next = (p.next + 1) % len(p.keys)
+	best.inFlight++
+	best.requests++
+	return best
+}
+
+// better reports whether a should be used instead of b
+func better(a, b *pooledKey, now time.Time, strategy KeyStrategy) bool {
+	aDemoted, bDemoted := now.Before(a.demotedUntil), now.Before(b.demotedUntil)
+	switch {
+	case aDemoted != bDemoted:
+		return !aDemoted
+	case aDemoted:
+		// All candidates are cooling down; use the one that recovers first
+		return a.demotedUntil.Before(b.demotedUntil)
+	case strategy == KeyLeastLoaded:
+		return a.inFlight < b.inFlight
+	default:
+		return false
+	}
+}
+
+// release records the outcome of a request on key
+func (p *KeyPoolProvider) release(key *pooledKey, rateLimited bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key.inFlight--
+	if rateLimited {
+		key.rateLimited++
+		key.demotedUntil = time.Now().Add(p.cooldown)
+	}
+}
+
+// size returns the number of keys in the pool
+func (p *KeyPoolProvider) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.keys)
+}
+
+// keySuffix returns the last four characters of key for display
+func keySuffix(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return key[len(key)-4:]
+}
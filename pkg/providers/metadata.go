@@ -0,0 +1,65 @@
+package providers
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Message metadata keys understood by the providers. Other keys stay with
+// the conversation but aren't sent.
+const (
+	// MetadataToolCallID links a tool message to the call it answers
+	MetadataToolCallID = "tool_call_id"
+
+	// MetadataIsError marks a tool message reporting a failed call
+	MetadataIsError = "is_error"
+
+	// MetadataHosted marks messages recording a provider-executed tool call
+	MetadataHosted = "hosted"
+
+	// MetadataName names the participant who wrote a user, assistant or
+	// system message, for conversations with several people. OpenAI's chat
+	// completions send it as the message name; other providers ignore it.
+	MetadataName = "name"
+
+	// MetadataCache set to true marks the message as the end of a prompt
+	// prefix worth caching. Anthropic places a cache breakpoint after it;
+	// OpenAI and Gemini cache prefixes on their own.
+	MetadataCache = "cache"
+
+	// MetadataCitations holds the []Citation of an assistant message.
+	// Providers set it on cited responses, and Anthropic replays the
+	// document citations with the message.
+	MetadataCitations = "citations"
+)
+
+// MessageName returns the participant name recorded on msg, if any
+func MessageName(msg Message) string {
+	name, _ := msg.Metadata[MetadataName].(string)
+	return strings.TrimSpace(name)
+}
+
+// MessageCached reports whether msg is marked as a cache breakpoint
+func MessageCached(msg Message) bool {
+	cached, _ := msg.Metadata[MetadataCache].(bool)
+	return cached
+}
+
+// CitationsOf returns the citations held in message metadata, which are
+// either []Citation or, for messages loaded from a session, their decoded
+// JSON
+func CitationsOf(metadata map[string]interface{}) []Citation {
+	raw, ok := metadata[MetadataCitations]
+	if !ok {
+		return nil
+	}
+	if citations, ok := raw.([]Citation); ok {
+		return citations
+	}
+
+	var citations []Citation
+	if data, err := json.Marshal(raw); err == nil {
+		_ = json.Unmarshal(data, &citations)
+	}
+	return citations
+}
@@ -0,0 +1,139 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Model modalities
+const (
+	ModalityText  = "text"
+	ModalityImage = "image"
+	ModalityAudio = "audio"
+	ModalityPDF   = "pdf"
+)
+
+// ModelInfo describes a model offered by a provider
+type ModelInfo struct {
+	ID               string    `json:"id"`
+	DisplayName      string    `json:"display_name,omitempty"`
+	ContextWindow    int       `json:"context_window,omitempty"`
+	MaxOutputTokens  int       `json:"max_output_tokens,omitempty"`
+	InputModalities  []string  `json:"input_modalities,omitempty"`
+	OutputModalities []string  `json:"output_modalities,omitempty"`
+	Created          time.Time `json:"created,omitempty"`
+}
+
+// ModelLister is implemented by providers that can enumerate their models
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+}
+
+// listModels lists the models of a wrapped provider, for wrappers that
+// implement ModelLister on its behalf
+func listModels(ctx context.Context, provider Provider) ([]ModelInfo, error) {
+	lister, ok := provider.(ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T does not list models", ErrUnsupportedProvider, provider)
+	}
+	return lister.ListModels(ctx)
+}
+
+// ValidateModel checks that the model is offered by the provider, returning
+// ErrInvalidModel otherwise
+func ValidateModel(ctx context.Context, lister ModelLister, model string) error {
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list models: %w", err)
+	}
+
+	for _, m := range models {
+		if m.ID == model {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrInvalidModel, model)
+}
+
+// knownModel holds static metadata for model families whose list endpoints
+// don't report limits
+type knownModel struct {
+	prefix          string
+	contextWindow   int
+	maxOutputTokens int
+	input           []string
+}
+
+// knownModels is matched by longest prefix
+var knownModels = []knownModel{
+	// OpenAI
+	{"gpt-4.1", 1047576, 32768, []string{ModalityText, ModalityImage}},
+	{"gpt-4o", 128000, 16384, []string{ModalityText, ModalityImage, ModalityAudio}},
+	{"gpt-4-turbo", 128000, 4096, []string{ModalityText, ModalityImage}},
+	{"gpt-4", 8192, 8192, []string{ModalityText}},
+	{"gpt-3.5-turbo", 16385, 4096, []string{ModalityText}},
+	{"o1", 200000, 100000, []string{ModalityText, ModalityImage}},
+	{"o3", 200000, 100000, []string{ModalityText, ModalityImage}},
+	{"o4-mini", 200000, 100000, []string{ModalityText, ModalityImage}},
+	{"chatgpt-4o", 128000, 16384, []string{ModalityText, ModalityImage}},
+
+	// Anthropic
+	{"claude-opus-4", 200000, 32000, []string{ModalityText, ModalityImage, ModalityPDF}},
+	{"claude-sonnet-4", 200000, 64000, []string{ModalityText, ModalityImage, ModalityPDF}},
+	{"claude-3-7-sonnet", 200000, 64000, []string{ModalityText, ModalityImage, ModalityPDF}},
+	{"claude-3-5", 200000, 8192, []string{ModalityText, ModalityImage, ModalityPDF}},
+	{"claude-3", 200000, 4096, []string{ModalityText, ModalityImage}},
+}
+
+// lookupKnownModel finds static metadata for a model ID
+func lookupKnownModel(id string) (knownModel, bool) {
+	var best knownModel
+	found := false
+	for _, m := range knownModels {
+		if strings.HasPrefix(id, m.prefix) && len(m.prefix) > len(best.prefix) {
+			best = m
+			found = true
+		}
+	}
+	return best, found
+}
+
+// ContextWindow returns the known context window for a model, or 0 if the
+// model isn't recognized
+func ContextWindow(model string) int {
+	if m, ok := lookupKnownModel(model); ok {
+		return m.contextWindow
+	}
+	return 0
+}
+
+// MaxOutputTokens returns the known output token limit for a model, or 0 if
+// the model isn't recognized
+func MaxOutputTokens(model string) int {
+	if m, ok := lookupKnownModel(model); ok {
+		return m.maxOutputTokens
+	}
+	return 0
+}
+
+// withKnownLimits fills in context and modality metadata from the static table
+func withKnownLimits(info ModelInfo) ModelInfo {
+	if m, ok := lookupKnownModel(info.ID); ok {
+		if info.ContextWindow == 0 {
+			info.ContextWindow = m.contextWindow
+		}
+		if info.MaxOutputTokens == 0 {
+			info.MaxOutputTokens = m.maxOutputTokens
+		}
+		if len(info.InputModalities) == 0 {
+			info.InputModalities = m.input
+		}
+	}
+	if len(info.OutputModalities) == 0 {
+		info.OutputModalities = []string{ModalityText}
+	}
+	return info
+}
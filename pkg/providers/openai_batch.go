@@ -0,0 +1,132 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/openai/openai-go"
+)
+
+// CompleteBatch implements BatchProvider using OpenAI's Batch API
+func (p *OpenAIProvider) CompleteBatch(ctx context.Context, agent Agent, requests []BatchRequest, tools []ToolDefinition) ([]BatchResponse, error) {
+	// Build the JSONL input file
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	for i, req := range requests {
+		line := map[string]interface{}{
+			"custom_id": strconv.Itoa(i),
+			"method":    "POST",
+			"url":       "/v1/chat/completions",
+			"body":      p.buildParams(agent, req.Messages, tools),
+		}
+		if err := encoder.Encode(line); err != nil {
+			return nil, fmt.Errorf("failed to encode OpenAI batch request %d: %w", i, err)
+		}
+	}
+
+	file, err := p.client.Files.New(ctx, openai.FileNewParams{
+		File:    openai.File(&input, "batch.jsonl", "application/jsonl"),
+		Purpose: openai.FilePurposeBatch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload OpenAI batch input: %w", err)
+	}
+
+	batch, err := p.client.Batches.New(ctx, openai.BatchNewParams{
+		CompletionWindow: openai.BatchNewParamsCompletionWindow24h,
+		Endpoint:         openai.BatchNewParamsEndpointV1ChatCompletions,
+		InputFileID:      file.ID,
+	})
+	if err != nil {
+		return nil, openAIError("create_batch", err)
+	}
+
+	for !openAIBatchDone(batch.Status) {
+		if err := waitForBatch(ctx, p.config.BatchPollInterval); err != nil {
+			return nil, fmt.Errorf("OpenAI batch %s not finished: %w", batch.ID, err)
+		}
+
+		batch, err = p.client.Batches.Get(ctx, batch.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to poll OpenAI batch: %w", err)
+		}
+	}
+
+	if batch.Status != openai.BatchStatusCompleted {
+		return nil, fmt.Errorf("OpenAI batch %s ended with status %s", batch.ID, batch.Status)
+	}
+
+	responses := make([]BatchResponse, len(requests))
+	for i := range responses {
+		responses[i].Err = fmt.Errorf("no result returned for batch request %d", i)
+	}
+
+	if batch.OutputFileID == "" {
+		return responses, nil
+	}
+
+	content, err := p.client.Files.Content(ctx, batch.OutputFileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download OpenAI batch output: %w", err)
+	}
+	defer content.Body.Close()
+
+	scanner := bufio.NewScanner(content.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line struct {
+			CustomID string `json:"custom_id"`
+			Response struct {
+				StatusCode int             `json:"status_code"`
+				Body       json.RawMessage `json:"body"`
+			} `json:"response"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+
+		idx, err := strconv.Atoi(line.CustomID)
+		if err != nil || idx < 0 || idx >= len(responses) {
+			continue
+		}
+
+		if line.Error != nil {
+			responses[idx].Err = fmt.Errorf("OpenAI batch request failed: %s", line.Error.Message)
+			continue
+		}
+		if line.Response.StatusCode != 200 {
+			responses[idx].Err = fmt.Errorf("OpenAI batch request failed with status %d: %s", line.Response.StatusCode, string(line.Response.Body))
+			continue
+		}
+
+		var completion openai.ChatCompletion
+		if err := json.Unmarshal(line.Response.Body, &completion); err != nil {
+			responses[idx].Err = fmt.Errorf("failed to decode OpenAI batch response: %w", err)
+			continue
+		}
+
+		result, err := completionFromOpenAI(&completion)
+		responses[idx] = BatchResponse{Completion: result, Err: err}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read OpenAI batch output: %w", err)
+	}
+
+	return responses, nil
+}
+
+// openAIBatchDone reports whether the batch reached a terminal status
+func openAIBatchDone(status openai.BatchStatus) bool {
+	switch status {
+	case openai.BatchStatusCompleted, openai.BatchStatusFailed, openai.BatchStatusExpired, openai.BatchStatusCancelled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package providers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
+	"github.com/openai/openai-go/responses"
+)
+
+// openAIComputerCallsKey is the assistant message metadata holding the
+// Responses API items of its computer calls, by call ID
+const openAIComputerCallsKey = "openai_computer_calls"
+
+// openAIComputerCall is what replaying a computer call needs beyond its
+// action: the output item's ID and the safety checks it raised
+type openAIComputerCall struct {
+	ItemID       string              `json:"item_id"`
+	SafetyChecks []openAISafetyCheck `json:"safety_checks,omitempty"`
+}
+
+// openAISafetyCheck is a check OpenAI raised on a computer call. The call's
+// output acknowledges it.
+type openAISafetyCheck struct {
+	ID      string `json:"id"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// openAIComputerToolCall converts a computer call to a call of the named
+// tool, whose arguments are the action as OpenAI sent it
+func openAIComputerToolCall(item responses.ResponseComputerToolCall, tool string) (ToolCall, openAIComputerCall) {
+	var args map[string]interface{}
+	_ = json.Unmarshal([]byte(item.Action.RawJSON()), &args)
+
+	call := openAIComputerCall{ItemID: item.ID}
+	for _, check := range item.PendingSafetyChecks {
+		call.SafetyChecks = append(call.SafetyChecks, openAISafetyCheck{ID: check.ID, Code: check.Code, Message: check.Message})
+	}
+	return ToolCall{ID: item.CallID, Name: tool, Arguments: args}, call
+}
+
+// openAIComputerCalls reads the computer calls recorded on an assistant
+// message, which may have been through a JSON round trip in a session store
+func openAIComputerCalls(msg Message) map[string]openAIComputerCall {
+	raw, ok := msg.Metadata[openAIComputerCallsKey]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var calls map[string]openAIComputerCall
+	if err := json.Unmarshal(data, &calls); err != nil {
+		return nil
+	}
+	return calls
+}
+
+// openAIComputerCallItem replays a computer call. The SDK's param type has
+// no way to set a decoded action, so the item's JSON is given directly.
+func openAIComputerCallItem(toolCall ToolCall, call openAIComputerCall) responses.ResponseInputItemUnionParam {
+	checks := call.SafetyChecks
+	if checks == nil {
+		checks = []openAISafetyCheck{}
+	}
+
+	item := param.Override[responses.ResponseComputerToolCallParam](map[string]interface{}{
+		"type":                  "computer_call",
+		"id":                    call.ItemID,
+		"call_id":               toolCall.ID,
+		"action":                toolCall.Arguments,
+		"pending_safety_checks": checks,
+		"status":                "completed",
+	})
+	return responses.ResponseInputItemUnionParam{OfComputerCall: &item}
+}
+
+// openAIComputerCallOutput sends a tool result's screenshot as the output of
+// a computer call, acknowledging the call's safety checks
+func openAIComputerCallOutput(toolCallID string, call openAIComputerCall, msg Message) responses.ResponseInputItemUnionParam {
+	var screenshot responses.ResponseComputerToolCallOutputScreenshotParam
+	for _, part := range msg.Parts {
+		if part.Type != ContentPartImage {
+			continue
+		}
+		if len(part.Data) > 0 {
+			screenshot.ImageURL = openai.String("data:" + part.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(part.Data))
+		} else {
+			screenshot.ImageURL = openai.String(part.URI)
+		}
+	}
+
+	output := responses.ResponseInputItemParamOfComputerCallOutput(toolCallID, screenshot)
+	for _, check := range call.SafetyChecks {
+		output.OfComputerCallOutput.AcknowledgedSafetyChecks = append(output.OfComputerCallOutput.AcknowledgedSafetyChecks,
+			responses.ResponseInputItemComputerCallOutputAcknowledgedSafetyCheckParam{
+				ID:      check.ID,
+				Code:    openai.String(check.Code),
+				Message: openai.String(check.Message),
+			})
+	}
+	return output
+}
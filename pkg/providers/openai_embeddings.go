@@ -0,0 +1,43 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openai/openai-go"
+)
+
+// Embed implements Embedder using OpenAI's embeddings API
+func (p *OpenAIProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	model := p.config.EmbeddingModel
+	if model == "" {
+		model = DefaultOpenAIEmbeddingModel
+	}
+
+	resp, err := p.client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: texts},
+		Model: openai.EmbeddingModel(model),
+	})
+	if err != nil {
+		return nil, openAIError("embed", err)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, data := range resp.Data {
+		if data.Index < 0 || int(data.Index) >= len(texts) {
+			return nil, fmt.Errorf("embedding index out of range: %d", data.Index)
+		}
+
+		vector := make([]float32, len(data.Embedding))
+		for i, v := range data.Embedding {
+			vector[i] = float32(v)
+		}
+		vectors[data.Index] = vector
+	}
+
+	return vectors, nil
+}
@@ -2,7 +2,11 @@ package providers
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/openai/openai-go"
@@ -24,6 +28,8 @@ func NewOpenAIProvider(config *OpenAIConfig) (*OpenAIProvider, error) {
 	// Initialize OpenAI client
 	opts := []option.RequestOption{
 		option.WithAPIKey(config.APIKey),
+		option.WithRequestTimeout(config.Timeout),
+		option.WithMaxRetries(config.MaxRetries),
 	}
 	
 	if config.BaseURL != "" {
@@ -38,6 +44,10 @@ func NewOpenAIProvider(config *OpenAIConfig) (*OpenAIProvider, error) {
 		opts = append(opts, option.WithHeader("OpenAI-Project", config.Project))
 	}
 
+	if httpClient := debugHTTPClient(&config.ProviderConfig, ProviderTypeOpenAI.String()); httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(httpClient))
+	}
+
 	client := openai.NewClient(opts...)
 
 	return &OpenAIProvider{
@@ -48,28 +58,133 @@ func NewOpenAIProvider(config *OpenAIConfig) (*OpenAIProvider, error) {
 
 // Complete implements the Provider interface for OpenAI
 func (p *OpenAIProvider) Complete(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (*Completion, error) {
+	// Hosted tools are only available through the Responses API
+	if hasHostedTools(tools) {
+		return p.completeWithResponses(ctx, agent, messages, tools)
+	}
+
+	params, callOpts := p.request(ctx, agent, messages, tools)
+
+	// Make API call
+	completion, err := p.client.Chat.Completions.New(ctx, params, callOpts...)
+	if err != nil {
+		return nil, openAIError("complete", err)
+	}
+
+	return completionFromOpenAI(completion)
+}
+
+// CompleteStreaming implements ToolCallStreamer for OpenAI, reporting a
+// tool call once the stream moves past it. Requests with hosted tools go
+// through the Responses API without streaming.
+func (p *OpenAIProvider) CompleteStreaming(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition, onToolCall func(ToolCall)) (*Completion, error) {
+	if hasHostedTools(tools) {
+		return p.completeWithResponses(ctx, agent, messages, tools)
+	}
+
+	params, callOpts := p.request(ctx, agent, messages, tools)
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+
+	start := time.Now()
+	var firstToken time.Duration
+	stream := p.client.Chat.Completions.NewStreaming(ctx, params, callOpts...)
+	defer stream.Close()
+
+	var acc openai.ChatCompletionAccumulator
+	for stream.Next() {
+		acc.AddChunk(stream.Current())
+		if firstToken == 0 && len(acc.Choices) > 0 {
+			firstToken = time.Since(start)
+		}
+		if call, ok := acc.JustFinishedToolCall(); ok {
+			onToolCall(ToolCall{ID: call.ID, Name: call.Name, Arguments: openAIToolArguments(call.Arguments)})
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, openAIError("complete", err)
+	}
+
+	completion, err := completionFromOpenAI(&acc.ChatCompletion)
+	if err != nil {
+		return nil, err
+	}
+	completion.TimeToFirstToken = firstToken
+	return completion, nil
+}
+
+// request builds the chat completion parameters and per-call options of a
+// request
+func (p *OpenAIProvider) request(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (openai.ChatCompletionNewParams, []option.RequestOption) {
+	params := p.buildParams(agent, messages, tools)
+
+	reqOpts := p.config.requestOptions(ctx)
+	if len(reqOpts.Metadata) > 0 {
+		params.Metadata = reqOpts.Metadata
+	}
+	if reqOpts.User != "" {
+		params.User = openai.String(reqOpts.User)
+	}
+	return params, p.callOptions(ctx, reqOpts)
+}
+
+// buildParams converts the agent, conversation, and tools into a chat
+// completion request
+func (p *OpenAIProvider) buildParams(agent Agent, messages []Message, tools []ToolDefinition) openai.ChatCompletionNewParams {
 	// Convert messages to OpenAI format
 	chatMessages := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages)+1)
 	
 	// Add system message if agent has instructions
-	if instructions := agent.GetInstructions(); instructions != "" {
+	if instructions := instructionsFor(agent, InstructionsMarkdown); instructions != "" {
 		chatMessages = append(chatMessages, openai.SystemMessage(instructions))
 	}
 	
-	// Convert messages
+	// Convert messages. The results of an assistant message's tool calls
+	// must follow it directly, so the images they return are held back until
+	// every call is answered.
+	var turn openAIToolTurn
 	for _, msg := range messages {
+		if isHostedMessage(msg) {
+			continue
+		}
+		if msg.Role != "tool" {
+			chatMessages = turn.finish(chatMessages)
+		}
+
+		converted := len(chatMessages)
 		switch msg.Role {
 		case "user":
-			chatMessages = append(chatMessages, openai.UserMessage(msg.Content))
+			if len(msg.Parts) > 0 {
+				parts := append([]openai.ChatCompletionContentPartUnionParam{openai.TextContentPart(msg.Content)}, openAIContentParts(msg.Parts)...)
+				chatMessages = append(chatMessages, openai.UserMessage(parts))
+			} else {
+				chatMessages = append(chatMessages, openai.UserMessage(msg.Content))
+			}
 		case "assistant":
-			chatMessages = append(chatMessages, openai.AssistantMessage(msg.Content))
+			chatMessages = append(chatMessages, openAIAssistantMessage(msg))
+			turn.start(msg.ToolCalls)
+		case "system":
+			chatMessages = append(chatMessages, openai.SystemMessage(msg.Content))
+		case "developer":
+			chatMessages = append(chatMessages, openai.DeveloperMessage(msg.Content))
 		case "tool":
-			// Handle tool responses
-			if toolCallID, ok := msg.Metadata["tool_call_id"].(string); ok {
-				chatMessages = append(chatMessages, openai.ToolMessage(toolCallID, msg.Content))
+			// Results of calls missing from the history, such as those cut
+			// off with older messages, would be rejected
+			toolCallID, _ := msg.Metadata[MetadataToolCallID].(string)
+			if !turn.answer(toolCallID) {
+				continue
 			}
+			chatMessages = append(chatMessages, openai.ToolMessage(msg.Content, toolCallID))
+
+			// Tool messages only accept text, so images and files returned
+			// by a tool are forwarded in a follow-up user message
+			turn.images = append(turn.images, openAIContentParts(msg.Parts)...)
+		}
+
+		if name := MessageName(msg); name != "" && msg.Role != "tool" && len(chatMessages) > converted {
+			setOpenAIName(&chatMessages[converted], name)
 		}
 	}
+	chatMessages = turn.finish(chatMessages)
 	
 	// Prepare chat completion request
 	params := openai.ChatCompletionNewParams{
@@ -82,27 +197,87 @@ func (p *OpenAIProvider) Complete(ctx context.Context, agent Agent, messages []M
 		params.Temperature = openai.Float(float64(temp))
 	}
 	
-	// Convert tools if provided
-	// Note: Tool calling implementation is disabled for now due to OpenAI SDK complexity
-	// The basic chat completion will work without tools
-	if len(tools) > 0 {
-		// TODO: Implement proper tool calling once SDK issues are resolved
-		// For now, we'll proceed without tools to get the basic functionality working
+	// Convert tools to OpenAI functions
+	if functions := openAIFunctionTools(tools); len(functions) > 0 {
+		params.Tools = functions
+		if parallel := parallelToolCalls(agent); parallel != nil {
+			params.ParallelToolCalls = openai.Bool(*parallel)
+		}
 	}
 	
-	// Make API call
-	completion, err := p.client.Chat.Completions.New(ctx, params)
-	if err != nil {
-		return nil, fmt.Errorf("OpenAI API call failed: %w", err)
+	return params
+}
+
+// completionFromOpenAI converts a chat completion response into a Completion
+func completionFromOpenAI(completion *openai.ChatCompletion) (*Completion, error) {
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("no completion choices returned from OpenAI")
 	}
 	
+	result := completionFromChoice(completion.Choices[0])
+	result.Usage = usageFromOpenAI(completion.Usage)
+	return result, nil
+}
+
+// usageFromOpenAI converts chat completion usage, including its cached,
+// reasoning and audio token details
+func usageFromOpenAI(usage openai.CompletionUsage) Usage {
+	result := Usage{
+		PromptTokens:       int(usage.PromptTokens),
+		CompletionTokens:   int(usage.CompletionTokens),
+		TotalTokens:        int(usage.TotalTokens),
+		CachedPromptTokens: int(usage.PromptTokensDetails.CachedTokens),
+		ReasoningTokens:    int(usage.CompletionTokensDetails.ReasoningTokens),
+	}
+	if audio := usage.PromptTokensDetails.AudioTokens; audio > 0 {
+		result.PromptModalities = map[string]int{"audio": int(audio)}
+	}
+	if audio := usage.CompletionTokensDetails.AudioTokens; audio > 0 {
+		result.CompletionModalities = map[string]int{"audio": int(audio)}
+	}
+	return result
+}
+
+// CompleteN implements Sampler with the n parameter, so the prompt is only
+// processed once. The usage of the whole request is reported on the first
+// completion.
+func (p *OpenAIProvider) CompleteN(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition, n int) ([]*Completion, error) {
+	// The Responses API has no n parameter
+	if hasHostedTools(tools) || n <= 1 {
+		completions := make([]*Completion, 0, n)
+		for i := 0; i < max(n, 1); i++ {
+			completion, err := p.Complete(ctx, agent, messages, tools)
+			if err != nil {
+				return nil, err
+			}
+			completions = append(completions, completion)
+		}
+		return completions, nil
+	}
+
+	params, callOpts := p.request(ctx, agent, messages, tools)
+	params.N = openai.Int(int64(n))
+
+	completion, err := p.client.Chat.Completions.New(ctx, params, callOpts...)
+	if err != nil {
+		return nil, openAIError("complete", err)
+	}
 	if len(completion.Choices) == 0 {
 		return nil, fmt.Errorf("no completion choices returned from OpenAI")
 	}
-	
-	choice := completion.Choices[0]
+
+	completions := make([]*Completion, len(completion.Choices))
+	for i, choice := range completion.Choices {
+		completions[i] = completionFromChoice(choice)
+	}
+	completions[0].Usage = usageFromOpenAI(completion.Usage)
+	return completions, nil
+}
+
+// completionFromChoice converts one choice of a chat completion response
+func completionFromChoice(choice openai.ChatCompletionChoice) *Completion {
 	message := choice.Message
-	
+
 	// Convert response
 	result := &Completion{
 		Message: Message{
@@ -110,11 +285,6 @@ func (p *OpenAIProvider) Complete(ctx context.Context, agent Agent, messages []M
 			Content:   message.Content,
 			Timestamp: time.Now(),
 		},
-		Usage: Usage{
-			PromptTokens:     int(completion.Usage.PromptTokens),
-			CompletionTokens: int(completion.Usage.CompletionTokens),
-			TotalTokens:      int(completion.Usage.TotalTokens),
-		},
 	}
 	
 	// Handle tool calls
@@ -125,17 +295,148 @@ func (p *OpenAIProvider) Complete(ctx context.Context, agent Agent, messages []M
 				toolCalls = append(toolCalls, ToolCall{
 					ID:        tc.ID,
 					Name:      tc.Function.Name,
-					Arguments: map[string]interface{}{
-						"raw": tc.Function.Arguments,
-					},
+					Arguments: openAIToolArguments(tc.Function.Arguments),
 				})
 			}
 		}
 		result.ToolCalls = toolCalls
+		result.Message.ToolCalls = toolCalls
 	}
 	
-	return result, nil
+	return result
 }
 
+// openAIContentParts converts image and file content parts into OpenAI
+// content parts. PDFs are sent as file inputs; other documents fall back to
+// locally extracted text.
+func openAIContentParts(parts []ContentPart) []openai.ChatCompletionContentPartUnionParam {
+	var result []openai.ChatCompletionContentPartUnionParam
+	for _, part := range parts {
+		switch part.Type {
+		case ContentPartImage:
+			url := part.URI
+			if len(part.Data) > 0 {
+				url = fmt.Sprintf("data:%s;base64,%s", part.MIMEType, base64.StdEncoding.EncodeToString(part.Data))
+			}
+			if url == "" {
+				continue
+			}
+			result = append(result, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: url}))
+		case ContentPartFile:
+			if len(part.Data) == 0 {
+				result = append(result, openai.TextContentPart(fmt.Sprintf("[file %s]", part.URI)))
+				continue
+			}
+			if !strings.EqualFold(part.MIMEType, MIMETypePDF) {
+				result = append(result, openai.TextContentPart(documentText(part)))
+				continue
+			}
+			file := openai.ChatCompletionContentPartFileFileParam{
+				FileData: openai.String(fmt.Sprintf("data:%s;base64,%s", part.MIMEType, base64.StdEncoding.EncodeToString(part.Data))),
+			}
+			if part.Name != "" {
+				file.Filename = openai.String(part.Name)
+			}
+			result = append(result, openai.FileContentPart(file))
+		}
+	}
+	return result
+}
 
+// Capabilities implements CapabilityProvider for OpenAI
+func (p *OpenAIProvider) Capabilities() Capabilities {
+	return Capabilities{
+		Tools:            true,
+		Streaming:        true,
+		Vision:           true,
+		Documents:        true,
+		StructuredOutput: false,
+		MaxContextTokens: 128000,
+		HostedTools:      []string{HostedToolFileSearch},
+		ComputerUse:      true,
+	}
+}
+
+// Ping implements Pinger for OpenAI by listing models
+func (p *OpenAIProvider) Ping(ctx context.Context) error {
+	if _, err := p.client.Models.List(ctx); err != nil {
+		return openAIError("ping", err)
+	}
+	return nil
+}
 
+// ListModels implements ModelLister for OpenAI
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	var models []ModelInfo
+
+	iter := p.client.Models.ListAutoPaging(ctx)
+	for iter.Next() {
+		model := iter.Current()
+		models = append(models, withKnownLimits(ModelInfo{
+			ID:      model.ID,
+			Created: time.Unix(model.Created, 0),
+		}))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, openAIError("list_models", err)
+	}
+
+	return models, nil
+}
+
+// openAIError converts an OpenAI SDK error into a ProviderError
+func openAIError(op string, err error) error {
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		return NewProviderError(ProviderTypeOpenAI.String(), op, err)
+	}
+
+	requestID := ""
+	if apiErr.Response != nil {
+		requestID = apiErr.Response.Header.Get("x-request-id")
+	}
+	code := apiErr.Code
+	if code == "" {
+		code = apiErr.Type
+	}
+	return newAPIError(ProviderTypeOpenAI.String(), op, apiErr.StatusCode, code, apiErr.Message, requestID, err)
+}
+
+// callOptions converts request headers to OpenAI request options and sets
+// the request fields of enabled feature flags
+func (p *OpenAIProvider) callOptions(ctx context.Context, reqOpts RequestOptions) []option.RequestOption {
+	result := openAIHeaders(reqOpts)
+	features := p.config.features(ctx, ProviderTypeOpenAI)
+	for _, name := range featureNames(features) {
+		result = append(result, option.WithJSONSet(name, featureValue(features[name])))
+	}
+	return result
+}
+
+// openAIHeaders converts request headers to OpenAI request options
+func openAIHeaders(opts RequestOptions) []option.RequestOption {
+	var result []option.RequestOption
+	for name, value := range opts.Headers {
+		result = append(result, option.WithHeader(name, value))
+	}
+	return result
+}
+
+// openAIInvalidName matches the characters OpenAI rejects in message names
+var openAIInvalidName = regexp.MustCompile(`[\s<|\\/>]+`)
+
+// setOpenAIName sets the participant name of a user, assistant, system or
+// developer message
+func setOpenAIName(msg *openai.ChatCompletionMessageParamUnion, name string) {
+	name = openAIInvalidName.ReplaceAllString(name, "_")
+	switch {
+	case msg.OfUser != nil:
+		msg.OfUser.Name = openai.String(name)
+	case msg.OfAssistant != nil:
+		msg.OfAssistant.Name = openai.String(name)
+	case msg.OfSystem != nil:
+		msg.OfSystem.Name = openai.String(name)
+	case msg.OfDeveloper != nil:
+		msg.OfDeveloper.Name = openai.String(name)
+	}
+}
@@ -0,0 +1,184 @@
+package providers
+
+import (
+	"context"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/responses"
+)
+
+// hasHostedTools reports whether any tool is only available through the
+// Responses API: OpenAI-hosted tools and computer use
+func hasHostedTools(tools []ToolDefinition) bool {
+	for _, tool := range tools {
+		if tool.Hosted == HostedToolFileSearch || tool.Display != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// HostedToolFileSearch is the hosted type of OpenAI's file search tool
+const HostedToolFileSearch = "file_search"
+
+// completeWithResponses runs a completion through the Responses API, which
+// supports OpenAI-hosted tools such as file search and computer use
+func (p *OpenAIProvider) completeWithResponses(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (*Completion, error) {
+	input := make(responses.ResponseInputParam, 0, len(messages))
+	computerCalls := make(map[string]openAIComputerCall)
+	for _, msg := range messages {
+		if isHostedMessage(msg) {
+			continue
+		}
+
+		switch msg.Role {
+		case "user":
+			input = append(input, responses.ResponseInputItemParamOfMessage(msg.Content, responses.EasyInputMessageRoleUser))
+		case "assistant":
+			if msg.Content != "" {
+				input = append(input, responses.ResponseInputItemParamOfMessage(msg.Content, responses.EasyInputMessageRoleAssistant))
+			}
+			calls := openAIComputerCalls(msg)
+			for _, toolCall := range msg.ToolCalls {
+				if call, ok := calls[toolCall.ID]; ok {
+					input = append(input, openAIComputerCallItem(toolCall, call))
+					computerCalls[toolCall.ID] = call
+					continue
+				}
+				input = append(input, responses.ResponseInputItemParamOfFunctionCall(
+					openAIArgumentsJSON(toolCall.Arguments), toolCall.ID, toolCall.Name))
+			}
+		case "system":
+			input = append(input, responses.ResponseInputItemParamOfMessage(msg.Content, responses.EasyInputMessageRoleSystem))
+		case "developer":
+			input = append(input, responses.ResponseInputItemParamOfMessage(msg.Content, responses.EasyInputMessageRoleDeveloper))
+		case "tool":
+			toolCallID, ok := msg.Metadata[MetadataToolCallID].(string)
+			if !ok {
+				continue
+			}
+			if call, ok := computerCalls[toolCallID]; ok {
+				input = append(input, openAIComputerCallOutput(toolCallID, call, msg))
+			} else {
+				input = append(input, responses.ResponseInputItemParamOfFunctionCallOutput(toolCallID, msg.Content))
+			}
+		}
+	}
+
+	params := responses.ResponseNewParams{
+		Model: agent.GetModel(),
+		Input: responses.ResponseNewParamsInputUnion{OfInputItemList: input},
+	}
+
+	if instructions := instructionsFor(agent, InstructionsMarkdown); instructions != "" {
+		params.Instructions = openai.String(instructions)
+	}
+	if temp := agent.GetTemperature(); temp > 0 {
+		params.Temperature = openai.Float(float64(temp))
+	}
+	if maxTokens := agent.GetMaxTokens(); maxTokens > 0 {
+		params.MaxOutputTokens = openai.Int(int64(maxTokens))
+	}
+
+	params.Tools = openAIResponsesFunctionTools(tools)
+
+	var computerTool string
+	for _, tool := range tools {
+		if tool.Display != nil {
+			computerTool = tool.Name
+			params.Tools = append(params.Tools, responses.ToolParamOfComputerUsePreview(
+				int64(tool.Display.Height), int64(tool.Display.Width), responses.ComputerToolEnvironmentBrowser))
+			// Computer use requires truncation of long conversations
+			params.Truncation = responses.ResponseNewParamsTruncationAuto
+			continue
+		}
+		if tool.Hosted != HostedToolFileSearch {
+			continue
+		}
+		ids, _ := tool.Config["vector_store_ids"].([]string)
+		params.Tools = append(params.Tools, responses.ToolUnionParam{
+			OfFileSearch: &responses.FileSearchToolParam{VectorStoreIDs: ids},
+		})
+	}
+
+	if parallel := parallelToolCalls(agent); parallel != nil {
+		params.ParallelToolCalls = openai.Bool(*parallel)
+	}
+
+	reqOpts := p.config.requestOptions(ctx)
+	if len(reqOpts.Metadata) > 0 {
+		params.Metadata = reqOpts.Metadata
+	}
+	if reqOpts.User != "" {
+		params.User = openai.String(reqOpts.User)
+	}
+
+	response, err := p.client.Responses.New(ctx, params, p.callOptions(ctx, reqOpts)...)
+	if err != nil {
+		return nil, openAIError("complete", err)
+	}
+
+	var citations []Citation
+	var toolCalls []ToolCall
+	calls := make(map[string]openAIComputerCall)
+	for _, item := range response.Output {
+		if item.Type == "computer_call" {
+			toolCall, call := openAIComputerToolCall(item.AsComputerCall(), computerTool)
+			toolCalls = append(toolCalls, toolCall)
+			calls[toolCall.ID] = call
+			continue
+		}
+		if item.Type == "function_call" {
+			toolCalls = append(toolCalls, ToolCall{
+				ID:        item.CallID,
+				Name:      item.Name,
+				Arguments: openAIToolArguments(item.Arguments),
+			})
+			continue
+		}
+		for _, content := range item.Content {
+			for _, annotation := range content.Annotations {
+				citations = append(citations, Citation{
+					Type:       annotation.Type,
+					FileID:     annotation.FileID,
+					Filename:   annotation.Filename,
+					URL:        annotation.URL,
+					Title:      annotation.Title,
+					StartIndex: int(annotation.StartIndex),
+					EndIndex:   int(annotation.EndIndex),
+				})
+			}
+		}
+	}
+
+	result := &Completion{
+		Message: Message{
+			Role:      "assistant",
+			Content:   response.OutputText(),
+			ToolCalls: toolCalls,
+			Timestamp: time.Now(),
+		},
+		Usage: Usage{
+			PromptTokens:       int(response.Usage.InputTokens),
+			CompletionTokens:   int(response.Usage.OutputTokens),
+			TotalTokens:        int(response.Usage.TotalTokens),
+			CachedPromptTokens: int(response.Usage.InputTokensDetails.CachedTokens),
+			ReasoningTokens:    int(response.Usage.OutputTokensDetails.ReasoningTokens),
+		},
+		ToolCalls: toolCalls,
+		Citations: citations,
+	}
+
+	if len(citations) > 0 || len(calls) > 0 {
+		result.Message.Metadata = make(map[string]interface{})
+	}
+	if len(citations) > 0 {
+		result.Message.Metadata[MetadataCitations] = citations
+	}
+	if len(calls) > 0 {
+		result.Message.Metadata[openAIComputerCallsKey] = calls
+	}
+
+	return result, nil
+}
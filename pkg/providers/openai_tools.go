@@ -0,0 +1,170 @@
+package providers
+
+import (
+	"encoding/json"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/responses"
+	"github.com/openai/openai-go/shared"
+)
+
+// openAIMissingResult answers tool calls whose results aren't in the history
+const openAIMissingResult = "No result was recorded for this tool call."
+
+// openAIToolTurn tracks the tool calls of the last assistant message while
+// their results are converted
+type openAIToolTurn struct {
+	calls    []string
+	answered map[string]bool
+	images   []openai.ChatCompletionContentPartUnionParam
+}
+
+// start begins the turn of an assistant message's tool calls
+func (t *openAIToolTurn) start(calls []ToolCall) {
+	t.calls = t.calls[:0]
+	t.answered = make(map[string]bool, len(calls))
+	for _, call := range calls {
+		t.calls = append(t.calls, call.ID)
+	}
+}
+
+// answer records the result of call id, reporting false when the turn has
+// no such call or it was already answered
+func (t *openAIToolTurn) answer(id string) bool {
+	if id == "" || t.answered == nil || t.answered[id] {
+		return false
+	}
+	for _, call := range t.calls {
+		if call == id {
+			t.answered[id] = true
+			return true
+		}
+	}
+	return false
+}
+
+// finish closes the turn: calls without a result are answered with a
+// placeholder, as the API requires, and held-back images follow the results
+func (t *openAIToolTurn) finish(messages []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+	for _, id := range t.calls {
+		if !t.answered[id] {
+			messages = append(messages, openai.ToolMessage(openAIMissingResult, id))
+		}
+	}
+	if len(t.images) > 0 {
+		messages = append(messages, openai.UserMessage(t.images))
+	}
+	*t = openAIToolTurn{}
+	return messages
+}
+
+// openAIAssistantMessage converts an assistant message with its tool calls
+func openAIAssistantMessage(msg Message) openai.ChatCompletionMessageParamUnion {
+	if len(msg.ToolCalls) == 0 {
+		return openai.AssistantMessage(msg.Content)
+	}
+
+	assistant := openai.ChatCompletionAssistantMessageParam{}
+	if msg.Content != "" {
+		assistant.Content.OfString = openai.String(msg.Content)
+	}
+	for _, call := range msg.ToolCalls {
+		assistant.ToolCalls = append(assistant.ToolCalls, openai.ChatCompletionMessageToolCallParam{
+			ID: call.ID,
+			Function: openai.ChatCompletionMessageToolCallFunctionParam{
+				Name:      call.Name,
+				Arguments: openAIArgumentsJSON(call.Arguments),
+			},
+		})
+	}
+	return openai.ChatCompletionMessageParamUnion{OfAssistant: &assistant}
+}
+
+// openAIFunctionTools converts function tools for chat completions. Hosted
+// and computer-use tools aren't functions.
+func openAIFunctionTools(tools []ToolDefinition) []openai.ChatCompletionToolParam {
+	var functions []openai.ChatCompletionToolParam
+	for _, tool := range tools {
+		if tool.Hosted != "" || tool.Display != nil {
+			continue
+		}
+		function := shared.FunctionDefinitionParam{
+			Name:       tool.Name,
+			Parameters: openAIParameters(tool.Schema),
+		}
+		if tool.Description != "" {
+			function.Description = openai.String(tool.Description)
+		}
+		functions = append(functions, openai.ChatCompletionToolParam{Function: function})
+	}
+	return functions
+}
+
+// openAIResponsesFunctionTools converts function tools for the Responses API
+func openAIResponsesFunctionTools(tools []ToolDefinition) []responses.ToolUnionParam {
+	var functions []responses.ToolUnionParam
+	for _, tool := range tools {
+		if tool.Hosted != "" || tool.Display != nil {
+			continue
+		}
+		function := responses.ToolParamOfFunction(tool.Name, openAIParameters(tool.Schema), false)
+		if tool.Description != "" {
+			function.OfFunction.Description = openai.String(tool.Description)
+		}
+		functions = append(functions, function)
+	}
+	return functions
+}
+
+// openAIParameters converts a parameter schema to its JSON object form
+func openAIParameters(schema ParameterSchema) map[string]interface{} {
+	if schema.Type == "" {
+		schema.Type = "object"
+	}
+	if schema.Properties == nil {
+		schema.Properties = make(map[string]PropertySchema)
+	}
+	if schema.Required == nil {
+		schema.Required = []string{}
+	}
+
+	var parameters map[string]interface{}
+	data, err := json.Marshal(schema)
+	if err == nil {
+		err = json.Unmarshal(data, &parameters)
+	}
+	if err != nil {
+		return map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	return parameters
+}
+
+// openAIToolArguments decodes the JSON arguments of a tool call. Arguments
+// that aren't a JSON object are kept as the string "raw", so the call still
+// reaches the runner and fails validation there.
+func openAIToolArguments(arguments string) map[string]interface{} {
+	if arguments == "" {
+		return map[string]interface{}{}
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(arguments), &decoded); err != nil || decoded == nil {
+		return map[string]interface{}{"raw": arguments}
+	}
+	return decoded
+}
+
+// openAIArgumentsJSON encodes tool call arguments as OpenAI returned them,
+// restoring arguments kept raw by openAIToolArguments
+func openAIArgumentsJSON(arguments map[string]interface{}) string {
+	if raw, ok := arguments["raw"].(string); ok && len(arguments) == 1 {
+		return raw
+	}
+	if arguments == nil {
+		return "{}"
+	}
+	data, err := json.Marshal(arguments)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
@@ -0,0 +1,58 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/openai/openai-go"
+)
+
+// UploadFile uploads a document to OpenAI for use with file search and
+// returns its file ID
+func (p *OpenAIProvider) UploadFile(ctx context.Context, filename string, data []byte) (string, error) {
+	file, err := p.client.Files.New(ctx, openai.FileNewParams{
+		File:    openai.File(bytes.NewReader(data), filename, ""),
+		Purpose: openai.FilePurposeAssistants,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload file %s: %w", filename, err)
+	}
+	return file.ID, nil
+}
+
+// CreateVectorStore creates a vector store containing the given files and
+// returns its ID
+func (p *OpenAIProvider) CreateVectorStore(ctx context.Context, name string, fileIDs ...string) (string, error) {
+	params := openai.VectorStoreNewParams{
+		Name:    openai.String(name),
+		FileIDs: fileIDs,
+	}
+
+	store, err := p.client.VectorStores.New(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to create vector store %s: %w", name, err)
+	}
+	return store.ID, nil
+}
+
+// AttachFiles adds previously uploaded files to an existing vector store
+func (p *OpenAIProvider) AttachFiles(ctx context.Context, vectorStoreID string, fileIDs ...string) error {
+	for _, fileID := range fileIDs {
+		_, err := p.client.VectorStores.Files.New(ctx, vectorStoreID, openai.VectorStoreFileNewParams{
+			FileID: fileID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to attach file %s to vector store %s: %w", fileID, vectorStoreID, err)
+		}
+	}
+	return nil
+}
+
+// DeleteVectorStore removes a vector store
+func (p *OpenAIProvider) DeleteVectorStore(ctx context.Context, vectorStoreID string) error {
+	if _, err := p.client.VectorStores.Delete(ctx, vectorStoreID); err != nil {
+		return fmt.Errorf("failed to delete vector store %s: %w", vectorStoreID, err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package providers
+
+import (
+	"strings"
+	"sync"
+)
+
+// Pricing is a model's price in US dollars per million tokens. CachedInput
+// and CacheWrite fall back to Input when zero.
+type Pricing struct {
+	Input       float64 `json:"input"`
+	CachedInput float64 `json:"cached_input,omitempty"`
+	CacheWrite  float64 `json:"cache_write,omitempty"`
+	Output      float64 `json:"output"`
+}
+
+// Cost computes the price of usage in US dollars. Reasoning tokens are part
+// of the completion tokens and billed as output.
+func (p Pricing) Cost(usage Usage) float64 {
+	cachedRate, writeRate := p.CachedInput, p.CacheWrite
+	if cachedRate == 0 {
+		cachedRate = p.Input
+	}
+	if writeRate == 0 {
+		writeRate = p.Input
+	}
+
+	uncached := usage.PromptTokens - usage.CachedPromptTokens - usage.CacheWriteTokens
+	if uncached < 0 {
+		uncached = 0
+	}
+
+	total := float64(uncached)*p.Input +
+		float64(usage.CachedPromptTokens)*cachedRate +
+		float64(usage.CacheWriteTokens)*writeRate +
+		float64(usage.CompletionTokens)*p.Output
+	return total / 1e6
+}
+
+// modelPrice is a price entry matched by model ID prefix
+type modelPrice struct {
+	prefix string
+	Pricing
+}
+
+// knownPricing holds list prices at the time of writing and is matched by
+// longest prefix; RegisterPricing overrides or extends it
+var knownPricing = []modelPrice{
+	// OpenAI
+	{"gpt-4.1", Pricing{Input: 2.00, CachedInput: 0.50, Output: 8.00}},
+	{"gpt-4.1-mini", Pricing{Input: 0.40, CachedInput: 0.10, Output: 1.60}},
+	{"gpt-4.1-nano", Pricing{Input: 0.10, CachedInput: 0.025, Output: 0.40}},
+	{"gpt-4o", Pricing{Input: 2.50, CachedInput: 1.25, Output: 10.00}},
+	{"gpt-4o-mini", Pricing{Input: 0.15, CachedInput: 0.075, Output: 0.60}},
+	{"chatgpt-4o", Pricing{Input: 5.00, Output: 15.00}},
+	{"gpt-4-turbo", Pricing{Input: 10.00, Output: 30.00}},
+	{"gpt-4", Pricing{Input: 30.00, Output: 60.00}},
+	{"gpt-3.5-turbo", Pricing{Input: 0.50, Output: 1.50}},
+	{"o1", Pricing{Input: 15.00, CachedInput: 7.50, Output: 60.00}},
+	{"o1-mini", Pricing{Input: 1.10, CachedInput: 0.55, Output: 4.40}},
+	{"o3", Pricing{Input: 2.00, CachedInput: 0.50, Output: 8.00}},
+	{"o3-mini", Pricing{Input: 1.10, CachedInput: 0.55, Output: 4.40}},
+	{"o4-mini", Pricing{Input: 1.10, CachedInput: 0.275, Output: 4.40}},
+
+	// Anthropic
+	{"claude-opus-4", Pricing{Input: 15.00, CachedInput: 1.50, CacheWrite: 18.75, Output: 75.00}},
+	{"claude-sonnet-4", Pricing{Input: 3.00, CachedInput: 0.30, CacheWrite: 3.75, Output: 15.00}},
+	{"claude-3-7-sonnet", Pricing{Input: 3.00, CachedInput: 0.30, CacheWrite: 3.75, Output: 15.00}},
+	{"claude-3-5-sonnet", Pricing{Input: 3.00, CachedInput: 0.30, CacheWrite: 3.75, Output: 15.00}},
+	{"claude-3-5-haiku", Pricing{Input: 0.80, CachedInput: 0.08, CacheWrite: 1.00, Output: 4.00}},
+	{"claude-3-opus", Pricing{Input: 15.00, CachedInput: 1.50, CacheWrite: 18.75, Output: 75.00}},
+	{"claude-3-haiku", Pricing{Input: 0.25, CachedInput: 0.03, CacheWrite: 0.30, Output: 1.25}},
+
+	// Gemini, at the prices for prompts up to 200k tokens
+	{"gemini-2.5-pro", Pricing{Input: 1.25, CachedInput: 0.31, Output: 10.00}},
+	{"gemini-2.5-flash", Pricing{Input: 0.30, CachedInput: 0.075, Output: 2.50}},
+	{"gemini-2.0-flash", Pricing{Input: 0.10, CachedInput: 0.025, Output: 0.40}},
+	{"gemini-1.5-pro", Pricing{Input: 1.25, CachedInput: 0.3125, Output: 5.00}},
+	{"gemini-1.5-flash", Pricing{Input: 0.075, CachedInput: 0.01875, Output: 0.30}},
+}
+
+var (
+	pricingMu         sync.RWMutex
+	registeredPricing []modelPrice
+)
+
+// RegisterPricing sets the price of models whose ID starts with prefix,
+// taking precedence over the built-in table. Use it for negotiated rates,
+// fine-tuned or self-hosted models.
+func RegisterPricing(prefix string, pricing Pricing) {
+	pricingMu.Lock()
+	defer pricingMu.Unlock()
+
+	for i := range registeredPricing {
+		if registeredPricing[i].prefix == prefix {
+			registeredPricing[i].Pricing = pricing
+			return
+		}
+	}
+	registeredPricing = append(registeredPricing, modelPrice{prefix, pricing})
+}
+
+// PricingFor returns the price of a model, matched by longest prefix among
+// the registered and then the built-in prices
+func PricingFor(model string) (Pricing, bool) {
+	pricingMu.RLock()
+	defer pricingMu.RUnlock()
+
+	if p, ok := longestPrefix(registeredPricing, model); ok {
+		return p, true
+	}
+	return longestPrefix(knownPricing, model)
+}
+
+// longestPrefix finds the entry with the longest prefix of model
+func longestPrefix(prices []modelPrice, model string) (Pricing, bool) {
+	best := -1
+	for i, p := range prices {
+		if strings.HasPrefix(model, p.prefix) && (best < 0 || len(p.prefix) > len(prices[best].prefix)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return Pricing{}, false
+	}
+	return prices[best].Pricing, true
+}
+
+// Cost computes the price of usage on model in US dollars, reporting false
+// when the model's price is unknown
+func Cost(model string, usage Usage) (float64, bool) {
+	pricing, ok := PricingFor(model)
+	if !ok {
+		return 0, false
+	}
+	return pricing.Cost(usage), true
+}
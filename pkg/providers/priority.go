@@ -0,0 +1,204 @@
+package providers
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// Priority orders requests waiting for capacity; higher is served first
+type Priority int
+
+// Standard priorities. Any other value works too, e.g. PriorityInteractive+1
+// for requests that must never wait behind a chat.
+const (
+	PriorityBatch       Priority = -1
+	PriorityNormal      Priority = 0
+	PriorityInteractive Priority = 1
+)
+
+type priorityKey struct{}
+
+// ContextWithPriority returns a context whose provider requests wait for
+// capacity at priority
+func ContextWithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFromContext returns the priority stored in ctx, PriorityNormal if
+// none is
+func PriorityFromContext(ctx context.Context) Priority {
+	priority, _ := ctx.Value(priorityKey{}).(Priority)
+	return priority
+}
+
+// PriorityLimiter admits at most a fixed number of holders at once. Waiters
+// are admitted highest priority first and in arrival order within a
+// priority, so a queue of batch work never delays interactive requests by
+// more than the time it takes one slot to free up.
+type PriorityLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	active  int
+	seq     uint64
+	waiters waiterQueue
+}
+
+// NewPriorityLimiter creates a limiter admitting limit holders at once
+func NewPriorityLimiter(limit int) *PriorityLimiter {
+	if limit <= 0 {
+		limit = 1
+	}
+	return &PriorityLimiter{limit: limit}
+}
+
+// Acquire waits for a slot, returning the context's error if ctx ends first.
+// Every successful Acquire must be paired with a Release.
+func (l *PriorityLimiter) Acquire(ctx context.Context, priority Priority) error {
+	l.mu.Lock()
+	if l.active < l.limit && len(l.waiters) == 0 {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+
+	w := &waiter{priority: priority, seq: l.seq, ready: make(chan struct{})}
+	l.seq++
+	heap.Push(&l.waiters, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if w.index < 0 {
+			// Admitted just as ctx ended; hand the slot on
+			l.release()
+		} else {
+			heap.Remove(&l.waiters, w.index)
+		}
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot for the next waiter
+func (l *PriorityLimiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.release()
+}
+
+// Waiting returns the number of callers waiting for a slot
+func (l *PriorityLimiter) Waiting() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.waiters)
+}
+
+// release passes the slot to the first waiter or frees it; callers hold l.mu
+func (l *PriorityLimiter) release() {
+	if len(l.waiters) == 0 {
+		l.active--
+		return
+	}
+	w := heap.Pop(&l.waiters).(*waiter)
+	close(w.ready)
+}
+
+// waiter is a caller blocked in Acquire
+type waiter struct {
+	priority Priority
+	seq      uint64
+	ready    chan struct{}
+
+	// index is the waiter's position in the queue, -1 once admitted
+	index int
+}
+
+// waiterQueue is a heap of waiters, highest priority and earliest first
+type waiterQueue []*waiter
+
+func (q waiterQueue) Len() int { return len(q) }
+
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waiterQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waiterQueue) Pop() interface{} {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*q = old[:len(old)-1]
+	return w
+}
+
+// LimitedProvider caps the requests in flight to a provider, admitting
+// waiting requests by the priority in their context. Share one across
+// runners to enforce a provider-wide limit, such as the concurrency an API
+// key's rate limit sustains.
+type LimitedProvider struct {
+	inner   Provider
+	limiter *PriorityLimiter
+}
+
+// NewLimitedProvider allows at most limit concurrent requests to inner
+func NewLimitedProvider(inner Provider, limit int) *LimitedProvider {
+	return &LimitedProvider{
+		inner:   inner,
+		limiter: NewPriorityLimiter(limit),
+	}
+}
+
+// Complete implements the Provider interface
+func (p *LimitedProvider) Complete(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (*Completion, error) {
+	if err := p.limiter.Acquire(ctx, PriorityFromContext(ctx)); err != nil {
+		return nil, err
+	}
+	defer p.limiter.Release()
+
+	return p.inner.Complete(ctx, agent, messages, tools)
+}
+
+// Waiting returns the number of requests waiting for capacity
+func (p *LimitedProvider) Waiting() int {
+	return p.limiter.Waiting()
+}
+
+// Capabilities reports the capabilities of the limited provider
+func (p *LimitedProvider) Capabilities() Capabilities {
+	caps := CapabilitiesOf(p.inner)
+	// Completions pass through Complete, so they aren't streamed
+	caps.Streaming = false
+	return caps
+}
+
+// Ping implements Pinger without waiting for capacity, so health checks
+// aren't held up by load
+func (p *LimitedProvider) Ping(ctx context.Context) error {
+	return Ping(ctx, p.inner)
+}
+
+// ListModels implements ModelLister for the limited provider, also without
+// waiting for capacity
+func (p *LimitedProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return listModels(ctx, p.inner)
+}
@@ -15,11 +15,62 @@ type Agent interface {
 	GetTopP() float32
 }
 
+// ParallelToolCallsAgent is implemented by agents that choose whether the
+// model may request several tool calls in one response
+type ParallelToolCallsAgent interface {
+	// GetParallelToolCalls returns the choice, or nil for the provider default
+	GetParallelToolCalls() *bool
+}
+
+// parallelToolCalls returns the agent's parallel tool call setting, or nil
+// when it doesn't choose
+func parallelToolCalls(agent Agent) *bool {
+	if parallel, ok := agent.(ParallelToolCallsAgent); ok {
+		return parallel.GetParallelToolCalls()
+	}
+	return nil
+}
+
 // ToolDefinition represents a tool's metadata for providers
 type ToolDefinition struct {
 	Name        string                    `json:"name"`
 	Description string                    `json:"description"`
 	Schema      ParameterSchema           `json:"schema"`
+
+	// Hosted is set for tools executed by the provider (e.g. "file_search")
+	Hosted string                 `json:"hosted,omitempty"`
+	Config map[string]interface{} `json:"config,omitempty"`
+
+	// Display is set for computer-use tools, which providers with native
+	// computer use declare in their own format instead of as functions
+	Display *Display `json:"display,omitempty"`
+}
+
+// Display is the screen size of a computer-use tool, in pixels
+type Display struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Citation references a source that supports part of a response.
+// StartIndex and EndIndex locate the supported passage in the response.
+type Citation struct {
+	Type       string `json:"type"`
+	FileID     string `json:"file_id,omitempty"`
+	Filename   string `json:"filename,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Title      string `json:"title,omitempty"`
+	Text       string `json:"text,omitempty"`
+	StartIndex int    `json:"start_index,omitempty"`
+	EndIndex   int    `json:"end_index,omitempty"`
+
+	// DocumentIndex, SourceStart and SourceEnd locate the cited text of a
+	// request document: the document's position among the request's
+	// documents and the cited range of characters, pages or content blocks
+	// depending on Type (end exclusive for characters and blocks)
+	DocumentIndex int `json:"document_index,omitempty"`
+	SourceStart   int `json:"source_start,omitempty"`
+	SourceEnd     int `json:"source_end,omitempty"`
 }
 
 // ParameterSchema describes function parameters
@@ -29,16 +80,60 @@ type ParameterSchema struct {
 	Required   []string                  `json:"required"`
 }
 
-// PropertySchema describes a single parameter
+// PropertySchema describes a single parameter. Type is empty for values of
+// any type.
 type PropertySchema struct {
-	Type        string `json:"type"`
+	Type        string `json:"type,omitempty"`
 	Description string `json:"description"`
+
+	// Items describes array elements
+	Items *PropertySchema `json:"items,omitempty"`
+
+	// Properties and Required describe the fields of nested objects
+	Properties map[string]PropertySchema `json:"properties,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+
+	// AdditionalProperties describes the values of map-typed objects
+	AdditionalProperties *PropertySchema `json:"additionalProperties,omitempty"`
+
+	// Default is the value used when the model omits the property
+	Default interface{} `json:"default,omitempty"`
+
+	// Constraints on allowed values
+	Enum    []interface{} `json:"enum,omitempty"`
+	Minimum *float64      `json:"minimum,omitempty"`
+	Maximum *float64      `json:"maximum,omitempty"`
+	Pattern string        `json:"pattern,omitempty"`
+	Format  string        `json:"format,omitempty"`
+}
+
+// Content part types
+const (
+	ContentPartText  = "text"
+	ContentPartJSON  = "json"
+	ContentPartImage = "image"
+	ContentPartFile  = "file"
+)
+
+// ContentPart is a typed piece of message content such as an image or file
+type ContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+	MIMEType string `json:"mime_type,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Name     string `json:"name,omitempty"`
+
+	// Citations asks the provider to cite the file in its response, where
+	// supported (Anthropic document blocks)
+	Citations bool `json:"citations,omitempty"`
 }
 
 // Message represents a conversation message
 type Message struct {
 	Role      string                 `json:"role"`
 	Content   string                 `json:"content"`
+	Parts     []ContentPart          `json:"parts,omitempty"`
 	ToolCalls []ToolCall             `json:"tool_calls,omitempty"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 	Timestamp time.Time              `json:"timestamp"`
@@ -56,6 +151,16 @@ type HandoffRequest struct {
 	TargetAgent string                 `json:"target_agent"`
 	Context     map[string]interface{} `json:"context,omitempty"`
 	Reason      string                 `json:"reason,omitempty"`
+
+	// Summary is the model's account of the conversation for the next agent
+	Summary string `json:"summary,omitempty"`
+}
+
+// isHostedMessage reports whether the message records a call another
+// provider executed on its side; such messages can't be replayed natively
+func isHostedMessage(msg Message) bool {
+	hosted, _ := msg.Metadata[MetadataHosted].(bool)
+	return hosted
 }
 
 // Provider represents an LLM provider
@@ -64,6 +169,12 @@ type Provider interface {
 	Complete(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (*Completion, error)
 }
 
+// Sampler is implemented by providers that can return several alternative
+// completions from one request, such as OpenAI's n parameter
+type Sampler interface {
+	CompleteN(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition, n int) ([]*Completion, error)
+}
+
 // Completion represents the result of an LLM completion
 type Completion struct {
 	Message          Message            `json:"message"`
@@ -71,13 +182,89 @@ type Completion struct {
 	ToolCalls        []ToolCall         `json:"tool_calls,omitempty"`
 	Handoff          *HandoffRequest    `json:"handoff,omitempty"`
 	StructuredOutput interface{}        `json:"structured_output,omitempty"`
+	Citations        []Citation         `json:"citations,omitempty"`
+	Grounding        *GroundingMetadata `json:"grounding,omitempty"`
+	HostedCalls      []HostedCall       `json:"hosted_calls,omitempty"`
+
+	// TimeToFirstToken is how long the first response token took, set by
+	// providers that stream; zero otherwise
+	TimeToFirstToken time.Duration `json:"time_to_first_token,omitempty"`
+}
+
+// HostedCall records a tool call the provider executed on its side, such as
+// Gemini code execution
+type HostedCall struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Output    string                 `json:"output,omitempty"`
+	Outcome   string                 `json:"outcome,omitempty"`
+}
+
+// GroundingMetadata describes the search results a response was grounded in
+type GroundingMetadata struct {
+	// Sources lists the retrieved documents or web pages
+	Sources []Citation `json:"sources,omitempty"`
+
+	// SearchQueries are the queries the provider executed
+	SearchQueries []string `json:"search_queries,omitempty"`
+
+	// RenderedContent is provider-rendered HTML for search suggestions, which
+	// must be displayed alongside grounded answers per Google's terms
+	RenderedContent string `json:"rendered_content,omitempty"`
 }
 
 // Usage tracks token consumption
+//
+// PromptTokens includes cached prompt tokens and CompletionTokens includes
+// reasoning tokens, as OpenAI reports them; the other providers' counts are
+// normalized to match so the totals are comparable.
 type Usage struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+
+	// CachedPromptTokens were read from the provider's prompt cache
+	CachedPromptTokens int `json:"cached_prompt_tokens,omitempty"`
+
+	// CacheWriteTokens were written to the prompt cache, which Anthropic
+	// bills at a premium
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+
+	// ReasoningTokens were spent on hidden reasoning before the response
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
+
+	// PromptModalities and CompletionModalities break the counts down by
+	// modality ("text", "image", "audio", "video", "document") as far as
+	// the provider reports it
+	PromptModalities     map[string]int `json:"prompt_modalities,omitempty"`
+	CompletionModalities map[string]int `json:"completion_modalities,omitempty"`
+}
+
+// Add accumulates other into u
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.CachedPromptTokens += other.CachedPromptTokens
+	u.CacheWriteTokens += other.CacheWriteTokens
+	u.ReasoningTokens += other.ReasoningTokens
+	u.PromptModalities = addModalities(u.PromptModalities, other.PromptModalities)
+	u.CompletionModalities = addModalities(u.CompletionModalities, other.CompletionModalities)
+}
+
+// addModalities sums per-modality counts
+func addModalities(into, from map[string]int) map[string]int {
+	if len(from) == 0 {
+		return into
+	}
+	if into == nil {
+		into = make(map[string]int, len(from))
+	}
+	for modality, n := range from {
+		into[modality] += n
+	}
+	return into
 }
 
 // NewDefaultOpenAIProvider creates a default OpenAI provider with no API key
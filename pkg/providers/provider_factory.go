@@ -1,34 +1,74 @@
 package providers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 )
 
+// ErrNoSecretsSource is returned when a secret is requested from a factory
+// without a SecretsSource
+var ErrNoSecretsSource = errors.New("no secrets source configured")
+
+// SecretsSource resolves named secrets such as API keys. The secrets
+// package provides environment, file, Vault and AWS Secrets Manager sources.
+type SecretsSource interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
 // ProviderFactory creates providers based on configuration
-type ProviderFactory struct{}
+type ProviderFactory struct {
+	secrets SecretsSource
+}
+
+// FactoryOption configures a ProviderFactory
+type FactoryOption func(*ProviderFactory)
+
+// WithSecrets resolves API keys from source. When no key is passed and none
+// is set in the environment, the provider's default secret name is used
+// (OPENAI_API_KEY, ANTHROPIC_API_KEY or GEMINI_API_KEY); WithAPIKeySecret
+// names another one.
+func WithSecrets(source SecretsSource) FactoryOption {
+	return func(f *ProviderFactory) {
+		f.secrets = source
+	}
+}
 
 // NewProviderFactory creates a new provider factory
-func NewProviderFactory() *ProviderFactory {
-	return &ProviderFactory{}
+func NewProviderFactory(opts ...FactoryOption) *ProviderFactory {
+	f := &ProviderFactory{}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
 // CreateProvider creates a provider based on type and configuration
 func (f *ProviderFactory) CreateProvider(providerType ProviderType, options ...ProviderOption) (Provider, error) {
+	return f.CreateProviderContext(context.Background(), providerType, options...)
+}
+
+// CreateProviderContext is CreateProvider with a context for resolving
+// secrets
+func (f *ProviderFactory) CreateProviderContext(ctx context.Context, providerType ProviderType, options ...ProviderOption) (Provider, error) {
 	switch providerType {
 	case ProviderTypeOpenAI:
-		return f.createOpenAIProvider(options...)
+		return f.createOpenAIProvider(ctx, options...)
 	case ProviderTypeAnthropic:
-		return f.createAnthropicProvider(options...)
+		return f.createAnthropicProvider(ctx, options...)
 	case ProviderTypeGemini:
-		return f.createGeminiProvider(options...)
-	default:
-		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProvider, providerType.String())
+		return f.createGeminiProvider(ctx, options...)
 	}
+
+	if registered, ok := registeredFactory(providerType); ok {
+		return registered.factory(ctx, f, options...)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedProvider, providerType.String())
 }
 
 // createOpenAIProvider creates an OpenAI provider with options
-func (f *ProviderFactory) createOpenAIProvider(options ...ProviderOption) (Provider, error) {
+func (f *ProviderFactory) createOpenAIProvider(ctx context.Context, options ...ProviderOption) (Provider, error) {
 	config := NewOpenAIConfig("")
 
 	// Apply options
@@ -38,11 +78,15 @@ func (f *ProviderFactory) createOpenAIProvider(options ...ProviderOption) (Provi
 		}
 	}
 
+	if err := f.ResolveAPIKey(ctx, &config.ProviderConfig, "OPENAI_API_KEY"); err != nil {
+		return nil, err
+	}
+
 	return NewOpenAIProvider(config)
 }
 
 // createAnthropicProvider creates an Anthropic provider with options
-func (f *ProviderFactory) createAnthropicProvider(options ...ProviderOption) (Provider, error) {
+func (f *ProviderFactory) createAnthropicProvider(ctx context.Context, options ...ProviderOption) (Provider, error) {
 	config := NewAnthropicConfig("")
 
 	// Apply options
@@ -52,11 +96,15 @@ func (f *ProviderFactory) createAnthropicProvider(options ...ProviderOption) (Pr
 		}
 	}
 
+	if err := f.ResolveAPIKey(ctx, &config.ProviderConfig, "ANTHROPIC_API_KEY"); err != nil {
+		return nil, err
+	}
+
 	return NewAnthropicProvider(config)
 }
 
 // createGeminiProvider creates a Gemini provider with options
-func (f *ProviderFactory) createGeminiProvider(options ...ProviderOption) (Provider, error) {
+func (f *ProviderFactory) createGeminiProvider(ctx context.Context, options ...ProviderOption) (Provider, error) {
 	config := NewGeminiConfig("")
 
 	// Apply options
@@ -66,9 +114,36 @@ func (f *ProviderFactory) createGeminiProvider(options ...ProviderOption) (Provi
 		}
 	}
 
+	if err := f.ResolveAPIKey(ctx, &config.ProviderConfig, "GEMINI_API_KEY"); err != nil {
+		return nil, err
+	}
+
 	return NewGeminiProvider(config)
 }
 
+// ResolveAPIKey fills in the API key from the factory's secrets source: the
+// secret named by APIKeySecret, or defaultSecret when no key is set.
+// Registered providers call it from their FactoryFunc.
+func (f *ProviderFactory) ResolveAPIKey(ctx context.Context, config *ProviderConfig, defaultSecret string) error {
+	name := config.APIKeySecret
+	if name == "" {
+		if config.APIKey != "" || f.secrets == nil {
+			return nil
+		}
+		name = defaultSecret
+	}
+	if f.secrets == nil {
+		return fmt.Errorf("%w for secret %s", ErrNoSecretsSource, name)
+	}
+
+	key, err := f.secrets.Secret(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to resolve API key: %w", err)
+	}
+	config.APIKey = key
+	return nil
+}
+
 // ProviderOption represents configuration options for providers
 type ProviderOption interface {
 	Apply(config interface{}) error
@@ -78,16 +153,24 @@ type ProviderOption interface {
 type WithAPIKey string
 
 func (w WithAPIKey) Apply(config interface{}) error {
-	switch c := config.(type) {
-	case *OpenAIConfig:
-		c.APIKey = string(w)
-	case *AnthropicConfig:
-		c.APIKey = string(w)
-	case *GeminiConfig:
-		c.APIKey = string(w)
-	default:
+	c, err := baseConfig(config)
+	if err != nil {
 		return fmt.Errorf("unsupported config type for API key option")
 	}
+	c.APIKey = string(w)
+	return nil
+}
+
+// WithAPIKeySecret reads the API key from the named secret of the factory's
+// SecretsSource
+type WithAPIKeySecret string
+
+func (w WithAPIKeySecret) Apply(config interface{}) error {
+	c, err := baseConfig(config)
+	if err != nil {
+		return fmt.Errorf("unsupported config type for API key secret option")
+	}
+	c.APIKeySecret = string(w)
 	return nil
 }
 
@@ -95,16 +178,11 @@ func (w WithAPIKey) Apply(config interface{}) error {
 type WithBaseURL string
 
 func (w WithBaseURL) Apply(config interface{}) error {
-	switch c := config.(type) {
-	case *OpenAIConfig:
-		c.BaseURL = string(w)
-	case *AnthropicConfig:
-		c.BaseURL = string(w)
-	case *GeminiConfig:
-		c.BaseURL = string(w)
-	default:
+	c, err := baseConfig(config)
+	if err != nil {
 		return fmt.Errorf("unsupported config type for base URL option")
 	}
+	c.BaseURL = string(w)
 	return nil
 }
 
@@ -112,19 +190,73 @@ func (w WithBaseURL) Apply(config interface{}) error {
 type WithDebug bool
 
 func (w WithDebug) Apply(config interface{}) error {
-	switch c := config.(type) {
-	case *OpenAIConfig:
-		c.Debug = bool(w)
-	case *AnthropicConfig:
-		c.Debug = bool(w)
-	case *GeminiConfig:
-		c.Debug = bool(w)
-	default:
+	c, err := baseConfig(config)
+	if err != nil {
 		return fmt.Errorf("unsupported config type for debug option")
 	}
+	c.Debug = bool(w)
+	return nil
+}
+
+// WithMaxRetries sets how often the provider retries rate-limited,
+// overloaded or unavailable requests
+type WithMaxRetries int
+
+func (w WithMaxRetries) Apply(config interface{}) error {
+	c, err := baseConfig(config)
+	if err != nil {
+		return err
+	}
+	c.MaxRetries = int(w)
+	return nil
+}
+
+// WithHeaders adds HTTP headers to every request of the provider
+type WithHeaders map[string]string
+
+func (w WithHeaders) Apply(config interface{}) error {
+	c, err := baseConfig(config)
+	if err != nil {
+		return err
+	}
+	c.Headers = mergeStrings(c.Headers, w)
 	return nil
 }
 
+// WithMetadata attaches request metadata (OpenAI metadata, Vertex AI labels)
+// to every request of the provider
+type WithMetadata map[string]string
+
+func (w WithMetadata) Apply(config interface{}) error {
+	c, err := baseConfig(config)
+	if err != nil {
+		return err
+	}
+	c.Metadata = mergeStrings(c.Metadata, w)
+	return nil
+}
+
+// WithUser identifies the end user in every request of the provider
+type WithUser string
+
+func (w WithUser) Apply(config interface{}) error {
+	c, err := baseConfig(config)
+	if err != nil {
+		return err
+	}
+	c.User = string(w)
+	return nil
+}
+
+// baseConfig returns the ProviderConfig embedded in a provider config,
+// including the configs of registered providers that embed one
+func baseConfig(config interface{}) (*ProviderConfig, error) {
+	if c, ok := config.(interface{ Base() *ProviderConfig }); ok {
+		return c.Base(), nil
+	}
+	return nil, fmt.Errorf("unsupported config type %T", config)
+}
+
 // WithOrganization sets the organization for OpenAI provider
 type WithOrganization string
 
@@ -0,0 +1,139 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FactoryFunc creates a provider from options. It receives the factory
+// doing the creation, whose ResolveAPIKey reads keys from the factory's
+// secrets source.
+type FactoryFunc func(ctx context.Context, factory *ProviderFactory, options ...ProviderOption) (Provider, error)
+
+// registeredProvider is a provider type added with Register
+type registeredProvider struct {
+	name    string
+	factory FactoryFunc
+}
+
+var (
+	registryMu sync.RWMutex
+
+	// registry holds the provider types by name, the built-in ones included
+	registry = map[string]ProviderType{
+		"openai":    ProviderTypeOpenAI,
+		"anthropic": ProviderTypeAnthropic,
+		"gemini":    ProviderTypeGemini,
+	}
+
+	// registered holds the factories of the types added with Register, by
+	// ProviderType
+	registered = make(map[ProviderType]registeredProvider)
+)
+
+// Register makes a third-party provider available under name to
+// ProviderFactory, ParseProviderType and ProviderForModel, and returns its
+// ProviderType. Registering a name again replaces its factory; the names of
+// the built-in providers can't be registered.
+//
+// Register is meant to be called from the provider package's init function:
+//
+//	var ProviderTypeMine = providers.Register("mine", newMineProvider)
+func Register(name string, factory FactoryFunc) ProviderType {
+	name = strings.ToLower(name)
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	providerType, ok := registry[name]
+	if !ok {
+		providerType = ProviderTypeGemini + 1 + ProviderType(len(registered))
+		registry[name] = providerType
+	} else if _, custom := registered[providerType]; !custom {
+		panic(fmt.Sprintf("providers: cannot register built-in provider %q", name))
+	}
+	registered[providerType] = registeredProvider{name: name, factory: factory}
+	return providerType
+}
+
+// ParseProviderType returns the provider type named name, e.g. from a
+// configuration file
+func ParseProviderType(name string) (ProviderType, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if providerType, ok := registry[strings.ToLower(name)]; ok {
+		return providerType, nil
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnsupportedProvider, name)
+}
+
+// RegisteredProviders returns the names of all provider types, sorted
+func RegisteredProviders() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registeredFactory returns the factory of a type added with Register
+func registeredFactory(providerType ProviderType) (registeredProvider, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	provider, ok := registered[providerType]
+	return provider, ok
+}
+
+// MarshalText encodes the type as its name
+func (p ProviderType) MarshalText() ([]byte, error) {
+	name := p.String()
+	if name == "unknown" {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedProvider, int(p))
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText decodes a provider name, so configuration files can name
+// built-in and registered providers alike
+func (p *ProviderType) UnmarshalText(text []byte) error {
+	providerType, err := ParseProviderType(string(text))
+	if err != nil {
+		return err
+	}
+	*p = providerType
+	return nil
+}
+
+// CreateProviderByName creates the provider registered under name
+func (f *ProviderFactory) CreateProviderByName(ctx context.Context, name string, options ...ProviderOption) (Provider, error) {
+	providerType, err := ParseProviderType(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.CreateProviderContext(ctx, providerType, options...)
+}
+
+// ProviderForModel routes a "provider/model" string, such as
+// "anthropic/claude-sonnet-4-5" or "mine/model-x", to a new provider of the
+// named type and returns it with the bare model name
+func (f *ProviderFactory) ProviderForModel(ctx context.Context, model string, options ...ProviderOption) (Provider, string, error) {
+	name, bare, ok := strings.Cut(model, "/")
+	if !ok || bare == "" {
+		return nil, "", fmt.Errorf("%w: model %q has no provider prefix", ErrUnsupportedProvider, model)
+	}
+
+	provider, err := f.CreateProviderByName(ctx, name, options...)
+	if err != nil {
+		return nil, "", err
+	}
+	return provider, bare, nil
+}
@@ -0,0 +1,81 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequestOptions are extra fields attached to provider API requests, such as
+// tracking IDs and routing hints for an LLM gateway
+type RequestOptions struct {
+	// Headers are added to every HTTP request
+	Headers map[string]string
+
+	// Metadata is sent as OpenAI request metadata and as Vertex AI labels;
+	// other providers ignore it
+	Metadata map[string]string
+
+	// User identifies the end user to OpenAI (user) and Anthropic
+	// (metadata.user_id)
+	User string
+}
+
+type requestOptionsKey struct{}
+
+// ContextWithRequestOptions returns a context whose provider requests carry
+// opts, merged over any options already in ctx
+func ContextWithRequestOptions(ctx context.Context, opts RequestOptions) context.Context {
+	return context.WithValue(ctx, requestOptionsKey{}, RequestOptionsFromContext(ctx).Merge(opts))
+}
+
+// RequestOptionsFromContext returns the request options stored in ctx
+func RequestOptionsFromContext(ctx context.Context) RequestOptions {
+	opts, _ := ctx.Value(requestOptionsKey{}).(RequestOptions)
+	return opts
+}
+
+// Merge returns o with the fields of other layered on top
+func (o RequestOptions) Merge(other RequestOptions) RequestOptions {
+	result := RequestOptions{
+		Headers:  mergeStrings(o.Headers, other.Headers),
+		Metadata: mergeStrings(o.Metadata, other.Metadata),
+		User:     o.User,
+	}
+	if other.User != "" {
+		result.User = other.User
+	}
+	return result
+}
+
+// requestOptions combines the provider's configured options with those in ctx
+func (c *ProviderConfig) requestOptions(ctx context.Context) RequestOptions {
+	configured := RequestOptions{
+		Headers:  c.Headers,
+		Metadata: c.Metadata,
+		User:     c.User,
+	}
+	return configured.Merge(RequestOptionsFromContext(ctx))
+}
+
+// httpHeader converts the headers to an http.Header
+func (o RequestOptions) httpHeader() http.Header {
+	header := make(http.Header, len(o.Headers))
+	for name, value := range o.Headers {
+		header.Set(name, value)
+	}
+	return header
+}
+
+func mergeStrings(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+	result := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range override {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,36 @@
+package providers
+
+import (
+	"context"
+	"time"
+)
+
+// Backoff bounds for providers whose SDK has no built-in retries
+const (
+	retryInitialBackoff = 500 * time.Millisecond
+	retryMaxBackoff     = 8 * time.Second
+)
+
+// withRetries calls fn until it succeeds, fails with a non-temporary error
+// or maxRetries retries have been made, backing off exponentially between
+// attempts. fn should return normalized provider errors.
+func withRetries(ctx context.Context, maxRetries int, fn func() error) error {
+	backoff := retryInitialBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= maxRetries || !IsTemporaryError(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > retryMaxBackoff {
+			backoff = retryMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrScriptExhausted is returned when a ScriptedProvider has no turn left
+// for a request and no Responder
+var ErrScriptExhausted = errors.New("scripted provider has no turn for request")
+
+// ScriptedTurn is one canned model response
+type ScriptedTurn struct {
+	// Agent restricts the turn to requests from the named agent (any if empty)
+	Agent string
+
+	Content          string
+	ToolCalls        []ToolCall
+	Handoff          *HandoffRequest
+	StructuredOutput interface{}
+	Usage            Usage
+
+	// Err makes the turn fail with this error instead of responding
+	Err error
+}
+
+// ScriptedRequest is a request received by a ScriptedProvider
+type ScriptedRequest struct {
+	Agent        string
+	Model        string
+	Instructions string
+	Messages     []Message
+	Tools        []ToolDefinition
+}
+
+// LastMessage returns the newest message of the request
+func (r ScriptedRequest) LastMessage() (Message, bool) {
+	if len(r.Messages) == 0 {
+		return Message{}, false
+	}
+	return r.Messages[len(r.Messages)-1], true
+}
+
+// HasTool reports whether a tool with the given name was offered
+func (r ScriptedRequest) HasTool(name string) bool {
+	for _, tool := range r.Tools {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Responder computes a completion for requests no queued turn matches
+type Responder func(req ScriptedRequest) (*Completion, error)
+
+// ScriptedProvider is a deterministic Provider for tests. Turns are served
+// in the order they were enqueued and every request is recorded so tests
+// can assert on what the runner sent.
+type ScriptedProvider struct {
+	mu        sync.Mutex
+	turns     []ScriptedTurn
+	requests  []ScriptedRequest
+	responder Responder
+}
+
+// NewScriptedProvider creates a provider that serves turns in order
+func NewScriptedProvider(turns ...ScriptedTurn) *ScriptedProvider {
+	return &ScriptedProvider{turns: turns}
+}
+
+// Enqueue appends turns to the script
+func (p *ScriptedProvider) Enqueue(turns ...ScriptedTurn) *ScriptedProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.turns = append(p.turns, turns...)
+	return p
+}
+
+// Reply enqueues a plain text response
+func (p *ScriptedProvider) Reply(content string) *ScriptedProvider {
+	return p.Enqueue(ScriptedTurn{Content: content})
+}
+
+// CallTools enqueues a response requesting the given tool calls. Calls
+// without an ID get one derived from their position in the script.
+func (p *ScriptedProvider) CallTools(calls ...ToolCall) *ScriptedProvider {
+	p.mu.Lock()
+	n := len(p.turns)
+	p.mu.Unlock()
+
+	for i := range calls {
+		if calls[i].ID == "" {
+			calls[i].ID = fmt.Sprintf("call_%d_%d", n, i)
+		}
+	}
+	return p.Enqueue(ScriptedTurn{ToolCalls: calls})
+}
+
+// HandoffTo enqueues a handoff to the named agent
+func (p *ScriptedProvider) HandoffTo(agent, reason string) *ScriptedProvider {
+	return p.Enqueue(ScriptedTurn{Handoff: &HandoffRequest{TargetAgent: agent, Reason: reason}})
+}
+
+// Fail enqueues a turn that returns err
+func (p *ScriptedProvider) Fail(err error) *ScriptedProvider {
+	return p.Enqueue(ScriptedTurn{Err: err})
+}
+
+// WithResponder sets a fallback used when no queued turn matches a request
+func (p *ScriptedProvider) WithResponder(responder Responder) *ScriptedProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.responder = responder
+	return p
+}
+
+// Complete implements the Provider interface
+func (p *ScriptedProvider) Complete(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition) (*Completion, error) {
+	req := ScriptedRequest{
+		Agent:        agent.GetName(),
+		Model:        agent.GetModel(),
+		Instructions: agent.GetInstructions(),
+		Messages:     append([]Message(nil), messages...),
+		Tools:        append([]ToolDefinition(nil), tools...),
+	}
+
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+
+	turn, ok := p.next(req.Agent)
+	responder := p.responder
+	p.mu.Unlock()
+
+	if !ok {
+		if responder != nil {
+			return responder(req)
+		}
+		return nil, fmt.Errorf("%w (agent %s, request %d)", ErrScriptExhausted, req.Agent, len(p.Requests()))
+	}
+
+	if turn.Err != nil {
+		return nil, turn.Err
+	}
+
+	return &Completion{
+		Message: Message{
+			Role:      "assistant",
+			Content:   turn.Content,
+			ToolCalls: turn.ToolCalls,
+		},
+		Usage:            turn.Usage,
+		ToolCalls:        turn.ToolCalls,
+		Handoff:          turn.Handoff,
+		StructuredOutput: turn.StructuredOutput,
+	}, nil
+}
+
+// next removes and returns the first turn usable by agent; callers hold p.mu
+func (p *ScriptedProvider) next(agent string) (ScriptedTurn, bool) {
+	for i, turn := range p.turns {
+		if turn.Agent == "" || turn.Agent == agent {
+			p.turns = append(p.turns[:i:i], p.turns[i+1:]...)
+			return turn, true
+		}
+	}
+	return ScriptedTurn{}, false
+}
+
+// Requests returns every request received so far
+func (p *ScriptedProvider) Requests() []ScriptedRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]ScriptedRequest(nil), p.requests...)
+}
+
+// LastRequest returns the most recent request
+func (p *ScriptedProvider) LastRequest() (ScriptedRequest, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.requests) == 0 {
+		return ScriptedRequest{}, false
+	}
+	return p.requests[len(p.requests)-1], true
+}
+
+// Remaining returns how many queued turns have not been served
+func (p *ScriptedProvider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.turns)
+}
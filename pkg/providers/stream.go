@@ -0,0 +1,12 @@
+package providers
+
+import "context"
+
+// ToolCallStreamer is implemented by providers that can stream a completion
+// and report each tool call as soon as its arguments are complete, while the
+// rest of the response is still arriving. onToolCall is called before
+// CompleteStreaming returns, in the order the calls appear, and must not
+// block; the returned completion holds every call as usual.
+type ToolCallStreamer interface {
+	CompleteStreaming(ctx context.Context, agent Agent, messages []Message, tools []ToolDefinition, onToolCall func(ToolCall)) (*Completion, error)
+}
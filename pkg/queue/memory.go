@@ -0,0 +1,193 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultVisibilityTimeout is how long a delivered job stays reserved
+const DefaultVisibilityTimeout = 5 * time.Minute
+
+// MemoryOption configures a MemoryBackend
+type MemoryOption func(*MemoryBackend)
+
+// WithMemoryVisibilityTimeout sets how long a delivered job stays reserved
+func WithMemoryVisibilityTimeout(timeout time.Duration) MemoryOption {
+	return func(b *MemoryBackend) {
+		b.visibility = timeout
+	}
+}
+
+// memoryJob is a queued job and its delivery state
+type memoryJob struct {
+	job       Job
+	attempts  int
+	visibleAt time.Time
+	token     string
+}
+
+// MemoryBackend is an in-process queue for development and single-process
+// deployments. Jobs are lost when the process exits.
+type MemoryBackend struct {
+	visibility time.Duration
+
+	mu     sync.Mutex
+	jobs   []*memoryJob
+	notify chan struct{}
+	closed bool
+}
+
+// NewMemoryBackend creates an empty in-process queue
+func NewMemoryBackend(opts ...MemoryOption) *MemoryBackend {
+	b := &MemoryBackend{
+		visibility: DefaultVisibilityTimeout,
+		notify:     make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Enqueue implements Backend
+func (b *MemoryBackend) Enqueue(ctx context.Context, job Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	b.jobs = append(b.jobs, &memoryJob{job: job})
+	b.wake()
+	return nil
+}
+
+// Dequeue implements Backend
+func (b *MemoryBackend) Dequeue(ctx context.Context) (*Delivery, error) {
+	for {
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			return nil, ErrClosed
+		}
+
+		now := time.Now()
+		var next time.Time
+		for _, j := range b.jobs {
+			if !j.visibleAt.After(now) {
+				j.attempts++
+				j.visibleAt = now.Add(b.visibility)
+				j.token = uuid.New().String()
+				b.mu.Unlock()
+				return &Delivery{Job: j.job, Attempt: j.attempts, token: j.token}, nil
+			}
+			if next.IsZero() || j.visibleAt.Before(next) {
+				next = j.visibleAt
+			}
+		}
+		notify := b.notify
+		b.mu.Unlock()
+
+		// Sleep until a job is added or the earliest reservation expires
+		var timer *time.Timer
+		var expired <-chan time.Time
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			expired = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			err := ctx.Err()
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil, err
+		case <-notify:
+		case <-expired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// Ack implements Backend
+func (b *MemoryBackend) Ack(ctx context.Context, delivery *Delivery) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, j := range b.jobs {
+		if j.token == delivery.token {
+			b.jobs = append(b.jobs[:i], b.jobs[i+1:]...)
+			return nil
+		}
+	}
+	// The reservation expired and the job was redelivered or acknowledged
+	return nil
+}
+
+// Nack implements Backend
+func (b *MemoryBackend) Nack(ctx context.Context, delivery *Delivery, delay time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, j := range b.jobs {
+		if j.token == delivery.token {
+			j.visibleAt = time.Now().Add(delay)
+			j.token = ""
+			b.wake()
+			return nil
+		}
+	}
+	return ErrLeaseLost
+}
+
+// Extend implements LeaseExtender
+func (b *MemoryBackend) Extend(ctx context.Context, delivery *Delivery) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, j := range b.jobs {
+		if j.token == delivery.token {
+			j.visibleAt = time.Now().Add(b.visibility)
+			return nil
+		}
+	}
+	return ErrLeaseLost
+}
+
+// VisibilityTimeout implements LeaseExtender
+func (b *MemoryBackend) VisibilityTimeout() time.Duration {
+	return b.visibility
+}
+
+// Len returns the number of queued and reserved jobs
+func (b *MemoryBackend) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.jobs)
+}
+
+// Close implements Backend, stopping blocked Dequeue calls
+func (b *MemoryBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.closed {
+		b.closed = true
+		b.wake()
+	}
+	return nil
+}
+
+// wake releases goroutines waiting in Dequeue; callers hold b.mu
+func (b *MemoryBackend) wake() {
+	close(b.notify)
+	b.notify = make(chan struct{})
+}
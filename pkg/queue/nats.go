@@ -0,0 +1,440 @@
+package queue
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSOption configures a NATSBackend
+type NATSOption func(*NATSBackend)
+
+// WithNATSCredentials authenticates with a user and password
+func WithNATSCredentials(user, password string) NATSOption {
+	return func(b *NATSBackend) {
+		b.options = append(b.options, nats.UserInfo(user, password))
+	}
+}
+
+// WithNATSToken authenticates with a token
+func WithNATSToken(token string) NATSOption {
+	return func(b *NATSBackend) {
+		b.options = append(b.options, nats.Token(token))
+	}
+}
+
+// WithNATSCredsFile authenticates with a JWT and NKey from a chained
+// credentials file, as issued for NGS/Synadia Cloud and operator-mode servers
+func WithNATSCredsFile(path string) NATSOption {
+	return func(b *NATSBackend) {
+		b.options = append(b.options, nats.UserCredentials(path))
+	}
+}
+
+// WithNATSNKey authenticates with the NKey seed in seedFile
+func WithNATSNKey(seedFile string) NATSOption {
+	return func(b *NATSBackend) {
+		b.nkeySeed = seedFile
+	}
+}
+
+// WithNATSTLS connects over TLS; config may carry client certificates for
+// mutual TLS
+func WithNATSTLS(config *tls.Config) NATSOption {
+	return func(b *NATSBackend) {
+		b.options = append(b.options, nats.Secure(config))
+	}
+}
+
+// WithNATSOptions passes options to the nats.go connection, for settings
+// the other options don't cover
+func WithNATSOptions(opts ...nats.Option) NATSOption {
+	return func(b *NATSBackend) {
+		b.options = append(b.options, opts...)
+	}
+}
+
+// WithNATSStream sets the JetStream stream holding jobs (default
+// "AGENT_JOBS") and its subject (default "agents.jobs")
+func WithNATSStream(stream, subject string) NATSOption {
+	return func(b *NATSBackend) {
+		b.stream = stream
+		b.subject = subject
+	}
+}
+
+// WithNATSConsumer sets the durable consumer shared by the worker fleet
+// (default "agents-workers")
+func WithNATSConsumer(consumer string) NATSOption {
+	return func(b *NATSBackend) {
+		b.consumer = consumer
+	}
+}
+
+// WithNATSAckWait sets how long a delivered job stays reserved before it is
+// redelivered, the consumer's ack wait
+func WithNATSAckWait(wait time.Duration) NATSOption {
+	return func(b *NATSBackend) {
+		b.ackWait = wait
+	}
+}
+
+// WithNATSResultTTL sets how long job results are kept (default 24h)
+func WithNATSResultTTL(ttl time.Duration) NATSOption {
+	return func(b *NATSBackend) {
+		b.resultTTL = ttl
+	}
+}
+
+// NATSBackend queues jobs on a JetStream work-queue stream consumed through
+// a durable pull consumer. Jobs are deduplicated by ID within the stream's
+// duplicate window. It also implements ResultStore, keeping the latest
+// result per job in a companion "<stream>_RESULTS" stream. The connection
+// is redialed after the server goes away, and the streams and consumer are
+// recreated if the restarted server lost them.
+type NATSBackend struct {
+	conn      *nats.Conn
+	js        jetstream.JetStream
+	options   []nats.Option
+	nkeySeed  string
+	stream    string
+	subject   string
+	consumer  string
+	ackWait   time.Duration
+	resultTTL time.Duration
+	closed    atomic.Bool
+
+	mu      sync.Mutex
+	pull    jetstream.Consumer
+	results jetstream.Stream
+}
+
+// natsPullWait bounds each pull request so cancellation is noticed
+const natsPullWait = 5 * time.Second
+
+// natsAckTimeout bounds acknowledgements without a deadline; a reply lost
+// while the connection is down would otherwise be waited for forever
+const natsAckTimeout = 10 * time.Second
+
+// NewNATSBackend connects to the NATS server and creates the streams and
+// consumer if needed. server is a URL such as "nats://[user:pass@]host:port"
+// or "tls://host:port", a comma-separated list of cluster URLs, or
+// "host:port".
+func NewNATSBackend(server string, opts ...NATSOption) (*NATSBackend, error) {
+	b := &NATSBackend{
+		// Keep redialing however long the server is away; nats.go gives up
+		// after a minute or two by default and closes the connection
+		options:   []nats.Option{nats.MaxReconnects(-1)},
+		stream:    "AGENT_JOBS",
+		subject:   "agents.jobs",
+		consumer:  "agents-workers",
+		ackWait:   DefaultVisibilityTimeout,
+		resultTTL: 24 * time.Hour,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	if b.nkeySeed != "" {
+		opt, err := nats.NkeyOptionFromSeed(b.nkeySeed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load nkey: %w", err)
+		}
+		b.options = append(b.options, opt)
+	}
+
+	conn, err := nats.Connect(server, b.options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	b.conn = conn
+
+	if b.js, err = jetstream.New(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open jetstream: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := b.setup(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// setup creates the job stream, the result stream and the consumer, or looks
+// up the ones that already exist
+func (b *NATSBackend) setup(ctx context.Context) error {
+	_, err := b.js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:      b.stream,
+		Subjects:  []string{b.subject},
+		Retention: jetstream.WorkQueuePolicy,
+		Storage:   jetstream.FileStorage,
+	})
+	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return fmt.Errorf("failed to create stream: %w", err)
+	}
+
+	results, err := b.js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:              b.resultStream(),
+		Subjects:          []string{b.subject + ".results.>"},
+		Storage:           jetstream.FileStorage,
+		MaxMsgsPerSubject: 1,
+		MaxAge:            b.resultTTL,
+	})
+	if errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		results, err = b.js.Stream(ctx, b.resultStream())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create result stream: %w", err)
+	}
+
+	pull, err := b.js.CreateConsumer(ctx, b.stream, jetstream.ConsumerConfig{
+		Durable:       b.consumer,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+		AckWait:       b.ackWait,
+	})
+	if errors.Is(err, jetstream.ErrConsumerExists) {
+		pull, err = b.js.Consumer(ctx, b.stream, b.consumer)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create consumer: %w", err)
+	}
+
+	b.mu.Lock()
+	b.pull = pull
+	b.results = results
+	b.mu.Unlock()
+	return nil
+}
+
+// Enqueue implements Backend
+func (b *NATSBackend) Enqueue(ctx context.Context, job Job) error {
+	if !validToken(job.ID) {
+		return fmt.Errorf("invalid job ID %q for NATS", job.ID)
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	if err := b.publish(ctx, b.subject, data, jetstream.WithMsgID(job.ID)); err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", b.connError(err))
+	}
+	return nil
+}
+
+// Dequeue implements Backend
+func (b *NATSBackend) Dequeue(ctx context.Context) (*Delivery, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		wait := natsPullWait
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			wait = time.Until(deadline)
+		}
+		if wait < time.Millisecond {
+			return nil, ctx.Err()
+		}
+
+		b.mu.Lock()
+		pull := b.pull
+		b.mu.Unlock()
+
+		batch, err := pull.Fetch(1, jetstream.FetchMaxWait(wait))
+		if err == nil {
+			var msg jetstream.Msg
+			for m := range batch.Messages() {
+				msg = m
+			}
+			if msg != nil {
+				if delivery := b.delivery(msg); delivery != nil {
+					return delivery, nil
+				}
+				continue
+			}
+			err = batch.Error()
+		}
+		if err == nil || errors.Is(err, nats.ErrTimeout) {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if lostStream(err) && b.setup(ctx) == nil {
+			continue
+		}
+		if b.closed.Load() {
+			return nil, ErrClosed
+		}
+		return nil, fmt.Errorf("failed to pull job: %w", err)
+	}
+}
+
+// delivery decodes a pulled message, terminating messages that aren't jobs
+// so they aren't redelivered
+func (b *NATSBackend) delivery(msg jetstream.Msg) *Delivery {
+	var job Job
+	if err := json.Unmarshal(msg.Data(), &job); err != nil {
+		msg.Term()
+		return nil
+	}
+
+	attempt := 1
+	if meta, err := msg.Metadata(); err == nil && meta.NumDelivered > 0 {
+		attempt = int(meta.NumDelivered)
+	}
+
+	// The reply subject is all later acknowledgements need, so deliveries
+	// survive a reconnect
+	return &Delivery{Job: job, Attempt: attempt, token: msg.Reply()}
+}
+
+// Ack implements Backend, waiting for the server to confirm
+func (b *NATSBackend) Ack(ctx context.Context, delivery *Delivery) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, natsAckTimeout)
+		defer cancel()
+	}
+
+	if _, err := b.conn.RequestWithContext(ctx, delivery.token, []byte("+ACK")); err != nil {
+		return fmt.Errorf("failed to acknowledge job: %w", b.connError(err))
+	}
+	return nil
+}
+
+// Nack implements Backend
+func (b *NATSBackend) Nack(ctx context.Context, delivery *Delivery, delay time.Duration) error {
+	nak := []byte("-NAK")
+	if delay > 0 {
+		nak = []byte(fmt.Sprintf(`-NAK {"delay": %d}`, delay.Nanoseconds()))
+	}
+
+	if err := b.conn.Publish(delivery.token, nak); err != nil {
+		return fmt.Errorf("failed to return job: %w", b.connError(err))
+	}
+	return nil
+}
+
+// Extend implements LeaseExtender by reporting the job in progress, which
+// restarts the consumer's ack wait
+func (b *NATSBackend) Extend(ctx context.Context, delivery *Delivery) error {
+	if err := b.conn.Publish(delivery.token, []byte("+WPI")); err != nil {
+		return fmt.Errorf("failed to extend job: %w", b.connError(err))
+	}
+	return nil
+}
+
+// VisibilityTimeout implements LeaseExtender
+func (b *NATSBackend) VisibilityTimeout() time.Duration {
+	return b.ackWait
+}
+
+// SaveResult implements ResultStore
+func (b *NATSBackend) SaveResult(ctx context.Context, result Result) error {
+	if !validToken(result.JobID) {
+		return fmt.Errorf("invalid job ID %q for NATS", result.JobID)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	if err := b.publish(ctx, b.resultSubject(result.JobID), data); err != nil {
+		return fmt.Errorf("failed to store result: %w", b.connError(err))
+	}
+	return nil
+}
+
+// Result implements ResultStore
+func (b *NATSBackend) Result(ctx context.Context, jobID string) (*Result, error) {
+	if !validToken(jobID) {
+		return nil, ErrNotFound
+	}
+
+	b.mu.Lock()
+	results := b.results
+	b.mu.Unlock()
+
+	msg, err := results.GetLastMsgForSubject(ctx, b.resultSubject(jobID))
+	if errors.Is(err, jetstream.ErrMsgNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load result: %w", b.connError(err))
+	}
+
+	var result Result
+	if err := json.Unmarshal(msg.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+	return &result, nil
+}
+
+// Close implements Backend
+func (b *NATSBackend) Close() error {
+	b.closed.Store(true)
+	b.conn.Close()
+	return nil
+}
+
+func (b *NATSBackend) resultStream() string {
+	return b.stream + "_RESULTS"
+}
+
+func (b *NATSBackend) resultSubject(jobID string) string {
+	return b.subject + ".results." + jobID
+}
+
+// publish stores a message in JetStream, recreating the streams once if the
+// server lost them
+func (b *NATSBackend) publish(ctx context.Context, subject string, data []byte, opts ...jetstream.PublishOpt) error {
+	_, err := b.js.Publish(ctx, subject, data, opts...)
+	if lostStream(err) && b.setup(ctx) == nil {
+		_, err = b.js.Publish(ctx, subject, data, opts...)
+	}
+	return err
+}
+
+// lostStream reports whether err means the streams or consumer are gone, as
+// after a server restarted without its storage
+func lostStream(err error) bool {
+	return errors.Is(err, jetstream.ErrStreamNotFound) ||
+		errors.Is(err, jetstream.ErrConsumerNotFound) ||
+		errors.Is(err, jetstream.ErrConsumerDeleted) ||
+		errors.Is(err, jetstream.ErrNoStreamResponse) ||
+		errors.Is(err, nats.ErrNoResponders)
+}
+
+// connError reports ErrClosed for failures after Close
+func (b *NATSBackend) connError(err error) error {
+	if b.closed.Load() {
+		return ErrClosed
+	}
+	return err
+}
+
+// validToken reports whether s can be used as a NATS subject token
+func validToken(s string) bool {
+	return s != "" && !strings.ContainsAny(s, " \t\r\n.*>")
+}
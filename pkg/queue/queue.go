@@ -0,0 +1,239 @@
+// Package queue distributes agent runs over a task queue so that web
+// handlers can enqueue work and a fleet of workers executes it with retries,
+// visibility timeouts and result storage.
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound is returned for a job without a stored result
+	ErrNotFound = errors.New("job result not found")
+
+	// ErrUnknownAgent is recorded for jobs naming an agent the worker doesn't
+	// serve; such jobs fail without retries
+	ErrUnknownAgent = errors.New("unknown agent")
+
+	// ErrClosed is returned by a backend after Close
+	ErrClosed = errors.New("queue closed")
+
+	// ErrLeaseLost is returned when renewing or returning a delivery whose
+	// reservation expired and was claimed by another worker
+	ErrLeaseLost = errors.New("job lease lost")
+)
+
+// Job is a queued agent run
+type Job struct {
+	ID         string            `json:"id"`
+	Agent      string            `json:"agent"`
+	Input      string            `json:"input"`
+	SessionID  string            `json:"session_id,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	EnqueuedAt time.Time         `json:"enqueued_at"`
+
+	// MaxAttempts overrides the worker's attempt limit for this job
+	MaxAttempts int `json:"max_attempts,omitempty"`
+}
+
+// Delivery is a job handed to a worker. It stays reserved for the backend's
+// visibility timeout; unless acknowledged in that time it is redelivered.
+type Delivery struct {
+	Job Job
+
+	// Attempt counts deliveries of the job, starting at 1
+	Attempt int
+
+	// token identifies the delivery to the backend
+	token string
+}
+
+// Backend stores queued jobs
+type Backend interface {
+	// Enqueue adds a job to the queue
+	Enqueue(ctx context.Context, job Job) error
+
+	// Dequeue blocks until a job is available or ctx is done
+	Dequeue(ctx context.Context) (*Delivery, error)
+
+	// Ack removes a delivered job from the queue
+	Ack(ctx context.Context, delivery *Delivery) error
+
+	// Nack returns a delivered job to the queue after delay
+	Nack(ctx context.Context, delivery *Delivery, delay time.Duration) error
+
+	// Close releases the backend's connections
+	Close() error
+}
+
+// LeaseExtender is implemented by backends whose reservations can be
+// renewed. Workers renew a delivery's reservation while its job runs, so a
+// run slower than the visibility timeout isn't redelivered to another
+// worker and executed twice.
+type LeaseExtender interface {
+	// Extend restarts the visibility timeout of a delivery, or returns
+	// ErrLeaseLost once the job was redelivered to another worker
+	Extend(ctx context.Context, delivery *Delivery) error
+
+	// VisibilityTimeout returns how long a delivery stays reserved
+	VisibilityTimeout() time.Duration
+}
+
+// Status is the outcome of a job
+type Status string
+
+const (
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// Result is the stored outcome of a job
+type Result struct {
+	JobID       string      `json:"job_id"`
+	Status      Status      `json:"status"`
+	Agent       string      `json:"agent"`
+	Output      interface{} `json:"output,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	Attempts    int         `json:"attempts"`
+	CompletedAt time.Time   `json:"completed_at"`
+}
+
+// ResultStore keeps job results for producers to collect
+type ResultStore interface {
+	// SaveResult stores the result of a job
+	SaveResult(ctx context.Context, result Result) error
+
+	// Result returns the result of a job, or ErrNotFound
+	Result(ctx context.Context, jobID string) (*Result, error)
+}
+
+// JobOption configures an enqueued job
+type JobOption func(*Job)
+
+// WithJobID sets the job ID instead of generating one; enqueuing the same ID
+// twice with an idempotent worker runs the job once
+func WithJobID(id string) JobOption {
+	return func(j *Job) {
+		j.ID = id
+	}
+}
+
+// WithJobSession runs the job on a stored session
+func WithJobSession(sessionID string) JobOption {
+	return func(j *Job) {
+		j.SessionID = sessionID
+	}
+}
+
+// WithJobMetadata attaches metadata to the job
+func WithJobMetadata(metadata map[string]string) JobOption {
+	return func(j *Job) {
+		j.Metadata = metadata
+	}
+}
+
+// WithJobMaxAttempts limits how often the job is tried
+func WithJobMaxAttempts(attempts int) JobOption {
+	return func(j *Job) {
+		j.MaxAttempts = attempts
+	}
+}
+
+// Producer enqueues agent runs and collects their results
+type Producer struct {
+	backend Backend
+	results ResultStore
+}
+
+// NewProducer creates a producer. results may be nil when the caller doesn't
+// collect results.
+func NewProducer(backend Backend, results ResultStore) *Producer {
+	return &Producer{backend: backend, results: results}
+}
+
+// Enqueue queues a run of the named agent and returns the job ID
+func (p *Producer) Enqueue(ctx context.Context, agent, input string, opts ...JobOption) (string, error) {
+	job := Job{
+		Agent:      agent,
+		Input:      input,
+		EnqueuedAt: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(&job)
+	}
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+
+	if err := p.backend.Enqueue(ctx, job); err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// Result returns the result of a job, or ErrNotFound while it is pending
+func (p *Producer) Result(ctx context.Context, jobID string) (*Result, error) {
+	if p.results == nil {
+		return nil, ErrNotFound
+	}
+	return p.results.Result(ctx, jobID)
+}
+
+// Wait polls for the result of a job until it is stored or ctx is done
+func (p *Producer) Wait(ctx context.Context, jobID string, interval time.Duration) (*Result, error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := p.Result(ctx, jobID)
+		if !errors.Is(err, ErrNotFound) {
+			return result, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// MemoryResults is an in-process ResultStore
+type MemoryResults struct {
+	mu      sync.RWMutex
+	results map[string]Result
+}
+
+// NewMemoryResults creates an empty in-process result store
+func NewMemoryResults() *MemoryResults {
+	return &MemoryResults{results: make(map[string]Result)}
+}
+
+// SaveResult implements ResultStore
+func (s *MemoryResults) SaveResult(ctx context.Context, result Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[result.JobID] = result
+	return nil
+}
+
+// Result implements ResultStore
+func (s *MemoryResults) Result(ctx context.Context, jobID string) (*Result, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result, ok := s.results[jobID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &result, nil
+}
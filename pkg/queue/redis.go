@@ -0,0 +1,458 @@
+package queue
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisOption configures a RedisBackend
+type RedisOption func(*RedisBackend)
+
+// WithRedisAuth sets the credentials; username may be empty for servers
+// without ACL users
+func WithRedisAuth(username, password string) RedisOption {
+	return func(b *RedisBackend) {
+		b.options.Username = username
+		b.options.Password = password
+	}
+}
+
+// WithRedisCredentialsProvider fetches credentials for each new connection,
+// for short-lived tokens such as ElastiCache IAM authentication
+func WithRedisCredentialsProvider(provider func(ctx context.Context) (username, password string, err error)) RedisOption {
+	return func(b *RedisBackend) {
+		b.options.CredentialsProviderContext = provider
+	}
+}
+
+// WithRedisTLS connects over TLS; config may carry client certificates for
+// mutual TLS
+func WithRedisTLS(config *tls.Config) RedisOption {
+	return func(b *RedisBackend) {
+		b.options.TLSConfig = config
+	}
+}
+
+// WithRedisDB selects the logical database
+func WithRedisDB(db int) RedisOption {
+	return func(b *RedisBackend) {
+		b.options.DB = db
+	}
+}
+
+// WithRedisStream sets the stream jobs are added to (default "agents:jobs")
+func WithRedisStream(stream string) RedisOption {
+	return func(b *RedisBackend) {
+		b.stream = stream
+	}
+}
+
+// WithRedisGroup sets the consumer group shared by the worker fleet
+// (default "agents-workers")
+func WithRedisGroup(group string) RedisOption {
+	return func(b *RedisBackend) {
+		b.group = group
+	}
+}
+
+// WithRedisConsumer names this worker within the group (default hostname
+// and process ID)
+func WithRedisConsumer(consumer string) RedisOption {
+	return func(b *RedisBackend) {
+		b.consumer = consumer
+	}
+}
+
+// WithRedisVisibilityTimeout sets how long a delivered job stays reserved
+// before other workers may claim it
+func WithRedisVisibilityTimeout(timeout time.Duration) RedisOption {
+	return func(b *RedisBackend) {
+		b.visibility = timeout
+	}
+}
+
+// WithRedisResultTTL sets how long job results are kept (default 24h)
+func WithRedisResultTTL(ttl time.Duration) RedisOption {
+	return func(b *RedisBackend) {
+		b.resultTTL = ttl
+	}
+}
+
+// RedisBackend queues jobs on a Redis stream read by a consumer group.
+// Unacknowledged jobs are claimed by other workers once the visibility
+// timeout passes. It also implements ResultStore, keeping results under
+// "<stream>:result:<job id>". Connections are pooled and redialed by
+// go-redis. Requires Redis 6.2 or later.
+type RedisBackend struct {
+	client     *redis.Client
+	options    redis.Options
+	stream     string
+	group      string
+	consumer   string
+	visibility time.Duration
+	resultTTL  time.Duration
+	closed     atomic.Bool
+}
+
+// redisBlock bounds each blocking read so cancellation is noticed
+const redisBlock = 5 * time.Second
+
+// reserveScript resets the idle time of a pending entry, but only while this
+// consumer still owns it: once the visibility timeout lapses another worker
+// may have claimed it, and taking it back would run the job twice.
+// KEYS[1] is the stream; ARGV is the group, consumer, entry ID and idle time
+// in milliseconds.
+var reserveScript = redis.NewScript(`
+local pending = redis.call('XPENDING', KEYS[1], ARGV[1], ARGV[3], ARGV[3], 1)
+if #pending == 0 or pending[1][2] ~= ARGV[2] then
+	return 0
+end
+redis.call('XCLAIM', KEYS[1], ARGV[1], ARGV[2], 0, ARGV[3], 'IDLE', ARGV[4], 'JUSTID')
+return 1
+`)
+
+// NewRedisBackend connects to Redis at addr ("host:port") and creates the
+// consumer group if needed
+func NewRedisBackend(addr string, opts ...RedisOption) (*RedisBackend, error) {
+	hostname, _ := os.Hostname()
+	b := &RedisBackend{
+		options:    redis.Options{Addr: addr},
+		stream:     "agents:jobs",
+		group:      "agents-workers",
+		consumer:   fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		visibility: DefaultVisibilityTimeout,
+		resultTTL:  24 * time.Hour,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.client = redis.NewClient(&b.options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := b.setup(ctx); err != nil {
+		b.client.Close()
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// setup creates the stream and consumer group if they don't exist
+func (b *RedisBackend) setup(ctx context.Context) error {
+	err := b.client.XGroupCreateMkStream(ctx, b.stream, b.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	return nil
+}
+
+// Enqueue implements Backend
+func (b *RedisBackend) Enqueue(ctx context.Context, job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	err = b.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.stream,
+		Values: map[string]interface{}{"job": string(data)},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", b.clientError(err))
+	}
+	return nil
+}
+
+// Dequeue implements Backend. Expired reservations are claimed before new
+// jobs are read.
+func (b *RedisBackend) Dequeue(ctx context.Context) (*Delivery, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		delivery, err := b.claim(ctx)
+		if err == nil && delivery == nil {
+			delivery, err = b.read(ctx)
+		}
+		if err == nil {
+			if delivery != nil {
+				return delivery, nil
+			}
+			continue
+		}
+
+		if b.closed.Load() {
+			return nil, ErrClosed
+		}
+		// A server restarted without its data has lost the group
+		var replyErr redis.Error
+		if errors.As(err, &replyErr) && strings.HasPrefix(replyErr.Error(), "NOGROUP") && b.setup(ctx) == nil {
+			continue
+		}
+		return nil, err
+	}
+}
+
+// claim takes over a job whose reservation expired. Jobs returned with a
+// delay longer than the visibility timeout are reserved again until due.
+func (b *RedisBackend) claim(ctx context.Context) (*Delivery, error) {
+	// JUSTID takes the entry without counting a delivery, which only the
+	// XCLAIM below does once the job is due
+	ids, _, err := b.client.XAutoClaimJustID(ctx, &redis.XAutoClaimArgs{
+		Stream:   b.stream,
+		Group:    b.group,
+		Consumer: b.consumer,
+		MinIdle:  b.visibility,
+		Start:    "0-0",
+		Count:    1,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", b.clientError(err))
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	id := ids[0]
+
+	due, err := b.client.Get(ctx, b.dueKey(id)).Int64()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to claim job: %w", b.clientError(err))
+	}
+	if err == nil {
+		if wait := time.Until(time.UnixMilli(due)); wait > 0 {
+			return nil, b.deferEntry(ctx, id, wait)
+		}
+	}
+
+	// XAUTOCLAIM just reset the entry's idle time, so no other worker can
+	// claim it before this one does
+	entries, err := b.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   b.stream,
+		Group:    b.group,
+		Consumer: b.consumer,
+		Messages: []string{id},
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", b.clientError(err))
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	job, ok := b.parseEntry(ctx, entries[0])
+	if !ok {
+		return nil, nil
+	}
+
+	attempt := 1
+	pending, err := b.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: b.stream,
+		Group:  b.group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err == nil && len(pending) > 0 {
+		attempt = int(pending[0].RetryCount)
+	}
+
+	return &Delivery{Job: job, Attempt: attempt, token: id}, nil
+}
+
+// read waits for a new job
+func (b *RedisBackend) read(ctx context.Context) (*Delivery, error) {
+	block := redisBlock
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < block {
+		block = time.Until(deadline)
+	}
+	if block < time.Millisecond {
+		return nil, ctx.Err()
+	}
+
+	streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    b.group,
+		Consumer: b.consumer,
+		Streams:  []string{b.stream, ">"},
+		Count:    1,
+		Block:    block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("failed to read job: %w", b.clientError(err))
+	}
+	if len(streams) == 0 || len(streams[0].Messages) == 0 {
+		return nil, nil
+	}
+
+	entry := streams[0].Messages[0]
+	job, ok := b.parseEntry(ctx, entry)
+	if !ok {
+		return nil, nil
+	}
+	return &Delivery{Job: job, Attempt: 1, token: entry.ID}, nil
+}
+
+// parseEntry decodes a stream entry, dropping entries that aren't jobs so
+// they aren't redelivered forever
+func (b *RedisBackend) parseEntry(ctx context.Context, entry redis.XMessage) (Job, bool) {
+	var job Job
+	if data, ok := entry.Values["job"].(string); ok {
+		if err := json.Unmarshal([]byte(data), &job); err == nil {
+			return job, true
+		}
+	}
+
+	b.remove(ctx, entry.ID)
+	return Job{}, false
+}
+
+// Ack implements Backend
+func (b *RedisBackend) Ack(ctx context.Context, delivery *Delivery) error {
+	return b.remove(ctx, delivery.token)
+}
+
+// remove acknowledges and deletes a stream entry
+func (b *RedisBackend) remove(ctx context.Context, id string) error {
+	if err := b.client.XAck(ctx, b.stream, b.group, id).Err(); err != nil {
+		return fmt.Errorf("failed to acknowledge job: %w", b.clientError(err))
+	}
+	if err := b.client.XDel(ctx, b.stream, id).Err(); err != nil {
+		return fmt.Errorf("failed to delete job: %w", b.clientError(err))
+	}
+	if err := b.client.Del(ctx, b.dueKey(id)).Err(); err != nil {
+		return fmt.Errorf("failed to delete job: %w", b.clientError(err))
+	}
+	return nil
+}
+
+// Nack implements Backend by backdating the reservation so the job can be
+// claimed once delay has passed. Reservations can't be dated into the
+// future, so for delays longer than the visibility timeout the due time is
+// stored under "<stream>:due:<entry id>" and checked when the job is claimed.
+// It returns ErrLeaseLost if another worker has claimed the job.
+func (b *RedisBackend) Nack(ctx context.Context, delivery *Delivery, delay time.Duration) error {
+	if err := b.deferEntry(ctx, delivery.token, delay); err != nil {
+		return err
+	}
+
+	if delay > b.visibility {
+		at := time.Now().Add(delay).UnixMilli()
+		if err := b.client.Set(ctx, b.dueKey(delivery.token), at, delay+b.visibility).Err(); err != nil {
+			return fmt.Errorf("failed to return job: %w", b.clientError(err))
+		}
+	}
+	return nil
+}
+
+// deferEntry reserves a pending entry this consumer owns so it can be claimed
+// after delay, or after the visibility timeout if that is shorter
+func (b *RedisBackend) deferEntry(ctx context.Context, id string, delay time.Duration) error {
+	idle := b.visibility - delay
+	if idle < 0 {
+		idle = 0
+	}
+
+	if err := b.reserve(ctx, id, idle); err != nil {
+		return fmt.Errorf("failed to return job: %w", err)
+	}
+	return nil
+}
+
+// Extend implements LeaseExtender by resetting the entry's idle time. It
+// returns ErrLeaseLost if another worker has claimed the job.
+func (b *RedisBackend) Extend(ctx context.Context, delivery *Delivery) error {
+	if err := b.reserve(ctx, delivery.token, 0); err != nil {
+		return fmt.Errorf("failed to extend job: %w", err)
+	}
+	return nil
+}
+
+// reserve sets the idle time of an entry this consumer still owns
+func (b *RedisBackend) reserve(ctx context.Context, id string, idle time.Duration) error {
+	owned, err := reserveScript.Run(ctx, b.client, []string{b.stream},
+		b.group, b.consumer, id, idle.Milliseconds()).Int()
+	if err != nil {
+		return b.clientError(err)
+	}
+	if owned == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// VisibilityTimeout implements LeaseExtender
+func (b *RedisBackend) VisibilityTimeout() time.Duration {
+	return b.visibility
+}
+
+// SaveResult implements ResultStore
+func (b *RedisBackend) SaveResult(ctx context.Context, result Result) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	if err := b.client.Set(ctx, b.resultKey(result.JobID), data, b.resultTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store result: %w", b.clientError(err))
+	}
+	return nil
+}
+
+// Result implements ResultStore
+func (b *RedisBackend) Result(ctx context.Context, jobID string) (*Result, error) {
+	data, err := b.client.Get(ctx, b.resultKey(jobID)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load result: %w", b.clientError(err))
+	}
+
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+	return &result, nil
+}
+
+// Close implements Backend
+func (b *RedisBackend) Close() error {
+	if b.closed.Swap(true) {
+		return nil
+	}
+	return b.client.Close()
+}
+
+func (b *RedisBackend) resultKey(jobID string) string {
+	return b.stream + ":result:" + jobID
+}
+
+func (b *RedisBackend) dueKey(id string) string {
+	return b.stream + ":due:" + id
+}
+
+// clientError reports ErrClosed for failures after Close
+func (b *RedisBackend) clientError(err error) error {
+	if b.closed.Load() {
+		return ErrClosed
+	}
+	return err
+}
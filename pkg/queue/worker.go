@@ -0,0 +1,315 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/agents"
+)
+
+// DefaultMaxAttempts is how often a failing job is tried before it is
+// recorded as failed
+const DefaultMaxAttempts = 3
+
+// Backoff returns the delay before retrying a job after the given attempt
+type Backoff func(attempt int) time.Duration
+
+// ExponentialBackoff doubles the delay from base after each attempt, up to max
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
+		}
+		return delay
+	}
+}
+
+// WorkerOption configures a Worker
+type WorkerOption func(*Worker)
+
+// WithAgents registers the agents the worker can run, by name
+func WithAgents(list ...*agents.Agent) WorkerOption {
+	return func(w *Worker) {
+		for _, agent := range list {
+			w.agents[agent.Name] = agent
+		}
+	}
+}
+
+// WithResults stores job results in store
+func WithResults(store ResultStore) WorkerOption {
+	return func(w *Worker) {
+		w.results = store
+	}
+}
+
+// WithConcurrency sets how many jobs the worker runs at once
+func WithConcurrency(n int) WorkerOption {
+	return func(w *Worker) {
+		if n > 0 {
+			w.concurrency = n
+		}
+	}
+}
+
+// WithMaxAttempts sets how often a failing job is tried
+func WithMaxAttempts(attempts int) WorkerOption {
+	return func(w *Worker) {
+		if attempts > 0 {
+			w.maxAttempts = attempts
+		}
+	}
+}
+
+// WithRetryBackoff sets the delay between attempts of a failing job
+func WithRetryBackoff(backoff Backoff) WorkerOption {
+	return func(w *Worker) {
+		w.backoff = backoff
+	}
+}
+
+// WithIdempotentRuns passes the job ID as the run's idempotency key, so a
+// job redelivered after completing isn't executed again. The runner must be
+// configured with a run store.
+func WithIdempotentRuns(enabled bool) WorkerOption {
+	return func(w *Worker) {
+		w.idempotent = enabled
+	}
+}
+
+// WithWorkerLogger sets the logger for job failures
+func WithWorkerLogger(logger *slog.Logger) WorkerOption {
+	return func(w *Worker) {
+		w.logger = logger
+	}
+}
+
+// Worker consumes jobs from a backend and runs them
+type Worker struct {
+	backend     Backend
+	runner      *agents.Runner
+	agents      map[string]*agents.Agent
+	results     ResultStore
+	concurrency int
+	maxAttempts int
+	backoff     Backoff
+	idempotent  bool
+	logger      *slog.Logger
+}
+
+// NewWorker creates a worker running jobs from backend with runner
+func NewWorker(backend Backend, runner *agents.Runner, opts ...WorkerOption) *Worker {
+	w := &Worker{
+		backend:     backend,
+		runner:      runner,
+		agents:      make(map[string]*agents.Agent),
+		concurrency: 1,
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     ExponentialBackoff(time.Second, time.Minute),
+		logger:      slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// dequeueBackoff spaces out attempts to dequeue while the backend fails
+var dequeueBackoff = ExponentialBackoff(time.Second, 30*time.Second)
+
+// Run processes jobs until ctx is done, then waits for jobs in progress.
+// Failures to dequeue are logged and retried with backoff. It returns nil
+// after cancellation and ErrClosed once the backend is closed.
+func (w *Worker) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, w.concurrency)
+
+	defer wg.Wait()
+	failures := 0
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil
+		}
+
+		delivery, err := w.backend.Dequeue(ctx)
+		if err != nil {
+			<-slots
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, ErrClosed) {
+				return err
+			}
+
+			failures++
+			delay := dequeueBackoff(failures)
+			w.logger.Error("failed to dequeue job, retrying", "attempt", failures, "retry_in", delay, "error", err)
+			select {
+			case <-time.After(delay):
+				continue
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		failures = 0
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			// Jobs in progress finish even when the worker is stopping
+			w.process(context.WithoutCancel(ctx), delivery)
+		}()
+	}
+}
+
+// process runs one delivery and acknowledges, retries or fails it
+func (w *Worker) process(ctx context.Context, delivery *Delivery) {
+	job := delivery.Job
+	runCtx, stop := w.extendWhileRunning(ctx, delivery)
+	output, err := w.execute(runCtx, job)
+	stop()
+	if errors.Is(context.Cause(runCtx), ErrLeaseLost) {
+		// Another worker owns the job now; acknowledging or returning it
+		// would interfere with that delivery
+		w.logger.Warn("agent job lease lost, abandoning run", "job", job.ID, "attempt", delivery.Attempt)
+		return
+	}
+	if err == nil {
+		w.finish(ctx, delivery, Result{
+			JobID:    job.ID,
+			Status:   StatusCompleted,
+			Agent:    job.Agent,
+			Output:   output,
+			Attempts: delivery.Attempt,
+		})
+		return
+	}
+
+	maxAttempts := w.maxAttempts
+	if job.MaxAttempts > 0 {
+		maxAttempts = job.MaxAttempts
+	}
+
+	if delivery.Attempt < maxAttempts && retryable(err) {
+		w.logger.Warn("agent job failed, retrying", "job", job.ID, "attempt", delivery.Attempt, "error", err)
+		if nackErr := w.backend.Nack(ctx, delivery, w.backoff(delivery.Attempt)); nackErr != nil {
+			w.logger.Error("failed to return job to queue", "job", job.ID, "error", nackErr)
+		}
+		return
+	}
+
+	w.logger.Error("agent job failed", "job", job.ID, "attempt", delivery.Attempt, "error", err)
+	w.finish(ctx, delivery, Result{
+		JobID:    job.ID,
+		Status:   StatusFailed,
+		Agent:    job.Agent,
+		Error:    err.Error(),
+		Attempts: delivery.Attempt,
+	})
+}
+
+// extendWhileRunning renews the delivery's reservation at a third of the
+// visibility timeout until the returned function is called, for backends
+// that support it. The returned context is cancelled with ErrLeaseLost as
+// its cause once the reservation can't be kept, so the run stops.
+func (w *Worker) extendWhileRunning(ctx context.Context, delivery *Delivery) (context.Context, func()) {
+	extender, ok := w.backend.(LeaseExtender)
+	if !ok || extender.VisibilityTimeout() <= 0 {
+		return ctx, func() {}
+	}
+
+	runCtx, cancelRun := context.WithCancelCause(ctx)
+	extendCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(extender.VisibilityTimeout() / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-extendCtx.Done():
+				return
+			case <-ticker.C:
+				err := extender.Extend(extendCtx, delivery)
+				if errors.Is(err, ErrLeaseLost) {
+					cancelRun(ErrLeaseLost)
+					return
+				}
+				if err != nil && extendCtx.Err() == nil {
+					w.logger.Warn("failed to extend job reservation", "job", delivery.Job.ID, "error", err)
+				}
+			}
+		}
+	}()
+
+	return runCtx, func() {
+		cancel()
+		<-done
+		cancelRun(nil)
+	}
+}
+
+// execute runs the job's agent
+func (w *Worker) execute(ctx context.Context, job Job) (interface{}, error) {
+	agent, ok := w.agents[job.Agent]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAgent, job.Agent)
+	}
+
+	var opts []agents.RunOption
+	if job.SessionID != "" {
+		opts = append(opts, agents.WithSessionID(job.SessionID))
+	}
+	if w.idempotent {
+		opts = append(opts, agents.WithIdempotencyKey(job.ID))
+	}
+	if len(job.Metadata) > 0 {
+		opts = append(opts, agents.WithRequestMetadata(job.Metadata))
+	}
+
+	result, err := w.runner.Run(ctx, agent, job.Input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return result.FinalOutput, nil
+}
+
+// finish stores the job's result and removes it from the queue. A job whose
+// result can't be stored stays queued and is redelivered.
+func (w *Worker) finish(ctx context.Context, delivery *Delivery, result Result) {
+	result.CompletedAt = time.Now()
+	if w.results != nil {
+		if err := w.results.SaveResult(ctx, result); err != nil {
+			w.logger.Error("failed to store job result", "job", result.JobID, "error", err)
+			return
+		}
+	}
+
+	if err := w.backend.Ack(ctx, delivery); err != nil {
+		w.logger.Error("failed to acknowledge job", "job", result.JobID, "error", err)
+	}
+}
+
+// retryable reports whether a failed job may succeed on another attempt
+func retryable(err error) bool {
+	return !errors.Is(err, ErrUnknownAgent) &&
+		!errors.Is(err, agents.ErrNoSessionStore) &&
+		!errors.Is(err, agents.ErrNoRunStore) &&
+		!errors.Is(err, agents.ErrRunFailed) &&
+		!errors.Is(err, agents.ErrUnsupported)
+}
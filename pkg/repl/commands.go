@@ -0,0 +1,108 @@
+package repl
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/agents"
+)
+
+// Command is a slash command, entered as /Name followed by arguments
+type Command struct {
+	Name string
+
+	// Usage shows the arguments, e.g. "[name]"
+	Usage string
+	Help  string
+
+	Run func(ctx context.Context, r *REPL, args []string) error
+}
+
+// builtinCommands are available in every REPL unless replaced
+func builtinCommands() []Command {
+	return []Command{
+		{
+			Name: "help",
+			Help: "list commands",
+			Run: func(ctx context.Context, r *REPL, args []string) error {
+				names := make([]string, 0, len(r.commands))
+				for name := range r.commands {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				for _, name := range names {
+					command := r.commands[name]
+					usage := strings.TrimSpace("/" + name + " " + command.Usage)
+					r.Printf("  %-18s %s\n", usage, command.Help)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "reset",
+			Help: "start a new conversation",
+			Run: func(ctx context.Context, r *REPL, args []string) error {
+				r.sessionID = uuid.New().String()
+				r.Printf("Started a new conversation.\n")
+				return nil
+			},
+		},
+		{
+			Name:  "model",
+			Usage: "[name]",
+			Help:  "show or change the agent's model",
+			Run: func(ctx context.Context, r *REPL, args []string) error {
+				if len(args) == 0 {
+					r.Printf("%s\n", r.agent.Model)
+					return nil
+				}
+				r.agent = r.agent.With(agents.WithModel(args[0]))
+				r.Printf("Model set to %s.\n", args[0])
+				return nil
+			},
+		},
+		{
+			Name: "tools",
+			Help: "list the agent's tools and handoffs",
+			Run: func(ctx context.Context, r *REPL, args []string) error {
+				if len(r.agent.Tools) == 0 && len(r.agent.Handoffs) == 0 {
+					r.Printf("%s has no tools.\n", r.agent.Name)
+					return nil
+				}
+				for _, tool := range r.agent.Tools {
+					r.Printf("  %-18s %s\n", tool.Name(), tool.Description())
+				}
+				for _, handoff := range r.agent.Handoffs {
+					if handoff != nil {
+						r.Printf("  %-18s %s\n", "↪ "+handoff.Name, handoff.HandoffDescription)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name: "trace",
+			Help: "toggle per-turn usage and run metrics",
+			Run: func(ctx context.Context, r *REPL, args []string) error {
+				r.trace = !r.trace
+				if r.trace {
+					r.Printf("Tracing on.\n")
+				} else {
+					r.Printf("Tracing off.\n")
+				}
+				return nil
+			},
+		},
+		{
+			Name: "exit",
+			Help: "leave",
+			Run: func(ctx context.Context, r *REPL, args []string) error {
+				return ErrExit
+			},
+		},
+	}
+}
@@ -0,0 +1,308 @@
+// Package repl provides an interactive terminal loop for trying agents
+// during development: read a line, run the agent, render its progress and
+// answer, with slash commands to inspect and adjust the session.
+package repl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/agents"
+)
+
+// ErrExit is returned by a command to end the loop
+var ErrExit = errors.New("repl: exit")
+
+// REPL reads input lines, runs them through an agent and prints the results
+type REPL struct {
+	runner *agents.Runner
+	agent  *agents.Agent
+	events *agents.EventBus
+
+	in     io.Reader
+	out    io.Writer
+	prompt string
+	banner string
+
+	sessionID string
+	trace     bool
+
+	// sessionless is set once the runner turns out to have no session store
+	sessionless bool
+
+	// traceID identifies the run being rendered, so events from other runs
+	// in the process are ignored
+	traceID string
+
+	// commands are keyed by name, without the slash
+	commands map[string]Command
+
+	// mu serializes writes from event handlers with the loop's own
+	mu sync.Mutex
+}
+
+// Option configures a REPL
+type Option func(*REPL)
+
+// WithInput reads lines from in instead of standard input
+func WithInput(in io.Reader) Option {
+	return func(r *REPL) {
+		r.in = in
+	}
+}
+
+// WithOutput writes to out instead of standard output
+func WithOutput(out io.Writer) Option {
+	return func(r *REPL) {
+		r.out = out
+	}
+}
+
+// WithPrompt sets the input prompt, "> " by default
+func WithPrompt(prompt string) Option {
+	return func(r *REPL) {
+		r.prompt = prompt
+	}
+}
+
+// WithBanner sets text printed when the loop starts
+func WithBanner(banner string) Option {
+	return func(r *REPL) {
+		r.banner = banner
+	}
+}
+
+// WithSessionID continues the named session instead of starting a new one.
+// Sessions need a runner configured with agents.WithSessionStore; without
+// one, each input runs on its own.
+func WithSessionID(sessionID string) Option {
+	return func(r *REPL) {
+		r.sessionID = sessionID
+	}
+}
+
+// WithEventBus sets the bus the runner publishes to, if it was configured
+// with agents.WithEventBus; progress is rendered from its events
+func WithEventBus(bus *agents.EventBus) Option {
+	return func(r *REPL) {
+		r.events = bus
+	}
+}
+
+// WithTrace starts with tracing on, as if /trace had been entered
+func WithTrace(enabled bool) Option {
+	return func(r *REPL) {
+		r.trace = enabled
+	}
+}
+
+// WithCommands adds slash commands, replacing built-in ones of the same name
+func WithCommands(commands ...Command) Option {
+	return func(r *REPL) {
+		for _, command := range commands {
+			r.commands[command.Name] = command
+		}
+	}
+}
+
+// New creates a REPL running agent with runner
+func New(runner *agents.Runner, agent *agents.Agent, opts ...Option) *REPL {
+	r := &REPL{
+		runner:    runner,
+		agent:     agent,
+		events:    agents.Events(),
+		in:        os.Stdin,
+		out:       os.Stdout,
+		prompt:    "> ",
+		sessionID: uuid.New().String(),
+		commands:  make(map[string]Command),
+	}
+	for _, command := range builtinCommands() {
+		r.commands[command.Name] = command
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Agent returns the agent inputs are run with
+func (r *REPL) Agent() *agents.Agent {
+	return r.agent
+}
+
+// SetAgent changes the agent later inputs are run with
+func (r *REPL) SetAgent(agent *agents.Agent) {
+	r.agent = agent
+}
+
+// SessionID returns the session inputs are run in
+func (r *REPL) SessionID() string {
+	return r.sessionID
+}
+
+// Printf writes to the REPL's output
+func (r *REPL) Printf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(r.out, format, args...)
+}
+
+// Run reads and handles lines until the input ends, the user enters exit
+// or quit, a command returns ErrExit or ctx is done. Failed runs and
+// commands are reported and the loop continues.
+func (r *REPL) Run(ctx context.Context) error {
+	if r.banner != "" {
+		r.Printf("%s\n", r.banner)
+	}
+
+	scanner := bufio.NewScanner(r.in)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		r.Printf("%s", r.prompt)
+		if !scanner.Scan() {
+			r.Printf("\n")
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if line == "exit" || line == "quit" {
+			return nil
+		}
+
+		if strings.HasPrefix(line, "/") {
+			err := r.command(ctx, line)
+			if errors.Is(err, ErrExit) {
+				return nil
+			}
+			if err != nil {
+				r.Printf("error: %v\n", err)
+			}
+			continue
+		}
+
+		if err := r.send(ctx, line); err != nil {
+			r.Printf("error: %v\n", err)
+		}
+	}
+}
+
+// command runs a slash command line
+func (r *REPL) command(ctx context.Context, line string) error {
+	fields := strings.Fields(strings.TrimPrefix(line, "/"))
+	if len(fields) == 0 {
+		return fmt.Errorf("empty command; try /help")
+	}
+
+	command, ok := r.commands[fields[0]]
+	if !ok {
+		return fmt.Errorf("unknown command /%s; try /help", fields[0])
+	}
+	return command.Run(ctx, r, fields[1:])
+}
+
+// send runs input through the agent, rendering progress as it happens, and
+// prints the answer
+func (r *REPL) send(ctx context.Context, input string) error {
+	if r.events != nil {
+		defer r.events.Subscribe(r.render)()
+	}
+
+	result, err := r.run(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	r.Printf("\n%s\n\n", formatOutput(result.FinalOutput))
+	if r.trace {
+		m := result.Metrics
+		r.Printf("[%d turns, %d tool calls, %d handoffs, %d tokens, $%.4f, %v]\n",
+			m.TotalTurns, m.ToolCalls, m.Handoffs, m.TotalTokens, m.TotalCost, m.Duration.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// run continues the REPL's session, or runs input on its own once the
+// runner turns out to have no session store
+func (r *REPL) run(ctx context.Context, input string) (*agents.RunResult, error) {
+	r.mu.Lock()
+	r.traceID = ""
+	r.mu.Unlock()
+
+	if !r.sessionless {
+		result, err := r.runner.Continue(ctx, r.sessionID, r.agent, input)
+		if !errors.Is(err, agents.ErrNoSessionStore) {
+			return result, err
+		}
+
+		r.sessionless = true
+		r.Printf("(the runner has no session store; earlier inputs are not remembered)\n")
+		r.mu.Lock()
+		r.traceID = ""
+		r.mu.Unlock()
+	}
+	return r.runner.Run(ctx, r.agent, input)
+}
+
+// render prints the progress of the current run as its events arrive. Tool
+// calls and handoffs are always shown; completions only with /trace.
+func (r *REPL) render(event agents.Event) {
+	r.mu.Lock()
+	if event.Type == agents.EventRunStart && r.traceID == "" &&
+		(event.SessionID == r.sessionID || r.sessionless) {
+		r.traceID = event.TraceID
+	}
+	mine := event.TraceID == r.traceID && r.traceID != ""
+	r.mu.Unlock()
+	if !mine {
+		return
+	}
+
+	switch event.Type {
+	case agents.EventToolStart:
+		args, _ := json.Marshal(event.ToolCall.Arguments)
+		r.Printf("  → %s %s\n", event.ToolCall.Name, args)
+	case agents.EventToolEnd:
+		if event.ToolResponse != nil && event.ToolResponse.Error != nil {
+			r.Printf("  ✗ %s: %v\n", event.ToolCall.Name, event.ToolResponse.Error)
+		}
+	case agents.EventHandoff:
+		r.Printf("  ↪ %s handed off to %s\n", event.Agent, event.Target)
+	case agents.EventCompletion:
+		if r.trace && event.Usage != nil {
+			r.Printf("  · turn %d: %s on %s, %d tokens, %v\n",
+				event.Turn+1, event.Agent, event.Usage.Model, event.Usage.TotalTokens, event.Usage.Latency.Round(time.Millisecond))
+		}
+	}
+}
+
+// formatOutput renders a final output: text as it is, structured output as
+// indented JSON
+func formatOutput(output interface{}) string {
+	if text, ok := output.(string); ok {
+		return text
+	}
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", output)
+	}
+	return string(data)
+}
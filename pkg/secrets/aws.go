@@ -0,0 +1,136 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// AWSSecretsManager reads secrets from AWS Secrets Manager. Names are secret
+// IDs or ARNs, optionally followed by #key to pick one field of a JSON
+// secret. Binary secrets are returned as their raw bytes. Credentials come
+// from the AWS SDK's default chain: environment variables, shared config
+// and SSO profiles, web identity (IRSA) and ECS or EC2 instance roles.
+type AWSSecretsManager struct {
+	client *secretsmanager.Client
+	err    error
+}
+
+// AWSOption configures an AWSSecretsManager source
+type AWSOption func(*awsOptions)
+
+// awsOptions holds what AWSOptions configure
+type awsOptions struct {
+	load     []func(*config.LoadOptions) error
+	endpoint string
+}
+
+// WithAWSCredentials sets static credentials instead of the default chain
+func WithAWSCredentials(accessKey, secretKey, sessionToken string) AWSOption {
+	return WithAWSCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, sessionToken))
+}
+
+// WithAWSCredentialsProvider sets where credentials come from instead of
+// the default chain, such as an assumed role from stscreds
+func WithAWSCredentialsProvider(provider aws.CredentialsProvider) AWSOption {
+	return func(o *awsOptions) {
+		o.load = append(o.load, config.WithCredentialsProvider(provider))
+	}
+}
+
+// WithAWSProfile selects a profile from the shared config and credentials
+// files instead of AWS_PROFILE
+func WithAWSProfile(profile string) AWSOption {
+	return func(o *awsOptions) {
+		o.load = append(o.load, config.WithSharedConfigProfile(profile))
+	}
+}
+
+// WithAWSEndpoint overrides the Secrets Manager endpoint, for VPC endpoints
+// or local emulators
+func WithAWSEndpoint(endpoint string) AWSOption {
+	return func(o *awsOptions) {
+		o.endpoint = strings.TrimRight(endpoint, "/")
+	}
+}
+
+// WithAWSHTTPClient sets the HTTP client used to reach AWS, such as an
+// *http.Client
+func WithAWSHTTPClient(client aws.HTTPClient) AWSOption {
+	return func(o *awsOptions) {
+		o.load = append(o.load, config.WithHTTPClient(client))
+	}
+}
+
+// NewAWSSecretsManager creates a Secrets Manager source for region. An empty
+// region falls back to AWS_REGION or the profile's region. A configuration
+// that can't be loaded, such as a missing profile, is reported by Secret.
+func NewAWSSecretsManager(region string, opts ...AWSOption) *AWSSecretsManager {
+	var o awsOptions
+	o.load = append(o.load, config.WithHTTPClient(awshttp.NewBuildableClient().WithTimeout(10*time.Second)))
+	if region != "" {
+		o.load = append(o.load, config.WithRegion(region))
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), o.load...)
+	if err != nil {
+		return &AWSSecretsManager{err: fmt.Errorf("failed to load AWS config: %w", err)}
+	}
+
+	client := secretsmanager.NewFromConfig(cfg, func(opts *secretsmanager.Options) {
+		if o.endpoint != "" {
+			opts.BaseEndpoint = aws.String(o.endpoint)
+		}
+	})
+	return &AWSSecretsManager{client: client}
+}
+
+// Secret implements Source
+func (a *AWSSecretsManager) Secret(ctx context.Context, name string) (string, error) {
+	if a.err != nil {
+		return "", a.err
+	}
+
+	id, key := splitKey(name)
+	out, err := a.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	var notFound *types.ResourceNotFoundException
+	if errors.As(err, &notFound) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return "", fmt.Errorf("secrets manager request failed: %w", err)
+	}
+
+	var value string
+	switch {
+	case out.SecretString != nil:
+		value = *out.SecretString
+	case out.SecretBinary != nil:
+		value = string(out.SecretBinary)
+	default:
+		return "", fmt.Errorf("secret %s has no value", id)
+	}
+	if key == "" {
+		return value, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object: %w", id, err)
+	}
+	return selectKey(id, key, fields)
+}
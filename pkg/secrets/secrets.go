@@ -0,0 +1,112 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned when a source has no secret with the given name
+var ErrNotFound = errors.New("secret not found")
+
+// Source resolves named secrets such as API keys
+type Source interface {
+	// Secret returns the value of the named secret
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// Env reads secrets from environment variables, optionally prefixed
+type Env struct {
+	Prefix string
+}
+
+// Secret implements Source
+func (e Env) Secret(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(e.Prefix + name)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, e.Prefix+name)
+	}
+	return value, nil
+}
+
+// Files reads each secret from a file named after it in Dir, as mounted by
+// Docker and Kubernetes secrets. Surrounding whitespace is trimmed.
+type Files struct {
+	Dir string
+}
+
+// Secret implements Source
+func (f Files) Secret(ctx context.Context, name string) (string, error) {
+	if name == "" || strings.Contains(name, "..") || filepath.IsAbs(name) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(f.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Chain tries each source in order, returning the first secret found
+func Chain(sources ...Source) Source {
+	return chain(sources)
+}
+
+type chain []Source
+
+// Secret implements Source
+func (c chain) Secret(ctx context.Context, name string) (string, error) {
+	for _, source := range c {
+		value, err := source.Secret(ctx, name)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// splitKey separates "name#key" into the secret name and the key of a JSON
+// object secret; key is empty for plain names
+func splitKey(name string) (string, string) {
+	if i := strings.LastIndex(name, "#"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// selectKey returns the value of key in a secret holding a JSON object. With
+// no key the secret must have exactly one field.
+func selectKey(name, key string, fields map[string]interface{}) (string, error) {
+	if key == "" {
+		if len(fields) != 1 {
+			return "", fmt.Errorf("secret %s has %d fields; name one with %s#key", name, len(fields), name)
+		}
+		for k := range fields {
+			key = k
+		}
+	}
+
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s#%s", ErrNotFound, name, key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Vault reads secrets from a HashiCorp Vault KV version 2 engine. Names are
+// secret paths, optionally followed by #key to pick one field, e.g.
+// "llm/openai#api_key".
+type Vault struct {
+	address string
+	token   string
+	mount   string
+	client  *http.Client
+}
+
+// VaultOption configures a Vault source
+type VaultOption func(*Vault)
+
+// WithVaultMount sets the KV engine mount path (default "secret")
+func WithVaultMount(mount string) VaultOption {
+	return func(v *Vault) {
+		v.mount = strings.Trim(mount, "/")
+	}
+}
+
+// WithVaultHTTPClient sets the HTTP client used to reach Vault
+func WithVaultHTTPClient(client *http.Client) VaultOption {
+	return func(v *Vault) {
+		v.client = client
+	}
+}
+
+// NewVault creates a Vault source. Empty address and token fall back to
+// VAULT_ADDR and VAULT_TOKEN.
+func NewVault(address, token string, opts ...VaultOption) *Vault {
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+
+	v := &Vault{
+		address: strings.TrimRight(address, "/"),
+		token:   token,
+		mount:   "secret",
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// Secret implements Source
+func (v *Vault) Secret(ctx context.Context, name string) (string, error) {
+	path, key := splitKey(name)
+	endpoint := fmt.Sprintf("%s/v1/%s/data/%s", v.address, v.mount, strings.TrimLeft(path, "/"))
+	if _, err := url.Parse(endpoint); err != nil {
+		return "", fmt.Errorf("invalid Vault address: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("vault returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+
+	return selectKey(path, key, payload.Data.Data)
+}
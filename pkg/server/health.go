@@ -0,0 +1,205 @@
+// Package server provides HTTP handlers for serving agents, such as health
+// and readiness probes for orchestrators.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ryanhill4L/agents-sdk/pkg/providers"
+)
+
+// Health statuses
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+// ProviderStatus is the result of pinging one provider
+type ProviderStatus struct {
+	Name      string        `json:"name"`
+	Status    string        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Latency   time.Duration `json:"latency"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// HealthReport is the combined status of every checked provider: ok when
+// all are up, down when none are and degraded in between
+type HealthReport struct {
+	Status    string           `json:"status"`
+	Providers []ProviderStatus `json:"providers"`
+}
+
+// HealthOption configures a HealthCheck
+type HealthOption func(*HealthCheck)
+
+// WithProvider checks provider with providers.Ping under name, e.g.
+// "openai-us-east"
+func WithProvider(name string, provider providers.Provider) HealthOption {
+	return func(h *HealthCheck) {
+		h.checks[name] = pinger{provider}
+	}
+}
+
+// WithPinger checks the named provider with a custom pinger, such as
+// providers.PingCompletion for a specific model
+func WithPinger(name string, p providers.Pinger) HealthOption {
+	return func(h *HealthCheck) {
+		h.checks[name] = p
+	}
+}
+
+// WithHealthTimeout bounds each ping, five seconds by default
+func WithHealthTimeout(timeout time.Duration) HealthOption {
+	return func(h *HealthCheck) {
+		h.timeout = timeout
+	}
+}
+
+// WithHealthCacheTTL reuses ping results for ttl, ten seconds by default,
+// so frequent probes don't turn into a stream of API calls
+func WithHealthCacheTTL(ttl time.Duration) HealthOption {
+	return func(h *HealthCheck) {
+		h.ttl = ttl
+	}
+}
+
+// HealthCheck pings providers and reports their status. As an http.Handler
+// it serves the report as JSON, with 503 Service Unavailable when every
+// provider is down. A provider query parameter limits the report to one
+// provider, answering 503 when it is down, so traffic can be routed away
+// from a failing provider or region while the others keep serving.
+type HealthCheck struct {
+	checks  map[string]providers.Pinger
+	timeout time.Duration
+	ttl     time.Duration
+
+	mu     sync.Mutex
+	cached map[string]ProviderStatus
+}
+
+// NewHealthCheck creates a health check of the given providers
+func NewHealthCheck(opts ...HealthOption) *HealthCheck {
+	h := &HealthCheck{
+		checks:  make(map[string]providers.Pinger),
+		timeout: 5 * time.Second,
+		ttl:     10 * time.Second,
+		cached:  make(map[string]ProviderStatus),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Check pings every provider concurrently, reusing results younger than the
+// cache TTL
+func (h *HealthCheck) Check(ctx context.Context) HealthReport {
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return h.check(ctx, names)
+}
+
+// ServeHTTP implements http.Handler
+func (h *HealthCheck) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var report HealthReport
+	if name := req.URL.Query().Get("provider"); name != "" {
+		if _, ok := h.checks[name]; !ok {
+			http.Error(w, "unknown provider: "+name, http.StatusNotFound)
+			return
+		}
+		report = h.check(req.Context(), []string{name})
+	} else {
+		report = h.Check(req.Context())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == StatusDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// check reports the status of the named providers
+func (h *HealthCheck) check(ctx context.Context, names []string) HealthReport {
+	report := HealthReport{Providers: make([]ProviderStatus, len(names))}
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			report.Providers[i] = h.status(ctx, name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	up := 0
+	for _, status := range report.Providers {
+		if status.Status == StatusOK {
+			up++
+		}
+	}
+	switch {
+	case up == len(report.Providers):
+		report.Status = StatusOK
+	case up == 0:
+		report.Status = StatusDown
+	default:
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+// status returns the cached status of a provider or pings it
+func (h *HealthCheck) status(ctx context.Context, name string) ProviderStatus {
+	h.mu.Lock()
+	cached, ok := h.cached[name]
+	h.mu.Unlock()
+	if ok && time.Since(cached.CheckedAt) < h.ttl {
+		return cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := h.checks[name].Ping(ctx)
+	status := ProviderStatus{
+		Name:      name,
+		Status:    StatusOK,
+		Latency:   time.Since(start),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+
+	h.mu.Lock()
+	h.cached[name] = status
+	h.mu.Unlock()
+	return status
+}
+
+// pinger adapts a provider to providers.Ping
+type pinger struct {
+	provider providers.Provider
+}
+
+// Ping implements providers.Pinger
+func (p pinger) Ping(ctx context.Context) error {
+	return providers.Ping(ctx, p.provider)
+}
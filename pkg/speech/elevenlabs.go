@@ -0,0 +1,129 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const defaultElevenLabsBaseURL = "https://api.elevenlabs.io"
+
+// ElevenLabsConfig configures the ElevenLabs text-to-speech backend
+type ElevenLabsConfig struct {
+	APIKey  string
+	BaseURL string
+
+	// VoiceID is the ElevenLabs voice identifier
+	VoiceID string
+
+	// ModelID is the synthesis model, e.g. "eleven_multilingual_v2"
+	ModelID string
+
+	// OutputFormat is the ElevenLabs output format, e.g. "mp3_44100_128"
+	OutputFormat string
+
+	// HTTPClient overrides the default HTTP client (optional)
+	HTTPClient *http.Client
+}
+
+// NewElevenLabsConfig creates ElevenLabs speech configuration with defaults
+func NewElevenLabsConfig(apiKey, voiceID string) *ElevenLabsConfig {
+	if apiKey == "" {
+		apiKey = os.Getenv("ELEVENLABS_API_KEY")
+	}
+
+	return &ElevenLabsConfig{
+		APIKey:       apiKey,
+		BaseURL:      defaultElevenLabsBaseURL,
+		VoiceID:      voiceID,
+		ModelID:      "eleven_multilingual_v2",
+		OutputFormat: "mp3_44100_128",
+	}
+}
+
+// ElevenLabsSynthesizer implements Synthesizer using the ElevenLabs API
+type ElevenLabsSynthesizer struct {
+	config *ElevenLabsConfig
+	client *http.Client
+}
+
+// NewElevenLabsSynthesizer creates a new ElevenLabs speech synthesizer
+func NewElevenLabsSynthesizer(config *ElevenLabsConfig) (*ElevenLabsSynthesizer, error) {
+	if config.APIKey == "" {
+		return nil, ErrMissingAPIKey
+	}
+	if config.VoiceID == "" {
+		return nil, fmt.Errorf("voice ID is required")
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &ElevenLabsSynthesizer{
+		config: config,
+		client: client,
+	}, nil
+}
+
+// Synthesize implements the Synthesizer interface for ElevenLabs
+func (s *ElevenLabsSynthesizer) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	if text == "" {
+		return nil, ErrEmptyText
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"text":     text,
+		"model_id": s.config.ModelID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ElevenLabs request: %w", err)
+	}
+
+	baseURL := s.config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultElevenLabsBaseURL
+	}
+	endpoint := fmt.Sprintf("%s/v1/text-to-speech/%s?output_format=%s",
+		strings.TrimRight(baseURL, "/"), url.PathEscape(s.config.VoiceID), url.QueryEscape(s.config.OutputFormat))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ElevenLabs request: %w", err)
+	}
+	req.Header.Set("xi-api-key", s.config.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ElevenLabs API call failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ElevenLabs response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ElevenLabs API call failed with status %d: %s", resp.StatusCode, string(data))
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = mimeTypeForFormat(strings.SplitN(s.config.OutputFormat, "_", 2)[0])
+	}
+
+	return &Audio{
+		Data:     data,
+		MIMEType: mimeType,
+		Voice:    s.config.VoiceID,
+	}, nil
+}
@@ -0,0 +1,104 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// OpenAIConfig configures the OpenAI text-to-speech backend
+type OpenAIConfig struct {
+	APIKey  string
+	BaseURL string
+
+	// Model is the TTS model, e.g. "gpt-4o-mini-tts" or "tts-1"
+	Model string
+
+	// Voice selects the speaker, e.g. "alloy" or "nova"
+	Voice string
+
+	// Format is the response format: mp3, opus, aac, flac, wav, or pcm
+	Format string
+
+	// Instructions steer tone and delivery (not supported by tts-1 models)
+	Instructions string
+}
+
+// NewOpenAIConfig creates OpenAI speech configuration with defaults
+func NewOpenAIConfig(apiKey string) *OpenAIConfig {
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENAI_API_KEY")
+	}
+
+	return &OpenAIConfig{
+		APIKey: apiKey,
+		Model:  openai.SpeechModelGPT4oMiniTTS,
+		Voice:  "alloy",
+		Format: "mp3",
+	}
+}
+
+// OpenAISynthesizer implements Synthesizer using OpenAI's speech API
+type OpenAISynthesizer struct {
+	config *OpenAIConfig
+	client *openai.Client
+}
+
+// NewOpenAISynthesizer creates a new OpenAI speech synthesizer
+func NewOpenAISynthesizer(config *OpenAIConfig) (*OpenAISynthesizer, error) {
+	if config.APIKey == "" {
+		return nil, ErrMissingAPIKey
+	}
+
+	opts := []option.RequestOption{
+		option.WithAPIKey(config.APIKey),
+	}
+	if config.BaseURL != "" {
+		opts = append(opts, option.WithBaseURL(config.BaseURL))
+	}
+
+	client := openai.NewClient(opts...)
+
+	return &OpenAISynthesizer{
+		config: config,
+		client: &client,
+	}, nil
+}
+
+// Synthesize implements the Synthesizer interface for OpenAI
+func (s *OpenAISynthesizer) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	if text == "" {
+		return nil, ErrEmptyText
+	}
+
+	params := openai.AudioSpeechNewParams{
+		Input:          text,
+		Model:          s.config.Model,
+		Voice:          openai.AudioSpeechNewParamsVoice(s.config.Voice),
+		ResponseFormat: openai.AudioSpeechNewParamsResponseFormat(s.config.Format),
+	}
+	if s.config.Instructions != "" {
+		params.Instructions = openai.String(s.config.Instructions)
+	}
+
+	resp, err := s.client.Audio.Speech.New(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI speech API call failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAI speech response: %w", err)
+	}
+
+	return &Audio{
+		Data:     data,
+		MIMEType: mimeTypeForFormat(s.config.Format),
+		Voice:    s.config.Voice,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package speech
+
+import (
+	"context"
+	"errors"
+)
+
+// Speech errors
+var (
+	ErrMissingAPIKey = errors.New("API key is required")
+	ErrEmptyText     = errors.New("text to synthesize cannot be empty")
+)
+
+// Audio holds synthesized speech
+type Audio struct {
+	Data     []byte `json:"data"`
+	MIMEType string `json:"mime_type"`
+	Voice    string `json:"voice,omitempty"`
+}
+
+// Synthesizer converts text into speech audio
+type Synthesizer interface {
+	// Synthesize renders the text as audio
+	Synthesize(ctx context.Context, text string) (*Audio, error)
+}
+
+// mimeTypeForFormat maps an audio format name to its media type
+func mimeTypeForFormat(format string) string {
+	switch format {
+	case "mp3", "":
+		return "audio/mpeg"
+	case "opus":
+		return "audio/opus"
+	case "aac":
+		return "audio/aac"
+	case "flac":
+		return "audio/flac"
+	case "wav":
+		return "audio/wav"
+	case "pcm":
+		return "audio/pcm"
+	default:
+		return "application/octet-stream"
+	}
+}
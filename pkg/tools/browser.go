@@ -0,0 +1,410 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BrowserToolName is the name of the Browser tool. Anthropic's computer use
+// requires it.
+const BrowserToolName = "computer"
+
+// Mouse buttons passed to BrowserDriver.Click
+const (
+	MouseLeft   = "left"
+	MouseRight  = "right"
+	MouseMiddle = "middle"
+)
+
+// Point is a position in the browser viewport, in pixels
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// BrowserDriver operates a browser for the Browser tool. Implementations
+// need not be safe for concurrent use; the tool serializes its calls.
+type BrowserDriver interface {
+	// Navigate loads url in the current tab
+	Navigate(ctx context.Context, url string) error
+
+	// Click presses and releases button at a point count times in a row,
+	// e.g. twice for a double click
+	Click(ctx context.Context, at Point, button string, count int) error
+
+	// Move moves the mouse without pressing a button
+	Move(ctx context.Context, to Point) error
+
+	// Drag holds the left button down along path
+	Drag(ctx context.Context, path []Point) error
+
+	// Type types text into the focused element
+	Type(ctx context.Context, text string) error
+
+	// Press presses keys together, e.g. "ctrl" and "a". Keys are lower
+	// case names such as "enter", "tab" or "arrowdown", or single characters.
+	Press(ctx context.Context, keys []string) error
+
+	// Scroll scrolls the page under a point by dx and dy pixels
+	Scroll(ctx context.Context, at Point, dx, dy int) error
+
+	// Screenshot returns a PNG of the viewport
+	Screenshot(ctx context.Context) ([]byte, error)
+
+	// Size returns the viewport size in pixels
+	Size() (width, height int)
+}
+
+// ComputerTool is implemented by tools that operate a screen. Providers with
+// native computer use (Anthropic, OpenAI) expose such tools in their own
+// format and pass the model's actions to Execute as they are.
+type ComputerTool interface {
+	Tool
+
+	// DisplaySize returns the screen size in pixels
+	DisplaySize() (width, height int)
+}
+
+// browserTool is the Tool returned by Browser
+type browserTool struct {
+	driver     BrowserDriver
+	scrollStep int
+	settle     time.Duration
+	mu         sync.Mutex
+}
+
+// BrowserOption configures the Browser tool
+type BrowserOption func(*browserTool)
+
+// WithScrollStep sets the pixels scrolled per step of a scroll_amount
+// (default 100)
+func WithScrollStep(pixels int) BrowserOption {
+	return func(b *browserTool) {
+		b.scrollStep = pixels
+	}
+}
+
+// WithSettleDelay sets how long the tool waits after an action before taking
+// the screenshot it returns, so pages can react (default 500ms)
+func WithSettleDelay(d time.Duration) BrowserOption {
+	return func(b *browserTool) {
+		b.settle = d
+	}
+}
+
+// Browser returns a computer-use tool driving a browser: it navigates,
+// clicks, types, scrolls and returns a screenshot after every action.
+//
+// It accepts the actions of Anthropic's computer use ("left_click" with a
+// "coordinate", ...) and of OpenAI's computer-use tool ("type": "click" with
+// "x" and "y", ...), plus a "navigate" action with a "url". Providers
+// without native computer use see the Anthropic form as a function schema.
+func Browser(driver BrowserDriver, opts ...BrowserOption) Tool {
+	b := &browserTool{
+		driver:     driver,
+		scrollStep: 100,
+		settle:     500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Name returns the tool name
+func (b *browserTool) Name() string {
+	return BrowserToolName
+}
+
+// Description returns the tool description
+func (b *browserTool) Description() string {
+	width, height := b.DisplaySize()
+	return fmt.Sprintf("Use a web browser with a %dx%d viewport. Every action returns a screenshot of the page.", width, height)
+}
+
+// Schema describes the Anthropic and OpenAI action formats
+func (b *browserTool) Schema() ParameterSchema {
+	coordinate := &PropertySchema{Type: "integer", Description: "Pixels"}
+	return ParameterSchema{
+		Type: "object",
+		Properties: map[string]PropertySchema{
+			"action": {
+				Type:        "string",
+				Description: "The action to perform",
+				Enum: []interface{}{
+					"navigate", "screenshot", "left_click", "right_click", "middle_click", "double_click",
+					"triple_click", "mouse_move", "left_click_drag", "type", "key", "scroll", "wait",
+				},
+			},
+			"url":              {Type: "string", Description: "The URL to load, for navigate"},
+			"coordinate":       {Type: "array", Description: "The [x, y] position to act on", Items: coordinate},
+			"start_coordinate": {Type: "array", Description: "The [x, y] position a drag starts at", Items: coordinate},
+			"text":             {Type: "string", Description: "The text to type, or the keys to press for key, e.g. ctrl+a"},
+			"scroll_direction": {Type: "string", Description: "The direction to scroll", Enum: []interface{}{"up", "down", "left", "right"}},
+			"scroll_amount":    {Type: "integer", Description: "The number of steps to scroll"},
+			"duration":         {Type: "number", Description: "The seconds to wait"},
+
+			// OpenAI computer-use actions
+			"type":     {Type: "string", Description: "The action to perform, in OpenAI's computer-use format"},
+			"x":        {Type: "integer", Description: "The x position to act on"},
+			"y":        {Type: "integer", Description: "The y position to act on"},
+			"button":   {Type: "string", Description: "The mouse button to click"},
+			"keys":     {Type: "array", Description: "The keys to press together", Items: &PropertySchema{Type: "string"}},
+			"scroll_x": {Type: "integer", Description: "The pixels to scroll horizontally"},
+			"scroll_y": {Type: "integer", Description: "The pixels to scroll vertically"},
+			"path":     {Type: "array", Description: "The positions a drag passes through", Items: &PropertySchema{Type: "object"}},
+		},
+	}
+}
+
+// Execute performs one action and returns a screenshot of the result. An
+// action that fails is reported next to the screenshot rather than as an
+// error, since computer-use models expect a screenshot for every action.
+func (b *browserTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var blocks []ContentBlock
+	if err := b.perform(ctx, args); err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		blocks = append(blocks, TextBlock("Action failed: "+err.Error()))
+	}
+
+	if b.settle > 0 {
+		select {
+		case <-time.After(b.settle):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	png, err := b.driver.Screenshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take screenshot: %w", err)
+	}
+	return NewResult(append(blocks, ImageBlock(png, "image/png"))...), nil
+}
+
+// perform dispatches an action in either provider's format to the driver
+func (b *browserTool) perform(ctx context.Context, args map[string]interface{}) error {
+	action, _ := args["action"].(string)
+	if action == "" {
+		action, _ = args["type"].(string)
+	}
+
+	switch action {
+	case "screenshot":
+		return nil
+	case "navigate":
+		url, _ := args["url"].(string)
+		if url == "" {
+			return errors.New("navigate requires a url")
+		}
+		return b.driver.Navigate(ctx, url)
+	case "left_click", "right_click", "middle_click", "click":
+		at, err := pointArg(args)
+		if err != nil {
+			return err
+		}
+		button := strings.TrimSuffix(action, "_click")
+		if action == "click" {
+			button, _ = args["button"].(string)
+			if button == "" {
+				button = MouseLeft
+			}
+		}
+		return b.driver.Click(ctx, at, button, 1)
+	case "double_click", "triple_click":
+		at, err := pointArg(args)
+		if err != nil {
+			return err
+		}
+		count := 2
+		if action == "triple_click" {
+			count = 3
+		}
+		return b.driver.Click(ctx, at, MouseLeft, count)
+	case "mouse_move", "move":
+		at, err := pointArg(args)
+		if err != nil {
+			return err
+		}
+		return b.driver.Move(ctx, at)
+	case "left_click_drag", "drag":
+		path, err := pathArg(args)
+		if err != nil {
+			return err
+		}
+		return b.driver.Drag(ctx, path)
+	case "type":
+		text, _ := args["text"].(string)
+		return b.driver.Type(ctx, text)
+	case "key", "keypress":
+		keys := keysArg(args)
+		if len(keys) == 0 {
+			return errors.New("key requires the keys to press")
+		}
+		return b.driver.Press(ctx, keys)
+	case "scroll":
+		return b.scroll(ctx, args)
+	case "wait":
+		wait := time.Second
+		if seconds, ok := toFloat(args["duration"]); ok {
+			wait = time.Duration(seconds * float64(time.Second))
+		}
+		select {
+		case <-time.After(wait):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case "":
+		return errors.New("no browser action given")
+	default:
+		return fmt.Errorf("unsupported browser action %q", action)
+	}
+}
+
+// scroll handles Anthropic's direction and step count as well as OpenAI's
+// pixel offsets
+func (b *browserTool) scroll(ctx context.Context, args map[string]interface{}) error {
+	at, err := pointArg(args)
+	if err != nil {
+		width, height := b.driver.Size()
+		at = Point{X: width / 2, Y: height / 2}
+	}
+
+	direction, _ := args["scroll_direction"].(string)
+	if direction == "" {
+		dx, _ := toFloat(args["scroll_x"])
+		dy, _ := toFloat(args["scroll_y"])
+		return b.driver.Scroll(ctx, at, int(dx), int(dy))
+	}
+
+	steps := 1
+	if amount, ok := toFloat(args["scroll_amount"]); ok && amount > 0 {
+		steps = int(amount)
+	}
+	distance := steps * b.scrollStep
+	switch direction {
+	case "up":
+		return b.driver.Scroll(ctx, at, 0, -distance)
+	case "down":
+		return b.driver.Scroll(ctx, at, 0, distance)
+	case "left":
+		return b.driver.Scroll(ctx, at, -distance, 0)
+	case "right":
+		return b.driver.Scroll(ctx, at, distance, 0)
+	default:
+		return fmt.Errorf("unsupported scroll direction %q", direction)
+	}
+}
+
+// DisplaySize returns the viewport size
+func (b *browserTool) DisplaySize() (int, int) {
+	return b.driver.Size()
+}
+
+// Mutating reports that browser actions have side effects, since clicks
+// and typing can submit forms
+func (b *browserTool) Mutating() bool {
+	return true
+}
+
+// Capabilities reports a mutating, slow tool
+func (b *browserTool) Capabilities() Capabilities {
+	return Capabilities{Mutating: true, Latency: LatencySlow}
+}
+
+// Validate checks if the tool is valid
+func (b *browserTool) Validate() error {
+	if b.driver == nil {
+		return errors.New("browser tool requires a driver")
+	}
+	return nil
+}
+
+// pointArg reads the position of an action from "coordinate" or "x" and "y"
+func pointArg(args map[string]interface{}) (Point, error) {
+	if coordinate, ok := args["coordinate"]; ok {
+		return toPoint(coordinate)
+	}
+	x, okX := toFloat(args["x"])
+	y, okY := toFloat(args["y"])
+	if !okX || !okY {
+		return Point{}, errors.New("action requires a position")
+	}
+	return Point{X: int(x), Y: int(y)}, nil
+}
+
+// pathArg reads a drag path from "start_coordinate" and "coordinate", or
+// from OpenAI's "path" of {x, y} objects
+func pathArg(args map[string]interface{}) ([]Point, error) {
+	if start, ok := args["start_coordinate"]; ok {
+		from, err := toPoint(start)
+		if err != nil {
+			return nil, err
+		}
+		to, err := pointArg(args)
+		if err != nil {
+			return nil, err
+		}
+		return []Point{from, to}, nil
+	}
+
+	raw, _ := args["path"].([]interface{})
+	path := make([]Point, 0, len(raw))
+	for _, item := range raw {
+		point, _ := item.(map[string]interface{})
+		x, okX := toFloat(point["x"])
+		y, okY := toFloat(point["y"])
+		if !okX || !okY {
+			return nil, errors.New("drag path points require x and y")
+		}
+		path = append(path, Point{X: int(x), Y: int(y)})
+	}
+	if len(path) < 2 {
+		return nil, errors.New("drag requires a start and an end position")
+	}
+	return path, nil
+}
+
+// toPoint converts an [x, y] array
+func toPoint(value interface{}) (Point, error) {
+	pair, _ := value.([]interface{})
+	if len(pair) != 2 {
+		return Point{}, errors.New("coordinate must be [x, y]")
+	}
+	x, okX := toFloat(pair[0])
+	y, okY := toFloat(pair[1])
+	if !okX || !okY {
+		return Point{}, errors.New("coordinate must be [x, y]")
+	}
+	return Point{X: int(x), Y: int(y)}, nil
+}
+
+// keysArg reads the keys of Anthropic's "ctrl+a" text or OpenAI's key list,
+// lower cased
+func keysArg(args map[string]interface{}) []string {
+	var keys []string
+	if text, ok := args["text"].(string); ok && text != "" {
+		keys = strings.Split(text, "+")
+	}
+	if list, ok := args["keys"].([]interface{}); ok {
+		for _, key := range list {
+			if s, ok := key.(string); ok {
+				keys = append(keys, s)
+			}
+		}
+	}
+	for i, key := range keys {
+		keys[i] = strings.ToLower(strings.TrimSpace(key))
+	}
+	return keys
+}
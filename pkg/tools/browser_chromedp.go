@@ -0,0 +1,210 @@
+//go:build chromedp
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/kb"
+)
+
+// ChromeOptions configures a ChromeDriver
+type ChromeOptions struct {
+	// Width and Height set the viewport size (default 1280x800)
+	Width  int
+	Height int
+
+	// Headful shows the browser window instead of running headless
+	Headful bool
+
+	// ExecPath is the Chrome binary; empty finds an installed one
+	ExecPath string
+}
+
+// ChromeDriver is the default BrowserDriver, controlling Chrome or Chromium
+// over the DevTools protocol. It's built with the chromedp build tag:
+//
+//	go build -tags chromedp
+type ChromeDriver struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	width  int
+	height int
+}
+
+// NewChromeDriver starts a browser, which runs until Close
+func NewChromeDriver(opts ChromeOptions) (*ChromeDriver, error) {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		opts.Width, opts.Height = 1280, 800
+	}
+
+	allocOpts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.WindowSize(opts.Width, opts.Height),
+		chromedp.Flag("headless", !opts.Headful),
+	)
+	if opts.ExecPath != "" {
+		allocOpts = append(allocOpts, chromedp.ExecPath(opts.ExecPath))
+	}
+
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), allocOpts...)
+	browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+	d := &ChromeDriver{
+		ctx: browserCtx,
+		cancel: func() {
+			cancelBrowser()
+			cancelAlloc()
+		},
+		width:  opts.Width,
+		height: opts.Height,
+	}
+
+	if err := chromedp.Run(browserCtx, chromedp.EmulateViewport(int64(opts.Width), int64(opts.Height))); err != nil {
+		d.cancel()
+		return nil, fmt.Errorf("failed to start browser: %w", err)
+	}
+	return d, nil
+}
+
+// Close shuts the browser down
+func (d *ChromeDriver) Close() error {
+	d.cancel()
+	return nil
+}
+
+// run executes actions in the browser, stopping early when ctx ends
+func (d *ChromeDriver) run(ctx context.Context, actions ...chromedp.Action) error {
+	runCtx, cancel := context.WithCancel(d.ctx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	return chromedp.Run(runCtx, actions...)
+}
+
+// Navigate implements BrowserDriver
+func (d *ChromeDriver) Navigate(ctx context.Context, url string) error {
+	return d.run(ctx, chromedp.Navigate(url))
+}
+
+// Click implements BrowserDriver
+func (d *ChromeDriver) Click(ctx context.Context, at Point, button string, count int) error {
+	return d.run(ctx, chromedp.MouseClickXY(float64(at.X), float64(at.Y), chromedp.Button(button), chromedp.ClickCount(count)))
+}
+
+// Move implements BrowserDriver
+func (d *ChromeDriver) Move(ctx context.Context, to Point) error {
+	return d.run(ctx, chromedp.MouseEvent(input.MouseMoved, float64(to.X), float64(to.Y)))
+}
+
+// Drag implements BrowserDriver
+func (d *ChromeDriver) Drag(ctx context.Context, path []Point) error {
+	if len(path) == 0 {
+		return nil
+	}
+
+	start, end := path[0], path[len(path)-1]
+	actions := []chromedp.Action{
+		chromedp.MouseEvent(input.MouseMoved, float64(start.X), float64(start.Y)),
+		chromedp.MouseEvent(input.MousePressed, float64(start.X), float64(start.Y), chromedp.Button(MouseLeft), chromedp.ClickCount(1)),
+	}
+	for _, point := range path[1:] {
+		actions = append(actions, chromedp.MouseEvent(input.MouseMoved, float64(point.X), float64(point.Y), chromedp.Button(MouseLeft)))
+	}
+	actions = append(actions, chromedp.MouseEvent(input.MouseReleased, float64(end.X), float64(end.Y), chromedp.Button(MouseLeft), chromedp.ClickCount(1)))
+	return d.run(ctx, actions...)
+}
+
+// Type implements BrowserDriver
+func (d *ChromeDriver) Type(ctx context.Context, text string) error {
+	return d.run(ctx, chromedp.KeyEvent(text))
+}
+
+// Press implements BrowserDriver. Modifier keys are held while the others
+// are pressed.
+func (d *ChromeDriver) Press(ctx context.Context, keys []string) error {
+	var modifiers input.Modifier
+	var pressed []string
+	for _, key := range keys {
+		switch key {
+		case "ctrl", "control":
+			modifiers |= input.ModifierCtrl
+		case "shift":
+			modifiers |= input.ModifierShift
+		case "alt", "option":
+			modifiers |= input.ModifierAlt
+		case "meta", "cmd", "command", "super", "win":
+			modifiers |= input.ModifierMeta
+		default:
+			pressed = append(pressed, chromeKey(key))
+		}
+	}
+
+	actions := make([]chromedp.Action, 0, len(pressed))
+	for _, key := range pressed {
+		actions = append(actions, chromedp.KeyEvent(key, chromedp.KeyModifiers(modifiers)))
+	}
+	return d.run(ctx, actions...)
+}
+
+// chromeKey maps the key names of both providers' computer use to the keys
+// chromedp sends
+func chromeKey(key string) string {
+	switch strings.ReplaceAll(key, "_", "") {
+	case "enter", "return":
+		return kb.Enter
+	case "tab":
+		return kb.Tab
+	case "escape", "esc":
+		return kb.Escape
+	case "backspace":
+		return kb.Backspace
+	case "delete", "del":
+		return kb.Delete
+	case "space":
+		return " "
+	case "arrowup", "up":
+		return kb.ArrowUp
+	case "arrowdown", "down":
+		return kb.ArrowDown
+	case "arrowleft", "left":
+		return kb.ArrowLeft
+	case "arrowright", "right":
+		return kb.ArrowRight
+	case "home":
+		return kb.Home
+	case "end":
+		return kb.End
+	case "pageup":
+		return kb.PageUp
+	case "pagedown":
+		return kb.PageDown
+	default:
+		return key
+	}
+}
+
+// Scroll implements BrowserDriver
+func (d *ChromeDriver) Scroll(ctx context.Context, at Point, dx, dy int) error {
+	return d.run(ctx, chromedp.MouseEvent(input.MouseWheel, float64(at.X), float64(at.Y),
+		func(p *input.DispatchMouseEventParams) *input.DispatchMouseEventParams {
+			return p.WithDeltaX(float64(dx)).WithDeltaY(float64(dy))
+		}))
+}
+
+// Screenshot implements BrowserDriver
+func (d *ChromeDriver) Screenshot(ctx context.Context) ([]byte, error) {
+	var png []byte
+	if err := d.run(ctx, chromedp.CaptureScreenshot(&png)); err != nil {
+		return nil, err
+	}
+	return png, nil
+}
+
+// Size implements BrowserDriver
+func (d *ChromeDriver) Size() (int, int) {
+	return d.width, d.height
+}
@@ -0,0 +1,85 @@
+package tools
+
+// CostClass is a rough estimate of what a tool call costs to make
+type CostClass string
+
+const (
+	CostUnknown CostClass = ""
+	CostFree    CostClass = "free"
+	CostLow     CostClass = "low"
+	CostHigh    CostClass = "high"
+)
+
+// LatencyClass is a rough estimate of how long a tool call takes
+type LatencyClass string
+
+const (
+	LatencyUnknown LatencyClass = ""
+	LatencyFast    LatencyClass = "fast"
+	LatencySlow    LatencyClass = "slow"
+)
+
+// Capabilities are the properties a tool declares about its calls, used by
+// the runner to decide which calls to cache, run concurrently, skip in dry
+// runs or send for approval
+type Capabilities struct {
+	// Mutating calls change state outside the run. The runner skips them in
+	// dry runs and never runs two of them at once.
+	Mutating bool `json:"mutating,omitempty"`
+
+	// Idempotent calls return the same result for the same arguments, so the
+	// run's tool cache may reuse them
+	Idempotent bool `json:"idempotent,omitempty"`
+
+	Cost    CostClass    `json:"cost,omitempty"`
+	Latency LatencyClass `json:"latency,omitempty"`
+}
+
+// CapableTool is implemented by tools that declare their capabilities
+type CapableTool interface {
+	Tool
+
+	// Capabilities returns the tool's declared capabilities
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf returns the capabilities the tool declares. Tools that
+// don't implement CapableTool are described by IdempotentTool and
+// MutatingTool instead, and have unknown cost and latency.
+func CapabilitiesOf(tool Tool) Capabilities {
+	if t, ok := tool.(CapableTool); ok {
+		return t.Capabilities()
+	}
+
+	var caps Capabilities
+	if t, ok := tool.(IdempotentTool); ok {
+		caps.Idempotent = t.Idempotent()
+	}
+	if t, ok := tool.(MutatingTool); ok {
+		caps.Mutating = t.Mutating()
+	}
+	return caps
+}
+
+// WithCapabilities declares all of the tool's capabilities at once,
+// replacing WithIdempotent and WithMutating
+func WithCapabilities(caps Capabilities) FunctionToolOption {
+	return func(o *toolOptions) {
+		o.caps = caps
+		o.declared = true
+	}
+}
+
+// WithCost sets the tool's estimated cost class
+func WithCost(cost CostClass) FunctionToolOption {
+	return func(o *toolOptions) {
+		o.caps.Cost = cost
+	}
+}
+
+// WithLatency sets the tool's estimated latency class
+func WithLatency(latency LatencyClass) FunctionToolOption {
+	return func(o *toolOptions) {
+		o.caps.Latency = latency
+	}
+}
@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PropertyOption adjusts the schema of one parameter
+type PropertyOption func(*PropertySchema)
+
+// WithParameter applies options to the named parameter of a FunctionTool
+// (arg0, arg1, ... in declaration order) or input field of a TypedTool (its
+// JSON name)
+func WithParameter(name string, opts ...PropertyOption) FunctionToolOption {
+	return func(o *toolOptions) {
+		if o.paramOptions == nil {
+			o.paramOptions = make(map[string][]PropertyOption)
+		}
+		o.paramOptions[name] = append(o.paramOptions[name], opts...)
+	}
+}
+
+// Describe sets the parameter description shown to the model
+func Describe(description string) PropertyOption {
+	return func(p *PropertySchema) {
+		p.Description = description
+	}
+}
+
+// Enum restricts the parameter to the given values
+func Enum(values ...interface{}) PropertyOption {
+	return func(p *PropertySchema) {
+		p.Enum = values
+	}
+}
+
+// Minimum sets the smallest allowed numeric value
+func Minimum(min float64) PropertyOption {
+	return func(p *PropertySchema) {
+		p.Minimum = &min
+	}
+}
+
+// Maximum sets the largest allowed numeric value
+func Maximum(max float64) PropertyOption {
+	return func(p *PropertySchema) {
+		p.Maximum = &max
+	}
+}
+
+// Pattern requires string values to match the regular expression
+func Pattern(pattern string) PropertyOption {
+	return func(p *PropertySchema) {
+		p.Pattern = pattern
+	}
+}
+
+// Default sets the value used when the model omits the parameter
+func Default(value interface{}) PropertyOption {
+	return func(p *PropertySchema) {
+		p.Default = value
+	}
+}
+
+// Format declares a well-known string format ("date-time", "date", "email",
+// "uri", "uuid")
+func Format(format string) PropertyOption {
+	return func(p *PropertySchema) {
+		p.Format = format
+	}
+}
+
+// applyConstraintTags reads enum:"a,b", minimum:"0", maximum:"10",
+// pattern:"..." and format:"..." struct tags into prop
+func applyConstraintTags(prop *PropertySchema, tag reflect.StructTag) error {
+	if enum, ok := tag.Lookup("enum"); ok {
+		for _, value := range strings.Split(enum, ",") {
+			value = strings.TrimSpace(value)
+			if prop.Type == "integer" || prop.Type == "number" {
+				n, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return fmt.Errorf("invalid enum value %q: %w", value, err)
+				}
+				prop.Enum = append(prop.Enum, n)
+			} else {
+				prop.Enum = append(prop.Enum, value)
+			}
+		}
+	}
+
+	for name, target := range map[string]**float64{"minimum": &prop.Minimum, "maximum": &prop.Maximum} {
+		if value, ok := tag.Lookup(name); ok {
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q: %w", name, value, err)
+			}
+			*target = &n
+		}
+	}
+
+	if pattern, ok := tag.Lookup("pattern"); ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		prop.Pattern = pattern
+	}
+
+	prop.Format = tag.Get("format")
+	return nil
+}
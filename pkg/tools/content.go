@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ContentType identifies the kind of a content block
+type ContentType string
+
+const (
+	ContentTypeText  ContentType = "text"
+	ContentTypeJSON  ContentType = "json"
+	ContentTypeImage ContentType = "image"
+	ContentTypeFile  ContentType = "file"
+)
+
+// ContentBlock is a typed piece of tool output
+type ContentBlock struct {
+	Type     ContentType `json:"type"`
+	Text     string      `json:"text,omitempty"`
+	Data     []byte      `json:"data,omitempty"`
+	MIMEType string      `json:"mime_type,omitempty"`
+	URI      string      `json:"uri,omitempty"`
+}
+
+// Result is returned by tools that produce typed content blocks instead of a
+// single value
+type Result struct {
+	Blocks []ContentBlock `json:"blocks"`
+}
+
+// NewResult creates a result from content blocks
+func NewResult(blocks ...ContentBlock) *Result {
+	return &Result{Blocks: blocks}
+}
+
+// TextBlock creates a plain text block
+func TextBlock(text string) ContentBlock {
+	return ContentBlock{Type: ContentTypeText, Text: text}
+}
+
+// JSONBlock creates a block holding the JSON encoding of v
+func JSONBlock(v interface{}) (ContentBlock, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ContentBlock{}, fmt.Errorf("failed to encode JSON block: %w", err)
+	}
+	return ContentBlock{Type: ContentTypeJSON, Text: string(data), MIMEType: "application/json"}, nil
+}
+
+// ImageBlock creates a block holding raw image bytes
+func ImageBlock(data []byte, mimeType string) ContentBlock {
+	return ContentBlock{Type: ContentTypeImage, Data: data, MIMEType: mimeType}
+}
+
+// FileBlock creates a block referencing a file by URI
+func FileBlock(uri, mimeType string) ContentBlock {
+	return ContentBlock{Type: ContentTypeFile, URI: uri, MIMEType: mimeType}
+}
+
+// String returns a textual rendering of the block for providers or messages
+// that only accept text
+func (b ContentBlock) String() string {
+	switch b.Type {
+	case ContentTypeText, ContentTypeJSON:
+		return b.Text
+	case ContentTypeImage:
+		return fmt.Sprintf("[image %s, %d bytes]", b.MIMEType, len(b.Data))
+	case ContentTypeFile:
+		return fmt.Sprintf("[file %s]", b.URI)
+	default:
+		return b.Text
+	}
+}
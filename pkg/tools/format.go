@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FormattingTool is implemented by tools that render their own results for
+// the model instead of leaving it to the runner
+type FormattingTool interface {
+	Tool
+
+	// FormatResult renders a result returned by Execute, reporting false to
+	// leave it to the runner
+	FormatResult(result interface{}) (string, bool)
+}
+
+// FormatResult renders result with the tool's formatter, reporting false when
+// the tool has none
+func FormatResult(tool Tool, result interface{}) (string, bool) {
+	if t, ok := tool.(FormattingTool); ok {
+		return t.FormatResult(result)
+	}
+	return "", false
+}
+
+// WithResultFormatter sets how the tool's results are rendered for the
+// model, e.g. FormatJSON or FormatTable. Results that are already strings
+// or Results are formatted too. A TypedTool's formatter receives the Out
+// value itself, in place of its JSON encoding.
+func WithResultFormatter(format func(interface{}) string) FunctionToolOption {
+	return func(o *toolOptions) {
+		o.format = format
+	}
+}
+
+// FormatJSON renders v as compact JSON, falling back to %v for values that
+// can't be encoded
+func FormatJSON(v interface{}) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// FormatTable renders a list of records, such as []map[string]interface{}
+// rows or a slice of structs, as a Markdown table. Columns appear in the
+// order their keys are first seen. Values that aren't lists of objects are
+// rendered with FormatJSON.
+func FormatTable(v interface{}) string {
+	columns, rows, ok := tableRows(v)
+	if !ok {
+		return FormatJSON(v)
+	}
+	if len(rows) == 0 {
+		return "(no rows)"
+	}
+
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(columns, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = tableCell(row[column])
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// tableRows decodes the JSON form of v as a list of objects, keeping the
+// order of their keys
+func tableRows(v interface{}) ([]string, []map[string]json.RawMessage, bool) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, false
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, nil, false
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	rows := make([]map[string]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		decoder := json.NewDecoder(bytes.NewReader(item))
+		if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+			return nil, nil, false
+		}
+		row := make(map[string]json.RawMessage)
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, nil, false
+			}
+			key, _ := token.(string)
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return nil, nil, false
+			}
+			row[key] = value
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows, true
+}
+
+// tableCell renders a JSON value as a table cell: strings unquoted, null and
+// missing values empty
+func tableCell(value json.RawMessage) string {
+	if len(value) == 0 || string(value) == "null" {
+		return ""
+	}
+	text := string(value)
+	var s string
+	if json.Unmarshal(value, &s) == nil {
+		text = s
+	}
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", " ")
+}
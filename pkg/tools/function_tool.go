@@ -2,8 +2,11 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 )
 
 // FunctionTool wraps a Go function as a tool
@@ -13,6 +16,41 @@ type FunctionTool struct {
 	fn          reflect.Value
 	fnType      reflect.Type
 	schema      ParameterSchema
+	toolOptions
+}
+
+// toolOptions holds what FunctionToolOptions configure, shared by
+// FunctionTool and TypedTool
+type toolOptions struct {
+	caps Capabilities
+
+	// declared is set once an option states the tool's side effects
+	declared bool
+
+	scopes []string
+	format func(interface{}) string
+
+	// paramOptions are applied to the generated schema by parameter name
+	paramOptions map[string][]PropertyOption
+}
+
+// FunctionToolOption configures a FunctionTool or a TypedTool
+type FunctionToolOption func(*toolOptions)
+
+// WithIdempotent marks the tool as safe to deduplicate within a run
+func WithIdempotent(idempotent bool) FunctionToolOption {
+	return func(o *toolOptions) {
+		o.caps.Idempotent = idempotent
+		o.declared = true
+	}
+}
+
+// WithMutating marks the tool as having side effects, so dry runs skip it
+func WithMutating(mutating bool) FunctionToolOption {
+	return func(o *toolOptions) {
+		o.caps.Mutating = mutating
+		o.declared = true
+	}
 }
 
 // ParameterSchema describes function parameters
@@ -22,14 +60,41 @@ type ParameterSchema struct {
 	Required   []string                  `json:"required"`
 }
 
-// PropertySchema describes a single parameter
+// PropertySchema describes a single parameter. Type is empty for values of
+// any type.
 type PropertySchema struct {
-	Type        string `json:"type"`
+	Type        string `json:"type,omitempty"`
 	Description string `json:"description"`
+
+	// Items describes array elements
+	Items *PropertySchema `json:"items,omitempty"`
+
+	// Properties and Required describe the fields of struct-typed objects
+	Properties map[string]PropertySchema `json:"properties,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+
+	// AdditionalProperties describes the values of map-typed objects
+	AdditionalProperties *PropertySchema `json:"additionalProperties,omitempty"`
+
+	// Default is applied when the model omits an optional value
+	Default interface{} `json:"default,omitempty"`
+
+	// Enum lists the allowed values (any value if empty)
+	Enum []interface{} `json:"enum,omitempty"`
+
+	// Minimum and Maximum bound numeric values
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	// Pattern is a regular expression string values must match
+	Pattern string `json:"pattern,omitempty"`
+
+	// Format names a well-known string format such as "date-time" or "email"
+	Format string `json:"format,omitempty"`
 }
 
 // NewFunctionTool creates a tool from a function
-func NewFunctionTool(name, description string, fn interface{}) (*FunctionTool, error) {
+func NewFunctionTool(name, description string, fn interface{}, opts ...FunctionToolOption) (*FunctionTool, error) {
 	fnValue := reflect.ValueOf(fn)
 	fnType := fnValue.Type()
 
@@ -44,11 +109,19 @@ func NewFunctionTool(name, description string, fn interface{}) (*FunctionTool, e
 		fnType:      fnType,
 	}
 
+	for _, opt := range opts {
+		opt(&tool.toolOptions)
+	}
+
 	// Build parameter schema
 	if err := tool.buildSchema(); err != nil {
 		return nil, err
 	}
 
+	if err := tool.applyParamOptions(tool.schema.Properties); err != nil {
+		return nil, err
+	}
+
 	return tool, nil
 }
 
@@ -69,10 +142,15 @@ func (f *FunctionTool) buildSchema() error {
 			continue
 		}
 
-		// Add to schema (simplified - real implementation would be more complex)
+		// Add to schema; pointer and variadic parameters are optional
 		paramName := fmt.Sprintf("arg%d", i)
-		f.schema.Properties[paramName] = PropertySchema{
-			Type: f.goTypeToJSONType(param),
+		prop, err := schemaForType(param, nil)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %w", paramName, err)
+		}
+		f.schema.Properties[paramName] = prop
+		if param.Kind() == reflect.Ptr || (f.fnType.IsVariadic() && i == f.fnType.NumIn()-1) {
+			continue
 		}
 		f.schema.Required = append(f.schema.Required, paramName)
 	}
@@ -80,21 +158,299 @@ func (f *FunctionTool) buildSchema() error {
 	return nil
 }
 
-// goTypeToJSONType converts Go types to JSON schema types
-func (f *FunctionTool) goTypeToJSONType(t reflect.Type) string {
+// SchemaOf derives the JSON schema of v's type the same way function tool
+// parameters are described, for prompts and output schemas
+func SchemaOf(v interface{}) (PropertySchema, error) {
+	if v == nil {
+		return PropertySchema{}, fmt.Errorf("cannot derive a schema from nil")
+	}
+	return schemaForType(reflect.TypeOf(v), nil)
+}
+
+// schemaForType derives a JSON schema for t, recursing into slice elements,
+// map values and struct fields. seen guards against self-referential types.
+// Pointer fields and fields with a default:"..." tag are optional.
+func schemaForType(t reflect.Type, seen map[reflect.Type]bool) (PropertySchema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return PropertySchema{Type: "string"}, nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		// []byte is encoded as a base64 string
+		return PropertySchema{Type: "string"}, nil
+	}
+
 	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		items, err := schemaForType(t.Elem(), seen)
+		if err != nil {
+			return PropertySchema{}, err
+		}
+		return PropertySchema{Type: "array", Items: &items}, nil
+	case reflect.Map:
+		schema := PropertySchema{Type: "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			values, err := schemaForType(t.Elem(), seen)
+			if err != nil {
+				return PropertySchema{}, err
+			}
+			schema.AdditionalProperties = &values
+		}
+		return schema, nil
+	case reflect.Struct:
+		if seen[t] {
+			return PropertySchema{Type: "object"}, nil
+		}
+		if seen == nil {
+			seen = make(map[reflect.Type]bool)
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		schema := PropertySchema{
+			Type:       "object",
+			Properties: make(map[string]PropertySchema),
+		}
+		for _, f := range jsonFields(t) {
+			field, name, omitempty := f.field, f.name, f.omitempty || f.viaPointer
+
+			prop, err := schemaForType(field.Type, seen)
+			if err != nil {
+				return PropertySchema{}, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			prop.Description = field.Tag.Get("description")
+			if err := applyConstraintTags(&prop, field.Tag); err != nil {
+				return PropertySchema{}, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+
+			def, hasDefault := field.Tag.Lookup("default")
+			if hasDefault {
+				if prop.Default, err = parseDefault(def, field.Type); err != nil {
+					return PropertySchema{}, fmt.Errorf("field %s: %w", field.Name, err)
+				}
+			}
+
+			schema.Properties[name] = prop
+			if !omitempty && !hasDefault && field.Type.Kind() != reflect.Ptr {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+		return schema, nil
+	case reflect.Interface:
+		return PropertySchema{}, nil
+	default:
+		return PropertySchema{Type: jsonTypeForKind(t.Kind())}, nil
+	}
+}
+
+// jsonField is a struct field as encoding/json sees it, possibly promoted
+// from an embedded struct
+type jsonField struct {
+	field     reflect.StructField
+	name      string
+	omitempty bool
+	tagged    bool
+	depth     int
+
+	// viaPointer marks fields promoted through an embedded pointer, which
+	// may be nil and so never makes them required
+	viaPointer bool
+}
+
+// jsonFields lists the fields of struct type t that encoding/json encodes,
+// flattening untagged embedded structs. As in encoding/json, a shallower
+// field hides deeper ones of the same name, and of several at the same
+// depth only a sole tagged one is kept.
+func jsonFields(t reflect.Type) []jsonField {
+	var all []jsonField
+	collectJSONFields(t, 0, false, map[reflect.Type]bool{}, &all)
+
+	byName := make(map[string][]jsonField)
+	var order []string
+	for _, f := range all {
+		if _, ok := byName[f.name]; !ok {
+			order = append(order, f.name)
+		}
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	fields := make([]jsonField, 0, len(order))
+	for _, name := range order {
+		if f, ok := dominantField(byName[name]); ok {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func collectJSONFields(t reflect.Type, depth int, viaPointer bool, visiting map[reflect.Type]bool, fields *[]jsonField) {
+	if visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous && field.Tag.Get("json") != "-" {
+			embedded := field.Type
+			pointer := embedded.Kind() == reflect.Ptr
+			if pointer {
+				embedded = embedded.Elem()
+			}
+			tagName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if embedded.Kind() == reflect.Struct && tagName == "" {
+				collectJSONFields(embedded, depth+1, viaPointer || pointer, visiting, fields)
+				continue
+			}
+		}
+
+		name, omitempty, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		tagName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		*fields = append(*fields, jsonField{
+			field:      field,
+			name:       name,
+			omitempty:  omitempty,
+			tagged:     tagName != "",
+			depth:      depth,
+			viaPointer: viaPointer,
+		})
+	}
+}
+
+// dominantField picks the field encoding/json uses among fields sharing a
+// name, reporting false when they conflict
+func dominantField(fields []jsonField) (jsonField, bool) {
+	depth := fields[0].depth
+	for _, f := range fields[1:] {
+		depth = min(depth, f.depth)
+	}
+
+	var candidates, tagged []jsonField
+	for _, f := range fields {
+		if f.depth != depth {
+			continue
+		}
+		candidates = append(candidates, f)
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+
+	switch {
+	case len(candidates) == 1:
+		return candidates[0], true
+	case len(tagged) == 1:
+		return tagged[0], true
+	}
+	return jsonField{}, false
+}
+
+// jsonFieldName returns the JSON name of an exported struct field and
+// whether it is tagged omitempty; ok is false for skipped fields
+func jsonFieldName(field reflect.StructField) (name string, omitempty bool, ok bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, true
+}
+
+// parseDefault converts a default:"..." tag to the JSON value of type t.
+// Strings are taken verbatim; other types use JSON syntax.
+func parseDefault(tag string, t reflect.Type) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.String {
+		return tag, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(tag), &value); err != nil {
+		return nil, fmt.Errorf("invalid default %q: %w", tag, err)
+	}
+
+	// Make sure the default decodes into the field type
+	if _, err := convertViaJSON(value, t); err != nil {
+		return nil, fmt.Errorf("invalid default %q: %w", tag, err)
+	}
+
+	return value, nil
+}
+
+// applyDefaults returns a copy of value with the schema's defaults filled in
+// for missing object fields, recursing into nested objects and arrays
+func applyDefaults(value interface{}, schema PropertySchema) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(schema.Properties) == 0 {
+			return v
+		}
+
+		result := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			if prop, ok := schema.Properties[key]; ok {
+				val = applyDefaults(val, prop)
+			}
+			result[key] = val
+		}
+		for key, prop := range schema.Properties {
+			if _, ok := result[key]; !ok && prop.Default != nil {
+				result[key] = prop.Default
+			}
+		}
+		return result
+	case []interface{}:
+		if schema.Items == nil {
+			return v
+		}
+
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = applyDefaults(item, *schema.Items)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// jsonTypeForKind maps scalar kinds to JSON schema types
+func jsonTypeForKind(kind reflect.Kind) string {
+	switch kind {
 	case reflect.String:
 		return "string"
-	case reflect.Int, reflect.Int32, reflect.Int64:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return "integer"
 	case reflect.Float32, reflect.Float64:
 		return "number"
 	case reflect.Bool:
 		return "boolean"
-	case reflect.Slice, reflect.Array:
-		return "array"
-	case reflect.Map, reflect.Struct:
-		return "object"
 	default:
 		return "string"
 	}
@@ -184,6 +540,17 @@ func (f *FunctionTool) convertToType(val interface{}, targetType reflect.Type) (
 		default:
 			return reflect.Zero(targetType), fmt.Errorf("cannot convert %T to bool", val)
 		}
+	case reflect.Interface:
+		if valType.Implements(targetType) {
+			v := reflect.New(targetType).Elem()
+			v.Set(reflect.ValueOf(val))
+			return v, nil
+		}
+		return reflect.Zero(targetType), fmt.Errorf("cannot convert %T to %s", val, targetType.String())
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Ptr:
+		// Decoded JSON (slices of interface{}, nested maps) is re-encoded and
+		// decoded into the target so element and field types are honored
+		return convertViaJSON(val, targetType)
 	default:
 		// For other types, try direct assignment if possible
 		valValue := reflect.ValueOf(val)
@@ -194,6 +561,36 @@ func (f *FunctionTool) convertToType(val interface{}, targetType reflect.Type) (
 	}
 }
 
+// convertViaJSON converts val to targetType through a JSON round trip
+func convertViaJSON(val interface{}, targetType reflect.Type) (reflect.Value, error) {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return reflect.Zero(targetType), fmt.Errorf("cannot encode %T: %w", val, err)
+	}
+
+	target := reflect.New(targetType)
+	if err := json.Unmarshal(data, target.Interface()); err != nil {
+		return reflect.Zero(targetType), fmt.Errorf("cannot convert %T to %s: %w", val, targetType.String(), err)
+	}
+	return target.Elem(), nil
+}
+
+// applyParamOptions applies the WithParameter options to the named
+// properties
+func (o *toolOptions) applyParamOptions(properties map[string]PropertySchema) error {
+	for name, opts := range o.paramOptions {
+		prop, ok := properties[name]
+		if !ok {
+			return fmt.Errorf("unknown parameter %s", name)
+		}
+		for _, opt := range opts {
+			opt(&prop)
+		}
+		properties[name] = prop
+	}
+	return nil
+}
+
 // Name returns the tool name
 func (f *FunctionTool) Name() string {
 	return f.name
@@ -209,6 +606,38 @@ func (f *FunctionTool) Schema() ParameterSchema {
 	return f.schema
 }
 
+// Idempotent reports whether the tool was marked idempotent
+func (f *FunctionTool) Idempotent() bool {
+	return f.caps.Idempotent
+}
+
+// Mutating reports whether the tool was marked as having side effects
+func (f *FunctionTool) Mutating() bool {
+	return f.caps.Mutating
+}
+
+// Capabilities returns the tool's declared capabilities
+func (f *FunctionTool) Capabilities() Capabilities {
+	return f.caps
+}
+
+func (f *FunctionTool) capabilitiesDeclared() bool {
+	return f.declared
+}
+
+// FormatResult renders a result with the tool's result formatter, if set
+func (f *FunctionTool) FormatResult(result interface{}) (string, bool) {
+	if f.format == nil {
+		return "", false
+	}
+	return f.format(result), true
+}
+
+// RequiredScopes returns the permission scopes needed to call the tool
+func (f *FunctionTool) RequiredScopes() []string {
+	return f.scopes
+}
+
 // Execute runs the function with provided arguments
 func (f *FunctionTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	// Build function arguments
@@ -223,20 +652,36 @@ func (f *FunctionTool) Execute(ctx context.Context, args map[string]interface{})
 		} else {
 			// Get argument from map and convert type if necessary
 			argName := fmt.Sprintf("arg%d", i)
-			if val, ok := args[argName]; ok {
+			val, ok := args[argName]
+			if def := f.schema.Properties[argName].Default; !ok && def != nil {
+				// An omitted parameter takes its default, like a struct field
+				val, ok = def, true
+			}
+			if ok {
+				val = applyDefaults(val, f.schema.Properties[argName])
 				convertedVal, err := f.convertToType(val, param)
 				if err != nil {
 					return nil, fmt.Errorf("failed to convert argument %s: %w", argName, err)
 				}
 				fnArgs = append(fnArgs, convertedVal)
+			} else if f.isRequired(argName) {
+				return nil, &ValidationError{
+					Tool:   f.name,
+					Issues: []ValidationIssue{{Field: argName, Message: "is required"}},
+				}
 			} else {
 				fnArgs = append(fnArgs, reflect.Zero(param))
 			}
 		}
 	}
 
-	// Call function
-	results := f.fn.Call(fnArgs)
+	// Call function; a variadic function receives its last argument as a slice
+	var results []reflect.Value
+	if f.fnType.IsVariadic() {
+		results = f.fn.CallSlice(fnArgs)
+	} else {
+		results = f.fn.Call(fnArgs)
+	}
 
 	// Handle results
 	if len(results) == 0 {
@@ -257,6 +702,16 @@ func (f *FunctionTool) Execute(ctx context.Context, args map[string]interface{})
 	return results[0].Interface(), nil
 }
 
+// isRequired reports whether the schema lists the parameter as required
+func (f *FunctionTool) isRequired(name string) bool {
+	for _, required := range f.schema.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Validate checks if the tool is valid
 func (f *FunctionTool) Validate() error {
 	if f.name == "" {
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrHostedToolExecution is returned when a hosted tool is executed locally
+var ErrHostedToolExecution = errors.New("hosted tools are executed by the provider")
+
+// Hosted tool types understood by providers
+const (
+	HostedFileSearch    = "file_search"
+	HostedGoogleSearch  = "google_search"
+	HostedCodeExecution = "code_execution"
+)
+
+// HostedTool is a tool executed by the provider itself, such as OpenAI's
+// file search. Providers that don't support the hosted type ignore it.
+type HostedTool interface {
+	Tool
+
+	// HostedType returns the provider-side tool type
+	HostedType() string
+
+	// HostedConfig returns provider-specific settings for the tool
+	HostedConfig() map[string]interface{}
+}
+
+// hostedTool is a marker Tool describing a provider-hosted capability
+type hostedTool struct {
+	name        string
+	description string
+	hostedType  string
+	config      map[string]interface{}
+}
+
+// FileSearch returns a hosted tool that searches the given OpenAI vector
+// stores. Citations to matched files are reported in the completion.
+func FileSearch(vectorStoreIDs ...string) Tool {
+	return &hostedTool{
+		name:        HostedFileSearch,
+		description: "Search uploaded files for relevant information",
+		hostedType:  HostedFileSearch,
+		config: map[string]interface{}{
+			"vector_store_ids": vectorStoreIDs,
+		},
+	}
+}
+
+// GoogleSearch returns a hosted tool that grounds Gemini responses in Google
+// Search results. Sources and search suggestions are reported in the
+// completion's grounding metadata.
+func GoogleSearch() Tool {
+	return &hostedTool{
+		name:        HostedGoogleSearch,
+		description: "Ground answers in Google Search results",
+		hostedType:  HostedGoogleSearch,
+		config:      make(map[string]interface{}),
+	}
+}
+
+// CodeExecution returns a hosted tool that lets Gemini write and run Python
+// code. Executed code and its output are recorded as tool messages.
+func CodeExecution() Tool {
+	return &hostedTool{
+		name:        HostedCodeExecution,
+		description: "Write and execute Python code to compute answers",
+		hostedType:  HostedCodeExecution,
+		config:      make(map[string]interface{}),
+	}
+}
+
+// Name returns the tool name
+func (h *hostedTool) Name() string {
+	return h.name
+}
+
+// Description returns the tool description
+func (h *hostedTool) Description() string {
+	return h.description
+}
+
+// Schema returns an empty object schema; hosted tools take no local arguments
+func (h *hostedTool) Schema() ParameterSchema {
+	return ParameterSchema{
+		Type:       "object",
+		Properties: make(map[string]PropertySchema),
+	}
+}
+
+// Execute always fails because hosted tools run on the provider
+func (h *hostedTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	return nil, fmt.Errorf("%w: %s", ErrHostedToolExecution, h.name)
+}
+
+// Validate checks if the tool is valid
+func (h *hostedTool) Validate() error {
+	if h.hostedType == HostedFileSearch {
+		ids, _ := h.config["vector_store_ids"].([]string)
+		if len(ids) == 0 {
+			return fmt.Errorf("file search requires at least one vector store ID")
+		}
+	}
+	return nil
+}
+
+// HostedType returns the provider-side tool type
+func (h *hostedTool) HostedType() string {
+	return h.hostedType
+}
+
+// HostedConfig returns provider-specific settings for the tool
+func (h *hostedTool) HostedConfig() map[string]interface{} {
+	return h.config
+}
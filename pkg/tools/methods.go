@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// MethodOption configures FromMethods
+type MethodOption func(*methodConfig)
+
+type methodConfig struct {
+	prefix       string
+	include      map[string]bool
+	exclude      map[string]bool
+	descriptions map[string]string
+	toolOptions  map[string][]FunctionToolOption
+}
+
+// WithMethodPrefix prepends prefix to every generated tool name
+func WithMethodPrefix(prefix string) MethodOption {
+	return func(c *methodConfig) {
+		c.prefix = prefix
+	}
+}
+
+// OnlyMethods limits the tool set to the named methods
+func OnlyMethods(names ...string) MethodOption {
+	return func(c *methodConfig) {
+		if c.include == nil {
+			c.include = make(map[string]bool)
+		}
+		for _, name := range names {
+			c.include[name] = true
+		}
+	}
+}
+
+// ExcludeMethods leaves the named methods out of the tool set
+func ExcludeMethods(names ...string) MethodOption {
+	return func(c *methodConfig) {
+		if c.exclude == nil {
+			c.exclude = make(map[string]bool)
+		}
+		for _, name := range names {
+			c.exclude[name] = true
+		}
+	}
+}
+
+// WithMethodDescription sets the description of the tool for a method.
+// Methods without one are described by their name.
+func WithMethodDescription(method, description string) MethodOption {
+	return func(c *methodConfig) {
+		if c.descriptions == nil {
+			c.descriptions = make(map[string]string)
+		}
+		c.descriptions[method] = description
+	}
+}
+
+// WithMethodToolOptions passes FunctionToolOptions to the tool for a method
+func WithMethodToolOptions(method string, opts ...FunctionToolOption) MethodOption {
+	return func(c *methodConfig) {
+		if c.toolOptions == nil {
+			c.toolOptions = make(map[string][]FunctionToolOption)
+		}
+		c.toolOptions[method] = append(c.toolOptions[method], opts...)
+	}
+}
+
+// FromMethods creates one FunctionTool per exported method of receiver.
+// Tool names are the snake_cased method names, so a method FindUser becomes
+// the tool find_user. Methods are bound to receiver, letting tools share its
+// state (database handles, clients) without globals.
+func FromMethods(receiver interface{}, opts ...MethodOption) ([]Tool, error) {
+	if receiver == nil {
+		return nil, fmt.Errorf("receiver cannot be nil")
+	}
+
+	cfg := &methodConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	value := reflect.ValueOf(receiver)
+	typ := value.Type()
+
+	for name := range cfg.include {
+		if _, ok := typ.MethodByName(name); !ok {
+			return nil, fmt.Errorf("%s has no exported method %s", typ, name)
+		}
+	}
+
+	// Methods are listed in lexicographic order
+	result := make([]Tool, 0, typ.NumMethod())
+	for i := 0; i < typ.NumMethod(); i++ {
+		method := typ.Method(i)
+		if cfg.include != nil && !cfg.include[method.Name] {
+			continue
+		}
+		if cfg.exclude[method.Name] {
+			continue
+		}
+
+		description := cfg.descriptions[method.Name]
+		if description == "" {
+			description = method.Name
+		}
+
+		tool, err := NewFunctionTool(
+			cfg.prefix+snakeCase(method.Name),
+			description,
+			value.Method(i).Interface(),
+			cfg.toolOptions[method.Name]...,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("method %s: %w", method.Name, err)
+		}
+		result = append(result, tool)
+	}
+
+	return result, nil
+}
+
+// snakeCase converts a Go identifier such as GetHTTPStatus to get_http_status
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NamespaceSeparator joins a namespace and a tool name. Provider tool names
+// are limited to letters, digits, '_' and '-', so a dot can't be used.
+const NamespaceSeparator = "__"
+
+// ErrToolConflict is returned when combined tool sets contain the same name
+var ErrToolConflict = errors.New("tool name conflict")
+
+// NamespacedTool is implemented by tools exposed under a namespace
+type NamespacedTool interface {
+	Tool
+
+	// Namespace returns the namespace the tool was registered under
+	Namespace() string
+
+	// Unwrap returns the underlying tool
+	Unwrap() Tool
+}
+
+// namespacedTool exposes a tool as namespace__name and delegates everything
+// else to the wrapped tool
+type namespacedTool struct {
+	namespace string
+	tool      Tool
+}
+
+// Namespace prefixes the names of the given tools with namespace. Hosted
+// tools keep their names, since providers identify them by type.
+func Namespace(namespace string, tools ...Tool) []Tool {
+	result := make([]Tool, len(tools))
+	for i, tool := range tools {
+		if _, hosted := tool.(HostedTool); hosted || namespace == "" {
+			result[i] = tool
+			continue
+		}
+		result[i] = &namespacedTool{namespace: namespace, tool: tool}
+	}
+	return result
+}
+
+// SplitName separates a namespaced tool name into its namespace and the
+// original name; namespace is empty for names without one
+func SplitName(name string) (namespace, base string) {
+	if i := strings.Index(name, NamespaceSeparator); i > 0 {
+		return name[:i], name[i+len(NamespaceSeparator):]
+	}
+	return "", name
+}
+
+// Unwrap returns the tool underneath any namespaces
+func Unwrap(tool Tool) Tool {
+	for {
+		ns, ok := tool.(NamespacedTool)
+		if !ok {
+			return tool
+		}
+		tool = ns.Unwrap()
+	}
+}
+
+func (t *namespacedTool) Name() string {
+	return t.namespace + NamespaceSeparator + t.tool.Name()
+}
+
+func (t *namespacedTool) Description() string {
+	return t.tool.Description()
+}
+
+func (t *namespacedTool) Schema() ParameterSchema {
+	return t.tool.Schema()
+}
+
+func (t *namespacedTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	return t.tool.Execute(ctx, args)
+}
+
+func (t *namespacedTool) Validate() error {
+	return t.tool.Validate()
+}
+
+func (t *namespacedTool) Idempotent() bool {
+	return IsIdempotent(t.tool)
+}
+
+func (t *namespacedTool) Mutating() bool {
+	return IsMutating(t.tool)
+}
+
+func (t *namespacedTool) Capabilities() Capabilities {
+	return CapabilitiesOf(t.tool)
+}
+
+func (t *namespacedTool) capabilitiesDeclared() bool {
+	return declaresSideEffects(t.tool)
+}
+
+func (t *namespacedTool) FormatResult(result interface{}) (string, bool) {
+	return FormatResult(t.tool, result)
+}
+
+func (t *namespacedTool) RequiredScopes() []string {
+	return RequiredScopes(t.tool)
+}
+
+func (t *namespacedTool) Namespace() string {
+	return t.namespace
+}
+
+func (t *namespacedTool) Unwrap() Tool {
+	return t.tool
+}
+
+// ConflictPolicy decides what Combine does with duplicate tool names
+type ConflictPolicy int
+
+const (
+	// ConflictError fails with ErrToolConflict
+	ConflictError ConflictPolicy = iota
+
+	// ConflictKeepFirst keeps the tool from the earliest source
+	ConflictKeepFirst
+
+	// ConflictNamespace exposes later duplicates under their source's
+	// namespace, failing if the source has none or the result still clashes
+	ConflictNamespace
+)
+
+// Source is a named group of tools, such as the tools of one MCP server or
+// OpenAPI document
+type Source struct {
+	// Namespace is used to disambiguate the source's tools; with Prefix set
+	// every tool is namespaced, not just conflicting ones
+	Namespace string
+	Prefix    bool
+	Tools     []Tool
+}
+
+// Combine merges tool sources in order, resolving duplicate names with the
+// given policy. The result is deterministic for the same inputs.
+func Combine(policy ConflictPolicy, sources ...Source) ([]Tool, error) {
+	var result []Tool
+	owner := make(map[string]int)
+
+	for i, source := range sources {
+		sourceTools := source.Tools
+		if source.Prefix {
+			sourceTools = Namespace(source.Namespace, sourceTools...)
+		}
+
+		for _, tool := range sourceTools {
+			name := tool.Name()
+			first, exists := owner[name]
+			if !exists {
+				owner[name] = i
+				result = append(result, tool)
+				continue
+			}
+
+			switch policy {
+			case ConflictKeepFirst:
+				continue
+			case ConflictNamespace:
+				if source.Namespace == "" || source.Prefix {
+					return nil, fmt.Errorf("%w: %s (sources %d and %d)", ErrToolConflict, name, first, i)
+				}
+				renamed := Namespace(source.Namespace, tool)[0]
+				if _, clash := owner[renamed.Name()]; clash {
+					return nil, fmt.Errorf("%w: %s", ErrToolConflict, renamed.Name())
+				}
+				owner[renamed.Name()] = i
+				result = append(result, renamed)
+			default:
+				return nil, fmt.Errorf("%w: %s (sources %d and %d)", ErrToolConflict, name, first, i)
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package tools
+
+import "strings"
+
+// ScopedTool is implemented by tools that may only be called when the run
+// has been granted certain permission scopes, such as "billing:write"
+type ScopedTool interface {
+	Tool
+
+	// RequiredScopes returns the scopes needed to call the tool
+	RequiredScopes() []string
+}
+
+// RequiredScopes returns the scopes the tool declares, if any
+func RequiredScopes(tool Tool) []string {
+	if t, ok := tool.(ScopedTool); ok {
+		return t.RequiredScopes()
+	}
+	return nil
+}
+
+// WithScopes declares the permission scopes needed to call the tool
+func WithScopes(scopes ...string) FunctionToolOption {
+	return func(o *toolOptions) {
+		o.scopes = append(o.scopes, scopes...)
+	}
+}
+
+// MissingScopes returns the required scopes not covered by granted. A
+// granted scope of "*" covers everything and "billing:*" covers every
+// scope starting with "billing:".
+func MissingScopes(granted, required []string) []string {
+	var missing []string
+	for _, scope := range required {
+		if !scopeGranted(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// scopeGranted reports whether any granted scope covers scope
+func scopeGranted(granted []string, scope string) bool {
+	for _, g := range granted {
+		if g == scope || g == "*" {
+			return true
+		}
+		if strings.HasSuffix(g, ":*") && strings.HasPrefix(scope, strings.TrimSuffix(g, "*")) {
+			return true
+		}
+	}
+	return false
+}
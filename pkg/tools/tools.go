@@ -20,4 +20,54 @@ type Tool interface {
 
 	// Validate checks if the tool configuration is valid
 	Validate() error
-}
\ No newline at end of file
+}
+
+// IdempotentTool is implemented by tools whose results can be safely reused
+// when called again with identical arguments
+type IdempotentTool interface {
+	Tool
+
+	// Idempotent reports whether repeated identical calls return the same result
+	Idempotent() bool
+}
+
+// IsIdempotent reports whether the tool declares itself idempotent
+func IsIdempotent(tool Tool) bool {
+	return CapabilitiesOf(tool).Idempotent
+}
+
+// MutatingTool is implemented by tools with side effects outside the run,
+// such as sending email or writing records, which dry runs skip
+type MutatingTool interface {
+	Tool
+
+	// Mutating reports whether calls change external state
+	Mutating() bool
+}
+
+// IsMutating reports whether the tool declares side effects
+func IsMutating(tool Tool) bool {
+	return CapabilitiesOf(tool).Mutating
+}
+
+// MayMutate reports whether calls might change external state: the tool
+// declares itself mutating, or says nothing about its side effects, like a
+// plain NewFunctionTool. Idempotent runs count such calls as side effects
+// that a retry could repeat.
+func MayMutate(tool Tool) bool {
+	return IsMutating(tool) || !declaresSideEffects(tool)
+}
+
+// declaresSideEffects reports whether the tool states whether it is
+// mutating or idempotent, rather than leaving the defaults
+func declaresSideEffects(tool Tool) bool {
+	if t, ok := tool.(interface{ capabilitiesDeclared() bool }); ok {
+		return t.capabilitiesDeclared()
+	}
+
+	switch tool.(type) {
+	case CapableTool, MutatingTool, IdempotentTool:
+		return true
+	}
+	return false
+}
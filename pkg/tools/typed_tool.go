@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// TypedTool is a tool whose arguments decode into In and whose result is Out.
+// The parameter schema is derived from In's fields and their json,
+// description, default and constraint tags.
+type TypedTool[In, Out any] struct {
+	name        string
+	description string
+	fn          func(context.Context, In) (Out, error)
+	schema      ParameterSchema
+	toolOptions
+}
+
+// NewTypedTool creates a tool from a function taking a struct argument.
+// In must be a struct or a pointer to one.
+func NewTypedTool[In, Out any](name, description string, fn func(context.Context, In) (Out, error), opts ...FunctionToolOption) (*TypedTool[In, Out], error) {
+	if fn == nil {
+		return nil, fmt.Errorf("invalid function")
+	}
+
+	inType := reflect.TypeOf((*In)(nil)).Elem()
+	structType := inType
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("input type %s is not a struct", inType)
+	}
+
+	prop, err := schemaForType(structType, nil)
+	if err != nil {
+		return nil, fmt.Errorf("input type %s: %w", inType, err)
+	}
+
+	var options toolOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if err := options.applyParamOptions(prop.Properties); err != nil {
+		return nil, err
+	}
+
+	required := prop.Required
+	if required == nil {
+		required = make([]string, 0)
+	}
+
+	return &TypedTool[In, Out]{
+		name:        name,
+		description: description,
+		fn:          fn,
+		schema: ParameterSchema{
+			Type:       "object",
+			Properties: prop.Properties,
+			Required:   required,
+		},
+		toolOptions: options,
+	}, nil
+}
+
+// Name returns the tool name
+func (t *TypedTool[In, Out]) Name() string {
+	return t.name
+}
+
+// Description returns the tool description
+func (t *TypedTool[In, Out]) Description() string {
+	return t.description
+}
+
+// Schema returns the parameter schema
+func (t *TypedTool[In, Out]) Schema() ParameterSchema {
+	return t.schema
+}
+
+// Idempotent reports whether the tool was marked idempotent
+func (t *TypedTool[In, Out]) Idempotent() bool {
+	return t.caps.Idempotent
+}
+
+// Mutating reports whether the tool was marked as having side effects
+func (t *TypedTool[In, Out]) Mutating() bool {
+	return t.caps.Mutating
+}
+
+// Capabilities returns the tool's declared capabilities
+func (t *TypedTool[In, Out]) Capabilities() Capabilities {
+	return t.caps
+}
+
+func (t *TypedTool[In, Out]) capabilitiesDeclared() bool {
+	return t.declared
+}
+
+// FormatResult renders a result with the tool's result formatter, if set
+func (t *TypedTool[In, Out]) FormatResult(result interface{}) (string, bool) {
+	if t.format == nil {
+		return "", false
+	}
+	return t.format(result), true
+}
+
+// RequiredScopes returns the permission scopes needed to call the tool
+func (t *TypedTool[In, Out]) RequiredScopes() []string {
+	return t.scopes
+}
+
+// Execute decodes the arguments into In, calls the function and encodes the
+// result. Strings and content results, and any result of a tool with a
+// result formatter, are returned as is; other values are marshaled to JSON,
+// which sorts map keys so identical results encode identically.
+func (t *TypedTool[In, Out]) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	for _, name := range t.schema.Required {
+		if _, ok := args[name]; !ok {
+			return nil, &ValidationError{
+				Tool:   t.name,
+				Issues: []ValidationIssue{{Field: name, Message: "is required"}},
+			}
+		}
+	}
+
+	withDefaults := applyDefaults(args, PropertySchema{Properties: t.schema.Properties})
+	data, err := json.Marshal(withDefaults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments: %w", err)
+	}
+
+	var in In
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, fmt.Errorf("failed to decode arguments: %w", err)
+	}
+
+	out, err := t.fn(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	if t.format != nil {
+		return out, nil
+	}
+
+	switch v := any(out).(type) {
+	case string, Result, *Result, ContentBlock:
+		return v, nil
+	}
+
+	encoded, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// Validate checks if the tool is valid
+func (t *TypedTool[In, Out]) Validate() error {
+	if t.name == "" {
+		return fmt.Errorf("tool name cannot be empty")
+	}
+
+	if t.fn == nil {
+		return fmt.Errorf("invalid function")
+	}
+
+	return nil
+}
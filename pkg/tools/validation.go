@@ -0,0 +1,268 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ValidationIssue describes one problem with a tool call's arguments
+type ValidationIssue struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError reports arguments that don't match a tool's schema. Its
+// message is written for the model so it can correct the call.
+type ValidationError struct {
+	Tool   string            `json:"tool"`
+	Issues []ValidationIssue `json:"issues"`
+}
+
+func (e *ValidationError) Error() string {
+	issues := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		issues[i] = fmt.Sprintf("%s %s", issue.Field, issue.Message)
+	}
+	return fmt.Sprintf("invalid arguments for %s: %s", e.Tool, strings.Join(issues, "; "))
+}
+
+// ValidateArguments checks args against the tool's schema: required fields,
+// unknown fields, JSON types, enums and numeric ranges. It returns a
+// *ValidationError listing every issue, or nil.
+func ValidateArguments(tool Tool, args map[string]interface{}) error {
+	schema := tool.Schema()
+	var issues []ValidationIssue
+
+	for _, name := range schema.Required {
+		if _, ok := args[name]; !ok {
+			issues = append(issues, ValidationIssue{Field: name, Message: "is required"})
+		}
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop, ok := schema.Properties[name]
+		if !ok {
+			issues = append(issues, ValidationIssue{Field: name, Message: "is not a known parameter"})
+			continue
+		}
+		// Optional parameters may be passed as null
+		if args[name] == nil && !contains(schema.Required, name) {
+			continue
+		}
+		issues = append(issues, validateValue(name, prop, args[name])...)
+	}
+
+	if len(issues) == 0 {
+		return nil
+	}
+	return &ValidationError{Tool: tool.Name(), Issues: issues}
+}
+
+// validateValue checks a single argument against its property schema
+func validateValue(field string, prop PropertySchema, value interface{}) []ValidationIssue {
+	if value == nil {
+		return []ValidationIssue{{Field: field, Message: "must not be null"}}
+	}
+
+	if !matchesType(prop.Type, value) {
+		return []ValidationIssue{{Field: field, Message: fmt.Sprintf("must be of type %s, got %s", prop.Type, jsonTypeOf(value))}}
+	}
+
+	var issues []ValidationIssue
+
+	if len(prop.Enum) > 0 && !inEnum(prop.Enum, value) {
+		allowed := make([]string, len(prop.Enum))
+		for i, v := range prop.Enum {
+			allowed[i] = fmt.Sprintf("%v", v)
+		}
+		issues = append(issues, ValidationIssue{Field: field, Message: fmt.Sprintf("must be one of [%s]", strings.Join(allowed, ", "))})
+	}
+
+	if n, ok := toFloat(value); ok {
+		if prop.Minimum != nil && n < *prop.Minimum {
+			issues = append(issues, ValidationIssue{Field: field, Message: fmt.Sprintf("must be >= %v", *prop.Minimum)})
+		}
+		if prop.Maximum != nil && n > *prop.Maximum {
+			issues = append(issues, ValidationIssue{Field: field, Message: fmt.Sprintf("must be <= %v", *prop.Maximum)})
+		}
+	}
+
+	if str, ok := value.(string); ok {
+		if prop.Pattern != "" {
+			if re, err := regexp.Compile(prop.Pattern); err == nil && !re.MatchString(str) {
+				issues = append(issues, ValidationIssue{Field: field, Message: fmt.Sprintf("must match pattern %s", prop.Pattern)})
+			}
+		}
+		if prop.Format != "" && !matchesFormat(prop.Format, str) {
+			issues = append(issues, ValidationIssue{Field: field, Message: fmt.Sprintf("must be a valid %s", prop.Format)})
+		}
+	}
+
+	return append(issues, validateNested(field, prop, value)...)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// matchesFormat checks the well-known string formats; unknown formats pass
+func matchesFormat(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "email":
+		_, err := mail.ParseAddress(value)
+		return err == nil
+	case "uri":
+		u, err := url.ParseRequestURI(value)
+		return err == nil && u.Scheme != ""
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	default:
+		return true
+	}
+}
+
+// validateNested checks array elements and object fields of decoded JSON
+func validateNested(field string, prop PropertySchema, value interface{}) []ValidationIssue {
+	var issues []ValidationIssue
+
+	switch v := value.(type) {
+	case []interface{}:
+		if prop.Items == nil {
+			return nil
+		}
+		for i, item := range v {
+			issues = append(issues, validateValue(fmt.Sprintf("%s[%d]", field, i), *prop.Items, item)...)
+		}
+	case map[string]interface{}:
+		for _, name := range prop.Required {
+			if _, ok := v[name]; !ok {
+				issues = append(issues, ValidationIssue{Field: field + "." + name, Message: "is required"})
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if v[key] == nil && !contains(prop.Required, key) {
+				continue
+			}
+			if child, ok := prop.Properties[key]; ok {
+				issues = append(issues, validateValue(field+"."+key, child, v[key])...)
+			} else if prop.AdditionalProperties != nil {
+				issues = append(issues, validateValue(field+"."+key, *prop.AdditionalProperties, v[key])...)
+			}
+		}
+	}
+
+	return issues
+}
+
+// matchesType reports whether value decoded from JSON has the schema type
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "", "any":
+		return true
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		n, ok := toFloat(value)
+		return ok && n == math.Trunc(n)
+	case "array":
+		kind := reflect.TypeOf(value).Kind()
+		return kind == reflect.Slice || kind == reflect.Array
+	case "object":
+		kind := reflect.TypeOf(value).Kind()
+		return kind == reflect.Map || kind == reflect.Struct
+	default:
+		return true
+	}
+}
+
+// jsonTypeOf names the JSON type of a decoded value
+func jsonTypeOf(value interface{}) string {
+	if _, ok := toFloat(value); ok {
+		return "number"
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return reflect.TypeOf(value).String()
+	}
+}
+
+// toFloat converts any numeric value to float64
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// inEnum reports whether value equals one of the allowed values, comparing
+// numbers by value so 1 matches 1.0
+func inEnum(allowed []interface{}, value interface{}) bool {
+	n, numeric := toFloat(value)
+	for _, candidate := range allowed {
+		if numeric {
+			if c, ok := toFloat(candidate); ok && c == n {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package tracing
+
+import "context"
+
+// spanKey is the context key for the active span
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span as the active span, so
+// spans started from it become its children
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the active span in ctx, or nil
+func SpanFromContext(ctx context.Context) Span {
+	span, _ := ctx.Value(spanKey{}).(Span)
+	return span
+}
@@ -0,0 +1,259 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileTracer appends every finished span to a file as NDJSON, one
+// RecordedSpan per line. Pair it with agents.WithTraceContent(true) to also
+// capture messages, tool arguments and results.
+type FileTracer struct {
+	mu     sync.Mutex
+	file   *os.File
+	enc    *json.Encoder
+	logger *slog.Logger
+}
+
+// FileTracerOption configures a FileTracer
+type FileTracerOption func(*FileTracer)
+
+// WithFileTracerLogger sets the logger for spans that fail to be written,
+// slog.Default() if unset
+func WithFileTracerLogger(logger *slog.Logger) FileTracerOption {
+	return func(t *FileTracer) {
+		t.logger = logger
+	}
+}
+
+// NewFileTracer opens path for appending, creating it if needed
+func NewFileTracer(path string, opts ...FileTracerOption) (*FileTracer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %w", err)
+	}
+
+	t := &FileTracer{
+		file:   file,
+		enc:    json.NewEncoder(file),
+		logger: slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t, nil
+}
+
+// StartSpan starts a span as a child of the span active in ctx, if any
+func (t *FileTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	span := newRecordedSpan(ctx, name)
+	return ContextWithSpan(ctx, span), span
+}
+
+// EndSpan ends the span and writes it to the file
+func (t *FileTracer) EndSpan(span Span) {
+	span.End()
+
+	recorded, ok := span.(*RecordedSpan)
+	if !ok {
+		return
+	}
+
+	recorded.mu.Lock()
+	defer recorded.mu.Unlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.enc.Encode(recorded); err != nil {
+		t.logger.Error("failed to write span", "span", recorded.Name, "error", err)
+	}
+}
+
+// Close closes the trace file
+func (t *FileTracer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.file.Close()
+}
+
+// LoadTrace reads spans written by FileTracer, sorted by start time
+func LoadTrace(r io.Reader) ([]*RecordedSpan, error) {
+	var spans []*RecordedSpan
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		span := &RecordedSpan{}
+		if err := json.Unmarshal(scanner.Bytes(), span); err != nil {
+			return nil, fmt.Errorf("invalid span on line %d: %w", line, err)
+		}
+		spans = append(spans, span)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].StartTime.Before(spans[j].StartTime)
+	})
+
+	return spans, nil
+}
+
+// Traces groups spans by trace ID, in order of each trace's first span
+func Traces(spans []*RecordedSpan) [][]*RecordedSpan {
+	var order []string
+	byTrace := make(map[string][]*RecordedSpan)
+	for _, span := range spans {
+		if _, ok := byTrace[span.TraceID]; !ok {
+			order = append(order, span.TraceID)
+		}
+		byTrace[span.TraceID] = append(byTrace[span.TraceID], span)
+	}
+
+	traces := make([][]*RecordedSpan, len(order))
+	for i, id := range order {
+		traces[i] = byTrace[id]
+	}
+	return traces
+}
+
+// contentAttributes hold message payloads; Render leaves them to Replay
+var contentAttributes = map[string]bool{
+	"input":           true,
+	"history":         true,
+	"response":        true,
+	"requested_tools": true,
+	"arguments":       true,
+	"result":          true,
+	"final_output":    true,
+}
+
+// Render writes spans as an indented tree with durations, errors and
+// non-content attributes
+func Render(w io.Writer, spans []*RecordedSpan) {
+	children := make(map[string][]*RecordedSpan)
+	known := make(map[string]bool)
+	for _, span := range spans {
+		known[span.SpanID] = true
+	}
+
+	var roots []*RecordedSpan
+	for _, span := range spans {
+		if span.ParentID == "" || !known[span.ParentID] {
+			roots = append(roots, span)
+			continue
+		}
+		children[span.ParentID] = append(children[span.ParentID], span)
+	}
+
+	var render func(span *RecordedSpan, depth int)
+	render = func(span *RecordedSpan, depth int) {
+		fmt.Fprintf(w, "%s%s (%s)", strings.Repeat("  ", depth), span.Name, span.Duration().Round(time.Millisecond))
+
+		keys := make([]string, 0, len(span.Attributes))
+		for key := range span.Attributes {
+			if !contentAttributes[key] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(w, " %s=%v", key, span.Attributes[key])
+		}
+
+		if span.Error != "" {
+			fmt.Fprintf(w, " error=%q", span.Error)
+		}
+		fmt.Fprintln(w)
+
+		for _, child := range children[span.SpanID] {
+			render(child, depth+1)
+		}
+	}
+
+	for _, root := range roots {
+		render(root, 0)
+	}
+}
+
+// Replay writes the conversation captured in spans step by step: the run
+// input, each model response, each tool call with its arguments and result,
+// and the final output. Spans recorded without trace content only show
+// their names.
+func Replay(w io.Writer, spans []*RecordedSpan) {
+	for _, trace := range Traces(spans) {
+		var run *RecordedSpan
+
+		for _, span := range trace {
+			switch span.Name {
+			case "agent.run":
+				run = span
+				fmt.Fprintf(w, "=== run agent=%v trace=%s\n", span.Attributes["agent"], span.TraceID)
+				if history, ok := span.Attributes["history"]; ok {
+					fmt.Fprintf(w, "history: %s\n", compactJSON(history))
+				}
+				if input, ok := span.Attributes["input"]; ok {
+					fmt.Fprintf(w, "user: %v\n", input)
+				}
+			case "agent.turn":
+				fmt.Fprintf(w, "--- turn %v (%v)\n", span.Attributes["turn"], span.Attributes["agent"])
+			case "provider.complete":
+				if span.Error != "" {
+					fmt.Fprintf(w, "assistant [%v]: error: %s\n", span.Attributes["model"], span.Error)
+				} else if response, ok := span.Attributes["response"]; ok {
+					fmt.Fprintf(w, "assistant [%v]: %s\n", span.Attributes["model"], compactJSON(response))
+					if requested, ok := span.Attributes["requested_tools"]; ok {
+						fmt.Fprintf(w, "requested tools: %s\n", compactJSON(requested))
+					}
+				}
+			case "tool.execute":
+				fmt.Fprintf(w, "tool %v(%s)", span.Attributes["tool"], compactJSON(span.Attributes["arguments"]))
+				if span.Error != "" {
+					fmt.Fprintf(w, " -> error: %s\n", span.Error)
+				} else {
+					fmt.Fprintf(w, " -> %s\n", compactJSON(span.Attributes["result"]))
+				}
+			case "agent.handoff":
+				fmt.Fprintf(w, "handoff %v -> %v\n", span.Attributes["from"], span.Attributes["to"])
+			}
+		}
+
+		if run == nil {
+			continue
+		}
+		if output, ok := run.Attributes["final_output"]; ok {
+			fmt.Fprintf(w, "final: %s\n", compactJSON(output))
+		}
+		if run.Error != "" {
+			fmt.Fprintf(w, "failed: %s\n", run.Error)
+		}
+	}
+}
+
+// compactJSON renders v on one line
+func compactJSON(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
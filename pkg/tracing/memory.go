@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// InMemoryTracer records spans and their parent/child relationships, for
+// tests, debugging, and exporters that run after the fact
+type InMemoryTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// NewInMemoryTracer creates an empty in-memory tracer
+func NewInMemoryTracer() *InMemoryTracer {
+	return &InMemoryTracer{}
+}
+
+// StartSpan starts a span as a child of the span active in ctx, if any
+func (t *InMemoryTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	span := newRecordedSpan(ctx, name)
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// EndSpan ends the given span
+func (t *InMemoryTracer) EndSpan(span Span) {
+	span.End()
+}
+
+// Spans returns every span recorded so far, in start order
+func (t *InMemoryTracer) Spans() []*RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	spans := make([]*RecordedSpan, len(t.spans))
+	copy(spans, t.spans)
+	return spans
+}
+
+// Reset discards all recorded spans
+func (t *InMemoryTracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.spans = nil
+}
+
+// newRecordedSpan starts a span as a child of the RecordedSpan active in ctx
+func newRecordedSpan(ctx context.Context, name string) *RecordedSpan {
+	span := &RecordedSpan{
+		Name:       name,
+		SpanID:     randomHex(8),
+		StartTime:  time.Now(),
+		Attributes: make(map[string]interface{}),
+	}
+
+	if parent, ok := SpanFromContext(ctx).(*RecordedSpan); ok {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+	} else {
+		span.TraceID = randomHex(16)
+	}
+
+	return span
+}
+
+// RecordedSpan is a span captured by InMemoryTracer or FileTracer
+type RecordedSpan struct {
+	mu sync.Mutex
+
+	Name       string                 `json:"name"`
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	StartTime  time.Time              `json:"start_time"`
+	EndTime    time.Time              `json:"end_time,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+func (s *RecordedSpan) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Attributes[key] = value
+}
+
+func (s *RecordedSpan) SetError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Error = err.Error()
+}
+
+func (s *RecordedSpan) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.EndTime.IsZero() {
+		s.EndTime = time.Now()
+	}
+}
+
+// Duration returns how long the span ran (zero until it ends)
+func (s *RecordedSpan) Duration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.EndTime.IsZero() {
+		return 0
+	}
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// randomHex returns n random bytes hex-encoded
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// TraceparentHeader is the W3C Trace Context request header
+const TraceparentHeader = "traceparent"
+
+// SpanContext identifies a span across process boundaries
+type SpanContext struct {
+	// TraceID is 32 lowercase hex characters
+	TraceID string `json:"trace_id"`
+
+	// SpanID is 16 lowercase hex characters
+	SpanID string `json:"span_id"`
+}
+
+// IsValid reports whether both IDs have the W3C length and aren't all zeros
+func (sc SpanContext) IsValid() bool {
+	return validHexID(sc.TraceID, 32) && validHexID(sc.SpanID, 16)
+}
+
+// Traceparent formats the span context as a traceparent header value. Spans
+// are always reported as sampled, since the SDK records every span.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-01", sc.TraceID, sc.SpanID)
+}
+
+// validHexID checks a W3C trace or span ID
+func validHexID(id string, length int) bool {
+	if len(id) != length {
+		return false
+	}
+	nonzero := false
+	for _, c := range id {
+		switch {
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'f':
+		default:
+			return false
+		}
+		if c != '0' {
+			nonzero = true
+		}
+	}
+	return nonzero
+}
+
+// SpanContextCarrier is implemented by spans that can report their
+// identifiers for propagation. Tracers bridging to OpenTelemetry should
+// return the underlying span's IDs.
+type SpanContextCarrier interface {
+	SpanContext() SpanContext
+}
+
+// SpanContext implements SpanContextCarrier
+func (s *RecordedSpan) SpanContext() SpanContext {
+	return SpanContext{TraceID: s.TraceID, SpanID: s.SpanID}
+}
+
+// SpanContextFromContext returns the context of the span active in ctx,
+// reporting false when there is none or it can't be propagated
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	carrier, ok := SpanFromContext(ctx).(SpanContextCarrier)
+	if !ok {
+		return SpanContext{}, false
+	}
+	sc := carrier.SpanContext()
+	return sc, sc.IsValid()
+}
+
+// InjectHeaders sets the traceparent header for the span active in ctx,
+// leaving headers unchanged when there is none
+func InjectHeaders(ctx context.Context, headers http.Header) {
+	if sc, ok := SpanContextFromContext(ctx); ok {
+		headers.Set(TraceparentHeader, sc.Traceparent())
+	}
+}
+
+// InjectHTTP sets the traceparent header on req for the span active in its
+// context, so the downstream service's trace joins the agent's. Tools pass
+// the context they were called with:
+//
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+//	tracing.InjectHTTP(req)
+func InjectHTTP(req *http.Request) {
+	InjectHeaders(req.Context(), req.Header)
+}
+
+// Transport wraps base (http.DefaultTransport when nil) to inject the
+// traceparent header into every request made with a span in its context
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &propagatingTransport{base: base}
+}
+
+// NewHTTPClient returns a client whose requests carry the traceparent
+// header of the span in their context
+func NewHTTPClient() *http.Client {
+	return &http.Client{Transport: Transport(nil)}
+}
+
+type propagatingTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper, cloning the request before adding
+// the header as RoundTrippers must not modify their input
+func (t *propagatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	sc, ok := SpanContextFromContext(req.Context())
+	if !ok || req.Header.Get(TraceparentHeader) != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Header.Set(TraceparentHeader, sc.Traceparent())
+	return t.base.RoundTrip(clone)
+}